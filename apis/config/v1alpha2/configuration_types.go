@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha2
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 )
@@ -43,8 +45,262 @@ type Configuration struct {
 	// unsuspended, they will start immediately.
 	ManageJobsWithoutQueueName bool `json:"manageJobsWithoutQueueName"`
 
+	// RequireExistingLocalQueue controls whether or not Kueue rejects the
+	// creation of batch/v1.Jobs whose kueue.x-k8s.io/queue-name annotation
+	// refers to a LocalQueue that doesn't exist in the namespace of the job.
+	// Defaults to false; therefore, those jobs are created and their workloads
+	// stay pending until the LocalQueue is created.
+	RequireExistingLocalQueue bool `json:"requireExistingLocalQueue,omitempty"`
+
+	// JobGatingOnly, when true, makes Kueue only run the Job controller and
+	// the webhooks: the Jobs are suspended until their Workloads are
+	// admitted, but Kueue doesn't queue nor admit the Workloads. An external
+	// system admits them by setting .spec.admission.
+	// Defaults to false.
+	JobGatingOnly bool `json:"jobGatingOnly,omitempty"`
+
+	// RequeueOnNodeFailure controls whether Kueue evicts the admitted
+	// workloads whose Jobs lose pods because their nodes aren't ready, and
+	// queues them again ahead of the pending workloads with the same
+	// priority, so that they can be admitted again, possibly in other
+	// flavors. Watching the pods requires a restart to take effect.
+	// Defaults to false.
+	RequeueOnNodeFailure bool `json:"requeueOnNodeFailure,omitempty"`
+
 	// InternalCertManagement is configuration for internalCertManagement
 	InternalCertManagement *InternalCertManagement `json:"internalCertManagement,omitempty"`
+
+	// SecureMetrics, when set, makes Kueue serve the metrics over HTTPS at
+	// metrics.bindAddress, instead of over plain HTTP, optionally
+	// authorizing the requests, so that the metrics can be exposed without a
+	// kube-rbac-proxy sidecar.
+	SecureMetrics *SecureMetrics `json:"secureMetrics,omitempty"`
+
+	// InitContainersAccounting controls how the requests of init containers
+	// count towards the quota used by a workload. Possible values are:
+	// - Max: the requests of a pod are the maximum between the sum of the
+	//   requests of its containers and the requests of each init container.
+	// - Ignore: the requests of init containers are not accounted for. This
+	//   is useful when init containers only use resources briefly, like image
+	//   warmers.
+	// Defaults to Max.
+	InitContainersAccounting InitContainersAccounting `json:"initContainersAccounting,omitempty"`
+
+	// NodeCapacityCheck controls whether Kueue verifies, before admitting a
+	// workload, that each of its pods fits in the allocatable resources of at
+	// least one schedulable node with the labels of the assigned flavors.
+	// Workloads that can't fit in any node are left inadmissible, instead of
+	// being admitted with pods that can never be scheduled.
+	// Defaults to false.
+	NodeCapacityCheck bool `json:"nodeCapacityCheck,omitempty"`
+
+	// RequeuingTimestamp defines the timestamp used to order workloads that
+	// are queued again after being evicted, for example, when an admission
+	// check asks to retry. Possible values are:
+	// - Eviction: the time of the last eviction. Evicted workloads are put
+	//   behind the workloads that were already pending.
+	// - Creation: the creation time of the workload. Evicted workloads keep
+	//   their original position in the queue.
+	// Defaults to Eviction.
+	RequeuingTimestamp RequeuingTimestamp `json:"requeuingTimestamp,omitempty"`
+
+	// LocalQueueMetrics controls whether Kueue reports metrics for each
+	// LocalQueue: the number of pending and admitted workloads and the
+	// resources they use. The labels of these metrics include the name and
+	// namespace of the LocalQueues, so their cardinality can be large.
+	// Defaults to false.
+	LocalQueueMetrics bool `json:"localQueueMetrics,omitempty"`
+
+	// LocalQueueConsumptionUpdatePeriod is how often Kueue updates, in the
+	// status of each LocalQueue, the resources consumed by its workloads while
+	// they were admitted. The consumption can be exported for chargeback.
+	// Defaults to nil; therefore, the consumption isn't reported.
+	LocalQueueConsumptionUpdatePeriod *metav1.Duration `json:"localQueueConsumptionUpdatePeriod,omitempty"`
+
+	// FairSharing, when set, makes Kueue order the pending workloads of each
+	// ClusterQueue that have the same priority fairly among its LocalQueues,
+	// according to their weights, instead of in FIFO order.
+	FairSharing *FairSharing `json:"fairSharing,omitempty"`
+
+	// MetadataPropagation controls which labels and annotations are copied
+	// from the jobs to their workloads when the workloads are created, so that
+	// they can be queried on the workloads or used by external admission
+	// checks.
+	MetadataPropagation *MetadataPropagation `json:"metadataPropagation,omitempty"`
+
+	// ReportedWorkloadLabels are the keys of the workload labels that Kueue
+	// attaches to the admission and eviction events of the workloads, as
+	// annotations, and uses as labels of the admitted_workloads_by_labels_total
+	// and evicted_workloads_by_labels_total metrics. Only set labels with a
+	// small number of distinct values, like a team name, to keep the
+	// cardinality of the metrics low.
+	// Defaults to empty; therefore, the metrics aren't reported.
+	ReportedWorkloadLabels []string `json:"reportedWorkloadLabels,omitempty"`
+
+	// AuditSink, when set, makes Kueue record every admission and eviction of
+	// a workload, with the change in the quota used in the ClusterQueue, so
+	// that the usage of the quota over time can be reconstructed.
+	AuditSink *AuditSink `json:"auditSink,omitempty"`
+
+	// Sharding, when set, splits the cohorts among several instances of the
+	// manager, each scheduling the ClusterQueues of the cohorts of its shard,
+	// for installations with too many ClusterQueues for a single scheduler.
+	// All the instances use the same configuration, except for the index of
+	// the shard, and each of them elects its own leader.
+	Sharding *Sharding `json:"sharding,omitempty"`
+
+	// StarvationWatchdog, when set, makes Kueue detect the pending workloads
+	// that are starving: they were evaluated for admission after being
+	// pending for longer than a threshold, while other workloads were
+	// admitted in their cohort. Kueue records a Starving event for them and
+	// counts them in the starving_workloads_total metric.
+	StarvationWatchdog *StarvationWatchdog `json:"starvationWatchdog,omitempty"`
+
+	// AdmissionConcurrency is the maximum number of admissions that Kueue
+	// applies in the apiserver at the same time. The admissions that fail with
+	// transient errors, such as throttling, are retried with backoff.
+	// Defaults to 10.
+	AdmissionConcurrency int32 `json:"admissionConcurrency,omitempty"`
+
+	// ResourceUnits overrides, for some resources, the units in which Kueue
+	// accounts for their quantities, and the format in which it reports
+	// them, for example, in the status of the ClusterQueues.
+	ResourceUnits []ResourceUnit `json:"resourceUnits,omitempty"`
+
+	// RequestsTransformer is the name of the transformer, registered in the
+	// binary with workload.RegisterRequestsTransformer, that translates the
+	// requests of the pods of the workloads before they are checked against
+	// the quota. No transformer is used if empty.
+	RequestsTransformer string `json:"requestsTransformer,omitempty"`
+
+	// WorkloadRetention, when set, makes Kueue delete the finished workloads
+	// after a retention period, which can be different for the workloads
+	// that failed, for example, because their Job exhausted its
+	// backoffLimit.
+	WorkloadRetention *WorkloadRetention `json:"workloadRetention,omitempty"`
+}
+
+type WorkloadRetention struct {
+	// AfterSucceeded is how long a workload that finished successfully is
+	// kept.
+	// Defaults to nil; therefore, the workload is kept until its job is
+	// deleted.
+	AfterSucceeded *metav1.Duration `json:"afterSucceeded,omitempty"`
+
+	// AfterFailed is how long a workload that failed is kept.
+	// Defaults to nil; therefore, the workload is kept until its job is
+	// deleted.
+	AfterFailed *metav1.Duration `json:"afterFailed,omitempty"`
+}
+
+type ResourceUnit struct {
+	// Name is the name of the resource.
+	Name corev1.ResourceName `json:"name"`
+
+	// MilliUnits controls whether the quantities of the resource are
+	// accounted for in milli-units, so that fractional quantities, like 0.5,
+	// are accurate. Otherwise, they are rounded up to whole units.
+	// Defaults to true for cpu and to false for the other resources.
+	MilliUnits *bool `json:"milliUnits,omitempty"`
+
+	// Format is the format of the reported quantities: DecimalSI, like 1G,
+	// BinarySI, like 1Gi, or DecimalExponent, like 1e9.
+	// Defaults to BinarySI for memory, ephemeral-storage and hugepages, and
+	// to DecimalSI for the other resources.
+	Format resource.Format `json:"format,omitempty"`
+}
+
+type StarvationWatchdog struct {
+	// Threshold is how long a workload can be pending before it's
+	// considered starving.
+	Threshold metav1.Duration `json:"threshold"`
+
+	// EscalatePriority controls whether the starving workloads are evaluated
+	// before the other workloads in each scheduling cycle, so that they get
+	// the quota of their cohort first.
+	// Defaults to false.
+	EscalatePriority bool `json:"escalatePriority,omitempty"`
+}
+
+type Sharding struct {
+	// Index is the index of the shard of this instance, from 0 to Count-1.
+	Index int32 `json:"index"`
+
+	// Count is the number of shards. The cohorts are assigned to the shards
+	// by the hash of their names; the ClusterQueues without a cohort, by the
+	// hash of their own names.
+	Count int32 `json:"count"`
+}
+
+// AuditSink configures where the admission decisions are recorded. Only one
+// of the fields can be set.
+type AuditSink struct {
+	// File is the path of a file where each decision is appended as a line
+	// with a JSON object. The file is created if it doesn't exist.
+	File string `json:"file,omitempty"`
+
+	// WebhookURL is the URL where each decision is sent, as a JSON object, in
+	// the body of a POST request. Decisions that fail to be delivered are
+	// logged and dropped.
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+type MetadataPropagation struct {
+	// Labels are the keys of the labels to copy. A key ending in "*" matches
+	// all the keys starting with the preceding prefix, for example,
+	// "example.com/*".
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations are the keys of the annotations to copy. A key ending in
+	// "*" matches all the keys starting with the preceding prefix.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+type FairSharing struct {
+	// UserLabel is the key of the label that identifies the user that
+	// submitted a workload. When set, the workloads of each LocalQueue are
+	// also ordered fairly among users, so that a user submitting many jobs
+	// doesn't starve the other users of the LocalQueue. The label is copied
+	// from the jobs to their workloads. Workloads without the label are
+	// ordered as if they belonged to the same user.
+	UserLabel string `json:"userLabel,omitempty"`
+}
+
+type RequeuingTimestamp string
+
+const (
+	RequeuingTimestampEviction RequeuingTimestamp = "Eviction"
+	RequeuingTimestampCreation RequeuingTimestamp = "Creation"
+)
+
+type InitContainersAccounting string
+
+const (
+	InitContainersAccountingMax    InitContainersAccounting = "Max"
+	InitContainersAccountingIgnore InitContainersAccounting = "Ignore"
+)
+
+type SecureMetrics struct {
+	// CertDir is the directory that contains the serving certificate and
+	// key. They are reloaded when they change.
+	// Defaults to /tmp/k8s-metrics-server/serving-certs.
+	CertDir string `json:"certDir,omitempty"`
+
+	// CertName is the name of the certificate file in CertDir.
+	// Defaults to tls.crt.
+	CertName string `json:"certName,omitempty"`
+
+	// KeyName is the name of the key file in CertDir.
+	// Defaults to tls.key.
+	KeyName string `json:"keyName,omitempty"`
+
+	// Authorization, when true, makes Kueue only serve the metrics to the
+	// requests with a bearer token of a user or service account that is
+	// allowed to get the /metrics non-resource URL. Kueue authenticates the
+	// tokens with TokenReviews and authorizes the users with
+	// SubjectAccessReviews.
+	// Defaults to false.
+	Authorization bool `json:"authorization,omitempty"`
 }
 
 type InternalCertManagement struct {
@@ -19,6 +19,7 @@ package v1alpha2
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
+	ctrlcfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 )
 
 const (
@@ -26,11 +27,27 @@ const (
 	DefaultWebhookServiceName     = "kueue-webhook-service"
 	DefaultWebhookSecretName      = "kueue-webhook-server-cert"
 	DefaultWebhookPort            = 9443
+	DefaultWebhookCertDir         = "/tmp/k8s-webhook-server/serving-certs"
 	DefaultHealthProbeBindAddress = ":8081"
 	DefaultMetricsBindAddress     = ":8080"
 	DefaultLeaderElectionID       = "c1f6bfd2.kueue.x-k8s.io"
+	DefaultMetricsCertDir         = "/tmp/k8s-metrics-server/serving-certs"
+	DefaultMetricsCertName        = "tls.crt"
+	DefaultMetricsKeyName         = "tls.key"
 )
 
+// defaultGroupKindConcurrency is the number of concurrent reconciles of the
+// controllers that aren't set in controller.groupKindConcurrency. The Job and
+// Workload controllers get several workers to keep up with bursts of job
+// creations.
+var defaultGroupKindConcurrency = map[string]int{
+	"Job.batch":                     5,
+	"Workload.kueue.x-k8s.io":       5,
+	"LocalQueue.kueue.x-k8s.io":     1,
+	"ClusterQueue.kueue.x-k8s.io":   1,
+	"ResourceFlavor.kueue.x-k8s.io": 1,
+}
+
 func addDefaultingFuncs(scheme *runtime.Scheme) error {
 	scheme.AddTypeDefaultingFunc(&Configuration{}, func(obj interface{}) {
 		SetDefaults_Configuration(obj.(*Configuration))
@@ -46,6 +63,9 @@ func SetDefaults_Configuration(cfg *Configuration) {
 	if cfg.Webhook.Port == nil {
 		cfg.Webhook.Port = pointer.Int(DefaultWebhookPort)
 	}
+	if cfg.Webhook.CertDir == "" {
+		cfg.Webhook.CertDir = DefaultWebhookCertDir
+	}
 	if len(cfg.Metrics.BindAddress) == 0 {
 		cfg.Metrics.BindAddress = DefaultMetricsBindAddress
 	}
@@ -56,6 +76,17 @@ func SetDefaults_Configuration(cfg *Configuration) {
 		*cfg.LeaderElection.LeaderElect && len(cfg.LeaderElection.ResourceName) == 0 {
 		cfg.LeaderElection.ResourceName = DefaultLeaderElectionID
 	}
+	if cfg.Controller == nil {
+		cfg.Controller = &ctrlcfg.ControllerConfigurationSpec{}
+	}
+	if cfg.Controller.GroupKindConcurrency == nil {
+		cfg.Controller.GroupKindConcurrency = make(map[string]int, len(defaultGroupKindConcurrency))
+	}
+	for gk, concurrency := range defaultGroupKindConcurrency {
+		if _, ok := cfg.Controller.GroupKindConcurrency[gk]; !ok {
+			cfg.Controller.GroupKindConcurrency[gk] = concurrency
+		}
+	}
 	if cfg.InternalCertManagement == nil {
 		cfg.InternalCertManagement = &InternalCertManagement{}
 	}
@@ -70,4 +101,18 @@ func SetDefaults_Configuration(cfg *Configuration) {
 			cfg.InternalCertManagement.WebhookSecretName = pointer.String(DefaultWebhookSecretName)
 		}
 	}
+	if cfg.InitContainersAccounting == "" {
+		cfg.InitContainersAccounting = InitContainersAccountingMax
+	}
+	if cfg.SecureMetrics != nil {
+		if cfg.SecureMetrics.CertDir == "" {
+			cfg.SecureMetrics.CertDir = DefaultMetricsCertDir
+		}
+		if cfg.SecureMetrics.CertName == "" {
+			cfg.SecureMetrics.CertName = DefaultMetricsCertName
+		}
+		if cfg.SecureMetrics.KeyName == "" {
+			cfg.SecureMetrics.KeyName = DefaultMetricsKeyName
+		}
+	}
 }
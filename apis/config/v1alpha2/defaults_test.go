@@ -28,15 +28,27 @@ import (
 const (
 	overwriteNamespace              = "kueue-tenant-a"
 	overwriteWebhookPort            = 9444
+	overwriteWebhookCertDir         = "/etc/kueue/webhook-certs"
 	overwriteMetricBindAddress      = ":38081"
 	overwriteHealthProbeBindAddress = ":38080"
 	overwriteLeaderElectionID       = "foo.kueue.x-k8s.io"
 )
 
 func TestSetDefaults_Configuration(t *testing.T) {
+	defaultCtrlConfigurationSpec := &ctrlconfigv1alpha1.ControllerConfigurationSpec{
+		GroupKindConcurrency: map[string]int{
+			"Job.batch":                     5,
+			"Workload.kueue.x-k8s.io":       5,
+			"LocalQueue.kueue.x-k8s.io":     1,
+			"ClusterQueue.kueue.x-k8s.io":   1,
+			"ResourceFlavor.kueue.x-k8s.io": 1,
+		},
+	}
 	defaultCtrlManagerConfigurationSpec := ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+		Controller: defaultCtrlConfigurationSpec,
 		Webhook: ctrlconfigv1alpha1.ControllerWebhook{
-			Port: pointer.Int(DefaultWebhookPort),
+			Port:    pointer.Int(DefaultWebhookPort),
+			CertDir: DefaultWebhookCertDir,
 		},
 		Metrics: ctrlconfigv1alpha1.ControllerMetrics{
 			BindAddress: DefaultMetricsBindAddress,
@@ -57,6 +69,7 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 			want: &Configuration{
+				InitContainersAccounting:           InitContainersAccountingMax,
 				Namespace:                          pointer.String(DefaultNamespace),
 				ControllerManagerConfigurationSpec: defaultCtrlManagerConfigurationSpec,
 				InternalCertManagement: &InternalCertManagement{
@@ -76,10 +89,13 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 			want: &Configuration{
-				Namespace: pointer.String(DefaultNamespace),
+				InitContainersAccounting: InitContainersAccountingMax,
+				Namespace:                pointer.String(DefaultNamespace),
 				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+					Controller: defaultCtrlConfigurationSpec,
 					Webhook: ctrlconfigv1alpha1.ControllerWebhook{
-						Port: pointer.Int(DefaultWebhookPort),
+						Port:    pointer.Int(DefaultWebhookPort),
+						CertDir: DefaultWebhookCertDir,
 					},
 					Metrics: ctrlconfigv1alpha1.ControllerMetrics{
 						BindAddress: DefaultMetricsBindAddress,
@@ -101,7 +117,8 @@ func TestSetDefaults_Configuration(t *testing.T) {
 			original: &Configuration{
 				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
 					Webhook: ctrlconfigv1alpha1.ControllerWebhook{
-						Port: pointer.Int(overwriteWebhookPort),
+						Port:    pointer.Int(overwriteWebhookPort),
+						CertDir: overwriteWebhookCertDir,
 					},
 					Metrics: ctrlconfigv1alpha1.ControllerMetrics{
 						BindAddress: overwriteMetricBindAddress,
@@ -119,10 +136,13 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 			want: &Configuration{
-				Namespace: pointer.String(DefaultNamespace),
+				InitContainersAccounting: InitContainersAccountingMax,
+				Namespace:                pointer.String(DefaultNamespace),
 				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+					Controller: defaultCtrlConfigurationSpec,
 					Webhook: ctrlconfigv1alpha1.ControllerWebhook{
-						Port: pointer.Int(overwriteWebhookPort),
+						Port:    pointer.Int(overwriteWebhookPort),
+						CertDir: overwriteWebhookCertDir,
 					},
 					Metrics: ctrlconfigv1alpha1.ControllerMetrics{
 						BindAddress: overwriteMetricBindAddress,
@@ -152,10 +172,13 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 			want: &Configuration{
-				Namespace: pointer.String(DefaultNamespace),
+				InitContainersAccounting: InitContainersAccountingMax,
+				Namespace:                pointer.String(DefaultNamespace),
 				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+					Controller: defaultCtrlConfigurationSpec,
 					Webhook: ctrlconfigv1alpha1.ControllerWebhook{
-						Port: pointer.Int(DefaultWebhookPort),
+						Port:    pointer.Int(DefaultWebhookPort),
+						CertDir: DefaultWebhookCertDir,
 					},
 					Metrics: ctrlconfigv1alpha1.ControllerMetrics{
 						BindAddress: DefaultMetricsBindAddress,
@@ -172,11 +195,54 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 		},
+		"should not override the set concurrency": {
+			original: &Configuration{
+				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+					Controller: &ctrlconfigv1alpha1.ControllerConfigurationSpec{
+						GroupKindConcurrency: map[string]int{
+							"Workload.kueue.x-k8s.io": 20,
+						},
+					},
+				},
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+			},
+			want: &Configuration{
+				InitContainersAccounting: InitContainersAccountingMax,
+				Namespace:                pointer.String(DefaultNamespace),
+				ControllerManagerConfigurationSpec: ctrlconfigv1alpha1.ControllerManagerConfigurationSpec{
+					Controller: &ctrlconfigv1alpha1.ControllerConfigurationSpec{
+						GroupKindConcurrency: map[string]int{
+							"Job.batch":                     5,
+							"Workload.kueue.x-k8s.io":       20,
+							"LocalQueue.kueue.x-k8s.io":     1,
+							"ClusterQueue.kueue.x-k8s.io":   1,
+							"ResourceFlavor.kueue.x-k8s.io": 1,
+						},
+					},
+					Webhook: ctrlconfigv1alpha1.ControllerWebhook{
+						Port:    pointer.Int(DefaultWebhookPort),
+						CertDir: DefaultWebhookCertDir,
+					},
+					Metrics: ctrlconfigv1alpha1.ControllerMetrics{
+						BindAddress: DefaultMetricsBindAddress,
+					},
+					Health: ctrlconfigv1alpha1.ControllerHealth{
+						HealthProbeBindAddress: DefaultHealthProbeBindAddress,
+					},
+				},
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+			},
+		},
 		"defaulting InternalCertManagement": {
 			original: &Configuration{
 				Namespace: pointer.String(overwriteNamespace),
 			},
 			want: &Configuration{
+				InitContainersAccounting:           InitContainersAccountingMax,
 				Namespace:                          pointer.String(overwriteNamespace),
 				ControllerManagerConfigurationSpec: defaultCtrlManagerConfigurationSpec,
 				InternalCertManagement: &InternalCertManagement{
@@ -194,6 +260,7 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 			want: &Configuration{
+				InitContainersAccounting:           InitContainersAccountingMax,
 				Namespace:                          pointer.String(overwriteNamespace),
 				ControllerManagerConfigurationSpec: defaultCtrlManagerConfigurationSpec,
 				InternalCertManagement: &InternalCertManagement{
@@ -201,6 +268,45 @@ func TestSetDefaults_Configuration(t *testing.T) {
 				},
 			},
 		},
+		"defaulting SecureMetrics": {
+			original: &Configuration{
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+				SecureMetrics: &SecureMetrics{
+					KeyName: "key.pem",
+				},
+			},
+			want: &Configuration{
+				InitContainersAccounting:           InitContainersAccountingMax,
+				Namespace:                          pointer.String(DefaultNamespace),
+				ControllerManagerConfigurationSpec: defaultCtrlManagerConfigurationSpec,
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+				SecureMetrics: &SecureMetrics{
+					CertDir:  DefaultMetricsCertDir,
+					CertName: DefaultMetricsCertName,
+					KeyName:  "key.pem",
+				},
+			},
+		},
+		"should not default InitContainersAccounting": {
+			original: &Configuration{
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+				InitContainersAccounting: InitContainersAccountingIgnore,
+			},
+			want: &Configuration{
+				Namespace:                          pointer.String(DefaultNamespace),
+				ControllerManagerConfigurationSpec: defaultCtrlManagerConfigurationSpec,
+				InternalCertManagement: &InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+				InitContainersAccounting: InitContainersAccountingIgnore,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
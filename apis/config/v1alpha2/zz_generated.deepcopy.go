@@ -22,9 +22,25 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSink) DeepCopyInto(out *AuditSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSink.
+func (in *AuditSink) DeepCopy() *AuditSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Configuration) DeepCopyInto(out *Configuration) {
 	*out = *in
@@ -40,6 +56,58 @@ func (in *Configuration) DeepCopyInto(out *Configuration) {
 		*out = new(InternalCertManagement)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SecureMetrics != nil {
+		in, out := &in.SecureMetrics, &out.SecureMetrics
+		*out = new(SecureMetrics)
+		**out = **in
+	}
+	if in.LocalQueueConsumptionUpdatePeriod != nil {
+		in, out := &in.LocalQueueConsumptionUpdatePeriod, &out.LocalQueueConsumptionUpdatePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FairSharing != nil {
+		in, out := &in.FairSharing, &out.FairSharing
+		*out = new(FairSharing)
+		**out = **in
+	}
+	if in.MetadataPropagation != nil {
+		in, out := &in.MetadataPropagation, &out.MetadataPropagation
+		*out = new(MetadataPropagation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReportedWorkloadLabels != nil {
+		in, out := &in.ReportedWorkloadLabels, &out.ReportedWorkloadLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuditSink != nil {
+		in, out := &in.AuditSink, &out.AuditSink
+		*out = new(AuditSink)
+		**out = **in
+	}
+	if in.Sharding != nil {
+		in, out := &in.Sharding, &out.Sharding
+		*out = new(Sharding)
+		**out = **in
+	}
+	if in.StarvationWatchdog != nil {
+		in, out := &in.StarvationWatchdog, &out.StarvationWatchdog
+		*out = new(StarvationWatchdog)
+		**out = **in
+	}
+	if in.ResourceUnits != nil {
+		in, out := &in.ResourceUnits, &out.ResourceUnits
+		*out = make([]ResourceUnit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WorkloadRetention != nil {
+		in, out := &in.WorkloadRetention, &out.WorkloadRetention
+		*out = new(WorkloadRetention)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Configuration.
@@ -60,6 +128,21 @@ func (in *Configuration) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FairSharing) DeepCopyInto(out *FairSharing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FairSharing.
+func (in *FairSharing) DeepCopy() *FairSharing {
+	if in == nil {
+		return nil
+	}
+	out := new(FairSharing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InternalCertManagement) DeepCopyInto(out *InternalCertManagement) {
 	*out = *in
@@ -89,3 +172,119 @@ func (in *InternalCertManagement) DeepCopy() *InternalCertManagement {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPropagation) DeepCopyInto(out *MetadataPropagation) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPropagation.
+func (in *MetadataPropagation) DeepCopy() *MetadataPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceUnit) DeepCopyInto(out *ResourceUnit) {
+	*out = *in
+	if in.MilliUnits != nil {
+		in, out := &in.MilliUnits, &out.MilliUnits
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUnit.
+func (in *ResourceUnit) DeepCopy() *ResourceUnit {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceUnit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecureMetrics) DeepCopyInto(out *SecureMetrics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecureMetrics.
+func (in *SecureMetrics) DeepCopy() *SecureMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(SecureMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sharding) DeepCopyInto(out *Sharding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sharding.
+func (in *Sharding) DeepCopy() *Sharding {
+	if in == nil {
+		return nil
+	}
+	out := new(Sharding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarvationWatchdog) DeepCopyInto(out *StarvationWatchdog) {
+	*out = *in
+	out.Threshold = in.Threshold
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarvationWatchdog.
+func (in *StarvationWatchdog) DeepCopy() *StarvationWatchdog {
+	if in == nil {
+		return nil
+	}
+	out := new(StarvationWatchdog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadRetention) DeepCopyInto(out *WorkloadRetention) {
+	*out = *in
+	if in.AfterSucceeded != nil {
+		in, out := &in.AfterSucceeded, &out.AfterSucceeded
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AfterFailed != nil {
+		in, out := &in.AfterFailed, &out.AfterFailed
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadRetention.
+func (in *WorkloadRetention) DeepCopy() *WorkloadRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadRetention)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AdmissionCheckSpec defines the desired state of AdmissionCheck
+type AdmissionCheckSpec struct {
+	// controllerName identifies the controller that evaluates the check and
+	// sets its state in the Workloads, like kueue.x-k8s.io/provisioning.
+	// controllerName cannot be changed.
+	//
+	// +kubebuilder:validation:MaxLength=253
+	ControllerName string `json:"controllerName"`
+
+	// parameters is a reference to an object holding the configuration of
+	// the check. The kind of the object is specific to the controller.
+	//
+	// +optional
+	Parameters *AdmissionCheckParametersReference `json:"parameters,omitempty"`
+}
+
+type AdmissionCheckParametersReference struct {
+	// apiGroup is the group for the resource being referenced.
+	//
+	// +kubebuilder:validation:MaxLength=253
+	APIGroup string `json:"apiGroup"`
+
+	// kind is the type of the resource being referenced.
+	//
+	// +kubebuilder:validation:MaxLength=63
+	Kind string `json:"kind"`
+
+	// name is the name of the resource being referenced.
+	//
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName={ac},categories=kueue
+//+kubebuilder:printcolumn:name="Controller",JSONPath=".spec.controllerName",type=string,description="Name of the controller that evaluates the check"
+//+kubebuilder:printcolumn:name="Age",JSONPath=".metadata.creationTimestamp",type=date,description="Time this AdmissionCheck was created"
+
+// AdmissionCheck is the Schema for the admissionchecks API.
+//
+// ClusterQueues reference AdmissionChecks by name in .spec.admissionChecks.
+type AdmissionCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AdmissionCheckSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AdmissionCheckList contains a list of AdmissionCheck
+type AdmissionCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AdmissionCheck `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AdmissionCheck{}, &AdmissionCheckList{})
+}
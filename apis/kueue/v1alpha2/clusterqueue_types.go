@@ -143,6 +143,93 @@ type ClusterQueueSpec struct {
 	// Defaults to null which is a nothing selector (no namespaces eligible).
 	// If set to an empty selector `{}`, then all namespaces are eligible.
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// podOverheadPolicy indicates whether the pod overhead, defined by the
+	// RuntimeClass of the pods, counts against the quota of this ClusterQueue.
+	// Supported Policies:
+	//
+	// - Include: the pod overhead is added to the requests of the workloads.
+	// - Exclude: the pod overhead is not accounted for, for example, when it is
+	// funded from a separate system budget.
+	//
+	// +kubebuilder:default=Include
+	// +kubebuilder:validation:Enum=Include;Exclude
+	PodOverheadPolicy PodOverheadPolicy `json:"podOverheadPolicy,omitempty"`
+
+	// flavorAssignmentPolicy indicates how the flavors are assigned to the
+	// podSets of a workload admitted by this ClusterQueue.
+	// Supported Policies:
+	//
+	// - PerPodSet: each podSet, in order, is assigned the first flavor with the
+	// lowest cost that fits, given the flavors assigned to the previous podSets.
+	// - MinimizeCost: the podSets are assigned the combination of flavors that
+	// fits with the lowest total cost, even if the workload needs to borrow
+	// quota from the cohort for it. The total cost is recorded in the
+	// workload's admission.
+	//
+	// +kubebuilder:default=PerPodSet
+	// +kubebuilder:validation:Enum=PerPodSet;MinimizeCost
+	FlavorAssignmentPolicy FlavorAssignmentPolicy `json:"flavorAssignmentPolicy,omitempty"`
+
+	// admissionChecks lists the names of the admission checks that a workload
+	// needs to pass, after getting quota in this ClusterQueue, before it's
+	// allowed to start. External controllers report the state of each check
+	// in the workload's .status.admissionChecks.
+	//
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	AdmissionChecks []string `json:"admissionChecks,omitempty"`
+
+	// budget limits how much of the resources the workloads admitted by this
+	// ClusterQueue can consume over a window of time, for example, GPU-hours
+	// per week. Once the budget for a flavor of a resource is exhausted, no
+	// more workloads are admitted with that flavor until the next window
+	// starts. The workloads that are already admitted keep running.
+	//
+	// +optional
+	Budget *Budget `json:"budget,omitempty"`
+
+	// maxNamespaceUsagePercentage is the percentage of the min quota of each
+	// flavor of a resource that the workloads admitted from a single
+	// namespace can use, so that a namespace sharing this ClusterQueue with
+	// others can't consume the whole quota. If unset, the namespaces aren't
+	// limited.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxNamespaceUsagePercentage *int32 `json:"maxNamespaceUsagePercentage,omitempty"`
+}
+
+type Budget struct {
+	// window is the length of the period over which the consumption is
+	// accounted for, like 168h for a week. The consumption is reset when a
+	// window ends and the next one starts.
+	Window metav1.Duration `json:"window"`
+
+	// resources are the limits of consumption during a window, for pairs of
+	// resource and flavor of the ClusterQueue. Pairs that the ClusterQueue
+	// doesn't define are ignored.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=flavor
+	// +kubebuilder:validation:MinItems=1
+	Resources []ResourceBudget `json:"resources"`
+}
+
+type ResourceBudget struct {
+	// name of the resource. For example, cpu, memory or nvidia.com/gpu.
+	Name corev1.ResourceName `json:"name"`
+
+	// flavor is the name of the ResourceFlavor of the resource.
+	Flavor ResourceFlavorReference `json:"flavor"`
+
+	// limit is the quantity of the resource multiplied by hours that the
+	// workloads can consume during a window. For example, a limit of 100 for
+	// nvidia.com/gpu allows 100 GPU-hours.
+	Limit resource.Quantity `json:"limit"`
 }
 
 type QueueingStrategy string
@@ -159,6 +246,29 @@ const (
 	BestEffortFIFO QueueingStrategy = "BestEffortFIFO"
 )
 
+type PodOverheadPolicy string
+
+const (
+	// PodOverheadInclude means that the pod overhead counts against the quota.
+	PodOverheadInclude PodOverheadPolicy = "Include"
+
+	// PodOverheadExclude means that the pod overhead doesn't count against
+	// the quota.
+	PodOverheadExclude PodOverheadPolicy = "Exclude"
+)
+
+type FlavorAssignmentPolicy string
+
+const (
+	// FlavorAssignmentPerPodSet means that the flavors are assigned to one
+	// podSet at a time.
+	FlavorAssignmentPerPodSet FlavorAssignmentPolicy = "PerPodSet"
+
+	// FlavorAssignmentMinimizeCost means that the flavors are assigned to
+	// minimize the total cost of the workload.
+	FlavorAssignmentMinimizeCost FlavorAssignmentPolicy = "MinimizeCost"
+)
+
 type Resource struct {
 	// name of the resource. For example, cpu, memory or nvidia.com/gpu.
 	Name corev1.ResourceName `json:"name"`
@@ -184,9 +294,10 @@ type Resource struct {
 	//     quota:
 	//       min: 10
 	//
-	// The flavors are evaluated in order, selecting the first to satisfy a
-	// workload’s requirements. Also the quantities are additive, in the example
-	// above the GPU quota in total is 20 (10 k80 + 10 p100).
+	// The flavors are evaluated in order, selecting the first with the lowest
+	// cost to satisfy a workload’s requirements. Also the quantities are
+	// additive, in the example above the GPU quota in total is 20 (10 k80 + 10
+	// p100).
 	// A workload is limited to the selected type by converting the labels to a node
 	// selector that gets injected into the workload. This list can’t be empty, at
 	// least one flavor must exist.
@@ -205,6 +316,55 @@ type Flavor struct {
 
 	// quota is the limit of resource usage at a point in time.
 	Quota Quota `json:"quota"`
+
+	// cost expresses the preference for this flavor. When a workload fits in
+	// more than one flavor, it gets the flavor with the lowest cost. For
+	// codependent resources, the costs of the requested resources are added.
+	// Flavors with the same cost are preferred in the order they are listed.
+	// Defaults to 0.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Cost *int32 `json:"cost,omitempty"`
+
+	// evictionLimit is the number of times a workload can be evicted while
+	// using this flavor. Once the limit is reached, the workload is no longer
+	// assigned this flavor when it's queued again, so that it can fall back to
+	// other flavors. This is useful for flavors of preemptible capacity, like
+	// spot VMs. If null, there is no limit.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	EvictionLimit *int32 `json:"evictionLimit,omitempty"`
+
+	// namespaceReservation reserves part of the min quota of this flavor for
+	// the workloads from the namespaces that match a selector, for example,
+	// when a team funded the hardware of the flavor. The workloads from other
+	// namespaces, in this ClusterQueue or in its cohort, are only admitted in
+	// this flavor if they leave the reserved quantity unused.
+	//
+	// +optional
+	NamespaceReservation *NamespaceReservation `json:"namespaceReservation,omitempty"`
+
+	// exclusive indicates that the min quota of this flavor is never lent to
+	// the other ClusterQueues in the cohort, even if it's unused, for example,
+	// for dedicated hardware like licensed accelerators. The workloads in this
+	// ClusterQueue can still borrow the flavor from the cohort.
+	// Defaults to false.
+	//
+	// +optional
+	Exclusive *bool `json:"exclusive,omitempty"`
+}
+
+type NamespaceReservation struct {
+	// quantity is the part of the min quota of the flavor that is reserved.
+	// It must be at most the min quota.
+	Quantity resource.Quantity `json:"quantity"`
+
+	// namespaceSelector selects the namespaces whose workloads can use the
+	// reserved quantity. If set to an empty selector `{}`, all the namespaces
+	// are selected.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
 }
 
 // ResourceFlavorReference is the name of the ResourceFlavor.
@@ -243,6 +403,37 @@ type ClusterQueueStatus struct {
 	// clusterQueue and haven't finished yet.
 	// +optional
 	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// budgetUsage is the consumption of the budget during the current
+	// window, if the ClusterQueue has a budget.
+	// +optional
+	BudgetUsage *BudgetUsage `json:"budgetUsage,omitempty"`
+}
+
+type BudgetUsage struct {
+	// windowStart is when the current window started.
+	WindowStart metav1.Time `json:"windowStart"`
+
+	// consumed is the quantity of each resource and flavor multiplied by hours
+	// that the workloads consumed during the current window.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=flavor
+	// +optional
+	Consumed []ConsumedResource `json:"consumed,omitempty"`
+}
+
+type ConsumedResource struct {
+	// name of the resource.
+	Name corev1.ResourceName `json:"name"`
+
+	// flavor is the name of the ResourceFlavor of the resource.
+	Flavor ResourceFlavorReference `json:"flavor"`
+
+	// quantity is the quantity of the resource multiplied by time that was
+	// consumed.
+	Quantity resource.Quantity `json:"quantity"`
 }
 
 type UsedResources map[corev1.ResourceName]map[string]Usage
@@ -257,7 +448,7 @@ type Usage struct {
 }
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:scope=Cluster,shortName={cq}
+//+kubebuilder:resource:scope=Cluster,shortName={cq},categories=kueue
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Cohort",JSONPath=".spec.cohort",type=string,description="Cohort that this ClusterQueue belongs to"
 //+kubebuilder:printcolumn:name="Strategy",JSONPath=".spec.queueingStrategy",type=string,description="The queueing strategy used to prioritize workloads",priority=1
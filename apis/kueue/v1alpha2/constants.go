@@ -20,4 +20,11 @@ const (
 	ResourceInUseFinalizerName = "kueue.k8s.io/resource-in-use"
 
 	DefaultPodSetName = "main"
+
+	// MaxPodSets is the maximum number of podSets in a Workload.
+	MaxPodSets = 8
+
+	// MaxPodsPerWorkload is the maximum number of pods across all the
+	// podSets of a Workload.
+	MaxPodsPerWorkload = 100000
 )
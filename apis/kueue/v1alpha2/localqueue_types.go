@@ -24,6 +24,26 @@ import (
 type LocalQueueSpec struct {
 	// clusterQueue is a reference to a clusterQueue that backs this localQueue.
 	ClusterQueue ClusterQueueReference `json:"clusterQueue,omitempty"`
+
+	// weight biases how often the workloads of this localQueue are admitted
+	// with respect to the workloads of the other localQueues that point to
+	// the same clusterQueue, when fair sharing is enabled in the manager. A
+	// localQueue with weight 4 gets about 4 workloads admitted for each
+	// workload of a localQueue with weight 1. Workloads with higher priority
+	// are still admitted first.
+	// Defaults to 1.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+
+	// defaultPriorityClass is the name of the PriorityClass used for the
+	// priority of the workloads submitted to this localQueue whose pods don't
+	// set a priorityClassName. When empty, such workloads get the priority of
+	// the global default PriorityClass, if any.
+	// +optional
+	DefaultPriorityClass string `json:"defaultPriorityClass,omitempty"`
 }
 
 // ClusterQueueReference is the name of the ClusterQueue.
@@ -39,6 +59,28 @@ type LocalQueueStatus struct {
 	// admitted to a ClusterQueue and that haven't finished yet.
 	// +optional
 	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// consumption is the resources consumed by the workloads of this
+	// LocalQueue while they were admitted. It's updated periodically when the
+	// manager is configured with a localQueueConsumptionUpdatePeriod.
+	// +optional
+	Consumption *LocalQueueConsumption `json:"consumption,omitempty"`
+}
+
+type LocalQueueConsumption struct {
+	// lastUpdateTime is the time until which the consumption is accounted for.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+
+	// resources is the quantity of each resource and flavor multiplied by
+	// seconds that the workloads consumed since the consumption started to be
+	// tracked. The values only increase, so the consumption over a period is
+	// the difference between two observations.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=flavor
+	// +optional
+	Resources []ConsumedResource `json:"resources,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -46,7 +88,7 @@ type LocalQueueStatus struct {
 //+kubebuilder:printcolumn:name="ClusterQueue",JSONPath=".spec.clusterQueue",type=string,description="Backing ClusterQueue"
 //+kubebuilder:printcolumn:name="Pending Workloads",JSONPath=".status.pendingWorkloads",type=integer,description="Number of pending workloads"
 //+kubebuilder:printcolumn:name="Admitted Workloads",JSONPath=".status.admittedWorkloads",type=integer,description="Number of admitted workloads that haven't finished yet."
-//+kubebuilder:resource:shortName={queue,queues}
+//+kubebuilder:resource:shortName={queue,queues,lq},categories=kueue
 
 // LocalQueue is the Schema for the localQueues API
 type LocalQueue struct {
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReservationSpec defines the desired state of Reservation
+type ReservationSpec struct {
+	// clusterQueue is the name of the ClusterQueue whose quota is reserved.
+	ClusterQueue ClusterQueueReference `json:"clusterQueue"`
+
+	// resources is the quota held by the reservation, for pairs of resource
+	// and flavor of the ClusterQueue. Pairs that the ClusterQueue doesn't
+	// define are ignored.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=flavor
+	// +kubebuilder:validation:MinItems=1
+	Resources []ReservedResource `json:"resources"`
+
+	// startTime is the beginning of the window during which the quota is
+	// held.
+	StartTime metav1.Time `json:"startTime"`
+
+	// endTime is the end of the window during which the quota is held. It
+	// must be after startTime.
+	EndTime metav1.Time `json:"endTime"`
+
+	// leadTime is how long before startTime the quota is already held for
+	// the new workloads of the ClusterQueue, so that the workloads admitted
+	// right before the window don't still use the quota when it starts. Set
+	// it to the maximum expected run time of the workloads. Defaults to 0.
+	// +optional
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+}
+
+type ReservedResource struct {
+	// name of the resource. For example, cpu, memory or nvidia.com/gpu.
+	Name corev1.ResourceName `json:"name"`
+
+	// flavor is the name of the ResourceFlavor of the resource.
+	Flavor ResourceFlavorReference `json:"flavor"`
+
+	// quantity is the amount of the resource that is held.
+	Quantity resource.Quantity `json:"quantity"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName={rsv},categories=kueue
+//+kubebuilder:printcolumn:name="ClusterQueue",JSONPath=".spec.clusterQueue",type=string,description="Name of the ClusterQueue whose quota is reserved"
+//+kubebuilder:printcolumn:name="Start",JSONPath=".spec.startTime",type=date,description="Beginning of the reserved window"
+//+kubebuilder:printcolumn:name="End",JSONPath=".spec.endTime",type=date,description="End of the reserved window"
+
+// Reservation is the Schema for the reservations API.
+//
+// Between startTime minus leadTime and endTime, the reserved quota is
+// unavailable for the workloads of the ClusterQueue and for borrowing within
+// its cohort.
+type Reservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReservationSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReservationList contains a list of Reservation
+type ReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Reservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Reservation{}, &ReservationList{})
+}
@@ -22,7 +22,7 @@ import (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:scope=Cluster,shortName={rf}
+//+kubebuilder:resource:scope=Cluster,shortName={rf},categories=kueue
 
 // ResourceFlavor is the Schema for the resourceflavors API.
 //
@@ -41,6 +41,24 @@ type ResourceFlavor struct {
 	//
 	// +listType=atomic
 	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// nodeProvisioning indicates that the nodes of this flavor are provisioned
+	// on demand, for example, by cluster autoscaler.
+	// When set, the pods of a workload admitted to this flavor are only
+	// started once the nodes of the flavor have enough allocatable resources
+	// for the workload, or when the timeout expires.
+	//
+	// +optional
+	NodeProvisioning *NodeProvisioning `json:"nodeProvisioning,omitempty"`
+}
+
+type NodeProvisioning struct {
+	// timeout is the maximum time to wait for the nodes after the workload is
+	// admitted. Once it expires, the pods of the workload are started anyway.
+	// Defaults to 5 minutes.
+	//
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -26,6 +26,7 @@ type WorkloadSpec struct {
 	// podSets is a list of sets of homogeneous pods, each described by a Pod spec
 	// and a count.
 	// There must be at least one element and at most 8.
+	// The sum of the counts of the podSets must be at most 100000.
 	// podSets cannot be changed.
 	//
 	// +listType=map
@@ -54,8 +55,51 @@ type WorkloadSpec struct {
 	// The higher the value, the higher the priority.
 	// If priorityClassName is specified, priority must not be null.
 	Priority *int32 `json:"priority,omitempty"`
+
+	// podSetFlavorPolicy indicates how the flavors are assigned across podSets:
+	//
+	// - Independent: flavors are assigned to each podSet independently.
+	// - SameFlavor: all the podSets are assigned the same flavor for each
+	// resource they request. For example, if the flavors map to zones, all the
+	// pods of the workload land in the same zone.
+	//
+	// podSetFlavorPolicy cannot be changed.
+	//
+	// +kubebuilder:default=Independent
+	// +kubebuilder:validation:Enum=Independent;SameFlavor
+	PodSetFlavorPolicy PodSetFlavorPolicy `json:"podSetFlavorPolicy,omitempty"`
+
+	// active determines if a workload can be admitted into a queue.
+	// Kueue sets active to false when an admission check rejects the workload.
+	// The workload is queued again once active is set to true.
+	//
+	// +kubebuilder:default=true
+	// +optional
+	Active *bool `json:"active,omitempty"`
+
+	// activeDeadlineSeconds is the duration in seconds, counted from the first
+	// time the workload is admitted, after which the workload fails instead
+	// of being queued again when it's evicted. It's copied from the
+	// activeDeadlineSeconds of the Job.
+	// activeDeadlineSeconds cannot be changed.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
 }
 
+type PodSetFlavorPolicy string
+
+const (
+	// PodSetFlavorIndependent means that the flavors are assigned to each
+	// podSet independently.
+	PodSetFlavorIndependent PodSetFlavorPolicy = "Independent"
+
+	// PodSetFlavorSame means that all the podSets are assigned the same
+	// flavor for each resource.
+	PodSetFlavorSame PodSetFlavorPolicy = "SameFlavor"
+)
+
 type Admission struct {
 	// clusterQueue is the name of the ClusterQueue that admitted this workload.
 	ClusterQueue ClusterQueueReference `json:"clusterQueue"`
@@ -64,6 +108,11 @@ type Admission struct {
 	// +listType=map
 	// +listMapKey=name
 	PodSetFlavors []PodSetFlavors `json:"podSetFlavors"`
+
+	// cost is the total cost of the flavors assigned to the podSets, when the
+	// ClusterQueue assigns the flavors that minimize it.
+	// +optional
+	Cost *int64 `json:"cost,omitempty"`
 }
 
 type PodSetFlavors struct {
@@ -104,8 +153,171 @@ type WorkloadStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// admissionChecks hold the state of the admission checks required by the
+	// ClusterQueue that admitted the Workload.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	AdmissionChecks []AdmissionCheckState `json:"admissionChecks,omitempty"`
+
+	// assignedFlavors summarizes the flavors assigned to the workload for
+	// each resource while it's admitted, such as
+	// "cpu=on-demand,memory=on-demand". When a resource is assigned different
+	// flavors in different podSets, or in different portions of a podSet,
+	// its flavors are separated by "|".
+	//
+	// +optional
+	AssignedFlavors string `json:"assignedFlavors,omitempty"`
+
+	// requeueState holds the state of the requeuing of the workload after an
+	// admission check asked to retry.
+	//
+	// +optional
+	RequeueState *RequeueState `json:"requeueState,omitempty"`
+
+	// reclaimablePods keeps track of the number of pods, per podSet, that
+	// finished and whose resources are no longer needed by the Workload.
+	// The quota used by the Workload excludes these pods.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReclaimablePods []ReclaimablePod `json:"reclaimablePods,omitempty"`
+
+	// flavorEvictions counts, for each flavor, the number of times the
+	// workload was evicted while it was assigned the flavor for any of its
+	// resources.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	FlavorEvictions []FlavorEvictions `json:"flavorEvictions,omitempty"`
+
+	// schedulingStats records when the workload went through each stage of
+	// its admission, and how many times it was evicted.
+	//
+	// +optional
+	SchedulingStats *SchedulingStats `json:"schedulingStats,omitempty"`
+
+	// deadline is the time when the workload exceeds its
+	// activeDeadlineSeconds. It's set when the workload is first admitted.
+	//
+	// +optional
+	Deadline *metav1.Time `json:"deadline,omitempty"`
+}
+
+type SchedulingStats struct {
+	// queuedAt is the time when the workload was last queued: its creation
+	// time, or the time of its last eviction.
+	//
+	// +optional
+	QueuedAt *metav1.Time `json:"queuedAt,omitempty"`
+
+	// quotaReservedAt is the time when the workload reserved quota, since it
+	// was last queued.
+	//
+	// +optional
+	QuotaReservedAt *metav1.Time `json:"quotaReservedAt,omitempty"`
+
+	// admittedAt is the time when the workload was admitted, once all the
+	// admission checks were ready, since it was last queued.
+	//
+	// +optional
+	AdmittedAt *metav1.Time `json:"admittedAt,omitempty"`
+
+	// evictionCount is the number of times the workload was evicted.
+	//
+	// +optional
+	EvictionCount int32 `json:"evictionCount,omitempty"`
+
+	// evictions counts, for each reason, the number of times the workload
+	// was evicted.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=reason
+	Evictions []EvictionsByReason `json:"evictions,omitempty"`
 }
 
+type EvictionsByReason struct {
+	// reason is the reason of the eviction, as in the Evicted condition.
+	Reason string `json:"reason"`
+
+	// count is the number of times the workload was evicted for the reason.
+	Count int32 `json:"count"`
+}
+
+type FlavorEvictions struct {
+	// name is the name of the flavor.
+	Name ResourceFlavorReference `json:"name"`
+
+	// count is the number of times the workload was evicted while using the
+	// flavor.
+	Count int32 `json:"count"`
+}
+
+type ReclaimablePod struct {
+	// name is the PodSet name.
+	Name string `json:"name"`
+
+	// count is the number of pods of the PodSet whose resources are no
+	// longer needed.
+	// +kubebuilder:validation:Minimum=0
+	Count int32 `json:"count"`
+}
+
+type RequeueState struct {
+	// count records the number of times the workload was requeued after an
+	// admission check asked to retry.
+	//
+	// +optional
+	Count int32 `json:"count,omitempty"`
+
+	// requeueAt is the time when the workload can be queued again.
+	// The workload isn't considered for admission before this time.
+	//
+	// +optional
+	RequeueAt *metav1.Time `json:"requeueAt,omitempty"`
+}
+
+type AdmissionCheckState struct {
+	// name identifies the admission check.
+	Name string `json:"name"`
+
+	// state of the admission check, one of Pending, Ready, Retry, Rejected.
+	// +kubebuilder:validation:Enum=Pending;Ready;Retry;Rejected
+	State CheckState `json:"state"`
+
+	// lastTransitionTime is the last time the state transitioned.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// message is a human readable message indicating details about the state.
+	// +kubebuilder:validation:MaxLength=32768
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type CheckState string
+
+const (
+	// CheckStatePending means that the check is still being evaluated.
+	CheckStatePending CheckState = "Pending"
+
+	// CheckStateReady means that the check passed.
+	CheckStateReady CheckState = "Ready"
+
+	// CheckStateRetry means that the check can't pass at the moment, and
+	// the workload should be admitted again later. Kueue releases the quota
+	// of the workload and requeues it with an exponential backoff.
+	CheckStateRetry CheckState = "Retry"
+
+	// CheckStateRejected means that the check will never pass. Kueue releases
+	// the quota of the workload and deactivates it.
+	CheckStateRejected CheckState = "Rejected"
+)
+
 const (
 	// WorkloadAdmitted means that the Workload was admitted by a ClusterQueue.
 	WorkloadAdmitted = "Admitted"
@@ -113,14 +325,25 @@ const (
 	// WorkloadFinished means that the workload associated to the
 	// ResourceClaim finished running (failed or succeeded).
 	WorkloadFinished = "Finished"
+
+	// WorkloadFailed means that the workload associated to the
+	// ResourceClaim failed, for example, because its Job exhausted its
+	// backoffLimit. It's set along with WorkloadFinished.
+	WorkloadFailed = "Failed"
+
+	// WorkloadEvicted means that the Workload released the quota it had
+	// reserved. The condition is set to false when the Workload reserves quota
+	// again.
+	WorkloadEvicted = "Evicted"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Queue",JSONPath=".spec.queueName",type=string,description="Name of the queue this workload was submitted to"
 // +kubebuilder:printcolumn:name="Admitted by",JSONPath=".spec.admission.clusterQueue",type=string,description="Name of the ClusterQueue that admitted this workload"
+// +kubebuilder:printcolumn:name="Flavors",JSONPath=".status.assignedFlavors",type=string,description="Flavors assigned to this workload for each resource"
 // +kubebuilder:printcolumn:name="Age",JSONPath=".metadata.creationTimestamp",type=date,description="Time this workload was created"
-// +kubebuilder:resource:shortName={wl}
+// +kubebuilder:resource:shortName={wl},categories=kueue
 
 // Workload is the Schema for the workloads API
 type Workload struct {
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// log is for logging in this package.
+var admissionCheckLog = ctrl.Log.WithName("admission-check-webhook")
+
+type AdmissionCheckWebhook struct{}
+
+func setupWebhookForAdmissionCheck(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kueue.AdmissionCheck{}).
+		WithValidator(&AdmissionCheckWebhook{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-kueue-x-k8s-io-v1alpha2-admissioncheck,mutating=false,failurePolicy=fail,sideEffects=None,groups=kueue.x-k8s.io,resources=admissionchecks,verbs=create;update,versions=v1alpha2,name=vadmissioncheck.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &AdmissionCheckWebhook{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *AdmissionCheckWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	ac := obj.(*kueue.AdmissionCheck)
+	admissionCheckLog.V(5).Info("Validating create", "admissionCheck", klog.KObj(ac))
+	return ValidateAdmissionCheck(ac).ToAggregate()
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *AdmissionCheckWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldAC := oldObj.(*kueue.AdmissionCheck)
+	newAC := newObj.(*kueue.AdmissionCheck)
+	admissionCheckLog.V(5).Info("Validating update", "admissionCheck", klog.KObj(newAC))
+	return ValidateAdmissionCheckUpdate(newAC, oldAC).ToAggregate()
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *AdmissionCheckWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func ValidateAdmissionCheck(ac *kueue.AdmissionCheck) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(ac.Spec.ControllerName) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "controllerName"), ""))
+	}
+	return allErrs
+}
+
+func ValidateAdmissionCheckUpdate(newObj, oldObj *kueue.AdmissionCheck) field.ErrorList {
+	allErrs := ValidateAdmissionCheck(newObj)
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.ControllerName, oldObj.Spec.ControllerName, field.NewPath("spec", "controllerName"))...)
+	return allErrs
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	. "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestValidateAdmissionCheck(t *testing.T) {
+	testCases := map[string]struct {
+		check   *AdmissionCheck
+		wantErr field.ErrorList
+	}{
+		"valid check": {
+			check: makeAdmissionCheck("kueue.x-k8s.io/example"),
+		},
+		"missing controllerName": {
+			check: makeAdmissionCheck(""),
+			wantErr: field.ErrorList{
+				field.Required(field.NewPath("spec", "controllerName"), ""),
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gotErr := ValidateAdmissionCheck(tc.check)
+			if diff := cmp.Diff(tc.wantErr, gotErr, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("ValidateAdmissionCheck() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAdmissionCheckUpdate(t *testing.T) {
+	testCases := map[string]struct {
+		before, after *AdmissionCheck
+		wantErr       field.ErrorList
+	}{
+		"same controllerName": {
+			before: makeAdmissionCheck("kueue.x-k8s.io/example"),
+			after:  makeAdmissionCheck("kueue.x-k8s.io/example"),
+		},
+		"parameters can change": {
+			before: makeAdmissionCheck("kueue.x-k8s.io/example"),
+			after: func() *AdmissionCheck {
+				ac := makeAdmissionCheck("kueue.x-k8s.io/example")
+				ac.Spec.Parameters = &AdmissionCheckParametersReference{
+					APIGroup: "example.kueue.x-k8s.io",
+					Kind:     "Parameters",
+					Name:     "small",
+				}
+				return ac
+			}(),
+		},
+		"controllerName can't change": {
+			before: makeAdmissionCheck("kueue.x-k8s.io/example"),
+			after:  makeAdmissionCheck("kueue.x-k8s.io/other"),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "controllerName"), nil, ""),
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gotErr := ValidateAdmissionCheckUpdate(tc.after, tc.before)
+			if diff := cmp.Diff(tc.wantErr, gotErr, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("ValidateAdmissionCheckUpdate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func makeAdmissionCheck(controllerName string) *AdmissionCheck {
+	return &AdmissionCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "check"},
+		Spec:       AdmissionCheckSpec{ControllerName: controllerName},
+	}
+}
@@ -19,7 +19,9 @@ package webhooks
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/validation"
@@ -32,13 +34,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 var (
 	// log is for logging in this package.
 	clusterQueueLog = ctrl.Log.WithName("clusterqueue-webhook")
 
-	queueingStrategies = sets.NewString(string(kueue.StrictFIFO), string(kueue.BestEffortFIFO))
+	queueingStrategies       = sets.NewString(string(kueue.StrictFIFO), string(kueue.BestEffortFIFO))
+	podOverheadPolicies      = sets.NewString(string(kueue.PodOverheadInclude), string(kueue.PodOverheadExclude))
+	flavorAssignmentPolicies = sets.NewString(string(kueue.FlavorAssignmentPerPodSet), string(kueue.FlavorAssignmentMinimizeCost))
 )
 
 const (
@@ -105,6 +110,15 @@ func ValidateClusterQueue(cq *kueue.ClusterQueue) field.ErrorList {
 	allErrs = append(allErrs, validateResources(cq.Spec.Resources, path.Child("resources"))...)
 	allErrs = append(allErrs, validateQueueingStrategy(string(cq.Spec.QueueingStrategy), path.Child("queueingStrategy"))...)
 	allErrs = append(allErrs, validateNamespaceSelector(cq.Spec.NamespaceSelector, path.Child("namespaceSelector"))...)
+	allErrs = append(allErrs, validatePodOverheadPolicy(string(cq.Spec.PodOverheadPolicy), path.Child("podOverheadPolicy"))...)
+	allErrs = append(allErrs, validateFlavorAssignmentPolicy(string(cq.Spec.FlavorAssignmentPolicy), path.Child("flavorAssignmentPolicy"))...)
+	allErrs = append(allErrs, validateAdmissionChecks(cq.Spec.AdmissionChecks, path.Child("admissionChecks"))...)
+	if cq.Spec.Budget != nil {
+		allErrs = append(allErrs, validateBudget(cq.Spec.Budget, path.Child("budget"))...)
+	}
+	if p := cq.Spec.MaxNamespaceUsagePercentage; p != nil && (*p < 1 || *p > 100) {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxNamespaceUsagePercentage"), *p, "must be between 1 and 100"))
+	}
 
 	return allErrs
 }
@@ -128,7 +142,16 @@ func validateResources(resources []kueue.Resource, path *field.Path) field.Error
 		for j, flavor := range resource.Flavors {
 			path := path.Child("flavors").Index(j)
 			allErrs = append(allErrs, validateNameReference(string(flavor.Name), path.Child("name"))...)
-			allErrs = append(allErrs, validateFlavorQuota(flavor, path.Child("quota"))...)
+			allErrs = append(allErrs, validateFlavorQuota(resource.Name, flavor, path.Child("quota"))...)
+			if flavor.Cost != nil && *flavor.Cost < 0 {
+				allErrs = append(allErrs, field.Invalid(path.Child("cost"), *flavor.Cost, isNegativeErrorMsg))
+			}
+			if flavor.EvictionLimit != nil && *flavor.EvictionLimit < 1 {
+				allErrs = append(allErrs, field.Invalid(path.Child("evictionLimit"), *flavor.EvictionLimit, "must be greater than 0"))
+			}
+			if flavor.NamespaceReservation != nil {
+				allErrs = append(allErrs, validateNamespaceReservation(flavor, path.Child("namespaceReservation"))...)
+			}
 			flavorsPerRes[i].Insert(string(flavor.Name))
 		}
 		for j := 0; j < i; j++ {
@@ -142,12 +165,38 @@ func validateResources(resources []kueue.Resource, path *field.Path) field.Error
 	return allErrs
 }
 
-func validateFlavorQuota(flavor kueue.Flavor, path *field.Path) field.ErrorList {
+func validateBudget(budget *kueue.Budget, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if budget.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("window"), budget.Window.Duration.String(), "must be positive"))
+	}
+	for i, rb := range budget.Resources {
+		path := path.Child("resources").Index(i)
+		allErrs = append(allErrs, validateResourceName(rb.Name, path.Child("name"))...)
+		allErrs = append(allErrs, validateNameReference(string(rb.Flavor), path.Child("flavor"))...)
+		allErrs = append(allErrs, validateResourceQuantity(rb.Limit, path.Child("limit"))...)
+	}
+	return allErrs
+}
+
+func validateNamespaceReservation(flavor kueue.Flavor, path *field.Path) field.ErrorList {
+	r := flavor.NamespaceReservation
+	allErrs := validateResourceQuantity(r.Quantity, path.Child("quantity"))
+	if r.Quantity.Cmp(flavor.Quota.Min) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("quantity"), r.Quantity.String(), "must be less than or equal to the min quota"))
+	}
+	allErrs = append(allErrs, validateNamespaceSelector(&r.NamespaceSelector, path.Child("namespaceSelector"))...)
+	return allErrs
+}
+
+func validateFlavorQuota(rName corev1.ResourceName, flavor kueue.Flavor, path *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 	allErrs = append(allErrs, validateResourceQuantity(flavor.Quota.Min, path.Child("min"))...)
+	allErrs = append(allErrs, validateQuantityForResource(rName, flavor.Quota.Min, path.Child("min"))...)
 
 	if flavor.Quota.Max != nil {
 		allErrs = append(allErrs, validateResourceQuantity(*flavor.Quota.Max, path.Child("max"))...)
+		allErrs = append(allErrs, validateQuantityForResource(rName, *flavor.Quota.Max, path.Child("max"))...)
 		if flavor.Quota.Min.Cmp(*flavor.Quota.Max) > 0 {
 			allErrs = append(allErrs, field.Invalid(path.Child("min"), flavor.Quota.Min.String(), fmt.Sprintf("must be less than or equal to %s max", flavor.Name)))
 		}
@@ -155,6 +204,39 @@ func validateFlavorQuota(flavor kueue.Flavor, path *field.Path) field.ErrorList
 	return allErrs
 }
 
+// validateQuantityForResource enforces the same constraints as the pods on
+// the quantities of huge pages, which must be a multiple of the page size,
+// and of extended resources, which must be whole, unless Kueue accounts for
+// them in milli-units.
+func validateQuantityForResource(rName corev1.ResourceName, value resource.Quantity, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if strings.HasPrefix(string(rName), corev1.ResourceHugePagesPrefix) {
+		pageSize, err := resource.ParseQuantity(strings.TrimPrefix(string(rName), corev1.ResourceHugePagesPrefix))
+		if err != nil || pageSize.Sign() <= 0 {
+			return append(allErrs, field.Invalid(fldPath, value.String(), fmt.Sprintf("invalid page size in resource %s", rName)))
+		}
+		if value.MilliValue()%1000 != 0 || value.Value()%pageSize.Value() != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, value.String(), fmt.Sprintf("must be a multiple of %s", &pageSize)))
+		}
+		return allErrs
+	}
+	if isExtendedResource(rName) && workload.UnitOf(rName).Scale == 0 && value.MilliValue()%1000 != 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, value.String(), "must be an integer"))
+	}
+	return allErrs
+}
+
+// isExtendedResource returns whether the resource is an extended resource:
+// it's not native to Kubernetes, which are the resources without a domain or
+// in the kubernetes.io domain, nor a quota of requests.
+func isExtendedResource(name corev1.ResourceName) bool {
+	s := string(name)
+	if !strings.Contains(s, "/") || strings.Contains(s, corev1.ResourceDefaultNamespacePrefix) {
+		return false
+	}
+	return !strings.HasPrefix(s, corev1.DefaultResourceRequestsPrefix)
+}
+
 func matchesFlavorsInOrder(f1, f2 []kueue.Flavor) bool {
 	if len(f1) != len(f2) {
 		return false
@@ -184,6 +266,38 @@ func validateQueueingStrategy(strategy string, path *field.Path) field.ErrorList
 	return allErrs
 }
 
+func validatePodOverheadPolicy(policy string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(policy) > 0 && !podOverheadPolicies.Has(policy) {
+		allErrs = append(allErrs, field.Invalid(path, policy, fmt.Sprintf("pod overhead policy %s is not supported, available policies are %v", policy, podOverheadPolicies.List())))
+	}
+	return allErrs
+}
+
+func validateFlavorAssignmentPolicy(policy string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(policy) > 0 && !flavorAssignmentPolicies.Has(policy) {
+		allErrs = append(allErrs, field.Invalid(path, policy, fmt.Sprintf("flavor assignment policy %s is not supported, available policies are %v", policy, flavorAssignmentPolicies.List())))
+	}
+	return allErrs
+}
+
+func validateAdmissionChecks(checks []string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(checks) > 8 {
+		allErrs = append(allErrs, field.TooMany(path, len(checks), 8))
+	}
+	names := sets.NewString()
+	for i, name := range checks {
+		allErrs = append(allErrs, validateNameReference(name, path.Index(i))...)
+		if names.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), name))
+		}
+		names.Insert(name)
+	}
+	return allErrs
+}
+
 func validateNamespaceSelector(selector *metav1.LabelSelector, path *field.Path) field.ErrorList {
 	allErrs := validation.ValidateLabelSelector(selector, path)
 	return allErrs
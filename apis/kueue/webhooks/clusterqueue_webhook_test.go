@@ -19,15 +19,17 @@ package webhooks
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	testingutil "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 )
 
 func TestValidateClusterQueue(t *testing.T) {
@@ -41,14 +43,14 @@ func TestValidateClusterQueue(t *testing.T) {
 	}{
 		{
 			name: "built-in resources with qualified names",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Obj(),
 			).Obj(),
 		},
 		{
 			name: "invalid resource name",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("@cpu").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("@cpu").Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(resourceField.Index(0).Child("name"), "@cpu", ""),
@@ -56,25 +58,25 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name:         "in cohort",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Cohort("prod").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Cohort("prod").Obj(),
 		},
 		{
 			name:         "invalid cohort",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Cohort("@prod").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Cohort("@prod").Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(specField.Child("cohort"), "@prod", ""),
 			},
 		},
 		{
 			name: "extended resources with qualified names",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("example.com/gpu").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("example.com/gpu").Obj(),
 			).Obj(),
 		},
 		{
 			name: "extended resources with unqualified names",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("example.com/@gpu").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("example.com/@gpu").Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(resourceField.Index(0).Child("name"), "example.com/@gpu", ""),
@@ -82,14 +84,14 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "flavor with qualified names",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("x86", "10").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "10").Obj()).Obj(),
 			).Obj(),
 		},
 		{
 			name: "flavor with unqualified names",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("invalid_name", "10").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("invalid_name", "10").Obj()).Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(resourceField.Index(0).Child("flavors").Index(0).Child("name"), "invalid_name", ""),
@@ -97,8 +99,8 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "flavor quota with negative value",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("x86", "-1").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "-1").Obj()).Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(resourceField.Index(0).Child("flavors").Index(0).Child("quota", "min"), "-1", ""),
@@ -106,20 +108,20 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "flavor quota with zero value",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("x86", "0").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "0").Obj()).Obj(),
 			).Obj(),
 		},
 		{
 			name: "flavor quota with min is equal to max",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("x86", "1").Max("1").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "1").Max("1").Obj()).Obj(),
 			).Obj(),
 		},
 		{
 			name: "flavor quota with min is greater than max",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Resource(
-				testingutil.MakeResource("cpu").Flavor(testingutil.MakeFlavor("x86", "2").Max("1").Obj()).Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "2").Max("1").Obj()).Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(resourceField.Index(0).Child("flavors").Index(0).Child("quota", "min"), "2", ""),
@@ -127,18 +129,40 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name:         "empty queueing strategy is supported",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").Obj(),
 		},
 		{
 			name:         "unknown queueing strategy is not supported",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").QueueingStrategy("unknown").Obj(),
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").QueueingStrategy("unknown").Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(specField.Child("queueingStrategy"), "unknown", ""),
 			},
 		},
+		{
+			name:         "unknown pod overhead policy is not supported",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").PodOverheadPolicy("unknown").Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("podOverheadPolicy"), "unknown", ""),
+			},
+		},
+		{
+			name:         "unknown flavor assignment policy is not supported",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").FlavorAssignmentPolicy("unknown").Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("flavorAssignmentPolicy"), "unknown", ""),
+			},
+		},
+		{
+			name:         "admissionChecks should be valid and unique",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").AdmissionChecks("check", "@invalid", "check").Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("admissionChecks").Index(1), "@invalid", ""),
+				field.Duplicate(specField.Child("admissionChecks").Index(2), "check"),
+			},
+		},
 		{
 			name: "namespaceSelector with invalid labels",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
 				MatchLabels: map[string]string{"nospecialchars^=@": "bar"},
 			}).Obj(),
 			wantErr: field.ErrorList{
@@ -147,7 +171,7 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "namespaceSelector with invalid expressions",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
 				MatchExpressions: []metav1.LabelSelectorRequirement{
 					{
 						Key:      "key",
@@ -162,9 +186,9 @@ func TestValidateClusterQueue(t *testing.T) {
 		{
 			name: "more than 16 resources",
 			clusterQueue: func() *kueue.ClusterQueue {
-				cq := testingutil.MakeClusterQueue("cluster-queue")
+				cq := builder.MakeClusterQueue("cluster-queue")
 				for i := 0; i < 17; i++ {
-					cq.Resource(testingutil.MakeResource(corev1.ResourceName(fmt.Sprintf("r%02d", i))).Obj())
+					cq.Resource(builder.MakeResource(corev1.ResourceName(fmt.Sprintf("r%02d", i))).Obj())
 				}
 				return cq.Obj()
 			}(),
@@ -173,10 +197,10 @@ func TestValidateClusterQueue(t *testing.T) {
 		{
 			name: "more than 16 flavors",
 			clusterQueue: func() *kueue.ClusterQueue {
-				cq := testingutil.MakeClusterQueue("cluster-queue")
-				res := testingutil.MakeResource("cpu")
+				cq := builder.MakeClusterQueue("cluster-queue")
+				res := builder.MakeResource("cpu")
 				for i := 0; i < 17; i++ {
-					res.Flavor(testingutil.MakeFlavor(fmt.Sprintf("f%02d", i), "0").Obj())
+					res.Flavor(builder.MakeFlavor(fmt.Sprintf("f%02d", i), "0").Obj())
 				}
 				return cq.Resource(res.Obj()).Obj()
 			}(),
@@ -184,27 +208,27 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "multiple independent and codependent resources",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").
-				Resource(testingutil.MakeResource("cpu").
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).Obj()).
-				Resource(testingutil.MakeResource("memory").
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).Obj()).
-				Resource(testingutil.MakeResource("example.com/gpu").
-					Flavor(testingutil.MakeFlavor("gamma", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("omega", "0").Obj()).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).Obj()).
+				Resource(builder.MakeResource("memory").
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).Obj()).
+				Resource(builder.MakeResource("example.com/gpu").
+					Flavor(builder.MakeFlavor("gamma", "0").Obj()).
+					Flavor(builder.MakeFlavor("omega", "0").Obj()).Obj()).
 				Obj(),
 		},
 		{
 			name: "multiple resources with matching flavors in different order",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").
-				Resource(testingutil.MakeResource("cpu").
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).Obj()).
-				Resource(testingutil.MakeResource("memory").
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).Obj()).
+				Resource(builder.MakeResource("memory").
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).Obj()).
 				Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(specField.Child("resources").Index(1).Child("flavors"), nil, ""),
@@ -212,19 +236,121 @@ func TestValidateClusterQueue(t *testing.T) {
 		},
 		{
 			name: "multiple resources with partial flavor match",
-			clusterQueue: testingutil.MakeClusterQueue("cluster-queue").
-				Resource(testingutil.MakeResource("cpu").
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).Obj()).
-				Resource(testingutil.MakeResource("example.com/gpu").
-					Flavor(testingutil.MakeFlavor("alpha", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("beta", "0").Obj()).
-					Flavor(testingutil.MakeFlavor("omega", "0").Obj()).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).Obj()).
+				Resource(builder.MakeResource("example.com/gpu").
+					Flavor(builder.MakeFlavor("alpha", "0").Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Obj()).
+					Flavor(builder.MakeFlavor("omega", "0").Obj()).Obj()).
 				Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(specField.Child("resources").Index(1).Child("flavors"), nil, ""),
 			},
 		},
+		{
+			name: "budget",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Budget(7*24*time.Hour, kueue.ResourceBudget{
+					Name:   "example.com/gpu",
+					Flavor: "default",
+					Limit:  resource.MustParse("100"),
+				}).
+				Obj(),
+		},
+		{
+			name: "invalid budget",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Budget(0, kueue.ResourceBudget{
+					Name:   "@gpu",
+					Flavor: "default",
+					Limit:  resource.MustParse("-1"),
+				}).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("budget", "window"), nil, ""),
+				field.Invalid(specField.Child("budget", "resources").Index(0).Child("name"), nil, ""),
+				field.Invalid(specField.Child("budget", "resources").Index(0).Child("limit"), nil, ""),
+			},
+		},
+		{
+			name: "max namespace usage percentage",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				MaxNamespaceUsagePercentage(50).
+				Obj(),
+		},
+		{
+			name: "invalid max namespace usage percentage",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				MaxNamespaceUsagePercentage(101).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("maxNamespaceUsagePercentage"), nil, ""),
+			},
+		},
+		{
+			name: "flavor costs",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "0").Cost(0).Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").Cost(-1).Obj()).Obj()).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(1).Child("cost"), nil, ""),
+			},
+		},
+		{
+			name: "flavor eviction limits",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "0").EvictionLimit(0).Obj()).
+					Flavor(builder.MakeFlavor("beta", "0").EvictionLimit(3).Obj()).Obj()).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(0).Child("evictionLimit"), nil, ""),
+			},
+		},
+		{
+			name: "hugepages quotas",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource(corev1.ResourceHugePagesPrefix + "2Mi").
+					Flavor(builder.MakeFlavor("alpha", "4Mi").Max("5Mi").Obj()).Obj()).
+				Resource(builder.MakeResource(corev1.ResourceHugePagesPrefix + "1Gi").
+					Flavor(builder.MakeFlavor("beta", "2Gi").Obj()).Obj()).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(0).Child("quota", "max"), nil, ""),
+			},
+		},
+		{
+			name: "extended resource quotas",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("example.com/gpu").
+					Flavor(builder.MakeFlavor("alpha", "0.5").Obj()).Obj()).
+				Resource(builder.MakeResource("example.com/nic").
+					Flavor(builder.MakeFlavor("beta", "2").Max("4").Obj()).Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor("gamma", "0.5").Obj()).Obj()).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(0).Child("quota", "min"), nil, ""),
+			},
+		},
+		{
+			name: "flavor namespace reservations",
+			clusterQueue: builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource("cpu").
+					Flavor(builder.MakeFlavor("alpha", "10").NamespaceReservation("4", metav1.LabelSelector{}).Obj()).
+					Flavor(builder.MakeFlavor("beta", "10").NamespaceReservation("11", metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Unknown"}},
+					}).Obj()).Obj()).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(1).Child("namespaceReservation", "quantity"), nil, ""),
+				field.Invalid(specField.Child("resources").Index(0).Child("flavors").Index(1).Child("namespaceReservation", "namespaceSelector", "matchExpressions").Index(0).Child("operator"), nil, ""),
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -19,11 +19,12 @@ package webhooks
 import (
 	"context"
 
-	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
@@ -32,12 +33,18 @@ import (
 // log is for logging in this package.
 var localQueueLog = ctrl.Log.WithName("localqueue-webhook")
 
-type LocalQueueWebhook struct{}
+// workloadQueueKey is the field of the Workloads indexed by the queue manager,
+// see queue.SetupIndexes.
+const workloadQueueKey = "spec.queueName"
+
+type LocalQueueWebhook struct {
+	client client.Client
+}
 
 func setupWebhookForLocalQueue(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&kueue.LocalQueue{}).
-		WithValidator(&LocalQueueWebhook{}).
+		WithValidator(&LocalQueueWebhook{client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -52,14 +59,45 @@ func (w *LocalQueueWebhook) ValidateCreate(ctx context.Context, obj runtime.Obje
 	return ValidateLocalQueue(q).ToAggregate()
 }
 
-// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// It forbids changing the clusterQueue while the LocalQueue has workloads that
+// haven't finished, because they can't be moved to another ClusterQueue.
 func (w *LocalQueueWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
 	newQ := newObj.(*kueue.LocalQueue)
 	oldQ := oldObj.(*kueue.LocalQueue)
 	localQueueLog.V(5).Info("Validating update", "localQueue", klog.KObj(newQ))
+	if newQ.Spec.ClusterQueue != oldQ.Spec.ClusterQueue {
+		inUse, err := w.hasWorkloads(ctx, oldQ)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "clusterQueue"), "can't be changed while the LocalQueue has workloads that haven't finished"),
+			}.ToAggregate()
+		}
+	}
 	return ValidateLocalQueueUpdate(newQ, oldQ).ToAggregate()
 }
 
+// hasWorkloads returns whether the LocalQueue has workloads that haven't
+// finished.
+func (w *LocalQueueWebhook) hasWorkloads(ctx context.Context, q *kueue.LocalQueue) (bool, error) {
+	var workloads kueue.WorkloadList
+	if err := w.client.List(ctx, &workloads, client.InNamespace(q.Namespace), client.MatchingFields{workloadQueueKey: q.Name}); err != nil {
+		return false, err
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		// Indexes don't work in unit tests, so we explicitly check for the
+		// queue name here.
+		if wl.Spec.QueueName == q.Name && !apimeta.IsStatusConditionTrue(wl.Status.Conditions, kueue.WorkloadFinished) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
 func (w *LocalQueueWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
 	return nil
@@ -69,9 +107,21 @@ func ValidateLocalQueue(q *kueue.LocalQueue) field.ErrorList {
 	var allErrs field.ErrorList
 	clusterQueuePath := field.NewPath("spec", "clusterQueue")
 	allErrs = append(allErrs, validateNameReference(string(q.Spec.ClusterQueue), clusterQueuePath)...)
+	if len(q.Spec.DefaultPriorityClass) > 0 {
+		allErrs = append(allErrs, validateNameReference(q.Spec.DefaultPriorityClass, field.NewPath("spec", "defaultPriorityClass"))...)
+	}
+	if q.Spec.Weight != nil && (*q.Spec.Weight < 1 || *q.Spec.Weight > 100) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "weight"), *q.Spec.Weight, "must be between 1 and 100"))
+	}
 	return allErrs
 }
 
+// ValidateLocalQueueUpdate validates the new clusterQueue of the LocalQueue,
+// if it changed. The webhook checks separately that the LocalQueue has no
+// workloads.
 func ValidateLocalQueueUpdate(newObj, oldObj *kueue.LocalQueue) field.ErrorList {
-	return apivalidation.ValidateImmutableField(newObj.Spec.ClusterQueue, oldObj.Spec.ClusterQueue, field.NewPath("spec", "clusterQueue"))
+	if newObj.Spec.ClusterQueue == oldObj.Spec.ClusterQueue {
+		return nil
+	}
+	return validateNameReference(string(newObj.Spec.ClusterQueue), field.NewPath("spec", "clusterQueue"))
 }
@@ -17,14 +17,18 @@ limitations under the License.
 package webhooks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	testingutil "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 )
 
 const (
@@ -38,11 +42,26 @@ func TestValidateLocalQueueCreate(t *testing.T) {
 		wantErr field.ErrorList
 	}{
 		"should reject queue creation with an invalid clusterQueue": {
-			queue: testingutil.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("invalid_cluster_queue").Obj(),
+			queue: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("invalid_cluster_queue").Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(field.NewPath("spec").Child("clusterQueue"), "invalid_name", ""),
 			},
 		},
+		"should reject queue creation with an invalid defaultPriorityClass": {
+			queue: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").DefaultPriorityClass("Low").Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("defaultPriorityClass"), "Low", ""),
+			},
+		},
+		"should allow queue creation with a weight": {
+			queue: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Weight(100).Obj(),
+		},
+		"should reject queue creation with a weight out of range": {
+			queue: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Weight(0).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("weight"), int32(0), ""),
+			},
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -59,25 +78,90 @@ func TestValidateLocalQueueUpdate(t *testing.T) {
 		before, after *LocalQueue
 		wantErr       field.ErrorList
 	}{
-		"clusterQueue cannot be updated": {
-			before: testingutil.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Obj(),
-			after:  testingutil.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("bar").Obj(),
+		"clusterQueue can be updated": {
+			before: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Obj(),
+			after:  builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("bar").Obj(),
+		},
+		"clusterQueue must be a valid name": {
+			before: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Obj(),
+			after:  builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("invalid_cluster_queue").Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(field.NewPath("spec").Child("clusterQueue"), nil, ""),
 			},
 		},
 		"status could be updated": {
-			before:  testingutil.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).Obj(),
-			after:   testingutil.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).PendingWorkloads(10).Obj(),
-			wantErr: field.ErrorList{},
+			before: builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).Obj(),
+			after:  builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).PendingWorkloads(10).Obj(),
 		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			errList := ValidateLocalQueueUpdate(tc.before, tc.after)
+			errList := ValidateLocalQueueUpdate(tc.after, tc.before)
 			if diff := cmp.Diff(tc.wantErr, errList, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
 				t.Errorf("ValidateLocalQueueUpdate() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestLocalQueueWebhookValidateUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	testCases := map[string]struct {
+		workloads []*Workload
+		wantErr   bool
+	}{
+		"no workloads": {},
+		"pending workload": {
+			workloads: []*Workload{
+				builder.MakeWorkload("wl", testLocalQueueNamespace).Queue(testLocalQueueName).Obj(),
+			},
+			wantErr: true,
+		},
+		"admitted workload": {
+			workloads: []*Workload{
+				builder.MakeWorkload("wl", testLocalQueueNamespace).Queue(testLocalQueueName).Admit(builder.MakeAdmission("foo").Obj()).Obj(),
+			},
+			wantErr: true,
+		},
+		"finished workload": {
+			workloads: []*Workload{
+				func() *Workload {
+					wl := builder.MakeWorkload("wl", testLocalQueueNamespace).Queue(testLocalQueueName).Obj()
+					wl.Status.Conditions = []metav1.Condition{{Type: WorkloadFinished, Status: metav1.ConditionTrue}}
+					return wl
+				}(),
+			},
+		},
+		"workloads of other queues": {
+			workloads: []*Workload{
+				builder.MakeWorkload("wl", testLocalQueueNamespace).Queue("other").Obj(),
+				builder.MakeWorkload("wl", "other-ns").Queue(testLocalQueueName).Obj(),
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, wl := range tc.workloads {
+				clientBuilder = clientBuilder.WithObjects(wl)
+			}
+			w := &LocalQueueWebhook{client: clientBuilder.Build()}
+			before := builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("foo").Obj()
+			after := builder.MakeLocalQueue(testLocalQueueName, testLocalQueueNamespace).ClusterQueue("bar").Obj()
+			err := w.ValidateUpdate(context.Background(), before, after)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("ValidateUpdate() returned error %v, want error: %t", err, tc.wantErr)
+			}
+
+			// The status can always be updated.
+			after = before.DeepCopy()
+			after.Status.PendingWorkloads = 10
+			if err := w.ValidateUpdate(context.Background(), before, after); err != nil {
+				t.Errorf("ValidateUpdate() returned error %v when updating the status", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// log is for logging in this package.
+var reservationLog = ctrl.Log.WithName("reservation-webhook")
+
+type ReservationWebhook struct{}
+
+func setupWebhookForReservation(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kueue.Reservation{}).
+		WithValidator(&ReservationWebhook{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-kueue-x-k8s-io-v1alpha2-reservation,mutating=false,failurePolicy=fail,sideEffects=None,groups=kueue.x-k8s.io,resources=reservations,verbs=create;update,versions=v1alpha2,name=vreservation.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &ReservationWebhook{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *ReservationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	r := obj.(*kueue.Reservation)
+	reservationLog.V(5).Info("Validating create", "reservation", klog.KObj(r))
+	return ValidateReservation(r).ToAggregate()
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *ReservationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	newR := newObj.(*kueue.Reservation)
+	reservationLog.V(5).Info("Validating update", "reservation", klog.KObj(newR))
+	return ValidateReservation(newR).ToAggregate()
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *ReservationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func ValidateReservation(r *kueue.Reservation) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	allErrs = append(allErrs, validateNameReference(string(r.Spec.ClusterQueue), specPath.Child("clusterQueue"))...)
+	for i, rr := range r.Spec.Resources {
+		path := specPath.Child("resources").Index(i)
+		allErrs = append(allErrs, validateResourceName(rr.Name, path.Child("name"))...)
+		allErrs = append(allErrs, validateNameReference(string(rr.Flavor), path.Child("flavor"))...)
+		allErrs = append(allErrs, validateResourceQuantity(rr.Quantity, path.Child("quantity"))...)
+	}
+	if !r.Spec.StartTime.Before(&r.Spec.EndTime) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("endTime"), r.Spec.EndTime, "must be after startTime"))
+	}
+	if r.Spec.LeadTime != nil && r.Spec.LeadTime.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("leadTime"), r.Spec.LeadTime, "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
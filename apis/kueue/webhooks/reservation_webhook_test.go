@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	. "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+func TestValidateReservation(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	specPath := field.NewPath("spec")
+	testCases := map[string]struct {
+		reservation *Reservation
+		wantErr     field.ErrorList
+	}{
+		"valid reservation": {
+			reservation: builder.MakeReservation("maintenance", "cq", start, end).
+				Resource(corev1.ResourceCPU, "default", "10").
+				Obj(),
+		},
+		"invalid clusterQueue and flavor": {
+			reservation: builder.MakeReservation("maintenance", "invalid_cq", start, end).
+				Resource(corev1.ResourceCPU, "invalid_flavor", "10").
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specPath.Child("clusterQueue"), "invalid_cq", ""),
+				field.Invalid(specPath.Child("resources").Index(0).Child("flavor"), "invalid_flavor", ""),
+			},
+		},
+		"negative quantity": {
+			reservation: builder.MakeReservation("maintenance", "cq", start, end).
+				Resource(corev1.ResourceCPU, "default", "-1").
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specPath.Child("resources").Index(0).Child("quantity"), "-1", ""),
+			},
+		},
+		"endTime before startTime": {
+			reservation: builder.MakeReservation("maintenance", "cq", end, start).
+				Resource(corev1.ResourceCPU, "default", "10").
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specPath.Child("endTime"), nil, ""),
+			},
+		},
+		"empty window": {
+			reservation: builder.MakeReservation("maintenance", "cq", start, start).
+				Resource(corev1.ResourceCPU, "default", "10").
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specPath.Child("endTime"), nil, ""),
+			},
+		},
+		"negative leadTime": {
+			reservation: builder.MakeReservation("maintenance", "cq", start, end).
+				Resource(corev1.ResourceCPU, "default", "10").
+				LeadTime(-time.Hour).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specPath.Child("leadTime"), nil, ""),
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			errList := ValidateReservation(tc.reservation)
+			if diff := cmp.Diff(tc.wantErr, errList, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("ValidateReservation() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
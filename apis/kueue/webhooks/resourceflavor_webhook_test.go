@@ -26,7 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 )
 
 func TestValidateResourceFlavor(t *testing.T) {
@@ -38,11 +38,11 @@ func TestValidateResourceFlavor(t *testing.T) {
 	}{
 		{
 			name: "empty",
-			rf:   utiltesting.MakeResourceFlavor("resource-flavor").Obj(),
+			rf:   builder.MakeResourceFlavor("resource-flavor").Obj(),
 		},
 		{
 			name: "valid",
-			rf: utiltesting.MakeResourceFlavor("resource-flavor").
+			rf: builder.MakeResourceFlavor("resource-flavor").
 				Label("foo", "bar").
 				Taint(corev1.Taint{
 					Key:    "spot",
@@ -53,7 +53,7 @@ func TestValidateResourceFlavor(t *testing.T) {
 		{
 			// Taint validation is not exhaustively tested, because the code was copied from upstream k8s.
 			name: "invalid taint",
-			rf: utiltesting.MakeResourceFlavor("resource-flavor").Taint(corev1.Taint{
+			rf: builder.MakeResourceFlavor("resource-flavor").Taint(corev1.Taint{
 				Key: "skdajf",
 			}).Obj(),
 			wantErr: field.ErrorList{
@@ -62,7 +62,7 @@ func TestValidateResourceFlavor(t *testing.T) {
 		},
 		{
 			name: "too many labels",
-			rf: utiltesting.MakeResourceFlavor("resource-flavor").MultiLabels(func() map[string]string {
+			rf: builder.MakeResourceFlavor("resource-flavor").MultiLabels(func() map[string]string {
 				m := make(map[string]string)
 				for i := 0; i < 9; i++ {
 					m[fmt.Sprintf("l%d", i)] = ""
@@ -76,7 +76,7 @@ func TestValidateResourceFlavor(t *testing.T) {
 		{
 			name: "too many taints",
 			rf: func() *kueue.ResourceFlavor {
-				rf := utiltesting.MakeResourceFlavor("resource-flavor")
+				rf := builder.MakeResourceFlavor("resource-flavor")
 				for i := 0; i < 9; i++ {
 					rf.Taint(corev1.Taint{
 						Key:    fmt.Sprintf("t%d", i),
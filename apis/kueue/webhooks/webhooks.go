@@ -36,5 +36,13 @@ func Setup(mgr ctrl.Manager) (string, error) {
 	if err := setupWebhookForLocalQueue(mgr); err != nil {
 		return "Queue", err
 	}
+
+	if err := setupWebhookForReservation(mgr); err != nil {
+		return "Reservation", err
+	}
+
+	if err := setupWebhookForAdmissionCheck(mgr); err != nil {
+		return "AdmissionCheck", err
+	}
 	return "", nil
 }
@@ -18,7 +18,10 @@ package webhooks
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
@@ -28,21 +31,35 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/constants"
 )
 
-// log is for logging in this package.
-var workloadlog = ctrl.Log.WithName("workload-webhook")
+// manualAdmissionVerb is the verb on the ClusterQueues that users need to be
+// allowed to set the manual admission annotation in the workloads.
+const manualAdmissionVerb = "admit"
 
-type WorkloadWebhook struct{}
+var (
+	// log is for logging in this package.
+	workloadlog = ctrl.Log.WithName("workload-webhook")
+
+	podSetFlavorPolicies = sets.NewString(string(kueue.PodSetFlavorIndependent), string(kueue.PodSetFlavorSame))
+)
+
+type WorkloadWebhook struct {
+	client client.Client
+}
 
 func setupWebhookForWorkload(mgr ctrl.Manager) error {
+	wh := &WorkloadWebhook{client: mgr.GetClient()}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&kueue.Workload{}).
-		WithDefaulter(&WorkloadWebhook{}).
-		WithValidator(&WorkloadWebhook{}).
+		WithDefaulter(wh).
+		WithValidator(wh).
 		Complete()
 }
 
@@ -93,6 +110,9 @@ var _ webhook.CustomValidator = &WorkloadWebhook{}
 func (w *WorkloadWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	wl := obj.(*kueue.Workload)
 	workloadlog.V(5).Info("Validating create", "workload", klog.KObj(wl))
+	if err := w.authorizeManualAdmission(ctx, wl, nil); err != nil {
+		return err
+	}
 	return ValidateWorkload(wl).ToAggregate()
 }
 
@@ -101,6 +121,9 @@ func (w *WorkloadWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj run
 	newWL := newObj.(*kueue.Workload)
 	oldWL := oldObj.(*kueue.Workload)
 	workloadlog.V(5).Info("Validating update", "workload", klog.KObj(newWL))
+	if err := w.authorizeManualAdmission(ctx, newWL, oldWL); err != nil {
+		return err
+	}
 	return ValidateWorkloadUpdate(newWL, oldWL).ToAggregate()
 }
 
@@ -109,6 +132,60 @@ func (w *WorkloadWebhook) ValidateDelete(ctx context.Context, obj runtime.Object
 	return nil
 }
 
+// authorizeManualAdmission returns an error if the request sets the manual
+// admission annotation in the workload, or changes its value, and the user
+// isn't allowed to admit workloads in the ClusterQueue of the annotation, as
+// reported by a SubjectAccessReview.
+func (w *WorkloadWebhook) authorizeManualAdmission(ctx context.Context, wl, oldWl *kueue.Workload) error {
+	value, ok := wl.Annotations[constants.ManualAdmissionAnnotation]
+	if !ok {
+		return nil
+	}
+	if oldWl != nil {
+		if oldValue, ok := oldWl.Annotations[constants.ManualAdmissionAnnotation]; ok && oldValue == value {
+			return nil
+		}
+	}
+	path := field.NewPath("metadata", "annotations").Key(constants.ManualAdmissionAnnotation)
+	var manual kueue.Admission
+	if err := json.Unmarshal([]byte(value), &manual); err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}.ToAggregate()
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	user := req.UserInfo
+	sar := authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:    kueue.GroupVersion.Group,
+				Resource: "clusterqueues",
+				Verb:     manualAdmissionVerb,
+				Name:     string(manual.ClusterQueue),
+			},
+		},
+	}
+	if len(user.Extra) > 0 {
+		sar.Spec.Extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			sar.Spec.Extra[k] = authorizationv1.ExtraValue(v)
+		}
+	}
+	if err := w.client.Create(ctx, &sar); err != nil {
+		return err
+	}
+	if !sar.Status.Allowed {
+		return field.ErrorList{
+			field.Forbidden(path, fmt.Sprintf("user %q can't admit workloads in ClusterQueue %s", user.Username, manual.ClusterQueue)),
+		}.ToAggregate()
+	}
+	return nil
+}
+
 func ValidateWorkload(obj *kueue.Workload) field.ErrorList {
 	var allErrs field.ErrorList
 	specPath := field.NewPath("spec")
@@ -116,12 +193,14 @@ func ValidateWorkload(obj *kueue.Workload) field.ErrorList {
 	if len(obj.Spec.PodSets) == 0 {
 		allErrs = append(allErrs, field.Required(podSetsPath, "at least one podSet is required"))
 	}
-	if len(obj.Spec.PodSets) > 8 {
-		allErrs = append(allErrs, field.TooMany(podSetsPath, len(obj.Spec.PodSets), 8))
+	if len(obj.Spec.PodSets) > kueue.MaxPodSets {
+		allErrs = append(allErrs, field.TooMany(podSetsPath, len(obj.Spec.PodSets), kueue.MaxPodSets))
 	}
 
+	var totalPods int64
 	for i, podSet := range obj.Spec.PodSets {
 		path := podSetsPath.Index(i)
+		totalPods += int64(podSet.Count)
 		allErrs = append(allErrs, validatePodSetName(podSet.Name, path.Child("name"))...)
 		if podSet.Count <= 0 {
 			allErrs = append(allErrs, field.Invalid(
@@ -131,6 +210,9 @@ func ValidateWorkload(obj *kueue.Workload) field.ErrorList {
 			)
 		}
 	}
+	if totalPods > kueue.MaxPodsPerWorkload {
+		allErrs = append(allErrs, field.Invalid(podSetsPath, totalPods, fmt.Sprintf("the total count of pods must be at most %d", kueue.MaxPodsPerWorkload)))
+	}
 
 	if len(obj.Spec.PriorityClassName) > 0 {
 		msgs := validation.IsDNS1123Subdomain(obj.Spec.PriorityClassName)
@@ -152,6 +234,14 @@ func ValidateWorkload(obj *kueue.Workload) field.ErrorList {
 		allErrs = append(allErrs, validateAdmission(obj, specPath.Child("admission"))...)
 	}
 
+	if policy := obj.Spec.PodSetFlavorPolicy; len(policy) > 0 && !podSetFlavorPolicies.Has(string(policy)) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("podSetFlavorPolicy"), policy, podSetFlavorPolicies.List()))
+	}
+
+	if d := obj.Spec.ActiveDeadlineSeconds; d != nil && *d < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("activeDeadlineSeconds"), *d, "must be greater than 0"))
+	}
+
 	allErrs = append(allErrs, metav1validation.ValidateConditions(obj.Status.Conditions, field.NewPath("status", "conditions"))...)
 
 	return allErrs
@@ -190,8 +280,13 @@ func ValidateWorkloadUpdate(newObj, oldObj *kueue.Workload) field.ErrorList {
 	specPath := field.NewPath("spec")
 	allErrs = append(allErrs, ValidateWorkload(newObj)...)
 	allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.PodSets, oldObj.Spec.PodSets, specPath.Child("podSets"))...)
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.PodSetFlavorPolicy, oldObj.Spec.PodSetFlavorPolicy, specPath.Child("podSetFlavorPolicy"))...)
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.ActiveDeadlineSeconds, oldObj.Spec.ActiveDeadlineSeconds, specPath.Child("activeDeadlineSeconds"))...)
 	if newObj.Spec.Admission != nil && oldObj.Spec.Admission != nil {
 		allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.QueueName, oldObj.Spec.QueueName, specPath.Child("queueName"))...)
+		// The priority of a pending workload can change to reorder the queue,
+		// but it has no effect once the workload is admitted.
+		allErrs = append(allErrs, apivalidation.ValidateImmutableField(newObj.Spec.Priority, oldObj.Spec.Priority, specPath.Child("priority"))...)
 	}
 	allErrs = append(allErrs, validateAdmissionUpdate(newObj.Spec.Admission, oldObj.Spec.Admission, specPath.Child("admission"))...)
 
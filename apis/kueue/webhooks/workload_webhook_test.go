@@ -23,13 +23,21 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
-	testingutil "sigs.k8s.io/kueue/pkg/util/testing"
 )
 
 const (
@@ -198,7 +206,7 @@ func TestValidateWorkload(t *testing.T) {
 		wantErr  field.ErrorList
 	}{
 		"valid": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
 				{
 					Name:  "driver",
 					Count: 1,
@@ -210,13 +218,13 @@ func TestValidateWorkload(t *testing.T) {
 			}).Obj(),
 		},
 		"should have at least one podSet": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets(nil).Obj(),
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets(nil).Obj(),
 			wantErr: field.ErrorList{
 				field.Required(podSetsField, ""),
 			},
 		},
 		"should have at most 8 podSets": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
 				PodSets(func() []kueue.PodSet {
 					ps := make([]kueue.PodSet, 9)
 					for i := range ps {
@@ -229,8 +237,23 @@ func TestValidateWorkload(t *testing.T) {
 				field.TooMany(podSetsField, 9, 8),
 			},
 		},
+		"should have at most 100000 pods": {
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+				{
+					Name:  "driver",
+					Count: 1,
+				},
+				{
+					Name:  "workers",
+					Count: 100000,
+				},
+			}).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(podSetsField, nil, ""),
+			},
+		},
 		"should have valid podSet name": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
 				{
 					Name:  "@driver",
 					Count: 1,
@@ -239,7 +262,7 @@ func TestValidateWorkload(t *testing.T) {
 			wantErr: field.ErrorList{field.Invalid(podSetsField.Index(0).Child("name"), nil, "")},
 		},
 		"count should be greater than 0": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
 				{
 					Name:  "main",
 					Count: -1,
@@ -260,7 +283,7 @@ func TestValidateWorkload(t *testing.T) {
 			},
 		},
 		"should have valid priorityClassName": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
 				PriorityClass("invalid_class").
 				Priority(pointer.Int32(0)).
 				Obj(),
@@ -269,11 +292,11 @@ func TestValidateWorkload(t *testing.T) {
 			},
 		},
 		"should pass validation when priorityClassName is empty": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
 			wantErr:  nil,
 		},
 		"should have priority once priorityClassName is set": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
 				PriorityClass("priority").
 				Obj(),
 			wantErr: field.ErrorList{
@@ -281,7 +304,7 @@ func TestValidateWorkload(t *testing.T) {
 			},
 		},
 		"should have a valid queueName": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
 				Queue("@invalid").
 				Obj(),
 			wantErr: field.ErrorList{
@@ -289,23 +312,23 @@ func TestValidateWorkload(t *testing.T) {
 			},
 		},
 		"should have a valid clusterQueue name": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
-				Admit(testingutil.MakeAdmission("@invalid").Obj()).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				Admit(builder.MakeAdmission("@invalid").Obj()).
 				Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(specField.Child("admission", "clusterQueue"), nil, ""),
 			},
 		},
 		"should have a valid podSet name": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
-				Admit(testingutil.MakeAdmission("cluster-queue", "@invalid").Obj()).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				Admit(builder.MakeAdmission("cluster-queue", "@invalid").Obj()).
 				Obj(),
 			wantErr: field.ErrorList{
 				field.NotFound(specField.Child("admission", "podSetFlavors").Index(0).Child("name"), nil),
 			},
 		},
 		"should have same podSets in admission": {
-			workload: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
 				PodSets([]kueue.PodSet{
 					{
 						Name:  "main2",
@@ -316,12 +339,28 @@ func TestValidateWorkload(t *testing.T) {
 						Count: 1,
 					},
 				}).
-				Admit(testingutil.MakeAdmission("cluster-queue", "main1", "main3").Obj()).
+				Admit(builder.MakeAdmission("cluster-queue", "main1", "main3").Obj()).
 				Obj(),
 			wantErr: field.ErrorList{
 				field.NotFound(specField.Child("admission", "podSetFlavors").Index(1).Child("name"), nil),
 			},
 		},
+		"should have a supported podSetFlavorPolicy": {
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				PodSetFlavorPolicy("Spread").
+				Obj(),
+			wantErr: field.ErrorList{
+				field.NotSupported(specField.Child("podSetFlavorPolicy"), nil, nil),
+			},
+		},
+		"should have a positive activeDeadlineSeconds": {
+			workload: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				ActiveDeadlineSeconds(0).
+				Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("activeDeadlineSeconds"), nil, ""),
+			},
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -339,8 +378,8 @@ func TestValidateWorkloadUpdate(t *testing.T) {
 		wantErr       field.ErrorList
 	}{
 		"podSets should not be updated: count": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
 				{
 					Name:  "main",
 					Count: 2,
@@ -361,8 +400,8 @@ func TestValidateWorkloadUpdate(t *testing.T) {
 			},
 		},
 		"podSets should not be updated: podSpec": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).PodSets([]kueue.PodSet{
 				{
 					Name:  "main",
 					Count: 1,
@@ -383,54 +422,82 @@ func TestValidateWorkloadUpdate(t *testing.T) {
 			},
 		},
 		"queueName can be updated when not admitted": {
-			before:  testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").Obj(),
-			after:   testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").Obj(),
+			before:  builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").Obj(),
+			after:   builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").Obj(),
 			wantErr: nil,
 		},
 		"queueName can be updated when admitting": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q").
-				Admit(testingutil.MakeAdmission("cq").Obj()).Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q").
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
 		},
 		"queueName should not be updated once admitted": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").
-				Admit(testingutil.MakeAdmission("cq").Obj()).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").
-				Admit(testingutil.MakeAdmission("cq").Obj()).Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(field.NewPath("spec").Child("queueName"), nil, ""),
 			},
 		},
+		"priority can be updated when not admitted": {
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Priority(pointer.Int32(0)).Obj(),
+			after:  builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Priority(pointer.Int32(100)).Obj(),
+		},
+		"priority should not be updated once admitted": {
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Priority(pointer.Int32(0)).
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Priority(pointer.Int32(100)).
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("priority"), nil, ""),
+			},
+		},
 		"queueName can be updated when admission is reset": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").
-				Admit(testingutil.MakeAdmission("cq").Obj()).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q1").
+				Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Queue("q2").Obj(),
 		},
 		"admission can be set": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
-				testingutil.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
+				builder.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
 			).Obj(),
 			wantErr: nil,
 		},
 		"admission can be unset": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
-				testingutil.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
+				builder.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
 			).Obj(),
-			after:   testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after:   builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
 			wantErr: nil,
 		},
 		"admission should not be updated once set": {
-			before: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
-				testingutil.MakeAdmission("cluster-queue").Obj(),
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
+				builder.MakeAdmission("cluster-queue").Obj(),
 			).Obj(),
-			after: testingutil.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
-				testingutil.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Admit(
+				builder.MakeAdmission("cluster-queue").Flavor("on-demand", "5").Obj(),
 			).Obj(),
 			wantErr: field.ErrorList{
 				field.Invalid(field.NewPath("spec").Child("admission"), nil, ""),
 			},
 		},
+		"podSetFlavorPolicy should not be updated": {
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj(),
+			after: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				PodSetFlavorPolicy(kueue.PodSetFlavorSame).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("podSetFlavorPolicy"), nil, ""),
+			},
+		},
+		"activeDeadlineSeconds should not be updated": {
+			before: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).ActiveDeadlineSeconds(60).Obj(),
+			after:  builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).ActiveDeadlineSeconds(120).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("activeDeadlineSeconds"), nil, ""),
+			},
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -441,3 +508,148 @@ func TestValidateWorkloadUpdate(t *testing.T) {
 		})
 	}
 }
+
+// sarClient answers the SubjectAccessReviews, allowing the users in allowed
+// to admit workloads in any ClusterQueue.
+type sarClient struct {
+	client.Client
+	allowed map[string]bool
+	reviews []authorizationv1.SubjectAccessReviewSpec
+}
+
+func (c *sarClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if sar, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		c.reviews = append(c.reviews, sar.Spec)
+		sar.Status.Allowed = c.allowed[sar.Spec.User]
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestWorkloadWebhookManualAdmission(t *testing.T) {
+	const manualAdmission = `{"clusterQueue":"cq","podSetFlavors":[{"name":"main","flavors":{"cpu":"default"}}]}`
+	pending := builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).Obj()
+	annotated := builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+		Annotation(constants.ManualAdmissionAnnotation, manualAdmission).Obj()
+	cases := map[string]struct {
+		oldWl       *kueue.Workload
+		wl          *kueue.Workload
+		user        string
+		wantErr     field.ErrorList
+		wantReviews []authorizationv1.SubjectAccessReviewSpec
+	}{
+		"no annotation": {
+			wl:   pending,
+			user: "user",
+		},
+		"annotation set by an allowed user": {
+			oldWl: pending,
+			wl:    annotated,
+			user:  "admin",
+			wantReviews: []authorizationv1.SubjectAccessReviewSpec{{
+				User: "admin",
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    "kueue.x-k8s.io",
+					Resource: "clusterqueues",
+					Verb:     "admit",
+					Name:     "cq",
+				},
+			}},
+		},
+		"annotation set by a user that isn't allowed": {
+			oldWl: pending,
+			wl:    annotated,
+			user:  "user",
+			wantErr: field.ErrorList{
+				field.Forbidden(field.NewPath("metadata", "annotations").Key(constants.ManualAdmissionAnnotation), ""),
+			},
+			wantReviews: []authorizationv1.SubjectAccessReviewSpec{{
+				User: "user",
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    "kueue.x-k8s.io",
+					Resource: "clusterqueues",
+					Verb:     "admit",
+					Name:     "cq",
+				},
+			}},
+		},
+		"annotation at creation by a user that isn't allowed": {
+			wl:   annotated,
+			user: "user",
+			wantErr: field.ErrorList{
+				field.Forbidden(field.NewPath("metadata", "annotations").Key(constants.ManualAdmissionAnnotation), ""),
+			},
+			wantReviews: []authorizationv1.SubjectAccessReviewSpec{{
+				User: "user",
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    "kueue.x-k8s.io",
+					Resource: "clusterqueues",
+					Verb:     "admit",
+					Name:     "cq",
+				},
+			}},
+		},
+		"unchanged annotation": {
+			oldWl: annotated,
+			wl:    annotated,
+			user:  "user",
+		},
+		"invalid annotation": {
+			oldWl: pending,
+			wl: builder.MakeWorkload(testWorkloadName, testWorkloadNamespace).
+				Annotation(constants.ManualAdmissionAnnotation, "cq").Obj(),
+			user: "admin",
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("metadata", "annotations").Key(constants.ManualAdmissionAnnotation), nil, ""),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := &sarClient{
+				Client:  fake.NewClientBuilder().Build(),
+				allowed: map[string]bool{"admin": true},
+			}
+			w := &WorkloadWebhook{client: cl}
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: tc.user},
+				},
+			})
+			var err error
+			if tc.oldWl == nil {
+				err = w.ValidateCreate(ctx, tc.wl)
+			} else {
+				err = w.ValidateUpdate(ctx, tc.oldWl, tc.wl)
+			}
+			var gotErr field.ErrorList
+			if err != nil {
+				gotErr = errorList(t, err)
+			}
+			if diff := cmp.Diff(tc.wantErr, gotErr, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("Unexpected error (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReviews, cl.reviews); diff != "" {
+				t.Errorf("Unexpected SubjectAccessReviews (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// errorList returns the field errors aggregated in err.
+func errorList(t *testing.T, err error) field.ErrorList {
+	t.Helper()
+	agg, ok := err.(utilerrors.Aggregate)
+	if !ok {
+		t.Fatalf("Got error %v, want field errors", err)
+	}
+	var errs field.ErrorList
+	for _, e := range agg.Errors() {
+		fe, ok := e.(*field.Error)
+		if !ok {
+			t.Fatalf("Got error %v, want field errors", e)
+		}
+		errs = append(errs, fe)
+	}
+	return errs
+}
@@ -0,0 +1,59 @@
+#!/usr/bin/env bash
+
+# Copyright 2022 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Creates a kind cluster, deploys the image of Kueue given in IMAGE_TAG and
+# runs the end-to-end tests against it.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd -P)"
+cd "${ROOT}"
+
+KIND=${KIND:-kind}
+KUSTOMIZE=${KUSTOMIZE:-kustomize}
+GINKGO=${GINKGO:-ginkgo}
+KIND_CLUSTER_NAME=${KIND_CLUSTER_NAME:-kueue-e2e}
+E2E_KIND_VERSION=${E2E_KIND_VERSION:-kindest/node:v1.24.0}
+ARTIFACTS=${ARTIFACTS:-${ROOT}/bin/e2e-artifacts}
+
+function cleanup {
+    if [ "${E2E_KEEP_CLUSTER:-false}" != "true" ]; then
+        mkdir -p "${ARTIFACTS}"
+        ${KIND} export logs "${ARTIFACTS}" --name "${KIND_CLUSTER_NAME}" || true
+        ${KIND} delete cluster --name "${KIND_CLUSTER_NAME}"
+    fi
+    (cd config/components/manager && ${KUSTOMIZE} edit set image controller=gcr.io/k8s-staging-kueue/kueue:main)
+}
+
+function startup {
+    ${KIND} create cluster --name "${KIND_CLUSTER_NAME}" --image "${E2E_KIND_VERSION}" \
+        --config hack/kind-cluster.yaml --wait 1m
+    ${KIND} load docker-image "${IMAGE_TAG}" --name "${KIND_CLUSTER_NAME}"
+}
+
+function deploy {
+    (cd config/components/manager && ${KUSTOMIZE} edit set image controller="${IMAGE_TAG}")
+    # The image is loaded in the nodes, it can't be pulled.
+    ${KUSTOMIZE} build config/default | sed 's/imagePullPolicy: Always/imagePullPolicy: IfNotPresent/' | kubectl apply -f -
+    kubectl -n kueue-system wait --for=condition=available --timeout=3m deployment/kueue-controller-manager
+}
+
+trap cleanup EXIT
+startup
+deploy
+${GINKGO} -v ./test/e2e/...
@@ -18,7 +18,6 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -29,19 +28,26 @@ import (
 
 	zaplog "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	config "sigs.k8s.io/kueue/apis/config/v1alpha2"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/apis/kueue/webhooks"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
+	kueueconfig "sigs.k8s.io/kueue/pkg/config"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/controller/core"
 	"sigs.k8s.io/kueue/pkg/controller/workload/job"
@@ -49,11 +55,17 @@ import (
 	"sigs.k8s.io/kueue/pkg/queue"
 	"sigs.k8s.io/kueue/pkg/scheduler"
 	"sigs.k8s.io/kueue/pkg/util/cert"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 	"sigs.k8s.io/kueue/pkg/util/useragent"
 	"sigs.k8s.io/kueue/pkg/version"
+	"sigs.k8s.io/kueue/pkg/workload"
 	// +kubebuilder:scaffold:imports
 )
 
+// managerConfigMapName is the name of the ConfigMap that holds the
+// configuration file in the default installation.
+const managerConfigMapName = "kueue-manager-config"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -87,13 +99,16 @@ func main() {
 
 	options, cfg := apply(configFile)
 
-	metrics.Register()
+	metrics.Register(cfg.ReportedWorkloadLabels...)
+	workload.SetResourceUnits(resourceUnits(&cfg))
 
 	kubeConfig := ctrl.GetConfigOrDie()
 	if kubeConfig.UserAgent == "" {
 		kubeConfig.UserAgent = useragent.Default()
 	}
 
+	// The Job reconciler only watches the pods of Jobs.
+	options.NewCache = ctrlcache.BuilderWithOptions(ctrlcache.Options{SelectorsByObject: job.CacheSelectors()})
 	mgr, err := ctrl.NewManager(kubeConfig, options)
 	if err != nil {
 		setupLog.Error(err, "Unable to start manager")
@@ -111,23 +126,39 @@ func main() {
 		close(certsReady)
 	}
 
-	cCache := cache.New(mgr.GetClient())
-	queues := queue.NewManager(mgr.GetClient(), cCache)
+	// In job gating only mode, an external system admits the workloads, so
+	// there is no cache, queues or scheduler.
+	var cCache *cache.Cache
+	var queues *queue.Manager
+	var auditSink audit.Sink
+	if !cfg.JobGatingOnly {
+		cCache = cache.New(mgr.GetClient(), cache.WithWorkloadInfoOptions(workloadInfoOptions(&cfg)...))
+		queueOpts := []queue.Option{
+			queue.WithWorkloadOrdering(workloadOrdering(&cfg)),
+			queue.WithShard(shard(&cfg)),
+		}
+		if cfg.FairSharing != nil {
+			queueOpts = append(queueOpts, queue.WithFairSharing(cfg.FairSharing.UserLabel))
+		}
+		queues = queue.NewManager(mgr.GetClient(), cCache, queueOpts...)
+		auditSink = setupAuditSink(&cfg)
+	}
 
 	setupIndexes(mgr)
 
 	setupProbeEndpoints(mgr)
+	setupSecureMetrics(mgr, &cfg)
 	// Cert won't be ready until manager starts, so start a goroutine here which
 	// will block until the cert is ready before setting up the controllers.
 	// Controllers who register after manager starts will start directly.
-	go setupControllers(mgr, cCache, queues, certsReady, cfg.ManageJobsWithoutQueueName)
+	go setupControllers(mgr, cCache, queues, certsReady, &cfg, auditSink, configFile)
 
 	ctx := ctrl.SetupSignalHandler()
-	go func() {
-		queues.CleanUpOnContext(ctx)
-	}()
-
-	setupScheduler(ctx, mgr, cCache, queues)
+	if !cfg.JobGatingOnly {
+		// The scheduler wakes up the routines waiting on the queues when it
+		// stops.
+		setupScheduler(mgr, cCache, queues, &cfg, auditSink)
+	}
 
 	setupLog.Info("Starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -136,6 +167,105 @@ func main() {
 	}
 }
 
+func workloadInfoOptions(cfg *config.Configuration) []workload.InfoOption {
+	var opts []workload.InfoOption
+	if cfg.InitContainersAccounting == config.InitContainersAccountingIgnore {
+		opts = append(opts, workload.WithoutInitContainers())
+	}
+	if cfg.RequestsTransformer != "" {
+		opts = append(opts, workload.WithRequestsTransformer(cfg.RequestsTransformer))
+	}
+	return opts
+}
+
+// resourceUnits returns the units of the resources that override the defaults.
+func resourceUnits(cfg *config.Configuration) map[corev1.ResourceName]workload.ResourceUnit {
+	if len(cfg.ResourceUnits) == 0 {
+		return nil
+	}
+	units := make(map[corev1.ResourceName]workload.ResourceUnit, len(cfg.ResourceUnits))
+	for _, ru := range cfg.ResourceUnits {
+		u := workload.UnitOf(ru.Name)
+		if ru.MilliUnits != nil {
+			u.Scale = 0
+			if *ru.MilliUnits {
+				u.Scale = resource.Milli
+			}
+		}
+		if ru.Format != "" {
+			u.Format = ru.Format
+		}
+		units[ru.Name] = u
+	}
+	return units
+}
+
+// shard returns the shard of the cohorts that this instance schedules.
+func shard(cfg *config.Configuration) sharding.Shard {
+	if cfg.Sharding == nil {
+		return sharding.Shard{}
+	}
+	return sharding.Shard{Index: cfg.Sharding.Index, Count: cfg.Sharding.Count}
+}
+
+func workloadOrdering(cfg *config.Configuration) workload.Ordering {
+	if cfg.RequeuingTimestamp == config.RequeuingTimestampCreation {
+		return workload.Ordering{}
+	}
+	return workload.Ordering{RequeueByEvictionTime: true}
+}
+
+// jobOptions returns the options of the Job reconciler and webhook, which can
+// change when the configuration is reloaded.
+func jobOptions(cfg *config.Configuration) []job.Option {
+	return []job.Option{
+		job.WithManageJobsWithoutQueueName(cfg.ManageJobsWithoutQueueName),
+		job.WithLabelKeysToCopy(labelKeysToCopy(cfg)...),
+		job.WithAnnotationKeysToCopy(annotationKeysToCopy(cfg)...),
+		job.WithRequireExistingLocalQueue(cfg.RequireExistingLocalQueue),
+		job.WithRequeueOnNodeFailure(cfg.RequeueOnNodeFailure),
+		job.WithShard(shard(cfg)),
+	}
+}
+
+func labelKeysToCopy(cfg *config.Configuration) []string {
+	var keys []string
+	if cfg.FairSharing != nil && cfg.FairSharing.UserLabel != "" {
+		keys = append(keys, cfg.FairSharing.UserLabel)
+	}
+	if cfg.MetadataPropagation != nil {
+		keys = append(keys, cfg.MetadataPropagation.Labels...)
+	}
+	return keys
+}
+
+func annotationKeysToCopy(cfg *config.Configuration) []string {
+	if cfg.MetadataPropagation == nil {
+		return nil
+	}
+	return cfg.MetadataPropagation.Annotations
+}
+
+// setupAuditSink returns the sink where the admission decisions are recorded,
+// or nil if it isn't configured.
+func setupAuditSink(cfg *config.Configuration) audit.Sink {
+	if cfg.AuditSink == nil {
+		return nil
+	}
+	switch {
+	case cfg.AuditSink.File != "":
+		sink, err := audit.NewFileSink(cfg.AuditSink.File)
+		if err != nil {
+			setupLog.Error(err, "Unable to set up the audit sink")
+			os.Exit(1)
+		}
+		return sink
+	case cfg.AuditSink.WebhookURL != "":
+		return audit.NewWebhookSink(cfg.AuditSink.WebhookURL)
+	}
+	return nil
+}
+
 func setupIndexes(mgr ctrl.Manager) {
 	if err := queue.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
 		setupLog.Error(err, "Unable to setup queue indexes")
@@ -148,22 +278,22 @@ func setupIndexes(mgr ctrl.Manager) {
 	}
 }
 
-func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, certsReady chan struct{}, manageJobsWithoutQueueName bool) {
+func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, certsReady chan struct{}, cfg *config.Configuration, auditSink audit.Sink, configFile string) {
 	// The controllers won't work until the webhooks are operating, and the webhook won't work until the
 	// certs are all in place.
 	setupLog.Info("Waiting for certificate generation to complete")
 	<-certsReady
 	setupLog.Info("Certs ready")
 
-	if failedCtrl, err := core.SetupControllers(mgr, queues, cCache); err != nil {
-		setupLog.Error(err, "Unable to create controller", "controller", failedCtrl)
-		os.Exit(1)
+	if !cfg.JobGatingOnly {
+		setupCoreControllers(mgr, cCache, queues, cfg, auditSink)
 	}
-	if err := job.NewReconciler(mgr.GetScheme(),
+	jobRec := job.NewReconciler(mgr.GetScheme(),
 		mgr.GetClient(),
 		mgr.GetEventRecorderFor(constants.JobControllerName),
-		job.WithManageJobsWithoutQueueName(manageJobsWithoutQueueName),
-	).SetupWithManager(mgr); err != nil {
+		jobOptions(cfg)...,
+	)
+	if err := jobRec.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Job")
 		os.Exit(1)
 	}
@@ -171,9 +301,53 @@ func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manag
 		setupLog.Error(err, "Unable to create webhook", "webhook", failedWebhook)
 		os.Exit(1)
 	}
+	jobWebhook := job.NewWebhook(mgr.GetClient(), jobOptions(cfg)...)
+	if err := jobWebhook.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Unable to create webhook", "webhook", "Job")
+		os.Exit(1)
+	}
+	if configFile != "" {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: managerConfigMapName, Namespace: *cfg.Namespace}}
+		reloader := kueueconfig.NewReloader(configFile, scheme, mgr.GetEventRecorderFor(constants.ConfigReloaderName), configMap, cfg, func(newCfg *config.Configuration) {
+			jobRec.UpdateOptions(jobOptions(newCfg)...)
+			jobWebhook.UpdateOptions(jobOptions(newCfg)...)
+		})
+		if err := mgr.Add(reloader); err != nil {
+			setupLog.Error(err, "Unable to set up the configuration reloader")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 }
 
+func setupCoreControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, cfg *config.Configuration, auditSink audit.Sink) {
+	coreOpts := []core.Option{
+		core.WithLocalQueueMetrics(cfg.LocalQueueMetrics),
+		core.WithReportedWorkloadLabels(cfg.ReportedWorkloadLabels...),
+		core.WithShard(shard(cfg)),
+	}
+	if auditSink != nil {
+		coreOpts = append(coreOpts, core.WithAuditSink(auditSink))
+	}
+	if cfg.LocalQueueConsumptionUpdatePeriod != nil {
+		coreOpts = append(coreOpts, core.WithLocalQueueConsumptionUpdatePeriod(cfg.LocalQueueConsumptionUpdatePeriod.Duration))
+	}
+	if r := cfg.WorkloadRetention; r != nil {
+		var retention workload.Retention
+		if r.AfterSucceeded != nil {
+			retention.AfterSucceeded = r.AfterSucceeded.Duration
+		}
+		if r.AfterFailed != nil {
+			retention.AfterFailed = r.AfterFailed.Duration
+		}
+		coreOpts = append(coreOpts, core.WithWorkloadRetention(retention))
+	}
+	if failedCtrl, err := core.SetupControllers(mgr, queues, cCache, coreOpts...); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", failedCtrl)
+		os.Exit(1)
+	}
+}
+
 // setupProbeEndpoints registers the health endpoints
 func setupProbeEndpoints(mgr ctrl.Manager) {
 	defer setupLog.Info("Probe endpoints are configured on healthz and readyz")
@@ -188,14 +362,48 @@ func setupProbeEndpoints(mgr ctrl.Manager) {
 	}
 }
 
-func setupScheduler(ctx context.Context, mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager) {
+// setupSecureMetrics serves the metrics over HTTPS, in place of the metrics
+// server of the manager, which apply disables.
+func setupSecureMetrics(mgr ctrl.Manager, cfg *config.Configuration) {
+	sm := cfg.SecureMetrics
+	if sm == nil {
+		return
+	}
+	var cl client.Client
+	if sm.Authorization {
+		cl = mgr.GetClient()
+	}
+	srv := metrics.NewSecureServer(cfg.Metrics.BindAddress, sm.CertDir, sm.CertName, sm.KeyName, cl)
+	if err := mgr.Add(srv); err != nil {
+		setupLog.Error(err, "Unable to add the metrics server to the manager")
+		os.Exit(1)
+	}
+}
+
+func setupScheduler(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, cfg *config.Configuration, auditSink audit.Sink) {
+	schedOpts := []scheduler.Option{
+		scheduler.WithNodeCapacityCheck(cfg.NodeCapacityCheck),
+		scheduler.WithWorkloadOrdering(workloadOrdering(cfg)),
+		scheduler.WithReportedWorkloadLabels(cfg.ReportedWorkloadLabels...),
+		scheduler.WithAdmissionConcurrency(int(cfg.AdmissionConcurrency)),
+	}
+	if auditSink != nil {
+		schedOpts = append(schedOpts, scheduler.WithAuditSink(auditSink))
+	}
+	if cfg.StarvationWatchdog != nil {
+		schedOpts = append(schedOpts, scheduler.WithStarvationWatchdog(cfg.StarvationWatchdog.Threshold.Duration, cfg.StarvationWatchdog.EscalatePriority))
+	}
 	sched := scheduler.New(
 		queues,
 		cCache,
 		mgr.GetClient(),
 		mgr.GetEventRecorderFor(constants.AdmissionName),
+		schedOpts...,
 	)
-	go sched.Start(ctx)
+	if err := mgr.Add(sched); err != nil {
+		setupLog.Error(err, "Unable to add the scheduler to the manager")
+		os.Exit(1)
+	}
 }
 
 func encodeConfig(cfg *config.Configuration) (string, error) {
@@ -231,6 +439,10 @@ func apply(configFile string) (ctrl.Options, config.Configuration) {
 		setupLog.Error(err, "unable to load the config")
 		os.Exit(1)
 	}
+	if errs := kueueconfig.Validate(&cfg); len(errs) > 0 {
+		setupLog.Error(errs.ToAggregate(), "invalid configuration")
+		os.Exit(1)
+	}
 
 	cfgStr, err := encodeConfig(&cfg)
 	if err != nil {
@@ -239,5 +451,15 @@ func apply(configFile string) (ctrl.Options, config.Configuration) {
 	}
 	setupLog.Info("Successfully loaded configuration", "config", cfgStr)
 
+	// The metrics are served over HTTPS by a server of Kueue instead.
+	if cfg.SecureMetrics != nil {
+		options.MetricsBindAddress = "0"
+	}
+
+	// Each shard elects its own leader.
+	if s := shard(&cfg); s.Enabled() && options.LeaderElectionID != "" {
+		options.LeaderElectionID = fmt.Sprintf("%s-shard-%d", options.LeaderElectionID, s.Index)
+	}
+
 	return options, cfg
 }
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlconfigv1alpha1 "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 
 	config "sigs.k8s.io/kueue/apis/config/v1alpha2"
 )
@@ -133,8 +134,61 @@ webhook:
 		t.Fatal(err)
 	}
 
+	initContainersIgnoredConfig := filepath.Join(tmpDir, "init-containers-ignored.yaml")
+	if err := os.WriteFile(initContainersIgnoredConfig, []byte(`
+apiVersion: config.kueue.x-k8s.io/v1alpha2
+kind: Configuration
+namespace: kueue-system
+health:
+  healthProbeBindAddress: :8081
+metrics:
+  bindAddress: :8080
+leaderElection:
+  leaderElect: true
+  resourceName: c1f6bfd2.kueue.x-k8s.io
+webhook:
+  port: 9443
+initContainersAccounting: Ignore
+`), os.FileMode(0600)); err != nil {
+		t.Fatal(err)
+	}
+
+	concurrencyOverWriteConfig := filepath.Join(tmpDir, "concurrency-overwrite.yaml")
+	if err := os.WriteFile(concurrencyOverWriteConfig, []byte(`
+apiVersion: config.kueue.x-k8s.io/v1alpha2
+kind: Configuration
+namespace: kueue-system
+health:
+  healthProbeBindAddress: :8081
+metrics:
+  bindAddress: :8080
+leaderElection:
+  leaderElect: true
+  resourceName: c1f6bfd2.kueue.x-k8s.io
+webhook:
+  port: 9443
+controller:
+  groupKindConcurrency:
+    Job.batch: 10
+    Workload.kueue.x-k8s.io: 20
+`), os.FileMode(0600)); err != nil {
+		t.Fatal(err)
+	}
+
+	defaultControllerOptions := ctrlconfigv1alpha1.ControllerConfigurationSpec{
+		GroupKindConcurrency: map[string]int{
+			"Job.batch":                     5,
+			"Workload.kueue.x-k8s.io":       5,
+			"LocalQueue.kueue.x-k8s.io":     1,
+			"ClusterQueue.kueue.x-k8s.io":   1,
+			"ResourceFlavor.kueue.x-k8s.io": 1,
+		},
+	}
+
 	defaultControlOptions := ctrl.Options{
+		Controller:             defaultControllerOptions,
 		Port:                   config.DefaultWebhookPort,
+		CertDir:                config.DefaultWebhookCertDir,
 		HealthProbeBindAddress: config.DefaultHealthProbeBindAddress,
 		MetricsBindAddress:     config.DefaultMetricsBindAddress,
 		LeaderElectionID:       config.DefaultLeaderElectionID,
@@ -166,11 +220,14 @@ webhook:
 			name:       "default config",
 			configFile: "",
 			wantConfiguration: config.Configuration{
-				Namespace:              pointer.String(config.DefaultNamespace),
-				InternalCertManagement: enableDefaultInternalCertManagement,
+				Namespace:                pointer.String(config.DefaultNamespace),
+				InternalCertManagement:   enableDefaultInternalCertManagement,
+				InitContainersAccounting: config.InitContainersAccountingMax,
 			},
 			wantOptions: ctrl.Options{
+				Controller:             defaultControllerOptions,
 				Port:                   config.DefaultWebhookPort,
+				CertDir:                config.DefaultWebhookCertDir,
 				HealthProbeBindAddress: config.DefaultHealthProbeBindAddress,
 				MetricsBindAddress:     config.DefaultMetricsBindAddress,
 				LeaderElectionID:       "",
@@ -188,6 +245,7 @@ webhook:
 				Namespace:                  pointer.String("kueue-tenant-a"),
 				ManageJobsWithoutQueueName: false,
 				InternalCertManagement:     enableDefaultInternalCertManagement,
+				InitContainersAccounting:   config.InitContainersAccountingMax,
 			},
 			wantOptions: defaultControlOptions,
 		},
@@ -202,11 +260,14 @@ webhook:
 				Namespace:                  pointer.String(config.DefaultNamespace),
 				ManageJobsWithoutQueueName: false,
 				InternalCertManagement:     enableDefaultInternalCertManagement,
+				InitContainersAccounting:   config.InitContainersAccountingMax,
 			},
 			wantOptions: ctrl.Options{
+				Controller:             defaultControllerOptions,
 				HealthProbeBindAddress: ":38081",
 				MetricsBindAddress:     ":38080",
 				Port:                   9444,
+				CertDir:                config.DefaultWebhookCertDir,
 				LeaderElection:         true,
 				LeaderElectionID:       "test-id",
 			},
@@ -226,6 +287,7 @@ webhook:
 					WebhookServiceName: pointer.String("kueue-tenant-a-webhook-service"),
 					WebhookSecretName:  pointer.String("kueue-tenant-a-webhook-server-cert"),
 				},
+				InitContainersAccounting: config.InitContainersAccountingMax,
 			},
 			wantOptions: defaultControlOptions,
 		},
@@ -242,6 +304,7 @@ webhook:
 				InternalCertManagement: &config.InternalCertManagement{
 					Enable: pointer.Bool(false),
 				},
+				InitContainersAccounting: config.InitContainersAccountingMax,
 			},
 			wantOptions: defaultControlOptions,
 		},
@@ -256,15 +319,63 @@ webhook:
 				Namespace:                  pointer.String("kueue-system"),
 				ManageJobsWithoutQueueName: false,
 				InternalCertManagement:     enableDefaultInternalCertManagement,
+				InitContainersAccounting:   config.InitContainersAccountingMax,
 			},
 			wantOptions: ctrl.Options{
+				Controller:             defaultControllerOptions,
 				Port:                   config.DefaultWebhookPort,
+				CertDir:                config.DefaultWebhookCertDir,
 				HealthProbeBindAddress: config.DefaultHealthProbeBindAddress,
 				MetricsBindAddress:     config.DefaultMetricsBindAddress,
 				LeaderElectionID:       "",
 				LeaderElection:         false,
 			},
 		},
+		{
+			name:       "init containers ignored config",
+			configFile: initContainersIgnoredConfig,
+			wantConfiguration: config.Configuration{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: config.GroupVersion.String(),
+					Kind:       "Configuration",
+				},
+				Namespace:                  pointer.String("kueue-system"),
+				ManageJobsWithoutQueueName: false,
+				InternalCertManagement:     enableDefaultInternalCertManagement,
+				InitContainersAccounting:   config.InitContainersAccountingIgnore,
+			},
+			wantOptions: defaultControlOptions,
+		},
+		{
+			name:       "concurrency overwrite config",
+			configFile: concurrencyOverWriteConfig,
+			wantConfiguration: config.Configuration{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: config.GroupVersion.String(),
+					Kind:       "Configuration",
+				},
+				Namespace:                pointer.String("kueue-system"),
+				InternalCertManagement:   enableDefaultInternalCertManagement,
+				InitContainersAccounting: config.InitContainersAccountingMax,
+			},
+			wantOptions: ctrl.Options{
+				Controller: ctrlconfigv1alpha1.ControllerConfigurationSpec{
+					GroupKindConcurrency: map[string]int{
+						"Job.batch":                     10,
+						"Workload.kueue.x-k8s.io":       20,
+						"LocalQueue.kueue.x-k8s.io":     1,
+						"ClusterQueue.kueue.x-k8s.io":   1,
+						"ResourceFlavor.kueue.x-k8s.io": 1,
+					},
+				},
+				Port:                   config.DefaultWebhookPort,
+				CertDir:                config.DefaultWebhookCertDir,
+				HealthProbeBindAddress: config.DefaultHealthProbeBindAddress,
+				MetricsBindAddress:     config.DefaultMetricsBindAddress,
+				LeaderElectionID:       config.DefaultLeaderElectionID,
+				LeaderElection:         true,
+			},
+		},
 	}
 
 	for _, tc := range testcases {
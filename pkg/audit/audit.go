@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the admission decisions of Kueue, so that the usage of
+// the quota over time can be reconstructed.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// Decision is the kind of decision recorded.
+type Decision string
+
+const (
+	// Admission is the decision to admit a workload, which reserves quota.
+	Admission Decision = "Admission"
+	// Eviction is the decision to evict a workload, which releases quota.
+	Eviction Decision = "Eviction"
+)
+
+// Record describes an admission decision.
+type Record struct {
+	Time         time.Time `json:"time"`
+	Decision     Decision  `json:"decision"`
+	Namespace    string    `json:"namespace"`
+	Workload     string    `json:"workload"`
+	UID          types.UID `json:"uid"`
+	ClusterQueue string    `json:"clusterQueue"`
+	Reason       string    `json:"reason,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	// QuotaDelta is the change in the quota used in the ClusterQueue by the
+	// decision. The quantities are positive for admissions and negative for
+	// evictions.
+	QuotaDelta []QuotaDelta `json:"quotaDelta,omitempty"`
+}
+
+// QuotaDelta is the change in the quota used for a resource and flavor.
+type QuotaDelta struct {
+	Resource corev1.ResourceName `json:"resource"`
+	Flavor   string              `json:"flavor"`
+	Quantity resource.Quantity   `json:"quantity"`
+}
+
+// NewRecord returns the record of a decision for an admitted workload, taken
+// at the given time. The quota delta is computed from the admission of the
+// workload.
+func NewRecord(decision Decision, wl *kueue.Workload, reason, message string, now time.Time) *Record {
+	r := &Record{
+		Time:      now,
+		Decision:  decision,
+		Namespace: wl.Namespace,
+		Workload:  wl.Name,
+		UID:       wl.UID,
+		Reason:    reason,
+		Message:   message,
+	}
+	if wl.Spec.Admission == nil {
+		return r
+	}
+	r.ClusterQueue = string(wl.Spec.Admission.ClusterQueue)
+	sign := int64(1)
+	if decision == Eviction {
+		sign = -1
+	}
+	r.QuotaDelta = quotaDelta(workload.NewInfo(wl), sign)
+	return r
+}
+
+func quotaDelta(wi *workload.Info, sign int64) []QuotaDelta {
+	used := make(map[corev1.ResourceName]map[string]int64)
+	add := func(requests workload.Requests, flavors map[corev1.ResourceName]string) {
+		for rName, flavor := range flavors {
+			v, ok := requests[rName]
+			if !ok {
+				continue
+			}
+			if used[rName] == nil {
+				used[rName] = make(map[string]int64)
+			}
+			used[rName][flavor] += v
+		}
+	}
+	for _, ps := range wi.TotalRequests {
+		add(ps.Requests, ps.Flavors)
+	}
+	var delta []QuotaDelta
+	for rName, flavors := range used {
+		for flavor, v := range flavors {
+			delta = append(delta, QuotaDelta{
+				Resource: rName,
+				Flavor:   flavor,
+				Quantity: workload.ResourceQuantity(rName, sign*v),
+			})
+		}
+	}
+	sort.Slice(delta, func(i, j int) bool {
+		a, b := delta[i], delta[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Flavor < b.Flavor
+	})
+	return delta
+}
+
+// Sink records the admission decisions. Implementations must be safe for
+// concurrent use.
+type Sink interface {
+	Record(ctx context.Context, r *Record) error
+}
+
+// FileSink appends the records to a file, one JSON object per line.
+type FileSink struct {
+	sync.Mutex
+	file *os.File
+}
+
+// NewFileSink returns a FileSink that appends to the file in the given path,
+// creating it if it doesn't exist.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit file: %w", err)
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Record(_ context.Context, r *Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// webhookTimeout is the maximum time to deliver a record to a webhook.
+const webhookTimeout = 10 * time.Second
+
+// WebhookSink sends each record, as a JSON object, in the body of a POST
+// request to a URL.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a WebhookSink that sends the records to the given URL.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (s *WebhookSink) Record(ctx context.Context, r *Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+func TestNewRecord(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	wl := builder.MakeWorkload("wl", "ns").
+		Request(corev1.ResourceCPU, "2").
+		Request("example.com/gpu", "1").
+		Admit(builder.MakeAdmission("cq").
+			Flavor(corev1.ResourceCPU, "default").
+			Flavor("example.com/gpu", "model-a").
+			Obj()).
+		Obj()
+	cases := map[string]struct {
+		decision Decision
+		want     *Record
+	}{
+		"admission": {
+			decision: Admission,
+			want: &Record{
+				Time:         now,
+				Decision:     Admission,
+				Namespace:    "ns",
+				Workload:     "wl",
+				ClusterQueue: "cq",
+				Reason:       "Reason",
+				Message:      "Message",
+				QuotaDelta: []QuotaDelta{
+					{Resource: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("2")},
+					{Resource: "example.com/gpu", Flavor: "model-a", Quantity: resource.MustParse("1")},
+				},
+			},
+		},
+		"eviction": {
+			decision: Eviction,
+			want: &Record{
+				Time:         now,
+				Decision:     Eviction,
+				Namespace:    "ns",
+				Workload:     "wl",
+				ClusterQueue: "cq",
+				Reason:       "Reason",
+				Message:      "Message",
+				QuotaDelta: []QuotaDelta{
+					{Resource: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("-2")},
+					{Resource: "example.com/gpu", Flavor: "model-a", Quantity: resource.MustParse("-1")},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewRecord(tc.decision, wl, "Reason", "Message", now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected record (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("Failed creating the sink: %v", err)
+	}
+	records := []*Record{
+		{Decision: Admission, Namespace: "ns", Workload: "a", ClusterQueue: "cq"},
+		{Decision: Eviction, Namespace: "ns", Workload: "a", ClusterQueue: "cq", Reason: "Inactive"},
+	}
+	for _, r := range records {
+		if err := sink.Record(context.Background(), r); err != nil {
+			t.Fatalf("Failed recording: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Failed closing the sink: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed opening the file: %v", err)
+	}
+	defer f.Close()
+	var got []*Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Failed decoding line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, &r)
+	}
+	if diff := cmp.Diff(records, got); diff != "" {
+		t.Errorf("Unexpected records in the file (-want,+got):\n%s", diff)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got []*Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r Record
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Workload == "rejected" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		got = append(got, &r)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	want := &Record{Decision: Admission, Namespace: "ns", Workload: "a", ClusterQueue: "cq"}
+	if err := sink.Record(context.Background(), want); err != nil {
+		t.Fatalf("Failed recording: %v", err)
+	}
+	if diff := cmp.Diff([]*Record{want}, got); diff != "" {
+		t.Errorf("Unexpected records received (-want,+got):\n%s", diff)
+	}
+	if err := sink.Record(context.Background(), &Record{Workload: "rejected"}); err == nil {
+		t.Error("Recording succeeded, want an error for the rejected record")
+	}
+}
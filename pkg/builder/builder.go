@@ -14,7 +14,11 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package testing
+// Package builder provides wrappers to construct Kueue objects, and the Jobs
+// they are created for, with a fluent API. The wrappers start from valid
+// objects and can be used outside of this repository, for example to write the
+// tests of job integrations.
+package builder
 
 import (
 	"time"
@@ -92,6 +96,17 @@ func (j *JobWrapper) Queue(queue string) *JobWrapper {
 	return j
 }
 
+// ControlledBy sets the controller owner reference of the job.
+func (j *JobWrapper) ControlledBy(apiVersion, kind, name string) *JobWrapper {
+	j.OwnerReferences = append(j.OwnerReferences, metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Controller: pointer.Bool(true),
+	})
+	return j
+}
+
 // Toleration adds a toleration to the job.
 func (j *JobWrapper) Toleration(t corev1.Toleration) *JobWrapper {
 	j.Spec.Template.Spec.Tolerations = append(j.Spec.Template.Spec.Tolerations, t)
@@ -110,6 +125,13 @@ func (j *JobWrapper) Request(r corev1.ResourceName, v string) *JobWrapper {
 	return j
 }
 
+// Image sets the image and the arguments of the default container.
+func (j *JobWrapper) Image(image string, args []string) *JobWrapper {
+	j.Spec.Template.Spec.Containers[0].Image = image
+	j.Spec.Template.Spec.Containers[0].Args = args
+	return j
+}
+
 // PriorityClassWrapper wraps a PriorityClass.
 type PriorityClassWrapper struct {
 	schedulingv1.PriorityClass
@@ -177,6 +199,23 @@ func (w *WorkloadWrapper) Queue(q string) *WorkloadWrapper {
 	return w
 }
 
+// Label adds a label to the Workload.
+func (w *WorkloadWrapper) Label(k, v string) *WorkloadWrapper {
+	if w.Labels == nil {
+		w.Labels = make(map[string]string)
+	}
+	w.Labels[k] = v
+	return w
+}
+
+func (w *WorkloadWrapper) Annotation(k, v string) *WorkloadWrapper {
+	if w.Annotations == nil {
+		w.Annotations = make(map[string]string)
+	}
+	w.Annotations[k] = v
+	return w
+}
+
 func (w *WorkloadWrapper) Admit(a *kueue.Admission) *WorkloadWrapper {
 	w.Spec.Admission = a
 	return w
@@ -209,6 +248,30 @@ func (w *WorkloadWrapper) PodSets(podSets []kueue.PodSet) *WorkloadWrapper {
 	return w
 }
 
+func (w *WorkloadWrapper) PodSetFlavorPolicy(p kueue.PodSetFlavorPolicy) *WorkloadWrapper {
+	w.Spec.PodSetFlavorPolicy = p
+	return w
+}
+
+func (w *WorkloadWrapper) ActiveDeadlineSeconds(d int64) *WorkloadWrapper {
+	w.Spec.ActiveDeadlineSeconds = &d
+	return w
+}
+
+func (w *WorkloadWrapper) Active(a bool) *WorkloadWrapper {
+	w.Spec.Active = pointer.Bool(a)
+	return w
+}
+
+func (w *WorkloadWrapper) RequeueAt(t time.Time) *WorkloadWrapper {
+	if w.Status.RequeueState == nil {
+		w.Status.RequeueState = &kueue.RequeueState{}
+	}
+	requeueAt := metav1.NewTime(t)
+	w.Status.RequeueState.RequeueAt = &requeueAt
+	return w
+}
+
 func (w *WorkloadWrapper) Toleration(t corev1.Toleration) *WorkloadWrapper {
 	w.Spec.PodSets[0].Spec.Tolerations = append(w.Spec.PodSets[0].Spec.Tolerations, t)
 	return w
@@ -281,6 +344,18 @@ func (q *LocalQueueWrapper) ClusterQueue(c string) *LocalQueueWrapper {
 	return q
 }
 
+// Weight updates the weight of the queue.
+func (q *LocalQueueWrapper) Weight(w int32) *LocalQueueWrapper {
+	q.Spec.Weight = &w
+	return q
+}
+
+// DefaultPriorityClass updates the defaultPriorityClass of the queue.
+func (q *LocalQueueWrapper) DefaultPriorityClass(pc string) *LocalQueueWrapper {
+	q.Spec.DefaultPriorityClass = pc
+	return q
+}
+
 // PendingWorkloads updates the pendingWorkloads in status.
 func (q *LocalQueueWrapper) PendingWorkloads(n int32) *LocalQueueWrapper {
 	q.Status.PendingWorkloads = n
@@ -327,7 +402,42 @@ func (c *ClusterQueueWrapper) QueueingStrategy(strategy kueue.QueueingStrategy)
 	return c
 }
 
+// PodOverheadPolicy sets the pod overhead policy.
+func (c *ClusterQueueWrapper) PodOverheadPolicy(policy kueue.PodOverheadPolicy) *ClusterQueueWrapper {
+	c.Spec.PodOverheadPolicy = policy
+	return c
+}
+
+// FlavorAssignmentPolicy sets the flavor assignment policy.
+func (c *ClusterQueueWrapper) FlavorAssignmentPolicy(policy kueue.FlavorAssignmentPolicy) *ClusterQueueWrapper {
+	c.Spec.FlavorAssignmentPolicy = policy
+	return c
+}
+
+// AdmissionChecks sets the admission checks.
+func (c *ClusterQueueWrapper) AdmissionChecks(checks ...string) *ClusterQueueWrapper {
+	c.Spec.AdmissionChecks = checks
+	return c
+}
+
 // NamespaceSelector sets the namespace selector.
+// Budget sets a budget of resource-hours per window, for pairs of resource
+// and flavor.
+func (c *ClusterQueueWrapper) Budget(window time.Duration, limits ...kueue.ResourceBudget) *ClusterQueueWrapper {
+	c.Spec.Budget = &kueue.Budget{
+		Window:    metav1.Duration{Duration: window},
+		Resources: limits,
+	}
+	return c
+}
+
+// MaxNamespaceUsagePercentage sets the percentage of the quota that a single
+// namespace can use.
+func (c *ClusterQueueWrapper) MaxNamespaceUsagePercentage(p int32) *ClusterQueueWrapper {
+	c.Spec.MaxNamespaceUsagePercentage = &p
+	return c
+}
+
 func (c *ClusterQueueWrapper) NamespaceSelector(s *metav1.LabelSelector) *ClusterQueueWrapper {
 	c.Spec.NamespaceSelector = s
 	return c
@@ -378,6 +488,34 @@ func (f *FlavorWrapper) Max(c string) *FlavorWrapper {
 	return f
 }
 
+// Cost sets the cost of the flavor.
+func (f *FlavorWrapper) Cost(c int32) *FlavorWrapper {
+	f.Flavor.Cost = pointer.Int32(c)
+	return f
+}
+
+// EvictionLimit sets the eviction limit of the flavor.
+func (f *FlavorWrapper) EvictionLimit(n int32) *FlavorWrapper {
+	f.Flavor.EvictionLimit = pointer.Int32(n)
+	return f
+}
+
+// Exclusive marks the flavor as not lent to the cohort.
+func (f *FlavorWrapper) Exclusive() *FlavorWrapper {
+	f.Flavor.Exclusive = pointer.Bool(true)
+	return f
+}
+
+// NamespaceReservation reserves a quantity of the flavor for the namespaces
+// that match the selector.
+func (f *FlavorWrapper) NamespaceReservation(q string, selector metav1.LabelSelector) *FlavorWrapper {
+	f.Flavor.NamespaceReservation = &kueue.NamespaceReservation{
+		Quantity:          resource.MustParse(q),
+		NamespaceSelector: selector,
+	}
+	return f
+}
+
 // ResourceFlavorWrapper wraps a ResourceFlavor.
 type ResourceFlavorWrapper struct{ kueue.ResourceFlavor }
 
@@ -416,6 +554,43 @@ func (rf *ResourceFlavorWrapper) Taint(t corev1.Taint) *ResourceFlavorWrapper {
 	return rf
 }
 
+// ReservationWrapper wraps a Reservation.
+type ReservationWrapper struct{ kueue.Reservation }
+
+// MakeReservation creates a wrapper for a Reservation of the quota of a
+// ClusterQueue between start and end.
+func MakeReservation(name, cq string, start, end time.Time) *ReservationWrapper {
+	return &ReservationWrapper{kueue.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: kueue.ReservationSpec{
+			ClusterQueue: kueue.ClusterQueueReference(cq),
+			StartTime:    metav1.NewTime(start),
+			EndTime:      metav1.NewTime(end),
+		},
+	}}
+}
+
+// Obj returns the inner Reservation.
+func (r *ReservationWrapper) Obj() *kueue.Reservation {
+	return &r.Reservation
+}
+
+// LeadTime sets how long before the start the quota is held.
+func (r *ReservationWrapper) LeadTime(d time.Duration) *ReservationWrapper {
+	r.Spec.LeadTime = &metav1.Duration{Duration: d}
+	return r
+}
+
+// Resource adds a reserved quantity of a resource and flavor.
+func (r *ReservationWrapper) Resource(name corev1.ResourceName, flavor, quantity string) *ReservationWrapper {
+	r.Spec.Resources = append(r.Spec.Resources, kueue.ReservedResource{
+		Name:     name,
+		Flavor:   kueue.ResourceFlavorReference(flavor),
+		Quantity: resource.MustParse(quantity),
+	})
+	return r
+}
+
 // RuntimeClassWrapper wraps a RuntimeClass.
 type RuntimeClassWrapper struct{ nodev1.RuntimeClass }
 
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// budget accounts for the consumption of a ClusterQueue during a window.
+// The limits and the consumption are expressed as the value of the resource,
+// as used in ResourceQuantities, multiplied by seconds.
+type budget struct {
+	window time.Duration
+	limits ResourceQuantities
+
+	windowStart  time.Time
+	accruedUntil time.Time
+	consumed     ResourceQuantities
+}
+
+// newBudget returns a budget that resumes the accounting recorded in the
+// status of the ClusterQueue, if any. The consumption since the status was
+// last updated is unknown and not accounted for.
+func newBudget(usage *kueue.BudgetUsage, now time.Time) *budget {
+	b := &budget{
+		windowStart:  now,
+		accruedUntil: now,
+	}
+	if usage == nil {
+		return b
+	}
+	b.windowStart = usage.WindowStart.Time
+	b.consumed = make(ResourceQuantities)
+	for _, c := range usage.Consumed {
+		if b.consumed[c.Name] == nil {
+			b.consumed[c.Name] = make(map[string]int64)
+		}
+		b.consumed[c.Name][string(c.Flavor)] = budgetValue(c.Name, c.Quantity)
+	}
+	return b
+}
+
+func (b *budget) update(in *kueue.Budget) {
+	b.window = in.Window.Duration
+	b.limits = make(ResourceQuantities)
+	for _, rb := range in.Resources {
+		if b.limits[rb.Name] == nil {
+			b.limits[rb.Name] = make(map[string]int64)
+		}
+		b.limits[rb.Name][string(rb.Flavor)] = budgetValue(rb.Name, rb.Limit)
+	}
+}
+
+// advance returns a copy of the budget with the consumption accrued until
+// now, given that the usage didn't change since the consumption was last
+// accrued. If the window ended, the copy accounts for the current window.
+func (b budget) advance(now time.Time, used ResourceQuantities) budget {
+	if elapsed := now.Sub(b.windowStart); b.window > 0 && elapsed >= b.window {
+		b.windowStart = b.windowStart.Add(elapsed / b.window * b.window)
+		b.accruedUntil = b.windowStart
+		b.consumed = nil
+	}
+	secs := int64(now.Sub(b.accruedUntil) / time.Second)
+	if secs <= 0 {
+		return b
+	}
+	consumed := make(ResourceQuantities, len(b.limits))
+	for res, flavors := range b.limits {
+		consumed[res] = make(map[string]int64, len(flavors))
+		for flv := range flavors {
+			consumed[res][flv] = b.consumed[res][flv] + used[res][flv]*secs
+		}
+	}
+	b.consumed = consumed
+	b.accruedUntil = b.accruedUntil.Add(time.Duration(secs) * time.Second)
+	return b
+}
+
+// exhausted returns, for each resource, the flavors whose consumption
+// reached the limit.
+func (b *budget) exhausted() map[corev1.ResourceName]sets.String {
+	var res map[corev1.ResourceName]sets.String
+	for name, flavors := range b.limits {
+		for flv, limit := range flavors {
+			if b.consumed[name][flv] < limit {
+				continue
+			}
+			if res == nil {
+				res = make(map[corev1.ResourceName]sets.String)
+			}
+			if res[name] == nil {
+				res[name] = sets.NewString()
+			}
+			res[name].Insert(flv)
+		}
+	}
+	return res
+}
+
+func (b *budget) usage() *kueue.BudgetUsage {
+	usage := &kueue.BudgetUsage{
+		WindowStart: metav1.NewTime(b.windowStart),
+	}
+	for name, flavors := range b.limits {
+		for flv := range flavors {
+			usage.Consumed = append(usage.Consumed, kueue.ConsumedResource{
+				Name:     name,
+				Flavor:   kueue.ResourceFlavorReference(flv),
+				Quantity: budgetQuantity(name, b.consumed[name][flv]),
+			})
+		}
+	}
+	sort.Slice(usage.Consumed, func(i, j int) bool {
+		a, b := usage.Consumed[i], usage.Consumed[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Flavor < b.Flavor
+	})
+	return usage
+}
+
+// budgetValue converts a quantity of resource-hours to the units of the
+// budget: the unit of the resource multiplied by seconds.
+func budgetValue(name corev1.ResourceName, q resource.Quantity) int64 {
+	// Thousandths of the unit keep the fractions of the resource-hours.
+	thousandths := q.ScaledValue(workload.UnitOf(name).Scale + resource.Milli)
+	return thousandths/1000*3600 + thousandths%1000*3600/1000
+}
+
+// budgetQuantity converts a value in the units of the budget to a quantity of
+// resource-hours.
+func budgetQuantity(name corev1.ResourceName, v int64) resource.Quantity {
+	u := workload.UnitOf(name)
+	q := u.Quantity(v / 3600)
+	q.Add(*resource.NewScaledQuantity(v%3600*1000/3600, u.Scale+resource.Milli))
+	return q
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestBudgetAdvance(t *testing.T) {
+	start := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	spec := &kueue.Budget{
+		Window: metav1.Duration{Duration: 7 * 24 * time.Hour},
+		Resources: []kueue.ResourceBudget{
+			{Name: corev1.ResourceCPU, Flavor: "default", Limit: resource.MustParse("100")},
+			{Name: "example.com/gpu", Flavor: "default", Limit: resource.MustParse("10")},
+		},
+	}
+	used := ResourceQuantities{
+		corev1.ResourceCPU: {"default": 4_000},
+		"example.com/gpu":  {"default": 4},
+	}
+	cases := map[string]struct {
+		usage         *kueue.BudgetUsage
+		after         time.Duration
+		want          *kueue.BudgetUsage
+		wantExhausted map[corev1.ResourceName]sets.String
+	}{
+		"new budget": {
+			after: 2 * time.Hour,
+			want: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start),
+				Consumed: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("8")},
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("8")},
+				},
+			},
+		},
+		"partial hours": {
+			after: 15 * time.Minute,
+			want: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start),
+				Consumed: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("1")},
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("1")},
+				},
+			},
+		},
+		"resumed from status": {
+			usage: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start.Add(-time.Hour)),
+				Consumed: []kueue.ConsumedResource{
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("6")},
+				},
+			},
+			after: time.Hour,
+			want: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start.Add(-time.Hour)),
+				Consumed: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("4")},
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("10")},
+				},
+			},
+			wantExhausted: map[corev1.ResourceName]sets.String{
+				"example.com/gpu": sets.NewString("default"),
+			},
+		},
+		"window rolled": {
+			usage: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start.Add(-7*24*time.Hour + time.Hour)),
+				Consumed: []kueue.ConsumedResource{
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("10")},
+				},
+			},
+			after: 2 * time.Hour,
+			want: &kueue.BudgetUsage{
+				WindowStart: metav1.NewTime(start.Add(time.Hour)),
+				Consumed: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("4")},
+					{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("4")},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := newBudget(tc.usage, start)
+			b.update(spec)
+			*b = b.advance(start.Add(tc.after), used)
+			if diff := cmp.Diff(tc.want, b.usage()); diff != "" {
+				t.Errorf("Unexpected budget usage (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantExhausted, b.exhausted()); diff != "" {
+				t.Errorf("Unexpected exhausted flavors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBudgetUnits(t *testing.T) {
+	cases := map[string]struct {
+		units     map[corev1.ResourceName]workload.ResourceUnit
+		name      corev1.ResourceName
+		quantity  string
+		wantValue int64
+		wantQuant string
+	}{
+		"cpu": {
+			name:      corev1.ResourceCPU,
+			quantity:  "1500m",
+			wantValue: 1_500 * 3600,
+			wantQuant: "1500m",
+		},
+		"memory": {
+			name:      corev1.ResourceMemory,
+			quantity:  "2Gi",
+			wantValue: (2 << 30) * 3600,
+			wantQuant: "2Gi",
+		},
+		"extended resource with fractions of hours": {
+			name:      "example.com/gpu",
+			quantity:  "1.5",
+			wantValue: 5400,
+			wantQuant: "1500m",
+		},
+		"extended resource in milli-units": {
+			units: map[corev1.ResourceName]workload.ResourceUnit{
+				"example.com/gpu": {Scale: resource.Milli, Format: resource.DecimalSI},
+			},
+			name:      "example.com/gpu",
+			quantity:  "1.5",
+			wantValue: 1_500 * 3600,
+			wantQuant: "1500m",
+		},
+		"memory in megabytes": {
+			units: map[corev1.ResourceName]workload.ResourceUnit{
+				corev1.ResourceMemory: {Scale: resource.Mega, Format: resource.DecimalSI},
+			},
+			name:      corev1.ResourceMemory,
+			quantity:  "2G",
+			wantValue: 2_000 * 3600,
+			wantQuant: "2G",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			workload.SetResourceUnits(tc.units)
+			defer workload.SetResourceUnits(nil)
+			v := budgetValue(tc.name, resource.MustParse(tc.quantity))
+			if v != tc.wantValue {
+				t.Errorf("budgetValue(_)=%d, want %d", v, tc.wantValue)
+			}
+			q := budgetQuantity(tc.name, v)
+			if q.String() != tc.wantQuant {
+				t.Errorf("budgetQuantity(_)=%s, want %s", &q, tc.wantQuant)
+			}
+		})
+	}
+}
+
+func TestCacheBudget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	cq := builder.MakeClusterQueue("cq").
+		Resource(builder.MakeResource("example.com/gpu").
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		Budget(7*24*time.Hour, kueue.ResourceBudget{
+			Name:   "example.com/gpu",
+			Flavor: "default",
+			Limit:  resource.MustParse("100"),
+		}).
+		Obj()
+	cq.Status.BudgetUsage = &kueue.BudgetUsage{
+		WindowStart: metav1.NewTime(time.Now()),
+		Consumed: []kueue.ConsumedResource{
+			{Name: "example.com/gpu", Flavor: "default", Quantity: resource.MustParse("100")},
+		},
+	}
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+
+	snapshot := cache.Snapshot()
+	wantExhausted := map[corev1.ResourceName]sets.String{"example.com/gpu": sets.NewString("default")}
+	if diff := cmp.Diff(wantExhausted, snapshot.ClusterQueues["cq"].BudgetExhausted); diff != "" {
+		t.Errorf("Unexpected exhausted flavors (-want,+got):\n%s", diff)
+	}
+	usage, err := cache.BudgetUsage(cq)
+	if err != nil {
+		t.Fatalf("Failed getting the budget usage: %v", err)
+	}
+	if diff := cmp.Diff(cq.Status.BudgetUsage, usage); diff != "" {
+		t.Errorf("Unexpected budget usage (-want,+got):\n%s", diff)
+	}
+
+	// Removing the budget stops the accounting.
+	cq.Spec.Budget = nil
+	if err := cache.UpdateClusterQueue(cq); err != nil {
+		t.Fatalf("Failed updating ClusterQueue: %v", err)
+	}
+	snapshot = cache.Snapshot()
+	if got := snapshot.ClusterQueues["cq"].BudgetExhausted; got != nil {
+		t.Errorf("Got exhausted flavors %v after removing the budget", got)
+	}
+	if usage, _ := cache.BudgetUsage(cq); usage != nil {
+		t.Errorf("Got budget usage %v after removing the budget", usage)
+	}
+}
@@ -20,12 +20,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
@@ -54,16 +58,49 @@ type Cache struct {
 	cohorts          map[string]*Cohort
 	assumedWorkloads map[string]string
 	resourceFlavors  map[string]*kueue.ResourceFlavor
+	reservations     map[string]*kueue.Reservation
+	// namespaceLabels holds the labels of each namespace, to find the
+	// namespaces that use the quota reserved for them.
+	namespaceLabels map[string]labels.Set
+
+	workloadInfoOptions []workload.InfoOption
+	clock               clock.Clock
+}
+
+// Option configures the cache.
+type Option func(*Cache)
+
+// WithWorkloadInfoOptions sets the options used to compute the requests of
+// the workloads, in addition to the policies of each ClusterQueue.
+func WithWorkloadInfoOptions(opts ...workload.InfoOption) Option {
+	return func(c *Cache) {
+		c.workloadInfoOptions = opts
+	}
+}
+
+// WithClock sets the clock used to account for the consumption of the budgets
+// and the LocalQueues, and to find the active reservations.
+func WithClock(c clock.Clock) Option {
+	return func(cache *Cache) {
+		cache.clock = c
+	}
 }
 
-func New(client client.Client) *Cache {
-	return &Cache{
+func New(client client.Client, opts ...Option) *Cache {
+	c := &Cache{
 		client:           client,
 		clusterQueues:    make(map[string]*ClusterQueue),
 		cohorts:          make(map[string]*Cohort),
 		assumedWorkloads: make(map[string]string),
 		resourceFlavors:  make(map[string]*kueue.ResourceFlavor),
+		reservations:     make(map[string]*kueue.Reservation),
+		namespaceLabels:  make(map[string]labels.Set),
+		clock:            clock.RealClock{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type ResourceQuantities map[corev1.ResourceName]map[string]int64
@@ -103,12 +140,40 @@ type ClusterQueue struct {
 	// The set of key labels from all flavors of a resource.
 	// Those keys define the affinity terms of a workload
 	// that can be matched against the flavors.
-	LabelKeys map[corev1.ResourceName]sets.String
-	Status    metrics.ClusterQueueStatus
+	LabelKeys              map[corev1.ResourceName]sets.String
+	Status                 metrics.ClusterQueueStatus
+	PodOverheadPolicy      kueue.PodOverheadPolicy
+	FlavorAssignmentPolicy kueue.FlavorAssignmentPolicy
+	AdmissionChecks        []string
+	// The flavors of each resource whose budget is exhausted in the current
+	// window. Only populated in a snapshot.
+	BudgetExhausted map[corev1.ResourceName]sets.String
+	// MaxNamespaceUsagePercentage is the percentage of the min quota of each
+	// flavor that the workloads of a single namespace can use. Nil if the
+	// namespaces aren't limited.
+	MaxNamespaceUsagePercentage *int32
+	// NamespaceUsage is the usage of the admitted workloads of each
+	// namespace. In a snapshot, it's only populated if the namespaces are
+	// limited.
+	NamespaceUsage map[string]ResourceQuantities
+	// ReservedUsage is the usage of each flavor with a namespace reservation
+	// by the workloads of the namespaces that match the reservation. Only
+	// populated in a snapshot.
+	ReservedUsage ResourceQuantities
+
+	workloadInfoOptions []workload.InfoOption
+	clock               clock.Clock
+
+	// workloadsShared indicates that Workloads is referenced by a snapshot,
+	// so it has to be copied before it's modified. It's accessed atomically,
+	// as the snapshots are taken under the read lock of the cache.
+	workloadsShared int32
 
 	// The following fields are not populated in a snapshot.
 
 	admittedWorkloadsPerQueue map[string]int
+	localQueueConsumption     map[string]*queueConsumption
+	budget                    *budget
 }
 
 type Resource struct {
@@ -116,6 +181,16 @@ type Resource struct {
 	Flavors              []FlavorLimits
 }
 
+// HasFlavor returns whether the flavor is one of the flavors of the resource.
+func (r *Resource) HasFlavor(name string) bool {
+	for _, f := range r.Flavors {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Resource) matchesFlavors(other *Resource) bool {
 	if len(r.Flavors) != len(other.Flavors) {
 		return false
@@ -133,13 +208,27 @@ type FlavorLimits struct {
 	Name string
 	Min  int64
 	Max  *int64
+	Cost int32
+	// EvictionLimit is the number of evictions of a workload while using the
+	// flavor after which the workload can't use it. Zero if there is no limit.
+	EvictionLimit int32
+	// Reserved is the part of Min reserved for the workloads from the
+	// namespaces that match ReservedFor. Zero if there is no reservation.
+	Reserved    int64
+	ReservedFor labels.Selector
+	// Exclusive indicates that Min isn't lent to the cohort.
+	Exclusive bool
 }
 
 func (c *Cache) newClusterQueue(cq *kueue.ClusterQueue) (*ClusterQueue, error) {
 	cqImpl := &ClusterQueue{
 		Name:                      cq.Name,
 		Workloads:                 make(map[string]*workload.Info),
+		workloadInfoOptions:       c.workloadInfoOptions,
+		clock:                     c.clock,
 		admittedWorkloadsPerQueue: make(map[string]int),
+		localQueueConsumption:     make(map[string]*queueConsumption),
+		NamespaceUsage:            make(map[string]ResourceQuantities),
 	}
 	if err := cqImpl.update(cq, c.resourceFlavors); err != nil {
 		return nil, err
@@ -159,11 +248,71 @@ func (c *Cache) AdmittedWorkloadsInLocalQueue(localQueue *kueue.LocalQueue) int3
 	return int32(cq.admittedWorkloadsPerQueue[qKey])
 }
 
+// LocalQueueUsage returns the resources used by the workloads of the LocalQueue
+// that are admitted in its ClusterQueue, per resource and flavor.
+func (c *Cache) LocalQueueUsage(localQueue *kueue.LocalQueue) ResourceQuantities {
+	c.RLock()
+	defer c.RUnlock()
+	cq, ok := c.clusterQueues[string(localQueue.Spec.ClusterQueue)]
+	if !ok {
+		return nil
+	}
+	usage := make(ResourceQuantities)
+	add := func(requests workload.Requests, flavors map[corev1.ResourceName]string) {
+		for rName, flavor := range flavors {
+			v, ok := requests[rName]
+			if !ok {
+				continue
+			}
+			if usage[rName] == nil {
+				usage[rName] = make(map[string]int64)
+			}
+			usage[rName][flavor] += v
+		}
+	}
+	for _, wl := range cq.Workloads {
+		if !workloadBelongsToLocalQueue(wl.Obj, localQueue) {
+			continue
+		}
+		for _, ps := range wl.TotalRequests {
+			add(ps.Requests, ps.Flavors)
+		}
+	}
+	return usage
+}
+
+// LocalQueueConsumption returns the resources consumed by the workloads of the
+// LocalQueue while they were admitted in its ClusterQueue, accounted until now.
+func (c *Cache) LocalQueueConsumption(localQueue *kueue.LocalQueue) *kueue.LocalQueueConsumption {
+	c.Lock()
+	defer c.Unlock()
+	cq, ok := c.clusterQueues[string(localQueue.Spec.ClusterQueue)]
+	if !ok {
+		return nil
+	}
+	qc, ok := cq.localQueueConsumption[queueKey(localQueue)]
+	if !ok {
+		return nil
+	}
+	qc.accrue(c.clock.Now())
+	return qc.status()
+}
+
 func (c *ClusterQueue) Active() bool {
 	return c.Status == active
 }
 
 func (c *ClusterQueue) update(in *kueue.ClusterQueue, resourceFlavors map[string]*kueue.ResourceFlavor) error {
+	now := c.clock.Now()
+	c.accrueBudget(now)
+	if in.Spec.Budget == nil {
+		c.budget = nil
+	} else {
+		if c.budget == nil {
+			c.budget = newBudget(in.Status.BudgetUsage, now)
+		}
+		c.budget.update(in.Spec.Budget)
+	}
 	c.RequestableResources = resourcesByName(in.Spec.Resources)
 	c.UpdateCodependentResources()
 	nsSelector, err := metav1.LabelSelectorAsSelector(in.Spec.NamespaceSelector)
@@ -171,6 +320,8 @@ func (c *ClusterQueue) update(in *kueue.ClusterQueue, resourceFlavors map[string
 		return err
 	}
 	c.NamespaceSelector = nsSelector
+	c.AdmissionChecks = in.Spec.AdmissionChecks
+	c.MaxNamespaceUsagePercentage = in.Spec.MaxNamespaceUsagePercentage
 
 	usedResources := make(ResourceQuantities, len(in.Spec.Resources))
 	for _, r := range in.Spec.Resources {
@@ -186,10 +337,34 @@ func (c *ClusterQueue) update(in *kueue.ClusterQueue, resourceFlavors map[string
 		usedResources[r.Name] = usedFlavors
 	}
 	c.UsedResources = usedResources
+
+	excludedOverhead := c.PodOverheadPolicy == kueue.PodOverheadExclude
+	c.PodOverheadPolicy = in.Spec.PodOverheadPolicy
+	if excludedOverhead != (c.PodOverheadPolicy == kueue.PodOverheadExclude) {
+		// The requests of the admitted workloads changed.
+		workloads := c.mutableWorkloads()
+		for k, wi := range workloads {
+			c.updateWorkloadUsage(wi, -1)
+			wi = workload.NewInfo(wi.Obj, c.WorkloadInfoOptions()...)
+			workloads[k] = wi
+			c.updateWorkloadUsage(wi, 1)
+		}
+	}
+	c.FlavorAssignmentPolicy = in.Spec.FlavorAssignmentPolicy
 	c.UpdateWithFlavors(resourceFlavors)
 	return nil
 }
 
+// WorkloadInfoOptions returns the options to compute the requests of the
+// workloads in this ClusterQueue.
+func (c *ClusterQueue) WorkloadInfoOptions() []workload.InfoOption {
+	opts := c.workloadInfoOptions
+	if c.PodOverheadPolicy == kueue.PodOverheadExclude {
+		opts = append(opts[:len(opts):len(opts)], workload.WithoutPodOverhead())
+	}
+	return opts
+}
+
 func (c *ClusterQueue) UpdateCodependentResources() {
 	for iName, iRes := range c.RequestableResources {
 		if len(iRes.CodependentResources) > 0 {
@@ -260,8 +435,8 @@ func (c *ClusterQueue) addWorkload(w *kueue.Workload) error {
 	if _, exist := c.Workloads[k]; exist {
 		return fmt.Errorf("workload already exists in ClusterQueue")
 	}
-	wi := workload.NewInfo(w)
-	c.Workloads[k] = wi
+	wi := workload.NewInfo(w, c.WorkloadInfoOptions()...)
+	c.mutableWorkloads()[k] = wi
 	c.updateWorkloadUsage(wi, 1)
 	reportAdmittedActiveWorkloads(wi.ClusterQueue, len(c.Workloads))
 	return nil
@@ -274,26 +449,247 @@ func (c *ClusterQueue) deleteWorkload(w *kueue.Workload) {
 		return
 	}
 	c.updateWorkloadUsage(wi, -1)
-	delete(c.Workloads, k)
+	delete(c.mutableWorkloads(), k)
 	reportAdmittedActiveWorkloads(wi.ClusterQueue, len(c.Workloads))
 }
 
+// mutableWorkloads returns the Workloads of the ClusterQueue, after copying
+// them if they are shared with a snapshot.
+func (c *ClusterQueue) mutableWorkloads() map[string]*workload.Info {
+	if atomic.CompareAndSwapInt32(&c.workloadsShared, 1, 0) {
+		workloads := make(map[string]*workload.Info, len(c.Workloads))
+		for k, v := range c.Workloads {
+			workloads[k] = v
+		}
+		c.Workloads = workloads
+	}
+	return c.Workloads
+}
+
 func (c *ClusterQueue) updateWorkloadUsage(wi *workload.Info, m int64) {
+	now := c.clock.Now()
+	c.accrueBudget(now)
 	for _, ps := range wi.TotalRequests {
-		for wlRes, wlResFlv := range ps.Flavors {
-			v, wlResExist := ps.Requests[wlRes]
-			cqResFlv, cqResExist := c.UsedResources[wlRes]
-			if cqResExist && wlResExist {
-				if _, cqFlvExist := cqResFlv[wlResFlv]; cqFlvExist {
-					cqResFlv[wlResFlv] += v * m
-				}
-			}
-		}
+		c.updateUsage(ps.Requests, ps.Flavors, m)
 	}
+	c.updateNamespaceUsage(wi, m)
 	qKey := workload.QueueKey(wi.Obj)
 	if _, ok := c.admittedWorkloadsPerQueue[qKey]; ok {
 		c.admittedWorkloadsPerQueue[qKey] += int(m)
 	}
+	if qc, ok := c.localQueueConsumption[qKey]; ok {
+		qc.accrue(now)
+		qc.updateWorkloadUsage(wi, m)
+	}
+}
+
+// accrueBudget accounts, in the budget, for the consumption of the current
+// usage until now.
+func (c *ClusterQueue) accrueBudget(now time.Time) {
+	if c.budget != nil {
+		*c.budget = c.budget.advance(now, c.UsedResources)
+	}
+}
+
+func (c *ClusterQueue) updateUsage(requests workload.Requests, flavors map[corev1.ResourceName]string, m int64) {
+	for wlRes, wlResFlv := range flavors {
+		v, wlResExist := requests[wlRes]
+		cqResFlv, cqResExist := c.UsedResources[wlRes]
+		if cqResExist && wlResExist {
+			if _, cqFlvExist := cqResFlv[wlResFlv]; cqFlvExist {
+				cqResFlv[wlResFlv] += v * m
+			}
+		}
+	}
+}
+
+// updateNamespaceUsage adds the requests of the workload, multiplied by m, to
+// the usage of its namespace. The namespaces without usage are dropped.
+func (c *ClusterQueue) updateNamespaceUsage(wi *workload.Info, m int64) {
+	ns := wi.Obj.Namespace
+	used, ok := c.NamespaceUsage[ns]
+	if !ok {
+		used = make(ResourceQuantities)
+	}
+	add := func(requests workload.Requests, flavors map[corev1.ResourceName]string) {
+		for rName, flavor := range flavors {
+			v, ok := requests[rName]
+			if !ok {
+				continue
+			}
+			if used[rName] == nil {
+				used[rName] = make(map[string]int64)
+			}
+			used[rName][flavor] += v * m
+			if used[rName][flavor] == 0 {
+				delete(used[rName], flavor)
+			}
+			if len(used[rName]) == 0 {
+				delete(used, rName)
+			}
+		}
+	}
+	for _, ps := range wi.TotalRequests {
+		add(ps.Requests, ps.Flavors)
+	}
+	if len(used) == 0 {
+		delete(c.NamespaceUsage, ns)
+	} else {
+		c.NamespaceUsage[ns] = used
+	}
+}
+
+// ReservedForOthers returns the quantity of the flavor for the resource that
+// the ClusterQueue, or the members of its cohort, reserve for the namespaces
+// that don't match the given labels.
+func (c *ClusterQueue) ReservedForOthers(rName corev1.ResourceName, flavor string, nsLabels labels.Set) int64 {
+	if c.Cohort == nil {
+		return c.reservedForOthers(rName, flavor, nsLabels)
+	}
+	var total int64
+	for member := range c.Cohort.members {
+		total += member.reservedForOthers(rName, flavor, nsLabels)
+	}
+	return total
+}
+
+// reservedForOthers returns the part of the quota reserved for the namespaces
+// that don't match the labels, and that those namespaces don't use yet. The
+// part that they use already counts as used in the ClusterQueue.
+func (c *ClusterQueue) reservedForOthers(rName corev1.ResourceName, flavor string, nsLabels labels.Set) int64 {
+	limits := flavorLimits(c, rName, flavor)
+	if limits == nil || limits.Reserved == 0 || limits.ReservedFor.Matches(nsLabels) {
+		return 0
+	}
+	if used := c.ReservedUsage[rName][flavor]; used < limits.Reserved {
+		return limits.Reserved - used
+	}
+	return 0
+}
+
+// reservedUsage returns the usage of each flavor with a namespace reservation
+// by the namespaces that match the reservation, given the labels of each
+// namespace.
+func (c *ClusterQueue) reservedUsage(namespaceLabels map[string]labels.Set) ResourceQuantities {
+	var usage ResourceQuantities
+	for rName, res := range c.RequestableResources {
+		for i := range res.Flavors {
+			limits := &res.Flavors[i]
+			if limits.Reserved == 0 {
+				continue
+			}
+			var used int64
+			for ns, nsUsage := range c.NamespaceUsage {
+				if limits.ReservedFor.Matches(namespaceLabels[ns]) {
+					used += nsUsage[rName][limits.Name]
+				}
+			}
+			if used == 0 {
+				continue
+			}
+			if usage == nil {
+				usage = make(ResourceQuantities)
+			}
+			if usage[rName] == nil {
+				usage[rName] = make(map[string]int64)
+			}
+			usage[rName][limits.Name] = used
+		}
+	}
+	return usage
+}
+
+// UpdateNamespace records the labels of the namespace.
+func (c *Cache) UpdateNamespace(ns *corev1.Namespace) {
+	c.Lock()
+	defer c.Unlock()
+	c.namespaceLabels[ns.Name] = labels.Set(ns.Labels)
+}
+
+// DeleteNamespace forgets the labels of the namespace.
+func (c *Cache) DeleteNamespace(ns *corev1.Namespace) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.namespaceLabels, ns.Name)
+}
+
+// ExclusiveInCohort returns the min quota of the flavor for the resource that
+// the other members of the cohort don't lend, because the flavor is exclusive,
+// and how much of it they use.
+func (c *ClusterQueue) ExclusiveInCohort(rName corev1.ResourceName, flavor string) (total, used int64) {
+	if c.Cohort == nil {
+		return 0, 0
+	}
+	for member := range c.Cohort.members {
+		if member == c {
+			continue
+		}
+		limits := flavorLimits(member, rName, flavor)
+		if limits == nil || !limits.Exclusive {
+			continue
+		}
+		total += limits.Min
+		if u := member.UsedResources[rName][flavor]; u < limits.Min {
+			used += u
+		} else {
+			used += limits.Min
+		}
+	}
+	return total, used
+}
+
+// FitsFlavorLimits returns an error if val more of the resource doesn't fit
+// in the quota of the flavor, which must be one of the flavors of the
+// ClusterQueue. The quota reserved for namespaces that don't match nsLabels
+// isn't available. If it fits, it also returns the quantity to borrow from
+// the cohort. It's meant to be called on the ClusterQueues of a snapshot.
+func (c *ClusterQueue) FitsFlavorLimits(rName corev1.ResourceName, val int64, nsLabels labels.Set, flavor *FlavorLimits) (int64, error) {
+	if c.BudgetExhausted[rName].Has(flavor.Name) {
+		return 0, fmt.Errorf("budget for %s flavor %s exhausted", rName, flavor.Name)
+	}
+	used := c.UsedResources[rName][flavor.Name]
+	if flavor.Max != nil && used+val > *flavor.Max {
+		return 0, fmt.Errorf("borrowing limit for %s flavor %s exceeded", rName, flavor.Name)
+	}
+	cohortUsed := used
+	cohortTotal := flavor.Min
+	if c.Cohort != nil {
+		cohortUsed = c.Cohort.UsedResources[rName][flavor.Name]
+		cohortTotal = c.Cohort.RequestableResources[rName][flavor.Name]
+		// The exclusive flavors of other ClusterQueues aren't lent.
+		exclusiveTotal, exclusiveUsed := c.ExclusiveInCohort(rName, flavor.Name)
+		cohortTotal -= exclusiveTotal
+		cohortUsed -= exclusiveUsed
+	}
+	cohortTotal -= c.ReservedForOthers(rName, flavor.Name, nsLabels)
+	borrow := used + val - flavor.Min
+	if borrow < 0 {
+		borrow = 0
+	}
+
+	lack := cohortUsed + val - cohortTotal
+	if lack > 0 {
+		lackQuantity := workload.ResourceQuantity(rName, lack)
+		if c.Cohort == nil {
+			return 0, fmt.Errorf("insufficient quota for %s flavor %s, %s more needed", rName, flavor.Name, &lackQuantity)
+		}
+		// TODO(PostMVP): preemption could help if borrow == 0
+		return 0, fmt.Errorf("insufficient quota for %s flavor %s, %s more needed after borrowing", rName, flavor.Name, &lackQuantity)
+	}
+	return borrow, nil
+}
+
+// FitsNamespaceLimit returns an error if val more of the resource doesn't fit
+// in the share of the min quota of the flavor that the namespace can use.
+func (c *ClusterQueue) FitsNamespaceLimit(rName corev1.ResourceName, val int64, namespace string, flavor *FlavorLimits) error {
+	if c.MaxNamespaceUsagePercentage == nil {
+		return nil
+	}
+	limit := flavor.Min * int64(*c.MaxNamespaceUsagePercentage) / 100
+	if c.NamespaceUsage[namespace][rName][flavor.Name]+val > limit {
+		return fmt.Errorf("namespace %s would exceed its %d%% share of the quota for %s flavor %s", namespace, *c.MaxNamespaceUsagePercentage, rName, flavor.Name)
+	}
+	return nil
 }
 
 func (c *ClusterQueue) addLocalQueue(q *kueue.LocalQueue) error {
@@ -304,18 +700,22 @@ func (c *ClusterQueue) addLocalQueue(q *kueue.LocalQueue) error {
 	// We need to count the workloads, because they could have been added before
 	// receiving the queue add event.
 	workloads := 0
+	qc := newQueueConsumption(q.Status.Consumption, c.clock.Now())
 	for _, wl := range c.Workloads {
 		if workloadBelongsToLocalQueue(wl.Obj, q) {
 			workloads++
+			qc.updateWorkloadUsage(wl, 1)
 		}
 	}
 	c.admittedWorkloadsPerQueue[qKey] = workloads
+	c.localQueueConsumption[qKey] = qc
 	return nil
 }
 
 func (c *ClusterQueue) deleteLocalQueue(q *kueue.LocalQueue) {
 	qKey := queueKey(q)
 	delete(c.admittedWorkloadsPerQueue, qKey)
+	delete(c.localQueueConsumption, qKey)
 }
 
 func (c *ClusterQueue) flavorInUse(flavor string) bool {
@@ -329,8 +729,11 @@ func (c *ClusterQueue) flavorInUse(flavor string) bool {
 	return false
 }
 
-func (c *Cache) updateClusterQueues() sets.String {
-	cqs := sets.NewString()
+// updateClusterQueues updates all the ClusterQueues with the current
+// ResourceFlavors. It returns the names of the ClusterQueues that became
+// active and of those that became pending.
+func (c *Cache) updateClusterQueues() (activated, deactivated sets.String) {
+	activated, deactivated = sets.NewString(), sets.NewString()
 
 	for _, cq := range c.clusterQueues {
 		prevStatus := cq.Status
@@ -340,30 +743,98 @@ func (c *Cache) updateClusterQueues() sets.String {
 		cq.UpdateWithFlavors(c.resourceFlavors)
 		curStatus := cq.Status
 		if prevStatus == pending && curStatus == active {
-			cqs.Insert(cq.Name)
+			activated.Insert(cq.Name)
+		}
+		if prevStatus == active && curStatus == pending {
+			deactivated.Insert(cq.Name)
 		}
 	}
-	return cqs
+	return activated, deactivated
 }
 
+// AddOrUpdateResourceFlavor stores the ResourceFlavor. It returns the names of
+// the ClusterQueues that became active.
 func (c *Cache) AddOrUpdateResourceFlavor(rf *kueue.ResourceFlavor) sets.String {
 	c.Lock()
 	defer c.Unlock()
 	c.resourceFlavors[rf.Name] = rf
-	return c.updateClusterQueues()
+	activated, _ := c.updateClusterQueues()
+	return activated
 }
 
+// DeleteResourceFlavor removes the ResourceFlavor. It returns the names of the
+// ClusterQueues that became pending because they reference it.
 func (c *Cache) DeleteResourceFlavor(rf *kueue.ResourceFlavor) sets.String {
 	c.Lock()
 	defer c.Unlock()
 	delete(c.resourceFlavors, rf.Name)
-	return c.updateClusterQueues()
+	_, deactivated := c.updateClusterQueues()
+	return deactivated
+}
+
+// MissingFlavors returns the names of the ResourceFlavors referenced by the
+// ClusterQueue that don't exist.
+func (c *Cache) MissingFlavors(name string) []string {
+	c.RLock()
+	defer c.RUnlock()
+	cq, ok := c.clusterQueues[name]
+	if !ok {
+		return nil
+	}
+	missing := sets.NewString()
+	for _, res := range cq.RequestableResources {
+		for _, rf := range res.Flavors {
+			if _, ok := c.resourceFlavors[rf.Name]; !ok {
+				missing.Insert(rf.Name)
+			}
+		}
+	}
+	return missing.List()
+}
+
+// AddOrUpdateReservation stores the reservation. It returns the names of the
+// ClusterQueues whose reserved quota changed.
+func (c *Cache) AddOrUpdateReservation(r *kueue.Reservation) sets.String {
+	c.Lock()
+	defer c.Unlock()
+	cqs := sets.NewString(string(r.Spec.ClusterQueue))
+	if old, ok := c.reservations[r.Name]; ok {
+		cqs.Insert(string(old.Spec.ClusterQueue))
+	}
+	c.reservations[r.Name] = r
+	return cqs
+}
+
+// DeleteReservation removes the reservation with the given name. It returns
+// the names of the ClusterQueues whose reserved quota changed.
+func (c *Cache) DeleteReservation(name string) sets.String {
+	c.Lock()
+	defer c.Unlock()
+	cqs := sets.NewString()
+	if old, ok := c.reservations[name]; ok {
+		cqs.Insert(string(old.Spec.ClusterQueue))
+		delete(c.reservations, name)
+	}
+	return cqs
 }
 
 func (c *Cache) ClusterQueueActive(name string) bool {
 	return c.clusterQueueInStatus(name, active)
 }
 
+// AdmissionChecksForClusterQueue returns the names of the admission checks
+// required by the ClusterQueue.
+func (c *Cache) AdmissionChecksForClusterQueue(name string) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq, exists := c.clusterQueues[name]
+	if !exists {
+		return nil
+	}
+	return cq.AdmissionChecks
+}
+
 func (c *Cache) ClusterQueueTerminating(name string) bool {
 	return c.clusterQueueInStatus(name, terminating)
 }
@@ -426,6 +897,7 @@ func (c *Cache) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) err
 		// Checking ClusterQueue name again because the field index is not available in tests.
 		if string(q.Spec.ClusterQueue) == cq.Name {
 			cqImpl.admittedWorkloadsPerQueue[queueKey(&q)] = 0
+			cqImpl.localQueueConsumption[queueKey(&q)] = newQueueConsumption(q.Status.Consumption, c.clock.Now())
 		}
 	}
 	var workloads kueue.WorkloadList
@@ -630,6 +1102,112 @@ func (c *Cache) ForgetWorkload(w *kueue.Workload) error {
 	return nil
 }
 
+// AssumeManualAdmission assumes the workload like AssumeWorkload, after
+// validating the admission set manually in it as the scheduler would: the
+// ClusterQueue must be active and its namespaceSelector must match the labels
+// of the namespace of the workload, nsLabels, the LocalQueue of the workload
+// must point to the ClusterQueue, every resource requested by the workload
+// must be assigned one of the flavors of the ClusterQueue, and the requests
+// must fit in the quota available for the namespace in a snapshot of the
+// ClusterQueue and its cohort.
+func (c *Cache) AssumeManualAdmission(w *kueue.Workload, nsLabels map[string]string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if w.Spec.Admission == nil {
+		return errWorkloadNotAdmitted
+	}
+
+	k := workload.Key(w)
+	if assumedCq, assumed := c.assumedWorkloads[k]; assumed {
+		return fmt.Errorf("the workload is already assumed to ClusterQueue %q", assumedCq)
+	}
+
+	cq, ok := c.clusterQueues[string(w.Spec.Admission.ClusterQueue)]
+	if !ok {
+		return errCqNotFound
+	}
+	if !cq.Active() {
+		return fmt.Errorf("ClusterQueue %s is inactive", cq.Name)
+	}
+	if !cq.NamespaceSelector.Matches(labels.Set(nsLabels)) {
+		return fmt.Errorf("namespace %s doesn't match the namespaceSelector of ClusterQueue %s", w.Namespace, cq.Name)
+	}
+	if _, ok := cq.admittedWorkloadsPerQueue[workload.QueueKey(w)]; !ok {
+		return fmt.Errorf("LocalQueue %s doesn't point to ClusterQueue %s", w.Spec.QueueName, cq.Name)
+	}
+	wi := workload.NewInfo(w, cq.WorkloadInfoOptions()...)
+	requests := make(ResourceQuantities)
+	addRequests := func(podSet string, reqs workload.Requests, flavors map[corev1.ResourceName]string) error {
+		if err := cq.validateFlavors(podSet, reqs, flavors); err != nil {
+			return err
+		}
+		for rName, v := range reqs {
+			if requests[rName] == nil {
+				requests[rName] = make(map[string]int64)
+			}
+			requests[rName][flavors[rName]] += v
+		}
+		return nil
+	}
+	for _, ps := range wi.TotalRequests {
+		if err := addRequests(ps.Name, ps.Requests, ps.Flavors); err != nil {
+			return err
+		}
+	}
+
+	// The requests of all the podSets for the same flavor are checked
+	// together, with the checks of the scheduler.
+	cqSnapshot := c.snapshotWithCohort(cq)
+	for rName, flavors := range requests {
+		for flavor, v := range flavors {
+			limits := flavorLimits(cqSnapshot, rName, flavor)
+			if _, err := cqSnapshot.FitsFlavorLimits(rName, v, nsLabels, limits); err != nil {
+				return err
+			}
+			if err := cqSnapshot.FitsNamespaceLimit(rName, v, w.Namespace, limits); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cq.addWorkload(w); err != nil {
+		return err
+	}
+	c.assumedWorkloads[k] = cq.Name
+	return nil
+}
+
+// validateFlavors returns an error if any of the requested resources isn't
+// assigned a flavor defined for it in the ClusterQueue.
+func (c *ClusterQueue) validateFlavors(podSet string, requests workload.Requests, flavors map[corev1.ResourceName]string) error {
+	for rName := range requests {
+		flavor, ok := flavors[rName]
+		if !ok {
+			return fmt.Errorf("podSet %s doesn't have a flavor assigned for resource %s", podSet, rName)
+		}
+		if res := c.RequestableResources[rName]; res == nil || !res.HasFlavor(flavor) {
+			return fmt.Errorf("flavor %s for resource %s isn't defined in ClusterQueue %s", flavor, rName, c.Name)
+		}
+	}
+	return nil
+}
+
+// flavorLimits returns the quota of the flavor for the resource in the
+// ClusterQueue, or nil if the ClusterQueue doesn't define it.
+func flavorLimits(cq *ClusterQueue, rName corev1.ResourceName, flavor string) *FlavorLimits {
+	res := cq.RequestableResources[rName]
+	if res == nil {
+		return nil
+	}
+	for i := range res.Flavors {
+		if res.Flavors[i].Name == flavor {
+			return &res.Flavors[i]
+		}
+	}
+	return nil
+}
+
 // Usage reports the used resources and number of workloads admitted by the ClusterQueue.
 func (c *Cache) Usage(cqObj *kueue.ClusterQueue) (kueue.UsedResources, int, error) {
 	c.RLock()
@@ -660,6 +1238,23 @@ func (c *Cache) Usage(cqObj *kueue.ClusterQueue) (kueue.UsedResources, int, erro
 	return usage, len(cq.Workloads), nil
 }
 
+// BudgetUsage reports the consumption of the budget of the ClusterQueue in
+// the current window, or nil if the ClusterQueue doesn't have a budget.
+func (c *Cache) BudgetUsage(cqObj *kueue.ClusterQueue) (*kueue.BudgetUsage, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cq := c.clusterQueues[cqObj.Name]
+	if cq == nil {
+		return nil, errCqNotFound
+	}
+	if cq.budget == nil {
+		return nil, nil
+	}
+	cq.accrueBudget(c.clock.Now())
+	return cq.budget.usage(), nil
+}
+
 func (c *Cache) cleanupAssumedState(w *kueue.Workload) {
 	k := workload.Key(w)
 	assumedCQName, assumed := c.assumedWorkloads[k]
@@ -711,6 +1306,78 @@ func (c *Cache) FlavorInUse(flavor string) (string, bool) {
 	return "", false
 }
 
+// BorrowingLimitsBeyondCohort returns a message for each flavor in the
+// ClusterQueue whose max quota can never be reached, because it's greater
+// than the sum of the min quotas of the cohort. For a ClusterQueue without a
+// cohort, any max quota greater than the min quota is reported.
+func (c *Cache) BorrowingLimitsBeyondCohort(cqName string) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.clusterQueues[cqName]
+	if cq == nil {
+		return nil
+	}
+	cohortMin := cq.cohortMinQuotas()
+	var msgs []string
+	for rName, res := range cq.RequestableResources {
+		for _, flv := range res.Flavors {
+			if flv.Max == nil {
+				continue
+			}
+			total := cohortMin[rName][flv.Name]
+			if *flv.Max <= total {
+				continue
+			}
+			maxQuantity := workload.ResourceQuantity(rName, *flv.Max)
+			totalQuantity := workload.ResourceQuantity(rName, total)
+			if cq.Cohort == nil {
+				msgs = append(msgs, fmt.Sprintf("max quota %s for %s flavor %s is greater than the min quota %s, but the ClusterQueue doesn't belong to a cohort", &maxQuantity, rName, flv.Name, &totalQuantity))
+			} else {
+				msgs = append(msgs, fmt.Sprintf("max quota %s for %s flavor %s is greater than the total min quota %s in cohort %s", &maxQuantity, rName, flv.Name, &totalQuantity, cq.Cohort.Name))
+			}
+		}
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+// ClusterQueuesInCohort returns the names of the ClusterQueues in the cohort.
+func (c *Cache) ClusterQueuesInCohort(cohortName string) sets.String {
+	c.RLock()
+	defer c.RUnlock()
+
+	names := sets.NewString()
+	if cohort := c.cohorts[cohortName]; cohort != nil {
+		for cq := range cohort.members {
+			names.Insert(cq.Name)
+		}
+	}
+	return names
+}
+
+// cohortMinQuotas returns the sum of the min quotas of all the ClusterQueues
+// in the cohort, or the min quotas of the ClusterQueue if it doesn't belong
+// to a cohort.
+func (c *ClusterQueue) cohortMinQuotas() ResourceQuantities {
+	members := map[*ClusterQueue]struct{}{c: {}}
+	if c.Cohort != nil {
+		members = c.Cohort.members
+	}
+	total := make(ResourceQuantities)
+	for member := range members {
+		for rName, res := range member.RequestableResources {
+			if total[rName] == nil {
+				total[rName] = make(map[string]int64, len(res.Flavors))
+			}
+			for _, flv := range res.Flavors {
+				total[rName][flv.Name] += flv.Min
+			}
+		}
+	}
+	return total
+}
+
 func (c *Cache) MatchingClusterQueues(nsLabels map[string]string) sets.String {
 	c.RLock()
 	defer c.RUnlock()
@@ -735,9 +1402,28 @@ func resourcesByName(in []kueue.Resource) map[corev1.ResourceName]*Resource {
 				Name: string(f.Name),
 				Min:  workload.ResourceValue(r.Name, f.Quota.Min),
 			}
+			if f.Cost != nil {
+				fLimits.Cost = *f.Cost
+			}
+			if f.EvictionLimit != nil {
+				fLimits.EvictionLimit = *f.EvictionLimit
+			}
 			if f.Quota.Max != nil {
 				fLimits.Max = pointer.Int64(workload.ResourceValue(r.Name, *f.Quota.Max))
 			}
+			if f.Exclusive != nil {
+				fLimits.Exclusive = *f.Exclusive
+			}
+			if nr := f.NamespaceReservation; nr != nil {
+				fLimits.Reserved = workload.ResourceValue(r.Name, nr.Quantity)
+				selector, err := metav1.LabelSelectorAsSelector(&nr.NamespaceSelector)
+				if err != nil {
+					// The selector is validated by the webhook. Keep the
+					// quantity reserved for no namespace.
+					selector = labels.Nothing()
+				}
+				fLimits.ReservedFor = selector
+			}
 			flavors[i] = fLimits
 
 		}
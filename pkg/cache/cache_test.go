@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
@@ -553,7 +554,7 @@ func TestCacheClusterQueueOperations(t *testing.T) {
 			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
 			tc.operation(cache)
 			if diff := cmp.Diff(tc.wantClusterQueues, cache.clusterQueues,
-				cmpopts.IgnoreFields(ClusterQueue{}, "Cohort", "Workloads"), cmpopts.IgnoreUnexported(ClusterQueue{})); diff != "" {
+				cmpopts.IgnoreFields(ClusterQueue{}, "Cohort", "Workloads", "NamespaceUsage"), cmpopts.IgnoreUnexported(ClusterQueue{})); diff != "" {
 				t.Errorf("Unexpected clusterQueues (-want,+got):\n%s", diff)
 			}
 
@@ -640,14 +641,14 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		t.Fatalf("Failed adding kueue scheme: %v", err)
 	}
 	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
-		utiltesting.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
+		builder.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
 			ClusterQueue:  "one",
 			PodSetFlavors: podSetFlavors,
 		}).Obj(),
-		utiltesting.MakeWorkload("b", "").Admit(&kueue.Admission{
+		builder.MakeWorkload("b", "").Admit(&kueue.Admission{
 			ClusterQueue: "one",
 		}).Obj(),
-		utiltesting.MakeWorkload("c", "").PodSets(podSets).Admit(&kueue.Admission{
+		builder.MakeWorkload("c", "").PodSets(podSets).Admit(&kueue.Admission{
 			ClusterQueue: "two",
 		}).Obj(),
 	).Build()
@@ -668,14 +669,14 @@ func TestCacheWorkloadOperations(t *testing.T) {
 			name: "add",
 			operation: func(cache *Cache) error {
 				workloads := []*kueue.Workload{
-					utiltesting.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "one",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
-					utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+					builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 						ClusterQueue: "two",
 					}).Obj(),
-					utiltesting.MakeWorkload("pending", "").Obj(),
+					builder.MakeWorkload("pending", "").Obj(),
 				}
 				for i := range workloads {
 					cache.AddOrUpdateWorkload(workloads[i])
@@ -696,7 +697,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "add error clusterQueue doesn't exist",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "three",
 				}).Obj()
 				if !cache.AddOrUpdateWorkload(w) {
@@ -719,7 +720,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "add already exists",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("b", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("b", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
 				if !cache.AddOrUpdateWorkload(w) {
@@ -741,10 +742,10 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "update",
 			operation: func(cache *Cache) error {
-				old := utiltesting.MakeWorkload("a", "").Admit(&kueue.Admission{
+				old := builder.MakeWorkload("a", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
-				latest := utiltesting.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
+				latest := builder.MakeWorkload("a", "").PodSets(podSets).Admit(&kueue.Admission{
 					ClusterQueue:  "two",
 					PodSetFlavors: podSetFlavors,
 				}).Obj()
@@ -764,10 +765,10 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "update error old clusterQueue doesn't exist",
 			operation: func(cache *Cache) error {
-				old := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				old := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "three",
 				}).Obj()
-				latest := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				latest := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
 				return cache.UpdateWorkload(old, latest)
@@ -787,10 +788,10 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "update error new clusterQueue doesn't exist",
 			operation: func(cache *Cache) error {
-				old := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				old := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
-				latest := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				latest := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "three",
 				}).Obj()
 				return cache.UpdateWorkload(old, latest)
@@ -810,10 +811,10 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "update workload which doesn't exist.",
 			operation: func(cache *Cache) error {
-				old := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				old := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
-				latest := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				latest := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "two",
 				}).Obj()
 				return cache.UpdateWorkload(old, latest)
@@ -832,7 +833,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "delete",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("a", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("a", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
 				return cache.DeleteWorkload(w)
@@ -851,7 +852,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "delete error clusterQueue doesn't exist",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("a", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("a", "").Admit(&kueue.Admission{
 					ClusterQueue: "three",
 				}).Obj()
 				return cache.DeleteWorkload(w)
@@ -871,7 +872,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "delete workload which doesn't exist",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("d", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("d", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
 				return cache.DeleteWorkload(w)
@@ -891,11 +892,11 @@ func TestCacheWorkloadOperations(t *testing.T) {
 			name: "assume",
 			operation: func(cache *Cache) error {
 				workloads := []*kueue.Workload{
-					utiltesting.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "one",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
-					utiltesting.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "two",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
@@ -925,7 +926,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "assume error clusterQueue doesn't exist",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
+				w := builder.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
 					ClusterQueue: "three",
 				}).Obj()
 				if err := cache.AssumeWorkload(w); err != nil {
@@ -950,11 +951,11 @@ func TestCacheWorkloadOperations(t *testing.T) {
 			name: "forget",
 			operation: func(cache *Cache) error {
 				workloads := []*kueue.Workload{
-					utiltesting.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "one",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
-					utiltesting.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "two",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
@@ -985,7 +986,7 @@ func TestCacheWorkloadOperations(t *testing.T) {
 		{
 			name: "forget error workload is not assumed",
 			operation: func(cache *Cache) error {
-				w := utiltesting.MakeWorkload("b", "").Admit(&kueue.Admission{
+				w := builder.MakeWorkload("b", "").Admit(&kueue.Admission{
 					ClusterQueue: "one",
 				}).Obj()
 				if err := cache.ForgetWorkload(w); err != nil {
@@ -1009,11 +1010,11 @@ func TestCacheWorkloadOperations(t *testing.T) {
 			name: "add assumed workload",
 			operation: func(cache *Cache) error {
 				workloads := []*kueue.Workload{
-					utiltesting.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("d", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "one",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
-					utiltesting.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
+					builder.MakeWorkload("e", "").PodSets(podSets).Admit(&kueue.Admission{
 						ClusterQueue:  "two",
 						PodSetFlavors: podSetFlavors,
 					}).Obj(),
@@ -1252,21 +1253,601 @@ func TestClusterQueueUsage(t *testing.T) {
 	}
 }
 
+func TestClusterQueuePodOverheadPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	ctx := context.Background()
+	cq := builder.MakeClusterQueue("foo").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		PodOverheadPolicy(kueue.PodOverheadExclude).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	wl := builder.MakeWorkload("one", "").
+		Request(corev1.ResourceCPU, "2").
+		Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	wl.Spec.PodSets[0].Spec.Overhead = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
+	if added := cache.AddOrUpdateWorkload(wl); !added {
+		t.Fatalf("Workload %s was not added", workload.Key(wl))
+	}
+	wantUsed := ResourceQuantities{
+		corev1.ResourceCPU: {"default": 2_000},
+	}
+	if diff := cmp.Diff(wantUsed, cache.clusterQueues["foo"].UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources with overhead excluded (-want,+got):\n%s", diff)
+	}
+
+	cq.Spec.PodOverheadPolicy = kueue.PodOverheadInclude
+	if err := cache.UpdateClusterQueue(cq); err != nil {
+		t.Fatalf("Updating ClusterQueue: %v", err)
+	}
+	wantUsed = ResourceQuantities{
+		corev1.ResourceCPU: {"default": 3_000},
+	}
+	if diff := cmp.Diff(wantUsed, cache.clusterQueues["foo"].UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources with overhead included (-want,+got):\n%s", diff)
+	}
+}
+
+func TestCacheWorkloadInfoOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build(), WithWorkloadInfoOptions(workload.WithoutInitContainers()))
+	ctx := context.Background()
+	cq := builder.MakeClusterQueue("foo").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	wl := builder.MakeWorkload("one", "").
+		Request(corev1.ResourceCPU, "2").
+		Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	wl.Spec.PodSets[0].Spec.InitContainers = []corev1.Container{
+		{
+			Name: "warmer",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("8"),
+				},
+			},
+		},
+	}
+	if added := cache.AddOrUpdateWorkload(wl); !added {
+		t.Fatalf("Workload %s was not added", workload.Key(wl))
+	}
+	wantUsed := ResourceQuantities{
+		corev1.ResourceCPU: {"default": 2_000},
+	}
+	if diff := cmp.Diff(wantUsed, cache.clusterQueues["foo"].UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources (-want,+got):\n%s", diff)
+	}
+}
+
+func TestAssumeManualAdmission(t *testing.T) {
+	clusterQueues := []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("solo").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "4").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("a").
+			Cohort("team").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "4").Max("6").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("b").
+			Cohort("team").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "4").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("selective").
+			NamespaceSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"dep": "eng"}}).
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "4").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("partly-reserved").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "4").
+					NamespaceReservation("3", metav1.LabelSelector{MatchLabels: map[string]string{"dep": "research"}}).
+					Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("inactive").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("missing", "4").Obj()).Obj()).
+			Obj(),
+	}
+	admitted := builder.MakeWorkload("admitted", "").
+		Request(corev1.ResourceCPU, "2").
+		Admit(builder.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	cases := map[string]struct {
+		workload *kueue.Workload
+		nsLabels map[string]string
+		wantErr  bool
+		wantUsed map[string]ResourceQuantities
+	}{
+		"fits in the min quota": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("solo").
+				Request(corev1.ResourceCPU, "4").
+				Admit(builder.MakeAdmission("solo").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantUsed: map[string]ResourceQuantities{
+				"solo": {corev1.ResourceCPU: {"default": 4_000}},
+				"a":    {corev1.ResourceCPU: {"default": 2_000}},
+			},
+		},
+		"exceeds the min quota without a cohort": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("solo").
+				Request(corev1.ResourceCPU, "5").
+				Admit(builder.MakeAdmission("solo").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"borrows from the cohort": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("a").
+				Request(corev1.ResourceCPU, "4").
+				Admit(builder.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantUsed: map[string]ResourceQuantities{
+				"solo": {corev1.ResourceCPU: {"default": 0}},
+				"a":    {corev1.ResourceCPU: {"default": 6_000}},
+			},
+		},
+		"exceeds the max quota": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("a").
+				Request(corev1.ResourceCPU, "5").
+				Admit(builder.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"exceeds the quota of the cohort": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("b").
+				Request(corev1.ResourceCPU, "7").
+				Admit(builder.MakeAdmission("b").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"namespace matches the selector": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("selective").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("selective").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			nsLabels: map[string]string{"dep": "eng"},
+			wantUsed: map[string]ResourceQuantities{
+				"selective": {corev1.ResourceCPU: {"default": 1_000}},
+			},
+		},
+		"namespace doesn't match the selector": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("selective").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("selective").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			nsLabels: map[string]string{"dep": "sales"},
+			wantErr:  true,
+		},
+		"LocalQueue points to another ClusterQueue": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("a").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("solo").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"LocalQueue doesn't exist": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("missing").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("solo").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"quota reserved for other namespaces": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("partly-reserved").
+				Request(corev1.ResourceCPU, "2").
+				Admit(builder.MakeAdmission("partly-reserved").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"several podSets exceed the quota together": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("solo").
+				PodSets([]kueue.PodSet{
+					{
+						Name:  "driver",
+						Spec:  utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{corev1.ResourceCPU: "2"}),
+						Count: 1,
+					},
+					{
+						Name:  "workers",
+						Spec:  utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{corev1.ResourceCPU: "1"}),
+						Count: 3,
+					},
+				}).
+				Admit(&kueue.Admission{
+					ClusterQueue: "solo",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{Name: "driver", Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: "default"}},
+						{Name: "workers", Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: "default"}},
+					},
+				}).
+				Obj(),
+			wantErr: true,
+		},
+		"flavor not defined in the ClusterQueue": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("solo").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("solo").Flavor(corev1.ResourceCPU, "spot").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"resource without a flavor": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("solo").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("solo").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"inactive ClusterQueue": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("inactive").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("inactive").Flavor(corev1.ResourceCPU, "missing").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+		"ClusterQueue not found": {
+			workload: builder.MakeWorkload("wl", "ns").Queue("nonexistent").
+				Request(corev1.ResourceCPU, "1").
+				Admit(builder.MakeAdmission("nonexistent").Flavor(corev1.ResourceCPU, "default").Obj()).
+				Obj(),
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+			ctx := context.Background()
+			cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+			for _, cq := range clusterQueues {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Adding ClusterQueue: %v", err)
+				}
+				if err := cache.AddLocalQueue(builder.MakeLocalQueue(cq.Name, "ns").ClusterQueue(cq.Name).Obj()); err != nil {
+					t.Fatalf("Adding LocalQueue: %v", err)
+				}
+			}
+			if added := cache.AddOrUpdateWorkload(admitted); !added {
+				t.Fatalf("Workload %s was not added", workload.Key(admitted))
+			}
+			err := cache.AssumeManualAdmission(tc.workload, tc.nsLabels)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("AssumeManualAdmission returned %v, want error %t", err, tc.wantErr)
+			}
+			_, assumed := cache.assumedWorkloads[workload.Key(tc.workload)]
+			if assumed == tc.wantErr {
+				t.Errorf("Workload assumed: %t, want %t", assumed, !tc.wantErr)
+			}
+			wantUsed := tc.wantUsed
+			if tc.wantErr {
+				wantUsed = map[string]ResourceQuantities{
+					"solo": {corev1.ResourceCPU: {"default": 0}},
+					"a":    {corev1.ResourceCPU: {"default": 2_000}},
+				}
+			}
+			for cqName, want := range wantUsed {
+				if diff := cmp.Diff(want, cache.clusterQueues[cqName].UsedResources); diff != "" {
+					t.Errorf("Unexpected used resources in ClusterQueue %s (-want,+got):\n%s", cqName, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheLocalQueueUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	ctx := context.Background()
+	cq := builder.MakeClusterQueue("foo").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("on-demand", "10").Obj()).
+			Flavor(builder.MakeFlavor("spot", "10").Obj()).Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	for _, wl := range []*kueue.Workload{
+		builder.MakeWorkload("one", "ns").Queue("alpha").
+			Request(corev1.ResourceCPU, "2").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+			Obj(),
+		builder.MakeWorkload("two", "ns").Queue("alpha").
+			Request(corev1.ResourceCPU, "3").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "spot").Obj()).
+			Obj(),
+		builder.MakeWorkload("three", "ns").Queue("beta").
+			Request(corev1.ResourceCPU, "4").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+			Obj(),
+	} {
+		if added := cache.AddOrUpdateWorkload(wl); !added {
+			t.Fatalf("Workload %s was not added", workload.Key(wl))
+		}
+	}
+	lq := builder.MakeLocalQueue("alpha", "ns").ClusterQueue("foo").Obj()
+	want := ResourceQuantities{
+		corev1.ResourceCPU: {"on-demand": 2_000, "spot": 3_000},
+	}
+	if diff := cmp.Diff(want, cache.LocalQueueUsage(lq)); diff != "" {
+		t.Errorf("Unexpected usage (-want,+got):\n%s", diff)
+	}
+}
+
+func TestClusterQueueNamespaceUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("on-demand").Obj())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("spot").Obj())
+	cq := builder.MakeClusterQueue("foo").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("on-demand", "10").Obj()).
+			Flavor(builder.MakeFlavor("spot", "10").Obj()).Obj()).
+		MaxNamespaceUsagePercentage(50).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	workloads := []*kueue.Workload{
+		builder.MakeWorkload("one", "ns1").
+			Request(corev1.ResourceCPU, "2").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+			Obj(),
+		builder.MakeWorkload("two", "ns1").
+			Request(corev1.ResourceCPU, "3").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "spot").Obj()).
+			Obj(),
+		builder.MakeWorkload("three", "ns2").
+			Request(corev1.ResourceCPU, "4").
+			Admit(builder.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+			Obj(),
+	}
+	for _, wl := range workloads {
+		if added := cache.AddOrUpdateWorkload(wl); !added {
+			t.Fatalf("Workload %s was not added", workload.Key(wl))
+		}
+	}
+	want := map[string]ResourceQuantities{
+		"ns1": {corev1.ResourceCPU: {"on-demand": 2_000, "spot": 3_000}},
+		"ns2": {corev1.ResourceCPU: {"on-demand": 4_000}},
+	}
+	snap := cache.Snapshot()
+	if diff := cmp.Diff(want, snap.ClusterQueues["foo"].NamespaceUsage); diff != "" {
+		t.Errorf("Unexpected usage in snapshot (-want,+got):\n%s", diff)
+	}
+
+	if err := cache.DeleteWorkload(workloads[2]); err != nil {
+		t.Fatalf("Deleting workload: %v", err)
+	}
+	delete(want, "ns2")
+	if diff := cmp.Diff(want, cache.clusterQueues["foo"].NamespaceUsage); diff != "" {
+		t.Errorf("Unexpected usage after deleting a workload (-want,+got):\n%s", diff)
+	}
+}
+
+func TestReservedForOthers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	teamA := metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	for _, cq := range []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("a").Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").NamespaceReservation("4", teamA).Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("b").Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("c").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").NamespaceReservation("2", teamA).Obj()).Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	snap := cache.Snapshot()
+	cases := map[string]struct {
+		clusterQueue string
+		nsLabels     labels.Set
+		want         int64
+	}{
+		"matching namespace": {
+			clusterQueue: "a",
+			nsLabels:     labels.Set{"team": "a"},
+		},
+		"other namespace": {
+			clusterQueue: "a",
+			nsLabels:     labels.Set{"team": "b"},
+			want:         4_000,
+		},
+		"other namespace in a cohort peer": {
+			clusterQueue: "b",
+			nsLabels:     labels.Set{"team": "b"},
+			want:         4_000,
+		},
+		"matching namespace in a cohort peer": {
+			clusterQueue: "b",
+			nsLabels:     labels.Set{"team": "a"},
+		},
+		"other namespace without cohort": {
+			clusterQueue: "c",
+			want:         2_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := snap.ClusterQueues[tc.clusterQueue].ReservedForOthers(corev1.ResourceCPU, "default", tc.nsLabels)
+			if got != tc.want {
+				t.Errorf("ReservedForOthers(_)=%d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReservedForOthersWithUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	teamA := metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	cq := builder.MakeClusterQueue("cq").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").NamespaceReservation("4", teamA).Obj()).Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	cache.UpdateNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-a", Labels: map[string]string{"team": "a"}}})
+	cache.UpdateNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-b", Labels: map[string]string{"team": "b"}}})
+	admission := builder.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()
+	for _, wl := range []*kueue.Workload{
+		builder.MakeWorkload("a", "ns-a").Request(corev1.ResourceCPU, "3").Admit(admission).Obj(),
+		builder.MakeWorkload("b", "ns-b").Request(corev1.ResourceCPU, "2").Admit(admission).Obj(),
+	} {
+		if !cache.AddOrUpdateWorkload(wl) {
+			t.Fatalf("Failed adding workload %s", wl.Name)
+		}
+	}
+
+	// The quota used by the namespace with the reservation counts as used, so
+	// only the rest of the reservation is unavailable for other namespaces.
+	snap := cache.Snapshot()
+	if got := snap.ClusterQueues["cq"].ReservedForOthers(corev1.ResourceCPU, "default", labels.Set{"team": "b"}); got != 1_000 {
+		t.Errorf("ReservedForOthers(_)=%d, want 1000", got)
+	}
+	cqSnap := snap.ClusterQueues["cq"]
+	limits := &cqSnap.RequestableResources[corev1.ResourceCPU].Flavors[0]
+	if _, err := cqSnap.FitsFlavorLimits(corev1.ResourceCPU, 4_000, labels.Set{"team": "b"}, limits); err != nil {
+		t.Errorf("FitsFlavorLimits(_) returned error: %v", err)
+	}
+	if _, err := cqSnap.FitsFlavorLimits(corev1.ResourceCPU, 4_001, labels.Set{"team": "b"}, limits); err == nil {
+		t.Error("FitsFlavorLimits(_) didn't return an error above the available quota")
+	}
+
+	// When the namespace no longer matches, its usage doesn't count towards
+	// the reservation.
+	cache.UpdateNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-a"}})
+	snap = cache.Snapshot()
+	if got := snap.ClusterQueues["cq"].ReservedForOthers(corev1.ResourceCPU, "default", labels.Set{"team": "b"}); got != 4_000 {
+		t.Errorf("ReservedForOthers(_)=%d after the namespace changed, want 4000", got)
+	}
+}
+
+func TestExclusiveInCohort(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("a").Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").Exclusive().Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("b").Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "6").Exclusive().Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("c").Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	for _, wl := range []*kueue.Workload{
+		builder.MakeWorkload("one", "ns").
+			Request(corev1.ResourceCPU, "4").
+			Admit(builder.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+			Obj(),
+		builder.MakeWorkload("two", "ns").
+			Request(corev1.ResourceCPU, "8").
+			Admit(builder.MakeAdmission("b").Flavor(corev1.ResourceCPU, "default").Obj()).
+			Obj(),
+	} {
+		if added := cache.AddOrUpdateWorkload(wl); !added {
+			t.Fatalf("Workload %s was not added", workload.Key(wl))
+		}
+	}
+	snap := cache.Snapshot()
+	cases := map[string]struct {
+		clusterQueue string
+		wantTotal    int64
+		wantUsed     int64
+	}{
+		"exclusive member": {
+			clusterQueue: "a",
+			wantTotal:    6_000,
+			wantUsed:     6_000,
+		},
+		"non-exclusive member": {
+			clusterQueue: "c",
+			wantTotal:    16_000,
+			wantUsed:     10_000,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			total, used := snap.ClusterQueues[tc.clusterQueue].ExclusiveInCohort(corev1.ResourceCPU, "default")
+			if total != tc.wantTotal || used != tc.wantUsed {
+				t.Errorf("ExclusiveInCohort(_)=(%d, %d), want (%d, %d)", total, used, tc.wantTotal, tc.wantUsed)
+			}
+		})
+	}
+}
+
 func TestCacheQueueOperations(t *testing.T) {
 	cqs := []*kueue.ClusterQueue{
-		utiltesting.MakeClusterQueue("foo").Obj(),
-		utiltesting.MakeClusterQueue("bar").Obj(),
+		builder.MakeClusterQueue("foo").Obj(),
+		builder.MakeClusterQueue("bar").Obj(),
 	}
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("alpha", "ns1").ClusterQueue("foo").Obj(),
-		utiltesting.MakeLocalQueue("beta", "ns2").ClusterQueue("foo").Obj(),
-		utiltesting.MakeLocalQueue("gamma", "ns1").ClusterQueue("bar").Obj(),
+		builder.MakeLocalQueue("alpha", "ns1").ClusterQueue("foo").Obj(),
+		builder.MakeLocalQueue("beta", "ns2").ClusterQueue("foo").Obj(),
+		builder.MakeLocalQueue("gamma", "ns1").ClusterQueue("bar").Obj(),
 	}
 	workloads := []*kueue.Workload{
-		utiltesting.MakeWorkload("job1", "ns1").Queue("alpha").Admit(utiltesting.MakeAdmission("foo").Obj()).Obj(),
-		utiltesting.MakeWorkload("job2", "ns2").Queue("beta").Admit(utiltesting.MakeAdmission("foo").Obj()).Obj(),
-		utiltesting.MakeWorkload("job3", "ns1").Queue("gamma").Admit(utiltesting.MakeAdmission("bar").Obj()).Obj(),
-		utiltesting.MakeWorkload("job4", "ns2").Queue("beta").Admit(utiltesting.MakeAdmission("foo").Obj()).Obj(),
+		builder.MakeWorkload("job1", "ns1").Queue("alpha").Admit(builder.MakeAdmission("foo").Obj()).Obj(),
+		builder.MakeWorkload("job2", "ns2").Queue("beta").Admit(builder.MakeAdmission("foo").Obj()).Obj(),
+		builder.MakeWorkload("job3", "ns1").Queue("gamma").Admit(builder.MakeAdmission("bar").Obj()).Obj(),
+		builder.MakeWorkload("job4", "ns2").Queue("beta").Admit(builder.MakeAdmission("foo").Obj()).Obj(),
 	}
 	insertAllClusterQueues := func(ctx context.Context, cl client.Client, cache *Cache) error {
 		for _, cq := range cqs {
@@ -1468,12 +2049,12 @@ func TestCacheQueueOperations(t *testing.T) {
 }
 
 func TestFlavorInUse(t *testing.T) {
-	rf := utiltesting.MakeResourceFlavor("x86").Obj()
-	flavor := utiltesting.MakeFlavor(rf.Name, "5").Obj()
-	fooCq := utiltesting.MakeClusterQueue("fooCq").
-		Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+	rf := builder.MakeResourceFlavor("x86").Obj()
+	flavor := builder.MakeFlavor(rf.Name, "5").Obj()
+	fooCq := builder.MakeClusterQueue("fooCq").
+		Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 		Obj()
-	barCq := utiltesting.MakeClusterQueue("barCq").Obj()
+	barCq := builder.MakeClusterQueue("barCq").Obj()
 
 	tests := []struct {
 		name                      string
@@ -1528,9 +2109,57 @@ func TestFlavorInUse(t *testing.T) {
 	}
 }
 
+func TestResourceFlavorTransitions(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	x86 := builder.MakeResourceFlavor("x86").Obj()
+	arm := builder.MakeResourceFlavor("arm").Obj()
+	cache.AddOrUpdateResourceFlavor(x86)
+	cache.AddOrUpdateResourceFlavor(arm)
+	cqs := []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("foo").
+			Resource(builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "5").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("bar").
+			Resource(builder.MakeResource("cpu").
+				Flavor(builder.MakeFlavor("x86", "5").Obj()).
+				Flavor(builder.MakeFlavor("arm", "5").Obj()).
+				Obj()).
+			Obj(),
+		builder.MakeClusterQueue("baz").
+			Resource(builder.MakeResource("cpu").Flavor(builder.MakeFlavor("arm", "5").Obj()).Obj()).
+			Obj(),
+	}
+	for _, cq := range cqs {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding clusterQueue %s: %v", cq.Name, err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"bar", "foo"}, cache.DeleteResourceFlavor(x86).List()); diff != "" {
+		t.Errorf("Unexpected deactivated clusterQueues after deleting x86 (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"x86"}, cache.MissingFlavors("bar")); diff != "" {
+		t.Errorf("Unexpected missing flavors of bar (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"baz"}, cache.DeleteResourceFlavor(arm).List()); diff != "" {
+		t.Errorf("Unexpected deactivated clusterQueues after deleting arm (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"arm", "x86"}, cache.MissingFlavors("bar")); diff != "" {
+		t.Errorf("Unexpected missing flavors of bar (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"foo"}, cache.AddOrUpdateResourceFlavor(x86).List()); diff != "" {
+		t.Errorf("Unexpected activated clusterQueues after adding x86 (-want,+got):\n%s", diff)
+	}
+}
+
 func TestClusterQueueUpdateWithFlavors(t *testing.T) {
-	rf := utiltesting.MakeResourceFlavor("x86").Obj()
-	flavor := utiltesting.MakeFlavor(rf.Name, "5").Obj()
+	rf := builder.MakeResourceFlavor("x86").Obj()
+	flavor := builder.MakeFlavor(rf.Name, "5").Obj()
 
 	testcases := []struct {
 		name         string
@@ -1542,8 +2171,8 @@ func TestClusterQueueUpdateWithFlavors(t *testing.T) {
 		{
 			name:      "Pending clusterQueue updated existent flavors",
 			curStatus: pending,
-			clusterQueue: utiltesting.MakeClusterQueue("cq").
-				Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 				Obj(),
 			flavors: map[string]*kueue.ResourceFlavor{
 				rf.Name: rf,
@@ -1553,8 +2182,8 @@ func TestClusterQueueUpdateWithFlavors(t *testing.T) {
 		{
 			name:      "Active clusterQueue updated with not found flavors",
 			curStatus: active,
-			clusterQueue: utiltesting.MakeClusterQueue("cq").
-				Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 				Obj(),
 			flavors:    map[string]*kueue.ResourceFlavor{},
 			wantStatus: pending,
@@ -1562,8 +2191,8 @@ func TestClusterQueueUpdateWithFlavors(t *testing.T) {
 		{
 			name:      "Terminating clusterQueue updated with existent flavors",
 			curStatus: terminating,
-			clusterQueue: utiltesting.MakeClusterQueue("cq").
-				Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 				Obj(),
 			flavors: map[string]*kueue.ResourceFlavor{
 				rf.Name: rf,
@@ -1573,8 +2202,8 @@ func TestClusterQueueUpdateWithFlavors(t *testing.T) {
 		{
 			name:      "Terminating clusterQueue updated with not found flavors",
 			curStatus: terminating,
-			clusterQueue: utiltesting.MakeClusterQueue("cq").
-				Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+			clusterQueue: builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 				Obj(),
 			flavors:    map[string]*kueue.ResourceFlavor{},
 			wantStatus: terminating,
@@ -1735,11 +2364,11 @@ func TestClusterQueueUpdateCodependentResources(t *testing.T) {
 
 func TestMatchingClusterQueues(t *testing.T) {
 	clusterQueues := []*kueue.ClusterQueue{
-		utiltesting.MakeClusterQueue("matching1").
+		builder.MakeClusterQueue("matching1").
 			NamespaceSelector(&metav1.LabelSelector{}).Obj(),
-		utiltesting.MakeClusterQueue("not-matching").
+		builder.MakeClusterQueue("not-matching").
 			NamespaceSelector(nil).Obj(),
-		utiltesting.MakeClusterQueue("matching2").
+		builder.MakeClusterQueue("matching2").
 			NamespaceSelector(&metav1.LabelSelector{
 				MatchExpressions: []metav1.LabelSelectorRequirement{
 					{
@@ -1776,3 +2405,137 @@ func messageOrEmpty(err error) string {
 	}
 	return err.Error()
 }
+
+func TestBorrowingLimitsBeyondCohort(t *testing.T) {
+	cases := map[string]struct {
+		clusterQueues []*kueue.ClusterQueue
+		cqName        string
+		want          []string
+	}{
+		"no cohort, no max": {
+			clusterQueues: []*kueue.ClusterQueue{
+				builder.MakeClusterQueue("foo").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Obj()).Obj()).
+					Obj(),
+			},
+			cqName: "foo",
+		},
+		"no cohort, max above min": {
+			clusterQueues: []*kueue.ClusterQueue{
+				builder.MakeClusterQueue("foo").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Max("10").Obj()).Obj()).
+					Obj(),
+			},
+			cqName: "foo",
+			want: []string{
+				"max quota 10 for cpu flavor default is greater than the min quota 5, but the ClusterQueue doesn't belong to a cohort",
+			},
+		},
+		"cohort can lend up to max": {
+			clusterQueues: []*kueue.ClusterQueue{
+				builder.MakeClusterQueue("foo").
+					Cohort("one").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Max("10").Obj()).Obj()).
+					Obj(),
+				builder.MakeClusterQueue("bar").
+					Cohort("one").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Obj()).Obj()).
+					Obj(),
+			},
+			cqName: "foo",
+		},
+		"cohort can't lend up to max": {
+			clusterQueues: []*kueue.ClusterQueue{
+				builder.MakeClusterQueue("foo").
+					Cohort("one").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Max("20").Obj()).
+						Flavor(builder.MakeFlavor("spot", "5").Max("10").Obj()).Obj()).
+					Obj(),
+				builder.MakeClusterQueue("bar").
+					Cohort("one").
+					Resource(builder.MakeResource(corev1.ResourceCPU).
+						Flavor(builder.MakeFlavor("default", "5").Obj()).Obj()).
+					Obj(),
+			},
+			cqName: "foo",
+			want: []string{
+				"max quota 10 for cpu flavor spot is greater than the total min quota 5 in cohort one",
+				"max quota 20 for cpu flavor default is greater than the total min quota 10 in cohort one",
+			},
+		},
+		"unknown ClusterQueue": {
+			cqName: "foo",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+			for _, cq := range tc.clusterQueues {
+				if err := cache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+				}
+			}
+			got := cache.BorrowingLimitsBeyondCohort(tc.cqName)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected messages (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClusterQueuesInCohort(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	for _, cq := range []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("foo").Cohort("one").Obj(),
+		builder.MakeClusterQueue("bar").Cohort("one").Obj(),
+		builder.MakeClusterQueue("baz").Cohort("two").Obj(),
+		builder.MakeClusterQueue("qux").Obj(),
+	} {
+		if err := cache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	cases := map[string][]string{
+		"one":     {"bar", "foo"},
+		"two":     {"baz"},
+		"unknown": {},
+	}
+	for cohort, want := range cases {
+		t.Run(cohort, func(t *testing.T) {
+			got := cache.ClusterQueuesInCohort(cohort).List()
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected ClusterQueues (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func BenchmarkAddAndDeleteWorkload(b *testing.B) {
+	cache := newCacheWithWorkloads(b, 10_000)
+	wl := builder.MakeWorkload("benchmark", "").Request(corev1.ResourceCPU, "1").
+		Admit(builder.MakeAdmission("cq-0").Flavor(corev1.ResourceCPU, "default").Obj()).Obj()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !cache.AddOrUpdateWorkload(wl) {
+			b.Fatal("Failed adding workload")
+		}
+		if err := cache.DeleteWorkload(wl); err != nil {
+			b.Fatalf("Failed deleting workload: %v", err)
+		}
+	}
+}
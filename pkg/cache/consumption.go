@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// queueConsumption accounts for the resources consumed by the admitted
+// workloads of a LocalQueue. The consumption is expressed as the value of the
+// resource, as used in ResourceQuantities, multiplied by seconds.
+type queueConsumption struct {
+	used         ResourceQuantities
+	consumed     ResourceQuantities
+	accruedUntil time.Time
+}
+
+// newQueueConsumption returns a queueConsumption that resumes the accounting
+// recorded in the status of the LocalQueue, if any. The consumption since the
+// status was last updated is unknown and not accounted for.
+func newQueueConsumption(status *kueue.LocalQueueConsumption, now time.Time) *queueConsumption {
+	qc := &queueConsumption{
+		used:         make(ResourceQuantities),
+		consumed:     make(ResourceQuantities),
+		accruedUntil: now,
+	}
+	if status == nil {
+		return qc
+	}
+	for _, c := range status.Resources {
+		if qc.consumed[c.Name] == nil {
+			qc.consumed[c.Name] = make(map[string]int64)
+		}
+		qc.consumed[c.Name][string(c.Flavor)] = workload.ResourceValue(c.Name, c.Quantity)
+	}
+	return qc
+}
+
+// accrue accounts for the consumption of the current usage until now.
+func (qc *queueConsumption) accrue(now time.Time) {
+	secs := int64(now.Sub(qc.accruedUntil) / time.Second)
+	if secs <= 0 {
+		return
+	}
+	for res, flavors := range qc.used {
+		for flv, v := range flavors {
+			if v == 0 {
+				continue
+			}
+			if qc.consumed[res] == nil {
+				qc.consumed[res] = make(map[string]int64)
+			}
+			qc.consumed[res][flv] += v * secs
+		}
+	}
+	qc.accruedUntil = qc.accruedUntil.Add(time.Duration(secs) * time.Second)
+}
+
+func (qc *queueConsumption) updateUsage(requests workload.Requests, flavors map[corev1.ResourceName]string, m int64) {
+	for rName, flavor := range flavors {
+		v, ok := requests[rName]
+		if !ok {
+			continue
+		}
+		if qc.used[rName] == nil {
+			qc.used[rName] = make(map[string]int64)
+		}
+		qc.used[rName][flavor] += v * m
+	}
+}
+
+func (qc *queueConsumption) updateWorkloadUsage(wi *workload.Info, m int64) {
+	for _, ps := range wi.TotalRequests {
+		qc.updateUsage(ps.Requests, ps.Flavors, m)
+	}
+}
+
+func (qc *queueConsumption) status() *kueue.LocalQueueConsumption {
+	status := &kueue.LocalQueueConsumption{
+		LastUpdateTime: metav1.NewTime(qc.accruedUntil),
+	}
+	for name, flavors := range qc.consumed {
+		for flv, v := range flavors {
+			status.Resources = append(status.Resources, kueue.ConsumedResource{
+				Name:     name,
+				Flavor:   kueue.ResourceFlavorReference(flv),
+				Quantity: workload.ResourceQuantity(name, v),
+			})
+		}
+	}
+	sort.Slice(status.Resources, func(i, j int) bool {
+		a, b := status.Resources[i], status.Resources[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Flavor < b.Flavor
+	})
+	return status
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestQueueConsumption(t *testing.T) {
+	start := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	small := workload.NewInfo(builder.MakeWorkload("small", "ns").
+		Request(corev1.ResourceCPU, "500m").
+		Admit(builder.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj())
+	big := workload.NewInfo(builder.MakeWorkload("big", "ns").
+		Request(corev1.ResourceCPU, "2").
+		Request("example.com/gpu", "1").
+		Admit(builder.MakeAdmission("cq").
+			Flavor(corev1.ResourceCPU, "default").
+			Flavor("example.com/gpu", "model-a").
+			Obj()).
+		Obj())
+
+	type step struct {
+		after    time.Duration
+		workload *workload.Info
+		m        int64
+	}
+	cases := map[string]struct {
+		status *kueue.LocalQueueConsumption
+		steps  []step
+		after  time.Duration
+		want   *kueue.LocalQueueConsumption
+	}{
+		"no workloads": {
+			after: time.Hour,
+			want: &kueue.LocalQueueConsumption{
+				LastUpdateTime: metav1.NewTime(start.Add(time.Hour)),
+			},
+		},
+		"workloads admitted and finished": {
+			steps: []step{
+				{workload: small, m: 1},
+				{after: time.Minute, workload: big, m: 1},
+				{after: time.Minute, workload: big, m: -1},
+			},
+			after: time.Minute,
+			want: &kueue.LocalQueueConsumption{
+				LastUpdateTime: metav1.NewTime(start.Add(3 * time.Minute)),
+				Resources: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("210")},
+					{Name: "example.com/gpu", Flavor: "model-a", Quantity: resource.MustParse("60")},
+				},
+			},
+		},
+		"resumed from status": {
+			status: &kueue.LocalQueueConsumption{
+				LastUpdateTime: metav1.NewTime(start.Add(-time.Hour)),
+				Resources: []kueue.ConsumedResource{
+					{Name: "example.com/gpu", Flavor: "model-a", Quantity: resource.MustParse("3600")},
+				},
+			},
+			steps: []step{
+				{workload: big, m: 1},
+			},
+			after: 1500 * time.Millisecond,
+			want: &kueue.LocalQueueConsumption{
+				LastUpdateTime: metav1.NewTime(start.Add(time.Second)),
+				Resources: []kueue.ConsumedResource{
+					{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("2")},
+					{Name: "example.com/gpu", Flavor: "model-a", Quantity: resource.MustParse("3601")},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := start
+			qc := newQueueConsumption(tc.status, now)
+			for _, s := range tc.steps {
+				now = now.Add(s.after)
+				qc.accrue(now)
+				qc.updateWorkloadUsage(s.workload, s.m)
+			}
+			qc.accrue(now.Add(tc.after))
+			if diff := cmp.Diff(tc.want, qc.status()); diff != "" {
+				t.Errorf("Unexpected consumption (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCacheLocalQueueConsumption(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	start := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	clock := testingclock.NewFakeClock(start)
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build(), WithClock(clock))
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	cq := builder.MakeClusterQueue("cq").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	q := builder.MakeLocalQueue("q", "ns").ClusterQueue("cq").Obj()
+	q.Status.Consumption = &kueue.LocalQueueConsumption{
+		LastUpdateTime: metav1.NewTime(start.Add(-time.Hour)),
+		Resources: []kueue.ConsumedResource{
+			{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("100")},
+		},
+	}
+	if err := cache.AddLocalQueue(q); err != nil {
+		t.Fatalf("Failed adding LocalQueue: %v", err)
+	}
+
+	// The consumption since the status was updated, before the LocalQueue
+	// was added to the cache, is unknown.
+	got := cache.LocalQueueConsumption(q)
+	if got == nil {
+		t.Fatalf("Got no consumption for the LocalQueue")
+	}
+	if diff := cmp.Diff(q.Status.Consumption.Resources, got.Resources); diff != "" {
+		t.Errorf("Unexpected consumed resources (-want,+got):\n%s", diff)
+	}
+
+	// The consumption of the admitted workloads accrues with the clock.
+	wl := builder.MakeWorkload("wl", "ns").
+		Queue("q").
+		Request(corev1.ResourceCPU, "2").
+		Admit(builder.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	if !cache.AddOrUpdateWorkload(wl) {
+		t.Fatalf("Failed adding workload")
+	}
+	clock.Step(time.Minute)
+	got = cache.LocalQueueConsumption(q)
+	want := &kueue.LocalQueueConsumption{
+		LastUpdateTime: metav1.NewTime(start.Add(time.Minute)),
+		Resources: []kueue.ConsumedResource{
+			{Name: corev1.ResourceCPU, Flavor: "default", Quantity: resource.MustParse("220")},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected consumption (-want,+got):\n%s", diff)
+	}
+
+	cache.DeleteLocalQueue(q)
+	if got := cache.LocalQueueConsumption(q); got != nil {
+		t.Errorf("Got consumption %v after deleting the LocalQueue", got)
+	}
+}
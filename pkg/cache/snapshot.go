@@ -17,6 +17,10 @@ limitations under the License.
 package cache
 
 import (
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
@@ -32,7 +36,11 @@ type Snapshot struct {
 func (c *Cache) Snapshot() Snapshot {
 	c.RLock()
 	defer c.RUnlock()
+	return c.snapshot()
+}
 
+// snapshot is like Snapshot, for callers that hold the lock of the cache.
+func (c *Cache) snapshot() Snapshot {
 	snap := Snapshot{
 		ClusterQueues:            make(map[string]*ClusterQueue, len(c.clusterQueues)),
 		ResourceFlavors:          make(map[string]*kueue.ResourceFlavor, len(c.resourceFlavors)),
@@ -43,12 +51,20 @@ func (c *Cache) Snapshot() Snapshot {
 			snap.InactiveClusterQueueSets.Insert(cq.Name)
 			continue
 		}
-		snap.ClusterQueues[cq.Name] = cq.snapshot()
+		snap.ClusterQueues[cq.Name] = c.snapshotClusterQueue(cq)
 	}
 	for _, rf := range c.resourceFlavors {
 		// Shallow copy is enough
 		snap.ResourceFlavors[rf.Name] = rf
 	}
+	// Reserved quota counts as used, before it's accumulated in the cohorts,
+	// so that it's not available for borrowing either.
+	now := metav1.NewTime(c.clock.Now())
+	for _, r := range c.reservations {
+		if cqCopy := snap.ClusterQueues[string(r.Spec.ClusterQueue)]; cqCopy != nil && ReservationActive(r, now) {
+			cqCopy.addReservedResources(r.Spec.Resources)
+		}
+	}
 	for _, cohort := range c.cohorts {
 		cohortCopy := newCohort(cohort.Name, len(cohort.members))
 		for cq := range cohort.members {
@@ -63,17 +79,64 @@ func (c *Cache) Snapshot() Snapshot {
 	return snap
 }
 
+// snapshotWithCohort returns a snapshot of the active ClusterQueue cq, along
+// with the active ClusterQueues of its cohort, like snapshot does, for callers
+// that hold the lock of the cache and only need to check the quota of cq.
+func (c *Cache) snapshotWithCohort(cq *ClusterQueue) *ClusterQueue {
+	members := map[*ClusterQueue]struct{}{cq: {}}
+	if cq.Cohort != nil {
+		members = cq.Cohort.members
+	}
+	copies := make(map[string]*ClusterQueue, len(members))
+	for member := range members {
+		if member.Active() {
+			copies[member.Name] = c.snapshotClusterQueue(member)
+		}
+	}
+	now := metav1.NewTime(c.clock.Now())
+	for _, r := range c.reservations {
+		if cqCopy := copies[string(r.Spec.ClusterQueue)]; cqCopy != nil && ReservationActive(r, now) {
+			cqCopy.addReservedResources(r.Spec.Resources)
+		}
+	}
+	if cq.Cohort != nil {
+		cohortCopy := newCohort(cq.Cohort.Name, len(copies))
+		for _, cqCopy := range copies {
+			cqCopy.accumulateResources(cohortCopy)
+			cqCopy.Cohort = cohortCopy
+			cohortCopy.members[cqCopy] = struct{}{}
+		}
+	}
+	return copies[cq.Name]
+}
+
+// snapshotClusterQueue returns a copy of the ClusterQueue with the usage of
+// its namespace reservations, for callers that hold the lock of the cache.
+func (c *Cache) snapshotClusterQueue(cq *ClusterQueue) *ClusterQueue {
+	cqCopy := cq.snapshot()
+	cqCopy.ReservedUsage = cq.reservedUsage(c.namespaceLabels)
+	return cqCopy
+}
+
 // Snapshot creates a copy of ClusterQueue that includes references to immutable
 // objects and deep copies of changing ones. A reference to the cohort is not included.
+// The workloads are shared with the cache until it modifies them, as they
+// aren't modified in the snapshot.
 func (c *ClusterQueue) snapshot() *ClusterQueue {
+	atomic.StoreInt32(&c.workloadsShared, 1)
 	cc := &ClusterQueue{
-		Name:                 c.Name,
-		RequestableResources: c.RequestableResources, // Shallow copy is enough.
-		UsedResources:        make(ResourceQuantities, len(c.UsedResources)),
-		Workloads:            make(map[string]*workload.Info, len(c.Workloads)),
-		LabelKeys:            c.LabelKeys, // Shallow copy is enough.
-		NamespaceSelector:    c.NamespaceSelector,
-		Status:               c.Status,
+		Name:                        c.Name,
+		RequestableResources:        c.RequestableResources, // Shallow copy is enough.
+		UsedResources:               make(ResourceQuantities, len(c.UsedResources)),
+		Workloads:                   c.Workloads,
+		LabelKeys:                   c.LabelKeys, // Shallow copy is enough.
+		NamespaceSelector:           c.NamespaceSelector,
+		Status:                      c.Status,
+		PodOverheadPolicy:           c.PodOverheadPolicy,
+		FlavorAssignmentPolicy:      c.FlavorAssignmentPolicy,
+		AdmissionChecks:             c.AdmissionChecks,
+		workloadInfoOptions:         c.workloadInfoOptions,
+		MaxNamespaceUsagePercentage: c.MaxNamespaceUsagePercentage,
 	}
 	for res, flavors := range c.UsedResources {
 		flavorsCopy := make(map[string]int64, len(flavors))
@@ -82,13 +145,56 @@ func (c *ClusterQueue) snapshot() *ClusterQueue {
 		}
 		cc.UsedResources[res] = flavorsCopy
 	}
-	for k, v := range c.Workloads {
-		// Shallow copy is enough.
-		cc.Workloads[k] = v
+	if c.budget != nil {
+		b := c.budget.advance(c.clock.Now(), c.UsedResources)
+		cc.BudgetExhausted = b.exhausted()
+	}
+	if c.MaxNamespaceUsagePercentage != nil {
+		cc.NamespaceUsage = make(map[string]ResourceQuantities, len(c.NamespaceUsage))
+		for ns, used := range c.NamespaceUsage {
+			usedCopy := make(ResourceQuantities, len(used))
+			for res, flavors := range used {
+				flavorsCopy := make(map[string]int64, len(flavors))
+				for k, v := range flavors {
+					flavorsCopy[k] = v
+				}
+				usedCopy[res] = flavorsCopy
+			}
+			cc.NamespaceUsage[ns] = usedCopy
+		}
 	}
 	return cc
 }
 
+// addReservedResources adds the reserved quantities to the used resources, for
+// the pairs of resource and flavor defined in the ClusterQueue.
+func (c *ClusterQueue) addReservedResources(resources []kueue.ReservedResource) {
+	for _, rr := range resources {
+		used, ok := c.UsedResources[rr.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := used[string(rr.Flavor)]; !ok {
+			continue
+		}
+		used[string(rr.Flavor)] += workload.ResourceValue(rr.Name, rr.Quantity)
+	}
+}
+
+// ReservationActive returns whether the reservation holds quota at the given
+// time, which starts leadTime before its window.
+func ReservationActive(r *kueue.Reservation, now metav1.Time) bool {
+	return !now.Time.Before(ReservationHoldTime(r)) && now.Before(&r.Spec.EndTime)
+}
+
+// ReservationHoldTime returns when the reservation starts holding quota.
+func ReservationHoldTime(r *kueue.Reservation) time.Time {
+	if r.Spec.LeadTime == nil {
+		return r.Spec.StartTime.Time
+	}
+	return r.Spec.StartTime.Add(-r.Spec.LeadTime.Duration)
+}
+
 func (c *ClusterQueue) accumulateResources(cohort *Cohort) {
 	if cohort.RequestableResources == nil {
 		cohort.RequestableResources = make(ResourceQuantities, len(c.RequestableResources))
@@ -18,7 +18,9 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -28,9 +30,11 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	testingclock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
@@ -404,3 +408,177 @@ func TestSnapshot(t *testing.T) {
 		t.Errorf("Unexpected Snapshot (-want,+got):\n%s", diff)
 	}
 }
+
+func TestSnapshotReservations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	now := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build(), WithClock(testingclock.NewFakeClock(now)))
+	clusterQueues := []*kueue.ClusterQueue{
+		builder.MakeClusterQueue("a").
+			Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+			Obj(),
+		builder.MakeClusterQueue("b").
+			Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+			Obj(),
+	}
+	for _, cq := range clusterQueues {
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	reservations := []*kueue.Reservation{
+		builder.MakeReservation("active", "a", now.Add(-time.Hour), now.Add(time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "4").
+			Resource(corev1.ResourceMemory, "default", "4Gi").
+			Resource(corev1.ResourceCPU, "other", "4").
+			Obj(),
+		builder.MakeReservation("active-too", "a", now.Add(-time.Hour), now.Add(time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "1").
+			Obj(),
+		builder.MakeReservation("future", "b", now.Add(time.Hour), now.Add(2*time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "4").
+			Obj(),
+		builder.MakeReservation("future-within-lead-time", "b", now.Add(time.Hour), now.Add(2*time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "2").
+			LeadTime(90 * time.Minute).
+			Obj(),
+		builder.MakeReservation("future-after-lead-time", "b", now.Add(time.Hour), now.Add(2*time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "3").
+			LeadTime(30 * time.Minute).
+			Obj(),
+		builder.MakeReservation("past", "b", now.Add(-2*time.Hour), now.Add(-time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "4").
+			Obj(),
+		builder.MakeReservation("missing-cq", "c", now.Add(-time.Hour), now.Add(time.Hour)).
+			Resource(corev1.ResourceCPU, "default", "4").
+			Obj(),
+	}
+	for _, r := range reservations {
+		cache.AddOrUpdateReservation(r)
+	}
+
+	snapshot := cache.Snapshot()
+	wantUsed := map[string]ResourceQuantities{
+		"a": {corev1.ResourceCPU: {"default": 5_000}},
+		"b": {corev1.ResourceCPU: {"default": 2_000}},
+	}
+	for name, want := range wantUsed {
+		if diff := cmp.Diff(want, snapshot.ClusterQueues[name].UsedResources); diff != "" {
+			t.Errorf("Unexpected used resources for ClusterQueue %q (-want,+got):\n%s", name, diff)
+		}
+	}
+	wantCohortUsed := ResourceQuantities{corev1.ResourceCPU: {"default": 7_000}}
+	if diff := cmp.Diff(wantCohortUsed, snapshot.ClusterQueues["a"].Cohort.UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources for the cohort (-want,+got):\n%s", diff)
+	}
+
+	// The snapshot of a single ClusterQueue and its cohort matches the full
+	// snapshot.
+	cqSnapshot := cache.snapshotWithCohort(cache.clusterQueues["a"])
+	if diff := cmp.Diff(wantUsed["a"], cqSnapshot.UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources in the snapshot of ClusterQueue \"a\" (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantCohortUsed, cqSnapshot.Cohort.UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources for the cohort in the snapshot of ClusterQueue \"a\" (-want,+got):\n%s", diff)
+	}
+
+	// The reserved quota is not recorded as usage in the cache.
+	cache.DeleteReservation("active")
+	cache.DeleteReservation("active-too")
+	snapshot = cache.Snapshot()
+	if diff := cmp.Diff(ResourceQuantities{corev1.ResourceCPU: {"default": 0}}, snapshot.ClusterQueues["a"].UsedResources); diff != "" {
+		t.Errorf("Unexpected used resources after deleting the reservations (-want,+got):\n%s", diff)
+	}
+}
+
+func TestSnapshotWorkloadsCopyOnWrite(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cq := builder.MakeClusterQueue("cq").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	admitted := func(name string) *kueue.Workload {
+		return builder.MakeWorkload(name, "").Request(corev1.ResourceCPU, "1").
+			Admit(builder.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()).Obj()
+	}
+	if !cache.AddOrUpdateWorkload(admitted("alpha")) {
+		t.Fatal("Failed adding workload alpha")
+	}
+	snapshot := cache.Snapshot()
+
+	// The changes in the cache after the snapshot is taken don't affect it.
+	if !cache.AddOrUpdateWorkload(admitted("beta")) {
+		t.Fatal("Failed adding workload beta")
+	}
+	if err := cache.DeleteWorkload(admitted("alpha")); err != nil {
+		t.Fatalf("Failed deleting workload alpha: %v", err)
+	}
+	gotSnapshot := sets.StringKeySet(snapshot.ClusterQueues["cq"].Workloads)
+	if diff := cmp.Diff(sets.NewString("/alpha"), gotSnapshot); diff != "" {
+		t.Errorf("Unexpected workloads in the first snapshot (-want,+got):\n%s", diff)
+	}
+	gotCache := sets.StringKeySet(cache.Snapshot().ClusterQueues["cq"].Workloads)
+	if diff := cmp.Diff(sets.NewString("/beta"), gotCache); diff != "" {
+		t.Errorf("Unexpected workloads in the second snapshot (-want,+got):\n%s", diff)
+	}
+}
+
+// newCacheWithWorkloads returns a cache with 10 ClusterQueues in a cohort,
+// among which the given number of admitted workloads are spread.
+func newCacheWithWorkloads(b *testing.B, workloads int) *Cache {
+	b.Helper()
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		b.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	const clusterQueues = 10
+	for i := 0; i < clusterQueues; i++ {
+		cq := builder.MakeClusterQueue(fmt.Sprintf("cq-%d", i)).
+			Cohort("cohort").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor("default", "100000").Obj()).Obj()).
+			Obj()
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			b.Fatalf("Failed adding ClusterQueue: %v", err)
+		}
+	}
+	for i := 0; i < workloads; i++ {
+		cq := fmt.Sprintf("cq-%d", i%clusterQueues)
+		wl := builder.MakeWorkload(fmt.Sprintf("wl-%d", i), "").Request(corev1.ResourceCPU, "1").
+			Admit(builder.MakeAdmission(cq).Flavor(corev1.ResourceCPU, "default").Obj()).Obj()
+		if !cache.AddOrUpdateWorkload(wl) {
+			b.Fatalf("Failed adding workload %s", wl.Name)
+		}
+	}
+	return cache
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	for _, workloads := range []int{100, 10_000} {
+		b.Run(fmt.Sprintf("%d workloads", workloads), func(b *testing.B) {
+			cache := newCacheWithWorkloads(b, workloads)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Snapshot()
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config reloads the configuration of the manager while it runs.
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha2"
+)
+
+// reloadPeriod is how often the configuration file is checked for changes.
+const reloadPeriod = 10 * time.Second
+
+// reloadableFields are the fields of the Configuration that can change
+// without restarting the manager. The timeouts, like workloadRetention or
+// starvationWatchdog, are read when the controllers are set up, so they
+// require a restart.
+var reloadableFields = sets.NewString(
+	"ManageJobsWithoutQueueName",
+	"RequireExistingLocalQueue",
+	"MetadataPropagation",
+)
+
+// Reloader watches the configuration file and applies the changes to the
+// fields that can change without restarting the manager. The changes to the
+// other fields are ignored, and recorded in a warning event for the object
+// that holds the configuration.
+type Reloader struct {
+	log      logr.Logger
+	path     string
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	object   runtime.Object
+	apply    func(*configapi.Configuration)
+	current  *configapi.Configuration
+	content  []byte
+}
+
+// NewReloader returns a Reloader for the configuration file in path, which
+// was loaded as current. apply is called with the configuration that results
+// from every change to the file. The changes that can't be applied are
+// recorded in events for object, usually the ConfigMap mounted in path.
+func NewReloader(path string, scheme *runtime.Scheme, recorder record.EventRecorder, object runtime.Object, current *configapi.Configuration, apply func(*configapi.Configuration)) *Reloader {
+	return &Reloader{
+		log:      ctrl.Log.WithName("config-reloader"),
+		path:     path,
+		scheme:   scheme,
+		recorder: recorder,
+		object:   object,
+		apply:    apply,
+		current:  current.DeepCopy(),
+	}
+}
+
+// Start implements manager.Runnable. It checks the file for changes until the
+// context is done.
+func (r *Reloader) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(context.Context) { r.reload() }, reloadPeriod)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The
+// configuration is reloaded in every replica, as the webhooks run in all of
+// them.
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Reloader) reload() {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		r.log.Error(err, "Unable to read the configuration file")
+		return
+	}
+	if bytes.Equal(content, r.content) {
+		return
+	}
+	r.content = content
+	var updated configapi.Configuration
+	codecs := serializer.NewCodecFactory(r.scheme)
+	if err := runtime.DecodeInto(codecs.UniversalDecoder(), content, &updated); err != nil {
+		r.log.Error(err, "Unable to decode the configuration file, ignoring the changes")
+		r.recorder.Eventf(r.object, corev1.EventTypeWarning, "InvalidConfiguration", "Ignoring the changes to the configuration: %v", err)
+		return
+	}
+	merged, rejected := Merge(r.current, &updated)
+	if len(rejected) > 0 {
+		r.log.Info("Ignoring changes to the configuration that require a restart", "fields", rejected)
+		r.recorder.Eventf(r.object, corev1.EventTypeWarning, "ConfigurationRestartRequired", "Ignoring the changes to %s until the manager restarts", strings.Join(rejected, ", "))
+	}
+	if equality.Semantic.DeepEqual(merged, r.current) {
+		return
+	}
+	r.current = merged
+	r.apply(merged.DeepCopy())
+	r.log.Info("Applied changes to the configuration")
+}
+
+// Merge returns a copy of current with the fields of updated that can change
+// without restarting the manager, along with the names of the other fields
+// that differ between current and updated.
+func Merge(current, updated *configapi.Configuration) (*configapi.Configuration, []string) {
+	merged := current.DeepCopy()
+	updated = updated.DeepCopy()
+	mergedVal := reflect.ValueOf(merged).Elem()
+	updatedVal := reflect.ValueOf(updated).Elem()
+	var rejected []string
+	for i := 0; i < mergedVal.NumField(); i++ {
+		field := mergedVal.Type().Field(i)
+		if field.Name == "TypeMeta" {
+			continue
+		}
+		if reloadableFields.Has(field.Name) {
+			mergedVal.Field(i).Set(updatedVal.Field(i))
+			continue
+		}
+		if !equality.Semantic.DeepEqual(mergedVal.Field(i).Interface(), updatedVal.Field(i).Interface()) {
+			rejected = append(rejected, fieldName(field))
+		}
+	}
+	return merged, rejected
+}
+
+// fieldName returns the name of the field in the configuration file, or the
+// name of the Go field for the inlined fields.
+func fieldName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
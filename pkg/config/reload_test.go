@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha2"
+)
+
+func TestMerge(t *testing.T) {
+	current := &configapi.Configuration{
+		ManageJobsWithoutQueueName: false,
+		LocalQueueMetrics:          true,
+	}
+	cases := map[string]struct {
+		updated      *configapi.Configuration
+		want         *configapi.Configuration
+		wantRejected []string
+	}{
+		"no changes": {
+			updated: current,
+			want:    current,
+		},
+		"reloadable changes": {
+			updated: &configapi.Configuration{
+				ManageJobsWithoutQueueName: true,
+				RequireExistingLocalQueue:  true,
+				LocalQueueMetrics:          true,
+				MetadataPropagation: &configapi.MetadataPropagation{
+					Labels: []string{"example.com/team"},
+				},
+			},
+			want: &configapi.Configuration{
+				ManageJobsWithoutQueueName: true,
+				RequireExistingLocalQueue:  true,
+				LocalQueueMetrics:          true,
+				MetadataPropagation: &configapi.MetadataPropagation{
+					Labels: []string{"example.com/team"},
+				},
+			},
+		},
+		"changes that require a restart": {
+			updated: &configapi.Configuration{
+				ManageJobsWithoutQueueName: true,
+				ReportedWorkloadLabels:     []string{"example.com/team"},
+			},
+			want: &configapi.Configuration{
+				ManageJobsWithoutQueueName: true,
+				LocalQueueMetrics:          true,
+			},
+			wantRejected: []string{"localQueueMetrics", "reportedWorkloadLabels"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, gotRejected := Merge(current, tc.updated)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected configuration (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantRejected, gotRejected); diff != "" {
+				t.Errorf("Unexpected rejected fields (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReloader(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := configapi.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding config scheme: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed writing the configuration file: %v", err)
+		}
+	}
+	var applied []*configapi.Configuration
+	current := &configapi.Configuration{}
+	scheme.Default(current)
+	recorder := record.NewFakeRecorder(10)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kueue-manager-config", Namespace: "kueue-system"}}
+	r := NewReloader(path, scheme, recorder, configMap, current, func(cfg *configapi.Configuration) {
+		applied = append(applied, cfg)
+	})
+
+	writeConfig(`apiVersion: config.kueue.x-k8s.io/v1alpha2
+kind: Configuration
+manageJobsWithoutQueueName: true
+localQueueMetrics: true
+`)
+	r.reload()
+	if len(applied) != 1 {
+		t.Fatalf("Applied %d configurations, want 1", len(applied))
+	}
+	if !applied[0].ManageJobsWithoutQueueName {
+		t.Error("The change to manageJobsWithoutQueueName wasn't applied")
+	}
+	if applied[0].LocalQueueMetrics {
+		t.Error("The change to localQueueMetrics was applied, but it requires a restart")
+	}
+	wantEvent := "Warning ConfigurationRestartRequired Ignoring the changes to localQueueMetrics until the manager restarts"
+	if got := <-recorder.Events; got != wantEvent {
+		t.Errorf("Got event %q, want %q", got, wantEvent)
+	}
+
+	// Files that didn't change or can't be decoded aren't applied.
+	r.reload()
+	writeConfig("invalid")
+	r.reload()
+	if len(applied) != 1 {
+		t.Errorf("Applied %d configurations, want 1", len(applied))
+	}
+	if got := <-recorder.Events; !strings.HasPrefix(got, "Warning InvalidConfiguration ") {
+		t.Errorf("Got event %q, want an InvalidConfiguration warning", got)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("Got %d unexpected events", len(recorder.Events))
+	}
+}
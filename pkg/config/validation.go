@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+var (
+	initContainersAccountingValues = []string{
+		string(configapi.InitContainersAccountingMax),
+		string(configapi.InitContainersAccountingIgnore),
+	}
+	requeuingTimestampValues = []string{
+		string(configapi.RequeuingTimestampEviction),
+		string(configapi.RequeuingTimestampCreation),
+	}
+	resourceFormatValues = []string{
+		string(resource.DecimalSI),
+		string(resource.BinarySI),
+		string(resource.DecimalExponent),
+	}
+)
+
+// Validate returns the errors in the values of a defaulted configuration.
+func Validate(cfg *configapi.Configuration) field.ErrorList {
+	var allErrs field.ErrorList
+	switch cfg.InitContainersAccounting {
+	case configapi.InitContainersAccountingMax, configapi.InitContainersAccountingIgnore:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("initContainersAccounting"), cfg.InitContainersAccounting, initContainersAccountingValues))
+	}
+	switch cfg.RequeuingTimestamp {
+	case "", configapi.RequeuingTimestampEviction, configapi.RequeuingTimestampCreation:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("requeuingTimestamp"), cfg.RequeuingTimestamp, requeuingTimestampValues))
+	}
+	allErrs = append(allErrs, validateResourceUnits(cfg.ResourceUnits, field.NewPath("resourceUnits"))...)
+	if cfg.RequestsTransformer != "" && !workload.RequestsTransformerRegistered(cfg.RequestsTransformer) {
+		allErrs = append(allErrs, field.NotFound(field.NewPath("requestsTransformer"), cfg.RequestsTransformer))
+	}
+	allErrs = append(allErrs, validateSharding(cfg.Sharding, field.NewPath("sharding"))...)
+	allErrs = append(allErrs, validateAuditSink(cfg.AuditSink, field.NewPath("auditSink"))...)
+	return allErrs
+}
+
+func validateResourceUnits(units []configapi.ResourceUnit, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, u := range units {
+		switch u.Format {
+		case "", resource.DecimalSI, resource.BinarySI, resource.DecimalExponent:
+		default:
+			allErrs = append(allErrs, field.NotSupported(path.Index(i).Child("format"), u.Format, resourceFormatValues))
+		}
+	}
+	return allErrs
+}
+
+func validateSharding(s *configapi.Sharding, path *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if s.Count < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("count"), s.Count, "must be greater than 0"))
+	}
+	if s.Index < 0 || s.Index >= s.Count {
+		allErrs = append(allErrs, field.Invalid(path.Child("index"), s.Index, "must be between 0 and count-1"))
+	}
+	return allErrs
+}
+
+func validateAuditSink(s *configapi.AuditSink, path *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+	if s.File != "" && s.WebhookURL != "" {
+		return field.ErrorList{field.Forbidden(path, "only one of file or webhookURL can be set")}
+	}
+	return nil
+}
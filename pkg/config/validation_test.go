@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha2"
+)
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		cfg     *configapi.Configuration
+		wantErr field.ErrorList
+	}{
+		"defaulted": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingMax,
+			},
+		},
+		"valid": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingIgnore,
+				RequeuingTimestamp:       configapi.RequeuingTimestampCreation,
+				ResourceUnits: []configapi.ResourceUnit{
+					{Name: "memory", Format: resource.BinarySI},
+					{Name: "example.com/gpu"},
+				},
+				Sharding:  &configapi.Sharding{Index: 1, Count: 2},
+				AuditSink: &configapi.AuditSink{File: "/var/log/kueue/audit.log"},
+			},
+		},
+		"unsupported values": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: "Sum",
+				RequeuingTimestamp:       "Admission",
+				ResourceUnits: []configapi.ResourceUnit{
+					{Name: "memory", Format: resource.BinarySI},
+					{Name: "example.com/gpu", Format: "Roman"},
+				},
+			},
+			wantErr: field.ErrorList{
+				field.NotSupported(field.NewPath("initContainersAccounting"), nil, nil),
+				field.NotSupported(field.NewPath("requeuingTimestamp"), nil, nil),
+				field.NotSupported(field.NewPath("resourceUnits").Index(1).Child("format"), nil, nil),
+			},
+		},
+		"unregistered requests transformer": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingMax,
+				RequestsTransformer:      "time-slicing",
+			},
+			wantErr: field.ErrorList{
+				field.NotFound(field.NewPath("requestsTransformer"), nil),
+			},
+		},
+		"index out of the shards": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingMax,
+				Sharding:                 &configapi.Sharding{Index: 2, Count: 2},
+			},
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("sharding", "index"), nil, ""),
+			},
+		},
+		"no shards": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingMax,
+				Sharding:                 &configapi.Sharding{},
+			},
+			wantErr: field.ErrorList{
+				field.Invalid(field.NewPath("sharding", "count"), nil, ""),
+				field.Invalid(field.NewPath("sharding", "index"), nil, ""),
+			},
+		},
+		"file and webhook audit sinks": {
+			cfg: &configapi.Configuration{
+				InitContainersAccounting: configapi.InitContainersAccountingMax,
+				AuditSink: &configapi.AuditSink{
+					File:       "/var/log/kueue/audit.log",
+					WebhookURL: "https://audit.example.com",
+				},
+			},
+			wantErr: field.ErrorList{
+				field.Forbidden(field.NewPath("auditSink"), ""),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotErr := Validate(tc.cfg)
+			if diff := cmp.Diff(tc.wantErr, gotErr, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -24,9 +24,40 @@ const (
 	// TODO(#23): Use the kubernetes.io domain when graduating APIs to beta.
 	QueueAnnotation = "kueue.x-k8s.io/queue-name"
 
-	KueueName         = "kueue"
-	JobControllerName = KueueName + "-job-controller"
-	AdmissionName     = KueueName + "-admission"
+	// DefaultQueueAnnotation is the annotation in a namespace that holds the
+	// name of the LocalQueue for the Jobs created in the namespace without
+	// the queue name annotation.
+	DefaultQueueAnnotation = "kueue.x-k8s.io/default-queue-name"
+
+	// ManualAdmissionAnnotation is the annotation that cluster administrators
+	// set in a pending workload to admit it, bypassing the queue. Its value is
+	// the JSON of the admission, which must fit in the quota of the
+	// ClusterQueue.
+	ManualAdmissionAnnotation = "kueue.x-k8s.io/manual-admission"
+
+	// DebugAnnotation is the annotation that, set to "true" in a Job or a
+	// workload, makes Kueue log the details of how the object is processed,
+	// regardless of the verbosity of the logs. It's copied from the Jobs to
+	// their workloads.
+	DebugAnnotation = "kueue.x-k8s.io/debug"
+
+	// NodeFailureAnnotation is the annotation that the Job controller sets in
+	// an admitted workload when a pod of its job is deleted from a node that
+	// isn't ready. The value is a message describing the failure. The Workload
+	// controller evicts the workload and queues it again.
+	NodeFailureAnnotation = "kueue.x-k8s.io/node-failure"
+
+	// NodeFailureEvictionReason is the reason of the eviction of the
+	// workloads that lost pods because of a node failure.
+	NodeFailureEvictionReason = "NodeFailure"
+
+	KueueName                    = "kueue"
+	JobControllerName            = KueueName + "-job-controller"
+	ClusterQueueControllerName   = KueueName + "-cluster-queue-controller"
+	WorkloadControllerName       = KueueName + "-workload-controller"
+	ResourceFlavorControllerName = KueueName + "-resource-flavor-controller"
+	AdmissionName                = KueueName + "-admission"
+	ConfigReloaderName           = KueueName + "-config-reloader"
 
 	// UpdatesBatchPeriod is the batch period to hold workload updates
 	// before syncing a Queue and ClusterQueue objects.
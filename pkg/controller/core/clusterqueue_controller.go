@@ -18,25 +18,45 @@ package core
 
 import (
 	"context"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
+)
+
+// budgetUpdatePeriod is how often the consumption of the budget of a
+// ClusterQueue is updated in its status.
+const budgetUpdatePeriod = time.Minute
+
+// Reasons of the events and metrics reported when a ClusterQueue becomes
+// inactive.
+const (
+	resourceFlavorDeletedReason  = "ResourceFlavorDeleted"
+	resourceFlavorNotFoundReason = "ResourceFlavorNotFound"
 )
 
 type ClusterQueueUpdateWatcher interface {
@@ -49,18 +69,39 @@ type ClusterQueueReconciler struct {
 	log        logr.Logger
 	qManager   *queue.Manager
 	cache      *cache.Cache
+	recorder   record.EventRecorder
 	wlUpdateCh chan event.GenericEvent
 	watchers   []ClusterQueueUpdateWatcher
+	shard      sharding.Shard
+	clock      clock.Clock
+	// cohortUpdateCh receives the ClusterQueues to reconcile because another
+	// member of their cohort changed.
+	cohortUpdateCh chan event.GenericEvent
+
+	// borrowingWarnings holds the last warnings reported for the borrowing
+	// limits of each ClusterQueue, so that they're only reported when they
+	// change.
+	borrowingWarningsLock sync.Mutex
+	borrowingWarnings     map[string]string
 }
 
-func NewClusterQueueReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache, watchers ...ClusterQueueUpdateWatcher) *ClusterQueueReconciler {
+func NewClusterQueueReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache, recorder record.EventRecorder, watchers []ClusterQueueUpdateWatcher, opts ...Option) *ClusterQueueReconciler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &ClusterQueueReconciler{
-		client:     client,
-		log:        ctrl.Log.WithName("cluster-queue-reconciler"),
-		qManager:   qMgr,
-		cache:      cache,
-		wlUpdateCh: make(chan event.GenericEvent, updateChBuffer),
-		watchers:   watchers,
+		client:            client,
+		log:               ctrl.Log.WithName("cluster-queue-reconciler"),
+		qManager:          qMgr,
+		cache:             cache,
+		recorder:          recorder,
+		wlUpdateCh:        make(chan event.GenericEvent, updateChBuffer),
+		watchers:          watchers,
+		shard:             options.shard,
+		clock:             options.clock,
+		cohortUpdateCh:    make(chan event.GenericEvent, updateChBuffer),
+		borrowingWarnings: make(map[string]string),
 	}
 }
 
@@ -76,6 +117,9 @@ func (r *ClusterQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// we'll ignore not-found errors, since there is nothing to do.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if !r.shard.Owns(&cqObj) {
+		return ctrl.Result{}, nil
+	}
 	log := ctrl.LoggerFrom(ctx).WithValues("clusterQueue", klog.KObj(&cqObj))
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling ClusterQueue")
@@ -107,19 +151,47 @@ func (r *ClusterQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	r.reportBorrowingLimits(&cqObj)
+
 	status, err := r.Status(&cqObj)
 	if err != nil {
 		log.Error(err, "Failed getting status from cache")
 		return ctrl.Result{}, err
 	}
 
+	if budgetWindowStarted(cqObj.Status.BudgetUsage, status.BudgetUsage) {
+		// The consumption was reset, the workloads that didn't fit the budget
+		// might fit now.
+		r.qManager.QueueInadmissibleWorkloads(ctx, sets.NewString(cqObj.Name))
+	}
+	result := budgetUpdateResult(&cqObj, status.BudgetUsage, r.clock.Now())
+
 	if !equality.Semantic.DeepEqual(status, cqObj.Status) {
 		cqObj.Status = status
 		err := r.client.Status().Update(ctx, &cqObj)
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		return result, client.IgnoreNotFound(err)
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// budgetWindowStarted returns whether a new window of the budget started
+// since the status was last updated.
+func budgetWindowStarted(oldUsage, newUsage *kueue.BudgetUsage) bool {
+	return oldUsage != nil && newUsage != nil && oldUsage.WindowStart.Before(&newUsage.WindowStart)
+}
+
+// budgetUpdateResult returns when to update the consumption of the budget in
+// the status again, at the latest when the current window ends.
+func budgetUpdateResult(cq *kueue.ClusterQueue, usage *kueue.BudgetUsage, now time.Time) ctrl.Result {
+	if cq.Spec.Budget == nil || usage == nil {
+		return ctrl.Result{}
+	}
+	after := usage.WindowStart.Add(cq.Spec.Budget.Window.Duration).Sub(now)
+	if after <= 0 || after > budgetUpdatePeriod {
+		after = budgetUpdatePeriod
+	}
+	return ctrl.Result{RequeueAfter: after}
 }
 
 func (r *ClusterQueueReconciler) NotifyWorkloadUpdate(w *kueue.Workload) {
@@ -141,6 +213,14 @@ func (r *ClusterQueueReconciler) Create(e event.CreateEvent) bool {
 		// No need to interact with the cache for other objects.
 		return true
 	}
+	if !r.shard.Owns(cq) {
+		return false
+	}
+	r.addClusterQueue(cq)
+	return true
+}
+
+func (r *ClusterQueueReconciler) addClusterQueue(cq *kueue.ClusterQueue) {
 	log := r.log.WithValues("clusterQueue", klog.KObj(cq))
 	log.V(2).Info("ClusterQueue create event")
 	ctx := ctrl.LoggerInto(context.Background(), log)
@@ -151,7 +231,7 @@ func (r *ClusterQueueReconciler) Create(e event.CreateEvent) bool {
 	if err := r.qManager.AddClusterQueue(ctx, cq); err != nil {
 		log.Error(err, "Failed to add clusterQueue to queue manager")
 	}
-	return true
+	r.notifyCohort(cq.Spec.Cohort)
 }
 
 func (r *ClusterQueueReconciler) Delete(e event.DeleteEvent) bool {
@@ -160,11 +240,16 @@ func (r *ClusterQueueReconciler) Delete(e event.DeleteEvent) bool {
 		// No need to interact with the cache for other objects.
 		return true
 	}
+	if !r.shard.Owns(cq) {
+		return false
+	}
 	defer r.notifyWatchers(cq, nil)
 
 	r.log.V(2).Info("ClusterQueue delete event", "clusterQueue", klog.KObj(cq))
 	r.cache.DeleteClusterQueue(cq)
 	r.qManager.DeleteClusterQueue(cq)
+	r.forgetBorrowingLimits(cq.Name)
+	r.notifyCohort(cq.Spec.Cohort)
 	return true
 }
 
@@ -180,6 +265,23 @@ func (r *ClusterQueueReconciler) Update(e event.UpdateEvent) bool {
 		return true
 	}
 
+	// The ClusterQueue moves between shards when its cohort changes.
+	ownsOld, ownsNew := r.shard.Owns(oldCq), r.shard.Owns(newCq)
+	if !ownsNew {
+		if ownsOld {
+			r.log.V(2).Info("ClusterQueue moved to another shard", "clusterQueue", klog.KObj(newCq))
+			r.cache.DeleteClusterQueue(oldCq)
+			r.qManager.ReleaseClusterQueue(oldCq)
+			r.notifyWatchers(oldCq, nil)
+			r.forgetBorrowingLimits(oldCq.Name)
+		}
+		return false
+	}
+	if !ownsOld {
+		r.addClusterQueue(newCq)
+		return true
+	}
+
 	log := r.log.WithValues("clusterQueue", klog.KObj(newCq))
 	log.V(2).Info("ClusterQueue update event")
 
@@ -188,16 +290,81 @@ func (r *ClusterQueueReconciler) Update(e event.UpdateEvent) bool {
 	}
 	defer r.notifyWatchers(oldCq, newCq)
 
+	wasActive := r.cache.ClusterQueueActive(newCq.Name)
 	if err := r.cache.UpdateClusterQueue(newCq); err != nil {
 		log.Error(err, "Failed to update clusterQueue in cache")
 	}
+	if wasActive && !r.cache.ClusterQueueActive(newCq.Name) {
+		r.reportInactive(newCq)
+	}
 	if err := r.qManager.UpdateClusterQueue(context.Background(), newCq); err != nil {
 		log.Error(err, "Failed to update clusterQueue in queue manager")
 	}
+	if !equality.Semantic.DeepEqual(oldCq.Spec, newCq.Spec) {
+		r.notifyCohort(oldCq.Spec.Cohort)
+		if newCq.Spec.Cohort != oldCq.Spec.Cohort {
+			r.notifyCohort(newCq.Spec.Cohort)
+		}
+	}
 	return true
 }
 
+// notifyCohort queues all the ClusterQueues in the cohort for reconciliation,
+// so that the borrowing limits of all of them are checked again.
+func (r *ClusterQueueReconciler) notifyCohort(cohort string) {
+	if cohort == "" {
+		return
+	}
+	for name := range r.cache.ClusterQueuesInCohort(cohort) {
+		r.cohortUpdateCh <- event.GenericEvent{Object: &kueue.ClusterQueue{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+	}
+}
+
+// reportBorrowingLimits emits a warning event for each max quota of the
+// ClusterQueue that can never be reached given the min quotas of its cohort.
+// Such configurations are valid, but usually point to a misconfiguration.
+// The warnings are only emitted when they differ from the last ones reported
+// for the ClusterQueue.
+func (r *ClusterQueueReconciler) reportBorrowingLimits(cq *kueue.ClusterQueue) {
+	msgs := r.cache.BorrowingLimitsBeyondCohort(cq.Name)
+	key := strings.Join(msgs, "\n")
+	r.borrowingWarningsLock.Lock()
+	changed := r.borrowingWarnings[cq.Name] != key
+	r.borrowingWarnings[cq.Name] = key
+	r.borrowingWarningsLock.Unlock()
+	if !changed {
+		return
+	}
+	for _, msg := range msgs {
+		r.recorder.Event(cq, corev1.EventTypeWarning, "UnreachableBorrowingLimit", msg)
+	}
+}
+
+// forgetBorrowingLimits drops the warnings reported for a ClusterQueue that
+// is no longer handled by the reconciler.
+func (r *ClusterQueueReconciler) forgetBorrowingLimits(name string) {
+	r.borrowingWarningsLock.Lock()
+	delete(r.borrowingWarnings, name)
+	r.borrowingWarningsLock.Unlock()
+}
+
+// reportInactive emits a warning event, naming the missing ResourceFlavors,
+// for a ClusterQueue that became inactive after an update.
+func (r *ClusterQueueReconciler) reportInactive(cq *kueue.ClusterQueue) {
+	missing := r.cache.MissingFlavors(cq.Name)
+	if len(missing) == 0 {
+		return
+	}
+	metrics.ReportClusterQueueInactive(cq.Name, resourceFlavorNotFoundReason)
+	r.recorder.Eventf(cq, corev1.EventTypeWarning, resourceFlavorNotFoundReason,
+		"ClusterQueue is inactive: ResourceFlavors %s not found", strings.Join(missing, ", "))
+}
+
 func (r *ClusterQueueReconciler) Generic(e event.GenericEvent) bool {
+	if _, match := e.Object.(*kueue.ClusterQueue); match {
+		r.log.V(2).Info("Got cohort update event", "clusterQueue", klog.KObj(e.Object))
+		return true
+	}
 	r.log.V(2).Info("Got Workload event", "workload", klog.KObj(e.Object))
 	return true
 }
@@ -252,12 +419,14 @@ type cqNamespaceHandler struct {
 }
 
 func (h *cqNamespaceHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.cache.UpdateNamespace(e.Object.(*corev1.Namespace))
 }
 
 func (h *cqNamespaceHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
 	oldNs := e.ObjectOld.(*corev1.Namespace)
 	oldMatchingCqs := h.cache.MatchingClusterQueues(oldNs.Labels)
 	newNs := e.ObjectNew.(*corev1.Namespace)
+	h.cache.UpdateNamespace(newNs)
 	newMatchingCqs := h.cache.MatchingClusterQueues(newNs.Labels)
 	cqs := sets.NewString()
 	for cq := range newMatchingCqs {
@@ -268,7 +437,10 @@ func (h *cqNamespaceHandler) Update(e event.UpdateEvent, q workqueue.RateLimitin
 	h.qManager.QueueInadmissibleWorkloads(context.Background(), cqs)
 }
 
-func (h *cqNamespaceHandler) Delete(event.DeleteEvent, workqueue.RateLimitingInterface) {
+func (h *cqNamespaceHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if ns, ok := e.Object.(*corev1.Namespace); ok {
+		h.cache.DeleteNamespace(ns)
+	}
 }
 
 func (h *cqNamespaceHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
@@ -287,6 +459,7 @@ func (r *ClusterQueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&kueue.ClusterQueue{}).
 		Watches(&source.Kind{Type: &corev1.Namespace{}}, &nsHandler).
 		Watches(&source.Channel{Source: r.wlUpdateCh}, &wHandler).
+		Watches(&source.Channel{Source: r.cohortUpdateCh}, &handler.EnqueueRequestForObject{}).
 		WithEventFilter(r).
 		Complete(r)
 }
@@ -300,9 +473,15 @@ func (r *ClusterQueueReconciler) Status(cq *kueue.ClusterQueue) (kueue.ClusterQu
 		return kueue.ClusterQueueStatus{}, err
 	}
 
+	budgetUsage, err := r.cache.BudgetUsage(cq)
+	if err != nil {
+		return kueue.ClusterQueueStatus{}, err
+	}
+
 	return kueue.ClusterQueueStatus{
 		UsedResources:     usage,
 		AdmittedWorkloads: int32(workloads),
 		PendingWorkloads:  int32(r.qManager.Pending(cq)),
+		BudgetUsage:       budgetUsage,
 	}, nil
 }
@@ -17,30 +17,122 @@ limitations under the License.
 package core
 
 import (
+	"time"
+
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const updateChBuffer = 10
 
+type options struct {
+	localQueueMetrics                 bool
+	localQueueConsumptionUpdatePeriod time.Duration
+	reportedWorkloadLabels            []string
+	auditSink                         audit.Sink
+	workloadRetention                 workload.Retention
+	shard                             sharding.Shard
+	clock                             clock.Clock
+}
+
+// Option configures the core controllers.
+type Option func(*options)
+
+// WithLocalQueueMetrics indicates if the LocalQueue controller should report
+// metrics for each LocalQueue.
+func WithLocalQueueMetrics(f bool) Option {
+	return func(o *options) {
+		o.localQueueMetrics = f
+	}
+}
+
+// WithLocalQueueConsumptionUpdatePeriod indicates how often the LocalQueue
+// controller should update the consumption in the status of each LocalQueue.
+// Zero disables the updates.
+func WithLocalQueueConsumptionUpdatePeriod(d time.Duration) Option {
+	return func(o *options) {
+		o.localQueueConsumptionUpdatePeriod = d
+	}
+}
+
+// WithReportedWorkloadLabels sets the keys of the workload labels that the
+// Workload controller attaches to the eviction events and metrics.
+func WithReportedWorkloadLabels(keys ...string) Option {
+	return func(o *options) {
+		o.reportedWorkloadLabels = keys
+	}
+}
+
+// WithAuditSink sets the sink where the Workload controller records the
+// evictions.
+func WithAuditSink(sink audit.Sink) Option {
+	return func(o *options) {
+		o.auditSink = sink
+	}
+}
+
+// WithWorkloadRetention sets how long the Workload controller keeps the
+// finished workloads before deleting them.
+func WithWorkloadRetention(r workload.Retention) Option {
+	return func(o *options) {
+		o.workloadRetention = r
+	}
+}
+
+// WithShard sets the shard of the cohorts that the controllers manage. The
+// controllers ignore the ClusterQueues of the other shards, along with their
+// LocalQueues and workloads.
+func WithShard(s sharding.Shard) Option {
+	return func(o *options) {
+		o.shard = s
+	}
+}
+
+// WithClock sets the clock used by the controllers to compute the backoffs,
+// the requeue delays and the timestamps in the status. It's meant to be
+// replaced in tests.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var defaultOptions = options{
+	clock: clock.RealClock{},
+}
+
 // SetupControllers sets up the core controllers. It returns the name of the
 // controller that failed to create and an error, if any.
-func SetupControllers(mgr ctrl.Manager, qManager *queue.Manager, cc *cache.Cache) (string, error) {
-	rfRec := NewResourceFlavorReconciler(mgr.GetClient(), qManager, cc)
+func SetupControllers(mgr ctrl.Manager, qManager *queue.Manager, cc *cache.Cache, opts ...Option) (string, error) {
+	rfRec := NewResourceFlavorReconciler(mgr.GetClient(), qManager, cc,
+		mgr.GetEventRecorderFor(constants.ResourceFlavorControllerName), opts...)
 	if err := rfRec.SetupWithManager(mgr); err != nil {
 		return "ResourceFlavor", err
 	}
-	qRec := NewLocalQueueReconciler(mgr.GetClient(), qManager, cc)
+	qRec := NewLocalQueueReconciler(mgr.GetClient(), qManager, cc, opts...)
 	if err := qRec.SetupWithManager(mgr); err != nil {
 		return "LocalQueue", err
 	}
-	cqRec := NewClusterQueueReconciler(mgr.GetClient(), qManager, cc, rfRec)
+	cqRec := NewClusterQueueReconciler(mgr.GetClient(), qManager, cc,
+		mgr.GetEventRecorderFor(constants.ClusterQueueControllerName),
+		[]ClusterQueueUpdateWatcher{rfRec}, opts...)
 	if err := cqRec.SetupWithManager(mgr); err != nil {
 		return "ClusterQueue", err
 	}
-	if err := NewWorkloadReconciler(mgr.GetClient(), qManager, cc, qRec, cqRec).SetupWithManager(mgr); err != nil {
+	if err := NewReservationReconciler(mgr.GetClient(), qManager, cc, opts...).SetupWithManager(mgr); err != nil {
+		return "Reservation", err
+	}
+	wlRec := NewWorkloadReconciler(mgr.GetClient(), qManager, cc,
+		mgr.GetEventRecorderFor(constants.WorkloadControllerName),
+		[]WorkloadUpdateWatcher{qRec, cqRec}, opts...)
+	if err := wlRec.SetupWithManager(mgr); err != nil {
 		return "Workload", err
 	}
 	return "", nil
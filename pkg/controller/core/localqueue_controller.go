@@ -18,12 +18,15 @@ package core
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -33,7 +36,10 @@ import (
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 // LocalQueueReconciler reconciles a LocalQueue object
@@ -43,15 +49,28 @@ type LocalQueueReconciler struct {
 	queues     *queue.Manager
 	cache      *cache.Cache
 	wlUpdateCh chan event.GenericEvent
+
+	localQueueMetrics                 bool
+	localQueueConsumptionUpdatePeriod time.Duration
+	shard                             sharding.Shard
+	clock                             clock.Clock
 }
 
-func NewLocalQueueReconciler(client client.Client, queues *queue.Manager, cache *cache.Cache) *LocalQueueReconciler {
+func NewLocalQueueReconciler(client client.Client, queues *queue.Manager, cache *cache.Cache, opts ...Option) *LocalQueueReconciler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &LocalQueueReconciler{
-		log:        ctrl.Log.WithName("localqueue-reconciler"),
-		queues:     queues,
-		cache:      cache,
-		client:     client,
-		wlUpdateCh: make(chan event.GenericEvent, updateChBuffer),
+		log:                               ctrl.Log.WithName("localqueue-reconciler"),
+		queues:                            queues,
+		cache:                             cache,
+		client:                            client,
+		wlUpdateCh:                        make(chan event.GenericEvent, updateChBuffer),
+		localQueueMetrics:                 options.localQueueMetrics,
+		localQueueConsumptionUpdatePeriod: options.localQueueConsumptionUpdatePeriod,
+		shard:                             options.shard,
+		clock:                             options.clock,
 	}
 }
 
@@ -70,6 +89,9 @@ func (r *LocalQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// we'll ignore not-found errors, since there is nothing to do.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if owned, err := r.shard.OwnsClusterQueue(ctx, r.client, string(queueObj.Spec.ClusterQueue)); err != nil || !owned {
+		return ctrl.Result{}, err
+	}
 	log := ctrl.LoggerFrom(ctx).WithValues("localQueue", klog.KObj(&queueObj))
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling LocalQueue")
@@ -85,11 +107,49 @@ func (r *LocalQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	queueObj.Status.PendingWorkloads = pending
 	queueObj.Status.AdmittedWorkloads = r.cache.AdmittedWorkloadsInLocalQueue(&queueObj)
+	if r.localQueueMetrics {
+		r.reportMetrics(&queueObj)
+	}
+	var result ctrl.Result
+	if r.localQueueConsumptionUpdatePeriod > 0 {
+		result.RequeueAfter = r.updateConsumption(&queueObj)
+	}
 	if !equality.Semantic.DeepEqual(oldStatus, queueObj.Status) {
 		err := r.client.Status().Update(ctx, &queueObj)
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		return result, client.IgnoreNotFound(err)
+	}
+	return result, nil
+}
+
+// updateConsumption refreshes the consumption in the status of the LocalQueue
+// if it's older than the update period. It returns the time until the next
+// refresh.
+func (r *LocalQueueReconciler) updateConsumption(q *kueue.LocalQueue) time.Duration {
+	now := r.clock.Now()
+	if c := q.Status.Consumption; c != nil {
+		if next := c.LastUpdateTime.Add(r.localQueueConsumptionUpdatePeriod); now.Before(next) {
+			return next.Sub(now)
+		}
+	}
+	// Keep the last reported consumption while the ClusterQueue isn't in the
+	// cache.
+	if c := r.cache.LocalQueueConsumption(q); c != nil {
+		q.Status.Consumption = c
+	}
+	return r.localQueueConsumptionUpdatePeriod
+}
+
+func (r *LocalQueueReconciler) reportMetrics(q *kueue.LocalQueue) {
+	metrics.ReportLocalQueueStatus(q.Name, q.Namespace, int(q.Status.PendingWorkloads), int(q.Status.AdmittedWorkloads))
+	usage := make(map[corev1.ResourceName]map[string]float64)
+	for rName, flavors := range r.cache.LocalQueueUsage(q) {
+		usage[rName] = make(map[string]float64, len(flavors))
+		for flavor, v := range flavors {
+			q := workload.ResourceQuantity(rName, v)
+			usage[rName][flavor] = q.AsApproximateFloat64()
+		}
 	}
-	return ctrl.Result{}, nil
+	metrics.ReportLocalQueueResourceUsage(q.Name, q.Namespace, usage)
 }
 
 func (r *LocalQueueReconciler) Create(e event.CreateEvent) bool {
@@ -98,6 +158,9 @@ func (r *LocalQueueReconciler) Create(e event.CreateEvent) bool {
 		// No need to interact with the queue manager for other objects.
 		return true
 	}
+	if !r.ownsLocalQueue(q) {
+		return false
+	}
 	log := r.log.WithValues("localQueue", klog.KObj(q))
 	log.V(2).Info("LocalQueue create event")
 	ctx := logr.NewContext(context.Background(), log)
@@ -116,9 +179,15 @@ func (r *LocalQueueReconciler) Delete(e event.DeleteEvent) bool {
 		// No need to interact with the queue manager for other objects.
 		return true
 	}
+	if !r.ownsLocalQueue(q) {
+		return false
+	}
 	r.log.V(2).Info("LocalQueue delete event", "localQueue", klog.KObj(q))
 	r.queues.DeleteLocalQueue(q)
 	r.cache.DeleteLocalQueue(q)
+	if r.localQueueMetrics {
+		metrics.ClearLocalQueueMetrics(q.Name, q.Namespace)
+	}
 	return true
 }
 
@@ -128,18 +197,32 @@ func (r *LocalQueueReconciler) Update(e event.UpdateEvent) bool {
 		// No need to interact with the queue manager for other objects.
 		return true
 	}
+	oldQ := e.ObjectOld.(*kueue.LocalQueue)
+	if !r.ownsLocalQueue(oldQ) && !r.ownsLocalQueue(q) {
+		return false
+	}
 	log := r.log.WithValues("localQueue", klog.KObj(q))
 	log.V(2).Info("Queue update event")
 	if err := r.queues.UpdateLocalQueue(q); err != nil {
 		log.Error(err, "Failed to update queue in the queueing system")
 	}
-	oldQ := e.ObjectOld.(*kueue.LocalQueue)
 	if err := r.cache.UpdateLocalQueue(oldQ, q); err != nil {
 		log.Error(err, "Failed to update localQueue in the cache")
 	}
 	return true
 }
 
+// ownsLocalQueue returns whether the ClusterQueue of the LocalQueue belongs to
+// the shard of the reconciler.
+func (r *LocalQueueReconciler) ownsLocalQueue(q *kueue.LocalQueue) bool {
+	owned, err := r.shard.OwnsClusterQueue(context.Background(), r.client, string(q.Spec.ClusterQueue))
+	if err != nil {
+		r.log.Error(err, "Failed to get the shard of the localQueue", "localQueue", klog.KObj(q))
+		return false
+	}
+	return owned
+}
+
 func (r *LocalQueueReconciler) Generic(e event.GenericEvent) bool {
 	r.log.V(3).Info("Got Workload event", "workload", klog.KObj(e.Object))
 	return true
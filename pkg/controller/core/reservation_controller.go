@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+)
+
+// ReservationReconciler reconciles a Reservation object
+type ReservationReconciler struct {
+	qManager *queue.Manager
+	cache    *cache.Cache
+	client   client.Client
+	clock    clock.Clock
+}
+
+func NewReservationReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache, opts ...Option) *ReservationReconciler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &ReservationReconciler{
+		cache:    cache,
+		client:   client,
+		qManager: qMgr,
+		clock:    options.clock,
+	}
+}
+
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=reservations,verbs=get;list;watch
+
+func (r *ReservationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var reservation kueue.Reservation
+	if err := r.client.Get(ctx, req.NamespacedName, &reservation); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The quota held by the reservation is available again.
+			r.qManager.QueueInadmissibleWorkloads(ctx, r.cache.DeleteReservation(req.Name))
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	log := ctrl.LoggerFrom(ctx).WithValues("reservation", klog.KObj(&reservation))
+	ctx = ctrl.LoggerInto(ctx, log)
+	log.V(2).Info("Reconciling Reservation")
+
+	// The reservation might hold less quota than before, or its window might
+	// be over, so the inadmissible workloads might fit now.
+	cqNames := r.cache.AddOrUpdateReservation(reservation.DeepCopy())
+	r.qManager.QueueInadmissibleWorkloads(ctx, cqNames)
+
+	// Reconcile again when the quota starts and stops being held. The
+	// scheduler takes the window into account on its own; at the end, the
+	// workloads that didn't fit have to be queued again.
+	now := r.clock.Now()
+	if holdTime := cache.ReservationHoldTime(&reservation); now.Before(holdTime) {
+		return ctrl.Result{RequeueAfter: holdTime.Sub(now)}, nil
+	}
+	if now.Before(reservation.Spec.EndTime.Time) {
+		return ctrl.Result{RequeueAfter: reservation.Spec.EndTime.Sub(now)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReservationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kueue.Reservation{}).
+		Complete(r)
+}
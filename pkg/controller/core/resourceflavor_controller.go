@@ -20,8 +20,10 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -33,7 +35,9 @@ import (
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 )
 
 // ResourceFlavorReconciler reconciles a ResourceFlavor object
@@ -42,16 +46,24 @@ type ResourceFlavorReconciler struct {
 	qManager   *queue.Manager
 	cache      *cache.Cache
 	client     client.Client
+	recorder   record.EventRecorder
 	cqUpdateCh chan event.GenericEvent
+	shard      sharding.Shard
 }
 
-func NewResourceFlavorReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache) *ResourceFlavorReconciler {
+func NewResourceFlavorReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache, recorder record.EventRecorder, opts ...Option) *ResourceFlavorReconciler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &ResourceFlavorReconciler{
 		log:        ctrl.Log.WithName("resourceflavor-reconciler"),
 		cache:      cache,
 		client:     client,
+		recorder:   recorder,
 		qManager:   qMgr,
 		cqUpdateCh: make(chan event.GenericEvent, updateChBuffer),
+		shard:      options.shard,
 	}
 }
 
@@ -79,7 +91,15 @@ func (r *ResourceFlavorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	} else {
 		if controllerutil.ContainsFinalizer(&flavor, kueue.ResourceInUseFinalizerName) {
-			if cqName, ok := r.cache.FlavorInUse(flavor.Name); ok {
+			cqName, ok := r.cache.FlavorInUse(flavor.Name)
+			if !ok && r.shard.Enabled() {
+				// The cache only has the ClusterQueues of this shard.
+				var err error
+				if cqName, ok, err = r.flavorInUseByAnyClusterQueue(ctx, flavor.Name); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			if ok {
 				log.V(3).Info("resourceFlavor is still in use", "ClusterQueue", cqName)
 				// We avoid to return error here to prevent backoff requeue, which is passive and wasteful.
 				// Instead, we drive the removal of finalizer by ClusterQueue Update/Delete events
@@ -97,6 +117,25 @@ func (r *ResourceFlavorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
+// flavorInUseByAnyClusterQueue returns the name of a ClusterQueue, of any
+// shard, that uses the flavor.
+func (r *ResourceFlavorReconciler) flavorInUseByAnyClusterQueue(ctx context.Context, flavor string) (string, bool, error) {
+	var cqs kueue.ClusterQueueList
+	if err := r.client.List(ctx, &cqs); err != nil {
+		return "", false, err
+	}
+	for _, cq := range cqs.Items {
+		for _, res := range cq.Spec.Resources {
+			for _, f := range res.Flavors {
+				if string(f.Name) == flavor {
+					return cq.Name, true, nil
+				}
+			}
+		}
+	}
+	return "", false, nil
+}
+
 func (r *ResourceFlavorReconciler) Create(e event.CreateEvent) bool {
 	flv, match := e.Object.(*kueue.ResourceFlavor)
 	if !match {
@@ -127,8 +166,15 @@ func (r *ResourceFlavorReconciler) Delete(e event.DeleteEvent) bool {
 	log := r.log.WithValues("resourceFlavor", klog.KObj(flv))
 	log.V(2).Info("ResourceFlavor delete event")
 
-	if cqNames := r.cache.DeleteResourceFlavor(flv); len(cqNames) > 0 {
-		r.qManager.QueueInadmissibleWorkloads(context.Background(), cqNames)
+	for _, cqName := range r.cache.DeleteResourceFlavor(flv).List() {
+		log.V(2).Info("ClusterQueue became inactive", "clusterQueue", klog.KRef("", cqName))
+		metrics.ReportClusterQueueInactive(cqName, resourceFlavorDeletedReason)
+		var cq kueue.ClusterQueue
+		if err := r.client.Get(context.Background(), types.NamespacedName{Name: cqName}, &cq); err != nil {
+			log.Error(err, "Failed to get the clusterQueue to record the event", "clusterQueue", klog.KRef("", cqName))
+			continue
+		}
+		r.recorder.Eventf(&cq, corev1.EventTypeWarning, resourceFlavorDeletedReason, "ClusterQueue is inactive: ResourceFlavor %s was deleted", flv.Name)
 	}
 	return false
 }
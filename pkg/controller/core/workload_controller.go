@@ -18,20 +18,34 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/api"
+	"sigs.k8s.io/kueue/pkg/util/logging"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -42,6 +56,16 @@ const (
 	finished = "finished"
 )
 
+const (
+	// admissionCheckRetryBaseDelay is the delay before a workload is queued
+	// again the first time an admission check asks to retry. The delay doubles
+	// on each subsequent retry, up to admissionCheckRetryMaxDelay.
+	admissionCheckRetryBaseDelay = 10 * time.Second
+	admissionCheckRetryMaxDelay  = 10 * time.Minute
+
+	inactiveReason = "Inactive"
+)
+
 type WorkloadUpdateWatcher interface {
 	NotifyWorkloadUpdate(*kueue.Workload)
 }
@@ -52,16 +76,36 @@ type WorkloadReconciler struct {
 	queues   *queue.Manager
 	cache    *cache.Cache
 	client   client.Client
+	recorder record.EventRecorder
 	watchers []WorkloadUpdateWatcher
+
+	// reportedWorkloadLabels are the keys of the workload labels attached to
+	// the eviction events and metrics.
+	reportedWorkloadLabels []string
+
+	auditSink audit.Sink
+	retention workload.Retention
+	shard     sharding.Shard
+	clock     clock.Clock
 }
 
-func NewWorkloadReconciler(client client.Client, queues *queue.Manager, cache *cache.Cache, watchers ...WorkloadUpdateWatcher) *WorkloadReconciler {
+func NewWorkloadReconciler(client client.Client, queues *queue.Manager, cache *cache.Cache, recorder record.EventRecorder, watchers []WorkloadUpdateWatcher, opts ...Option) *WorkloadReconciler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &WorkloadReconciler{
-		log:      ctrl.Log.WithName("workload-reconciler"),
-		client:   client,
-		queues:   queues,
-		cache:    cache,
-		watchers: watchers,
+		log:                    ctrl.Log.WithName("workload-reconciler"),
+		client:                 client,
+		queues:                 queues,
+		cache:                  cache,
+		recorder:               recorder,
+		watchers:               watchers,
+		reportedWorkloadLabels: options.reportedWorkloadLabels,
+		auditSink:              options.auditSink,
+		retention:              options.workloadRetention,
+		shard:                  options.shard,
+		clock:                  options.clock,
 	}
 }
 
@@ -77,13 +121,50 @@ func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		// we'll ignore not-found errors, since there is nothing to do.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	log := ctrl.LoggerFrom(ctx).WithValues("workload", klog.KObj(&wl))
+	if owned, err := r.shard.OwnsWorkload(ctx, r.client, &wl); err != nil || !owned {
+		return ctrl.Result{}, err
+	}
+	log := logging.ForObject(ctrl.LoggerFrom(ctx).WithValues("workload", klog.KObj(&wl)), &wl)
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling Workload")
 
 	status := workloadStatus(&wl)
 	switch status {
 	case pending:
+		if len(wl.Status.AdmissionChecks) > 0 {
+			// The workload lost its quota; the checks are evaluated again on
+			// the next admission.
+			wl.Status.AdmissionChecks = nil
+			err := r.client.Status().Update(ctx, &wl)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if workload.DeadlineExceeded(&wl, r.clock.Now()) {
+			log.V(2).Info("Workload exceeded its deadline while pending, failing it")
+			err := r.failDeadlineExceeded(ctx, &wl)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if !workload.IsActive(&wl) {
+			if i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted); i != -1 && wl.Status.Conditions[i].Reason == inactiveReason {
+				return ctrl.Result{}, nil
+			}
+			err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, metav1.ConditionFalse,
+				inactiveReason, "The workload is deactivated")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if value, ok := wl.Annotations[constants.ManualAdmissionAnnotation]; ok {
+			err := r.admitManually(ctx, &wl, value)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if d := workload.RequeueAfter(&wl, r.clock.Now()); d > 0 {
+			log.V(3).Info("Workload is waiting to be requeued", "requeueAfter", d)
+			return ctrl.Result{RequeueAfter: d}, nil
+		}
+		if wl.Status.RequeueState != nil && wl.Status.RequeueState.RequeueAt != nil {
+			// The backoff expired; clearing requeueAt queues the workload again.
+			wl.Status.RequeueState.RequeueAt = nil
+			err := r.client.Status().Update(ctx, &wl)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
 		if !r.queues.QueueForWorkloadExists(&wl) {
 			err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, metav1.ConditionFalse,
 				"Inadmissible", fmt.Sprintf("Queue %s doesn't exist", wl.Spec.QueueName))
@@ -103,16 +184,262 @@ func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 	case admitted:
-		msg := fmt.Sprintf("Admitted by ClusterQueue %s", wl.Spec.Admission.ClusterQueue)
-		err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, metav1.ConditionTrue, "AdmissionByKueue", msg)
+		now := r.clock.Now()
+		checks := r.cache.AdmissionChecksForClusterQueue(string(wl.Spec.Admission.ClusterQueue))
+		statusChanged := workload.SyncAdmissionChecks(&wl, checks)
+		if workload.RecordQuotaReservation(&wl, now) {
+			statusChanged = true
+		}
+		if workload.RecordAssignedFlavors(&wl) {
+			statusChanged = true
+		}
+		if apimeta.IsStatusConditionTrue(wl.Status.Conditions, kueue.WorkloadEvicted) {
+			apimeta.SetStatusCondition(&wl.Status.Conditions, metav1.Condition{
+				Type:    kueue.WorkloadEvicted,
+				Status:  metav1.ConditionFalse,
+				Reason:  "QuotaReserved",
+				Message: fmt.Sprintf("Quota reserved in ClusterQueue %s", wl.Spec.Admission.ClusterQueue),
+			})
+			statusChanged = true
+		}
+		if msg, ok := wl.Annotations[constants.NodeFailureAnnotation]; ok {
+			log.V(2).Info("Workload lost pods because of a node failure, requeueing", "failure", msg)
+			err := r.evict(ctx, &wl, requeueNow, constants.NodeFailureEvictionReason, msg)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if c := workload.FirstAdmissionCheckInState(&wl, kueue.CheckStateRejected); c != nil {
+			log.V(2).Info("Admission check rejected the workload, deactivating", "admissionCheck", c.Name)
+			err := r.evict(ctx, &wl, noRequeue, inactiveReason, admissionCheckMessage("Admission check %s rejected the workload", c))
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if c := workload.FirstAdmissionCheckInState(&wl, kueue.CheckStateRetry); c != nil {
+			log.V(2).Info("Admission check asked to retry, requeueing", "admissionCheck", c.Name)
+			err := r.evict(ctx, &wl, requeueWithBackoff, "AdmissionCheckRetry", admissionCheckMessage("Admission check %s asked to retry", c))
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		var err error
+		if workload.AdmissionChecksReady(&wl, checks) {
+			if wl.Status.RequeueState != nil {
+				wl.Status.RequeueState = nil
+				statusChanged = true
+			}
+			if workload.RecordAdmission(&wl, now) {
+				statusChanged = true
+			}
+			if workload.RecordDeadline(&wl, now) {
+				statusChanged = true
+			}
+			wasAdmitted := apimeta.IsStatusConditionTrue(wl.Status.Conditions, kueue.WorkloadAdmitted)
+			msg := fmt.Sprintf("Admitted by ClusterQueue %s", wl.Spec.Admission.ClusterQueue)
+			err = r.updateStatus(ctx, &wl, statusChanged, metav1.ConditionTrue, "AdmissionByKueue", msg)
+			if err == nil && !wasAdmitted {
+				metrics.ReportAdmissionWaitTime(string(wl.Spec.Admission.ClusterQueue), r.clock.Since(wl.CreationTimestamp.Time))
+			}
+		} else {
+			msg := fmt.Sprintf("Quota reserved in ClusterQueue %s, waiting for admission checks", wl.Spec.Admission.ClusterQueue)
+			err = r.updateStatus(ctx, &wl, statusChanged, metav1.ConditionFalse, "AdmissionChecksPending", msg)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
+	case finished:
+		if d, ok := r.retention.DeleteAfter(&wl, r.clock.Now()); ok {
+			if d > 0 {
+				return ctrl.Result{RequeueAfter: d}, nil
+			}
+			log.V(2).Info("Deleting the finished workload after its retention period", "failed", workload.IsFailed(&wl))
+			err := r.client.Delete(ctx, &wl)
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// admitManually admits the workload with the admission in the value of the
+// manual admission annotation, if the cache validates it. The annotation is
+// removed, and the workload stays pending if the admission is rejected.
+func (r *WorkloadReconciler) admitManually(ctx context.Context, wl *kueue.Workload, value string) error {
+	log := ctrl.LoggerFrom(ctx)
+	var ns corev1.Namespace
+	if err := r.client.Get(ctx, types.NamespacedName{Name: wl.Namespace}, &ns); err != nil {
+		return err
+	}
+	newWl := wl.DeepCopy()
+	delete(newWl.Annotations, constants.ManualAdmissionAnnotation)
+	admission := &kueue.Admission{}
+	err := json.Unmarshal([]byte(value), admission)
+	if err == nil {
+		newWl.Spec.Admission = admission
+		err = r.cache.AssumeManualAdmission(newWl, ns.Labels)
+	}
+	if err != nil {
+		log.V(2).Info("Manual admission rejected", "reason", err.Error())
+		r.recorder.Eventf(wl, corev1.EventTypeWarning, "ManualAdmissionRejected", "Manual admission rejected: %v", err)
+		newWl.Spec.Admission = nil
+		return r.client.Update(ctx, newWl)
+	}
+	if err := r.client.Update(ctx, newWl); err != nil {
+		// Ignore errors because the workload or clusterQueue could have been
+		// deleted by an event.
+		_ = r.cache.ForgetWorkload(newWl)
+		return err
+	}
+	waitTime := r.clock.Since(wl.CreationTimestamp.Time)
+	wlLabels := workload.SelectLabels(newWl, r.reportedWorkloadLabels)
+	msg := fmt.Sprintf("Manually admitted by ClusterQueue %v, wait time was %.3fs", admission.ClusterQueue, waitTime.Seconds())
+	r.recorder.AnnotatedEventf(newWl, wlLabels, corev1.EventTypeNormal, "Admitted", msg)
+	metrics.AdmittedWorkload(admission.ClusterQueue, waitTime, wlLabels)
+	if r.auditSink != nil {
+		if err := r.auditSink.Record(ctx, audit.NewRecord(audit.Admission, newWl, "ManuallyAdmitted", msg, r.clock.Now())); err != nil {
+			log.Error(err, "Failed recording the admission in the audit sink")
+		}
+	}
+	log.V(2).Info("Workload manually admitted", "clusterQueue", admission.ClusterQueue)
+	return nil
+}
+
+// updateStatus sets the Admitted condition of the workload. The status is
+// always updated if force is true, as other fields of the status changed.
+func (r *WorkloadReconciler) updateStatus(ctx context.Context, wl *kueue.Workload, force bool,
+	conditionStatus metav1.ConditionStatus, reason, message string) error {
+	if force {
+		return workload.UpdateStatus(ctx, r.client, wl, kueue.WorkloadAdmitted, conditionStatus, reason, message)
+	}
+	return workload.UpdateStatusIfChanged(ctx, r.client, wl, kueue.WorkloadAdmitted, conditionStatus, reason, message)
+}
+
+// requeuePolicy determines what happens to an evicted workload.
+type requeuePolicy int
+
+const (
+	// noRequeue deactivates the workload.
+	noRequeue requeuePolicy = iota
+	// requeueWithBackoff queues the workload again after a backoff.
+	requeueWithBackoff
+	// requeueNow queues the workload again right away.
+	requeueNow
+)
+
+// evict releases the quota reserved by the workload and counts the eviction
+// for the flavors it was assigned. The workload is then deactivated or queued
+// again, according to the requeue policy.
+// The status is updated first so that the workload isn't queued before the
+// backoff is recorded.
+func (r *WorkloadReconciler) evict(ctx context.Context, wl *kueue.Workload, requeue requeuePolicy, reason, message string) error {
+	if requeue != noRequeue && workload.DeadlineExceeded(wl, r.clock.Now()) {
+		ctrl.LoggerFrom(ctx).V(2).Info("Workload exceeded its deadline, failing it instead of requeueing", "reason", reason)
+		return r.failDeadlineExceeded(ctx, wl)
+	}
+	cqName := string(wl.Spec.Admission.ClusterQueue)
+	now := r.clock.Now()
+	var auditRecord *audit.Record
+	if r.auditSink != nil {
+		// The quota released is computed before the admission is cleared.
+		auditRecord = audit.NewRecord(audit.Eviction, wl, reason, message, now)
+	}
+	wl.Status.AdmissionChecks = nil
+	wl.Status.AssignedFlavors = ""
+	workload.RecordFlavorEvictions(wl)
+	firstForReason := workload.RecordEviction(wl, reason, now)
+	if requeue == requeueWithBackoff {
+		if wl.Status.RequeueState == nil {
+			wl.Status.RequeueState = &kueue.RequeueState{}
+		}
+		wl.Status.RequeueState.Count++
+		requeueAt := metav1.NewTime(now.Add(retryBackoff(wl.Status.RequeueState.Count)))
+		wl.Status.RequeueState.RequeueAt = &requeueAt
+	}
+	apimeta.SetStatusCondition(&wl.Status.Conditions, metav1.Condition{
+		Type:    kueue.WorkloadAdmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: api.TruncateConditionMessage(message),
+	})
+	apimeta.SetStatusCondition(&wl.Status.Conditions, metav1.Condition{
+		Type:    kueue.WorkloadEvicted,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: api.TruncateConditionMessage(message),
+	})
+	if err := r.client.Status().Update(ctx, wl); err != nil {
+		return err
+	}
+	if firstForReason {
+		// The evictions are counted in the status, which was just persisted,
+		// so a workload is counted once per reason even across restarts.
+		metrics.ReportEvictedWorkloadOnce(cqName, reason)
+	}
+	wl.Spec.Admission = nil
+	delete(wl.Annotations, constants.NodeFailureAnnotation)
+	if requeue == noRequeue {
+		wl.Spec.Active = pointer.Bool(false)
+	}
+	if err := r.client.Update(ctx, wl); err != nil {
+		return err
+	}
+	wlLabels := workload.SelectLabels(wl, r.reportedWorkloadLabels)
+	r.recorder.AnnotatedEventf(wl, wlLabels, corev1.EventTypeNormal, "Evicted", "Evicted from ClusterQueue %s: %s", cqName, message)
+	metrics.ReportEvictedWorkload(cqName, reason, wlLabels)
+	if auditRecord != nil {
+		if err := r.auditSink.Record(ctx, auditRecord); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "Failed recording the eviction in the audit sink")
+		}
+	}
+	return nil
+}
+
+// failDeadlineExceeded marks the workload, which exceeded its
+// activeDeadlineSeconds, as finished and failed, instead of queueing it again.
+// The admission is cleared so that the job is stopped.
+func (r *WorkloadReconciler) failDeadlineExceeded(ctx context.Context, wl *kueue.Workload) error {
+	const reason = "DeadlineExceeded"
+	message := "The workload exceeded its activeDeadlineSeconds"
+	for _, condType := range []string{kueue.WorkloadFinished, kueue.WorkloadFailed} {
+		apimeta.SetStatusCondition(&wl.Status.Conditions, metav1.Condition{
+			Type:    condType,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+	wl.Status.AdmissionChecks = nil
+	wl.Status.RequeueState = nil
+	if err := r.client.Status().Update(ctx, wl); err != nil {
+		return err
+	}
+	r.recorder.Event(wl, corev1.EventTypeWarning, reason, message)
+	if wl.Spec.Admission == nil {
+		return nil
+	}
+	wl.Spec.Admission = nil
+	return r.client.Update(ctx, wl)
+}
+
+// retryBackoff returns the delay before requeueing a workload for the given
+// retry count.
+func retryBackoff(count int32) time.Duration {
+	d := admissionCheckRetryBaseDelay
+	for i := int32(1); i < count && d < admissionCheckRetryMaxDelay; i++ {
+		d *= 2
+	}
+	if d > admissionCheckRetryMaxDelay {
+		return admissionCheckRetryMaxDelay
+	}
+	return d
+}
+
+func admissionCheckMessage(format string, c *kueue.AdmissionCheckState) string {
+	msg := fmt.Sprintf(format, c.Name)
+	if c.Message != "" {
+		msg += ": " + c.Message
+	}
+	return msg
+}
+
 func (r *WorkloadReconciler) Create(e event.CreateEvent) bool {
 	wl := e.Object.(*kueue.Workload)
+	if !r.ownsWorkload(wl) {
+		return false
+	}
 	defer r.notifyWatchers(wl)
 	status := workloadStatus(wl)
 	log := r.log.WithValues("workload", klog.KObj(wl), "queue", wl.Spec.QueueName, "status", status)
@@ -140,6 +467,9 @@ func (r *WorkloadReconciler) Create(e event.CreateEvent) bool {
 
 func (r *WorkloadReconciler) Delete(e event.DeleteEvent) bool {
 	wl := e.Object.(*kueue.Workload)
+	if !r.ownsWorkload(wl) {
+		return false
+	}
 	defer r.notifyWatchers(wl)
 	status := "unknown"
 	if !e.DeleteStateUnknown {
@@ -174,6 +504,9 @@ func (r *WorkloadReconciler) Delete(e event.DeleteEvent) bool {
 func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 	oldWl := e.ObjectOld.(*kueue.Workload)
 	wl := e.ObjectNew.(*kueue.Workload)
+	if !r.ownsWorkload(oldWl) && !r.ownsWorkload(wl) {
+		return false
+	}
 	defer r.notifyWatchers(oldWl)
 	defer r.notifyWatchers(wl)
 
@@ -208,8 +541,11 @@ func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 		}
 		r.queues.DeleteWorkload(oldWl)
 
-		// trigger the move of associated inadmissibleWorkloads if required.
-		r.queues.QueueAssociatedInadmissibleWorkloads(ctx, wl)
+		// trigger the move of associated inadmissibleWorkloads if the
+		// workload released quota.
+		if prevStatus == admitted {
+			r.queues.QueueAssociatedInadmissibleWorkloads(ctx, oldWl)
+		}
 
 	case prevStatus == pending && status == pending:
 		if !r.queues.UpdateWorkload(oldWl, wlCopy) {
@@ -227,7 +563,7 @@ func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 			log.Error(err, "Failed to delete workload from cache")
 		}
 		// trigger the move of associated inadmissibleWorkloads if required.
-		r.queues.QueueAssociatedInadmissibleWorkloads(ctx, wl)
+		r.queues.QueueAssociatedInadmissibleWorkloads(ctx, oldWl)
 
 		if !r.queues.AddOrUpdateWorkload(wlCopy) {
 			log.V(2).Info("Queue for workload didn't exist; ignored for now")
@@ -239,6 +575,10 @@ func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 		if err := r.cache.UpdateWorkload(oldWl, wlCopy); err != nil {
 			log.Error(err, "Updating workload in cache")
 		}
+		if status == admitted && !equality.Semantic.DeepEqual(oldWl.Status.ReclaimablePods, wl.Status.ReclaimablePods) {
+			// Part of the quota might have been released.
+			r.queues.QueueAssociatedInadmissibleWorkloads(ctx, wl)
+		}
 	}
 
 	return true
@@ -249,6 +589,17 @@ func (r *WorkloadReconciler) Generic(e event.GenericEvent) bool {
 	return false
 }
 
+// ownsWorkload returns whether the ClusterQueue of the workload belongs to the
+// shard of the reconciler.
+func (r *WorkloadReconciler) ownsWorkload(wl *kueue.Workload) bool {
+	owned, err := r.shard.OwnsWorkload(context.Background(), r.client, wl)
+	if err != nil {
+		r.log.Error(err, "Failed to get the shard of the workload", "workload", klog.KObj(wl))
+		return false
+	}
+	return owned
+}
+
 func (r *WorkloadReconciler) notifyWatchers(wl *kueue.Workload) {
 	for _, w := range r.watchers {
 		w.NotifyWorkloadUpdate(wl)
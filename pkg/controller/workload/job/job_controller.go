@@ -19,24 +19,31 @@ package job
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/util/logging"
 	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -44,19 +51,37 @@ var (
 	ownerKey = ".metadata.controller"
 )
 
+// kueueKeyPrefix is the prefix of the annotations that Kueue reads from the
+// workloads.
+const kueueKeyPrefix = "kueue.x-k8s.io/"
+
 // JobReconciler reconciles a Job object
 type JobReconciler struct {
-	client                     client.Client
-	scheme                     *runtime.Scheme
-	record                     record.EventRecorder
-	manageJobsWithoutQueueName bool
+	client client.Client
+	scheme *runtime.Scheme
+	record record.EventRecorder
+
+	// optionsLock guards the options, which can be updated at runtime.
+	optionsLock sync.RWMutex
+	options     options
+
+	nodeFailures *nodeFailures
+	clock        clock.Clock
+	// podReader lists the pods bound to a node, which aren't cached.
+	podReader client.Reader
 }
 
 type options struct {
 	manageJobsWithoutQueueName bool
+	labelKeysToCopy            []string
+	annotationKeysToCopy       []string
+	requireExistingLocalQueue  bool
+	requeueOnNodeFailure       bool
+	shard                      sharding.Shard
+	clock                      clock.Clock
 }
 
-// Option configures the reconciler.
+// Option configures the reconciler and the webhook.
 type Option func(*options)
 
 // WithManageJobsWithoutQueueName indicates if the controller should reconcile
@@ -67,34 +92,114 @@ func WithManageJobsWithoutQueueName(f bool) Option {
 	}
 }
 
-var defaultOptions = options{}
+// WithLabelKeysToCopy sets the keys of the labels that are copied from the
+// jobs to their workloads when the workloads are created. A key ending in "*"
+// matches all the keys with the preceding prefix.
+func WithLabelKeysToCopy(keys ...string) Option {
+	return func(o *options) {
+		o.labelKeysToCopy = keys
+	}
+}
 
-func NewReconciler(
-	scheme *runtime.Scheme,
-	client client.Client,
-	record record.EventRecorder,
-	opts ...Option) *JobReconciler {
+// WithAnnotationKeysToCopy sets the keys of the annotations that are copied
+// from the jobs to their workloads when the workloads are created. A key
+// ending in "*" matches all the keys with the preceding prefix.
+func WithAnnotationKeysToCopy(keys ...string) Option {
+	return func(o *options) {
+		o.annotationKeysToCopy = keys
+	}
+}
+
+// WithRequireExistingLocalQueue indicates if the webhook should reject the
+// creation of jobs that set a LocalQueue that doesn't exist in their
+// namespace.
+func WithRequireExistingLocalQueue(f bool) Option {
+	return func(o *options) {
+		o.requireExistingLocalQueue = f
+	}
+}
+
+// WithRequeueOnNodeFailure indicates if the controller should requeue the
+// admitted workloads whose jobs lose pods because of a node failure. It only
+// takes effect if it's set when the controller is set up.
+func WithRequeueOnNodeFailure(f bool) Option {
+	return func(o *options) {
+		o.requeueOnNodeFailure = f
+	}
+}
+
+// WithShard sets the shard of the cohorts whose jobs the controller manages.
+// The jobs are assigned to the shard of the ClusterQueue of their LocalQueue.
+func WithShard(s sharding.Shard) Option {
+	return func(o *options) {
+		o.shard = s
+	}
+}
+
+// WithClock sets the clock used to time the provisioning of nodes. It only
+// takes effect if it's set when the reconciler is created.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var defaultOptions = options{
+	clock: clock.RealClock{},
+}
 
+func newOptions(opts []Option) options {
 	options := defaultOptions
 	for _, opt := range opts {
 		opt(&options)
 	}
+	return options
+}
+
+func NewReconciler(
+	scheme *runtime.Scheme,
+	client client.Client,
+	record record.EventRecorder,
+	opts ...Option) *JobReconciler {
 
+	options := newOptions(opts)
 	return &JobReconciler{
-		scheme:                     scheme,
-		client:                     client,
-		record:                     record,
-		manageJobsWithoutQueueName: options.manageJobsWithoutQueueName,
+		scheme:       scheme,
+		client:       client,
+		record:       record,
+		options:      options,
+		nodeFailures: newNodeFailures(),
+		clock:        options.clock,
+		podReader:    client,
 	}
 }
 
+// UpdateOptions replaces the options of the reconciler. The options not
+// given are reset to their defaults.
+func (r *JobReconciler) UpdateOptions(opts ...Option) {
+	options := newOptions(opts)
+	r.optionsLock.Lock()
+	defer r.optionsLock.Unlock()
+	r.options = options
+}
+
+func (r *JobReconciler) currentOptions() options {
+	r.optionsLock.RLock()
+	defer r.optionsLock.RUnlock()
+	return r.options
+}
+
 // SetupWithManager sets up the controller with the Manager. It indexes workloads
 // based on the owning jobs.
 func (r *JobReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	r.podReader = mgr.GetAPIReader()
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&batchv1.Job{}).
-		Owns(&kueue.Workload{}).
-		Complete(r)
+		Owns(&kueue.Workload{})
+	if r.currentOptions().requeueOnNodeFailure {
+		b = b.Watches(&source.Kind{Type: &corev1.Pod{}}, &podHandler{client: r.client, failures: r.nodeFailures})
+	}
+	return b.Complete(r)
 }
 
 func SetupIndexes(indexer client.FieldIndexer) error {
@@ -117,12 +222,15 @@ func SetupIndexes(indexer client.FieldIndexer) error {
 //+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=list;get;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;watch;update
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;update;patch
-//+kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
+//+kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get;update
 //+kubebuilder:rbac:groups=batch,resources=jobs/finalizers,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/finalizers,verbs=update
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=resourceflavors,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=clusterqueues,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
 func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var job batchv1.Job
@@ -131,12 +239,16 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	log := ctrl.LoggerFrom(ctx).WithValues("job", klog.KObj(&job))
+	log := logging.ForObject(ctrl.LoggerFrom(ctx).WithValues("job", klog.KObj(&job)), &job)
 	ctx = ctrl.LoggerInto(ctx, log)
-	if queueName(&job) == "" && !r.manageJobsWithoutQueueName {
+	options := r.currentOptions()
+	if queueName(&job) == "" && !options.manageJobsWithoutQueueName {
 		log.V(3).Info(fmt.Sprintf("%s annotation is not set, ignoring the job", constants.QueueAnnotation))
 		return ctrl.Result{}, nil
 	}
+	if owned, err := options.shard.OwnsLocalQueue(ctx, r.client, job.Namespace, queueName(&job)); err != nil || !owned {
+		return ctrl.Result{}, err
+	}
 
 	log.V(2).Info("Reconciling Job")
 
@@ -155,6 +267,7 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	}
 
 	jobFinishedCond, jobFinished := jobFinishedCondition(&job)
+	nodeFailure, lostPods := r.nodeFailures.take(req.NamespacedName)
 	// 2. create new workload if none exists
 	if wl == nil {
 		// Nothing to do if the job is finished
@@ -183,18 +296,70 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	}
 
 	// 4. Handle a not finished job
+	if lostPods && wl.Spec.Admission != nil {
+		// The Workload controller evicts the workload and queues it again.
+		log.V(2).Info("Job lost pods because of a node failure, requeueing the workload", "failure", nodeFailure)
+		if wl.Annotations == nil {
+			wl.Annotations = make(map[string]string, 1)
+		}
+		wl.Annotations[constants.NodeFailureAnnotation] = nodeFailure
+		if err := r.client.Update(ctx, wl); err != nil {
+			log.Error(err, "Marking the workload for requeueing")
+			r.nodeFailures.add(req.NamespacedName, nodeFailure)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
 	if jobSuspended(&job) {
-		// 4.1 start the job if the workload has been admitted, and the job is still suspended
+		// 4.1 fail the job if the workload failed on its own, because it
+		// exceeded its deadline, so that its workload isn't created again.
+		if failed := apimeta.FindStatusCondition(wl.Status.Conditions, kueue.WorkloadFailed); failed != nil && failed.Status == metav1.ConditionTrue {
+			log.V(2).Info("Workload failed, failing the job", "reason", failed.Reason)
+			err := r.failJob(ctx, &job, failed)
+			if err != nil {
+				log.Error(err, "Failing job")
+			}
+			return ctrl.Result{}, err
+		}
+
+		// 4.2 start the job if the workload has been admitted, and the job is still suspended
 		if wl.Spec.Admission != nil {
+			ready, err := r.admissionChecksReady(ctx, wl)
+			if err != nil {
+				log.Error(err, "Checking the admission checks")
+				return ctrl.Result{}, err
+			}
+			if !ready {
+				log.V(3).Info("Job has quota reserved, waiting for admission checks")
+				return ctrl.Result{}, nil
+			}
+			// In job gating only mode, there is no Workload controller to
+			// record when the workload was admitted, which anchors the timeout
+			// of the node provisioning.
+			if workload.RecordAdmission(wl, r.clock.Now()) {
+				if err := r.client.Status().Update(ctx, wl); err != nil {
+					log.Error(err, "Recording the admission of the workload")
+					return ctrl.Result{}, err
+				}
+			}
+			wait, err := r.waitForNodes(ctx, wl)
+			if err != nil {
+				log.Error(err, "Checking the provisioned nodes")
+				return ctrl.Result{}, err
+			}
+			if wait > 0 {
+				log.V(3).Info("Job admitted, waiting for nodes to be provisioned", "requeueAfter", wait)
+				return ctrl.Result{RequeueAfter: wait}, nil
+			}
 			log.V(2).Info("Job admitted, unsuspending")
-			err := r.startJob(ctx, wl, &job)
+			err = r.startJob(ctx, wl, &job)
 			if err != nil {
 				log.Error(err, "Unsuspending job")
 			}
 			return ctrl.Result{}, err
 		}
 
-		// 4.2 update queue name if changed.
+		// 4.3 update queue name if changed.
 		q := queueName(&job)
 		if wl.Spec.QueueName != q {
 			log.V(2).Info("Job changed queues, updating workload")
@@ -210,7 +375,7 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	}
 
 	if wl.Spec.Admission == nil {
-		// 4.3 the job must be suspended if the workload is not yet admitted.
+		// 4.4 the job must be suspended if the workload is not yet admitted.
 		log.V(2).Info("Running job is not admitted by a cluster queue, suspending")
 		err := r.stopJob(ctx, wl, &job, "Not admitted by cluster queue")
 		if err != nil {
@@ -219,11 +384,42 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// 4.4 workload is admitted and job is running, nothing to do.
+	// 4.5 workload is admitted and job is running, release the quota of the
+	// pods that are no longer needed.
+	if reclaimable := reclaimablePods(&job, wl); !equality.Semantic.DeepEqual(reclaimable, wl.Status.ReclaimablePods) {
+		log.V(2).Info("Job pods finished, updating the reclaimable pods of the workload", "reclaimablePods", reclaimable)
+		wl.Status.ReclaimablePods = reclaimable
+		err := r.client.Status().Update(ctx, wl)
+		if err != nil {
+			log.Error(err, "Updating workload reclaimable pods")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
 	log.V(3).Info("Job running with admitted workload, nothing to do")
 	return ctrl.Result{}, nil
 }
 
+// reclaimablePods returns the number of pods of the job that are no longer
+// needed, because the remaining completions need less pods than the
+// parallelism.
+func reclaimablePods(job *batchv1.Job, wl *kueue.Workload) []kueue.ReclaimablePod {
+	parallelism := pointer.Int32Deref(job.Spec.Parallelism, 1)
+	if parallelism == 1 || job.Status.Succeeded == 0 || len(wl.Spec.PodSets) != 1 {
+		return nil
+	}
+	remaining := pointer.Int32Deref(job.Spec.Completions, parallelism) - job.Status.Succeeded
+	if remaining >= parallelism {
+		return nil
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return []kueue.ReclaimablePod{{
+		Name:  wl.Spec.PodSets[0].Name,
+		Count: parallelism - remaining,
+	}}
+}
+
 // stopJob sends updates to suspend the job, reset the startTime so we can update the scheduling directives
 // later when unsuspending and resets the nodeSelector to its previous state based on what is available in
 // the workload (which should include the original affinities that the job had).
@@ -255,6 +451,26 @@ func (r *JobReconciler) stopJob(ctx context.Context, w *kueue.Workload,
 	return nil
 }
 
+// failJob adds the Failed condition to the job, with the reason and message of
+// the Failed condition of its workload. The job controller doesn't run the
+// pods of a failed job.
+func (r *JobReconciler) failJob(ctx context.Context, job *batchv1.Job, cond *metav1.Condition) error {
+	now := metav1.NewTime(r.clock.Now())
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:               batchv1.JobFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             cond.Reason,
+		Message:            cond.Message,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	})
+	if err := r.client.Status().Update(ctx, job); err != nil {
+		return err
+	}
+	r.record.Eventf(job, corev1.EventTypeWarning, "Failed", "Workload failed: %s", cond.Message)
+	return nil
+}
+
 func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *batchv1.Job) error {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -288,6 +504,20 @@ func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *ba
 	return nil
 }
 
+// admissionChecksReady returns whether the admission checks required by the
+// ClusterQueue that admitted the workload are Ready.
+func (r *JobReconciler) admissionChecksReady(ctx context.Context, w *kueue.Workload) (bool, error) {
+	var cq kueue.ClusterQueue
+	if err := r.client.Get(ctx, types.NamespacedName{Name: string(w.Spec.Admission.ClusterQueue)}, &cq); err != nil {
+		if apierrors.IsNotFound(err) {
+			// There are no checks to wait for.
+			return true, nil
+		}
+		return false, err
+	}
+	return workload.AdmissionChecksReady(w, cq.Spec.AdmissionChecks), nil
+}
+
 func (r *JobReconciler) getNodeSelectors(ctx context.Context, w *kueue.Workload) (map[string]string, error) {
 	if len(w.Spec.Admission.PodSetFlavors[0].Flavors) == 0 {
 		return nil, nil
@@ -321,11 +551,27 @@ func (r *JobReconciler) handleJobWithNoWorkload(ctx context.Context, job *batchv
 		return nil
 	}
 
+	// The Job webhook only validates the parallelism on creation, and the
+	// Workload webhook would reject the workload.
+	if parallelism := pointer.Int32Deref(job.Spec.Parallelism, 1); parallelism > kueue.MaxPodsPerWorkload {
+		log.V(2).Info("Job parallelism exceeds the maximum pods of a workload", "parallelism", parallelism)
+		r.record.Eventf(job, corev1.EventTypeWarning, "InvalidParallelism",
+			"Parallelism %d exceeds the maximum of %d pods of a Workload", parallelism, kueue.MaxPodsPerWorkload)
+		return nil
+	}
+
 	// Create the corresponding workload.
 	wl, err := ConstructWorkloadFor(ctx, r.client, job, r.scheme)
 	if err != nil {
 		return err
 	}
+	options := r.currentOptions()
+	wl.Labels = copyMetadata(wl.Labels, job.Labels, options.labelKeysToCopy)
+	// The Kueue annotations are never copied from the jobs, so that the users
+	// that can create jobs can't set them in the workloads, like the manual
+	// admission. Only the debug annotation is copied.
+	wl.Annotations = copyMetadata(wl.Annotations, withoutKueueKeys(job.Annotations), options.annotationKeysToCopy)
+	wl.Annotations = copyMetadata(wl.Annotations, job.Annotations, []string{constants.DebugAnnotation})
 	if err = r.client.Create(ctx, wl); err != nil {
 		return err
 	}
@@ -335,6 +581,47 @@ func (r *JobReconciler) handleJobWithNoWorkload(ctx context.Context, job *batchv
 	return nil
 }
 
+// copyMetadata copies the entries of src that match the keys into dst, which
+// is returned. A key ending in "*" matches all the keys with the preceding
+// prefix.
+func copyMetadata(dst, src map[string]string, keys []string) map[string]string {
+	for k, v := range src {
+		if !matchesAnyKey(k, keys) {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]string)
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// withoutKueueKeys returns the entries of m whose keys don't have the Kueue
+// prefix.
+func withoutKueueKeys(m map[string]string) map[string]string {
+	filtered := make(map[string]string, len(m))
+	for k, v := range m {
+		if !strings.HasPrefix(k, kueueKeyPrefix) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+func matchesAnyKey(k string, keys []string) bool {
+	for _, key := range keys {
+		if prefix := strings.TrimSuffix(key, "*"); prefix != key {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+		} else if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 // ensureAtMostOneWorkload finds a matching workload and deletes redundant ones.
 func (r *JobReconciler) ensureAtMostOneWorkload(ctx context.Context, job *batchv1.Job, workloads kueue.WorkloadList) (*kueue.Workload, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -415,10 +702,23 @@ func ConstructWorkloadFor(ctx context.Context, client client.Client,
 			QueueName: queueName(job),
 		},
 	}
+	if d := job.Spec.ActiveDeadlineSeconds; d != nil {
+		w.Spec.ActiveDeadlineSeconds = pointer.Int64(*d)
+	}
 
-	// Populate priority from priority class.
+	// Populate priority from priority class, falling back to the default of
+	// the LocalQueue.
+	priorityClassName := job.Spec.Template.Spec.PriorityClassName
+	if len(priorityClassName) == 0 && len(w.Spec.QueueName) > 0 {
+		var q kueue.LocalQueue
+		err := client.Get(ctx, types.NamespacedName{Name: w.Spec.QueueName, Namespace: w.Namespace}, &q)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		priorityClassName = q.Spec.DefaultPriorityClass
+	}
 	priorityClassName, p, err := utilpriority.GetPriorityFromPriorityClass(
-		ctx, client, job.Spec.Template.Spec.PriorityClassName)
+		ctx, client, priorityClassName)
 	if err != nil {
 		return nil, err
 	}
@@ -432,7 +732,11 @@ func ConstructWorkloadFor(ctx context.Context, client client.Client,
 	return w, nil
 }
 
-func appendFinishedConditionIfNotExists(conds []metav1.Condition, jobStatus batchv1.JobConditionType) ([]metav1.Condition, bool) {
+// appendFinishedConditionIfNotExists adds the Finished condition to the
+// conditions of a workload, with the reason and message of the condition that
+// finished the job, like BackoffLimitExceeded or DeadlineExceeded, if any.
+// If the job failed, it also adds the Failed condition.
+func appendFinishedConditionIfNotExists(conds []metav1.Condition, jobCond batchv1.JobCondition) ([]metav1.Condition, bool) {
 	for i, c := range conds {
 		if c.Type == kueue.WorkloadFinished {
 			if c.Status == metav1.ConditionTrue {
@@ -442,29 +746,45 @@ func appendFinishedConditionIfNotExists(conds []metav1.Condition, jobStatus batc
 			break
 		}
 	}
+	reason := "JobFinished"
+	if jobCond.Reason != "" {
+		reason = jobCond.Reason
+	}
 	message := "Job finished successfully"
-	if jobStatus == batchv1.JobFailed {
+	if jobCond.Type == batchv1.JobFailed {
 		message = "Job failed"
 	}
+	if jobCond.Message != "" {
+		message += ": " + jobCond.Message
+	}
 	now := metav1.Now()
 	conds = append(conds, metav1.Condition{
 		Type:               kueue.WorkloadFinished,
 		Status:             metav1.ConditionTrue,
 		LastTransitionTime: now,
-		Reason:             "JobFinished",
+		Reason:             reason,
 		Message:            message,
 	})
+	if jobCond.Type == batchv1.JobFailed {
+		apimeta.SetStatusCondition(&conds, metav1.Condition{
+			Type:               kueue.WorkloadFailed,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: now,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
 	return conds, true
 }
 
 // From https://github.com/kubernetes/kubernetes/blob/master/pkg/controller/job/utils.go
-func jobFinishedCondition(j *batchv1.Job) (batchv1.JobConditionType, bool) {
+func jobFinishedCondition(j *batchv1.Job) (batchv1.JobCondition, bool) {
 	for _, c := range j.Status.Conditions {
 		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
-			return c.Type, true
+			return c, true
 		}
 	}
-	return "", false
+	return batchv1.JobCondition{}, false
 }
 
 func jobSuspended(j *batchv1.Job) bool {
@@ -0,0 +1,385 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+func TestReclaimablePods(t *testing.T) {
+	wl := builder.MakeWorkload("wl", "ns").Obj()
+	cases := map[string]struct {
+		parallelism *int32
+		completions *int32
+		succeeded   int32
+		want        []kueue.ReclaimablePod
+	}{
+		"no pods succeeded": {
+			parallelism: pointer.Int32(4),
+			completions: pointer.Int32(10),
+		},
+		"remaining completions need all the pods": {
+			parallelism: pointer.Int32(4),
+			completions: pointer.Int32(10),
+			succeeded:   6,
+		},
+		"remaining completions need less pods": {
+			parallelism: pointer.Int32(4),
+			completions: pointer.Int32(10),
+			succeeded:   8,
+			want:        []kueue.ReclaimablePod{{Name: "main", Count: 2}},
+		},
+		"work queue": {
+			parallelism: pointer.Int32(4),
+			succeeded:   1,
+			want:        []kueue.ReclaimablePod{{Name: "main", Count: 1}},
+		},
+		"single pod": {
+			parallelism: pointer.Int32(1),
+			completions: pointer.Int32(3),
+			succeeded:   2,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := &batchv1.Job{
+				Spec: batchv1.JobSpec{
+					Parallelism: tc.parallelism,
+					Completions: tc.completions,
+				},
+				Status: batchv1.JobStatus{Succeeded: tc.succeeded},
+			}
+			got := reclaimablePods(job, wl)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected reclaimable pods (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConstructWorkloadForPriority(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{batchv1.AddToScheme, schedulingv1.AddToScheme, kueue.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("Failed adding to the scheme: %v", err)
+		}
+	}
+	objs := []runtime.Object{
+		builder.MakePriorityClass("high").PriorityValue(100).Obj(),
+		builder.MakePriorityClass("low").PriorityValue(-10).Obj(),
+		builder.MakeLocalQueue("dev", "ns").ClusterQueue("cq").DefaultPriorityClass("low").Obj(),
+		builder.MakeLocalQueue("prod", "ns").ClusterQueue("cq").Obj(),
+	}
+	cases := map[string]struct {
+		job               *batchv1.Job
+		wantPriorityClass string
+		wantPriority      int32
+	}{
+		"priority class of the job": {
+			job:               builder.MakeJob("job", "ns").Queue("dev").PriorityClass("high").Obj(),
+			wantPriorityClass: "high",
+			wantPriority:      100,
+		},
+		"default priority class of the queue": {
+			job:               builder.MakeJob("job", "ns").Queue("dev").Obj(),
+			wantPriorityClass: "low",
+			wantPriority:      -10,
+		},
+		"queue without default priority class": {
+			job: builder.MakeJob("job", "ns").Queue("prod").Obj(),
+		},
+		"queue not found": {
+			job: builder.MakeJob("job", "ns").Queue("missing").Obj(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+			wl, err := ConstructWorkloadFor(context.Background(), cl, tc.job, scheme)
+			if err != nil {
+				t.Fatalf("Failed constructing the workload: %v", err)
+			}
+			if wl.Spec.PriorityClassName != tc.wantPriorityClass {
+				t.Errorf("Got priorityClassName %q, want %q", wl.Spec.PriorityClassName, tc.wantPriorityClass)
+			}
+			if diff := cmp.Diff(&tc.wantPriority, wl.Spec.Priority); diff != "" {
+				t.Errorf("Unexpected priority (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleJobWithNoWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{batchv1.AddToScheme, schedulingv1.AddToScheme, kueue.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("Failed adding to the scheme: %v", err)
+		}
+	}
+	cases := map[string]struct {
+		job             *batchv1.Job
+		opts            []Option
+		wantWorkload    bool
+		wantAnnotations map[string]string
+		wantEvent       string
+	}{
+		"parallelism within the limit": {
+			job:          builder.MakeJob("job", "ns").Queue("main").Parallelism(kueue.MaxPodsPerWorkload).Obj(),
+			wantWorkload: true,
+			wantEvent:    "Normal CreatedWorkload Created Workload: ns/job",
+		},
+		"parallelism above the limit": {
+			job:       builder.MakeJob("job", "ns").Queue("main").Parallelism(kueue.MaxPodsPerWorkload + 1).Obj(),
+			wantEvent: "Warning InvalidParallelism Parallelism 100001 exceeds the maximum of 100000 pods of a Workload",
+		},
+		"propagated annotations": {
+			job: func() *batchv1.Job {
+				job := builder.MakeJob("job", "ns").Queue("main").Obj()
+				job.Annotations[constants.ManualAdmissionAnnotation] = `{"clusterQueue":"cq"}`
+				job.Annotations[constants.DebugAnnotation] = "true"
+				job.Annotations["example.com/team"] = "ml"
+				return job
+			}(),
+			opts:         []Option{WithAnnotationKeysToCopy("*")},
+			wantWorkload: true,
+			wantAnnotations: map[string]string{
+				constants.DebugAnnotation: "true",
+				"example.com/team":        "ml",
+			},
+			wantEvent: "Normal CreatedWorkload Created Workload: ns/job",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.job).Build()
+			recorder := record.NewFakeRecorder(1)
+			r := NewReconciler(scheme, cl, recorder, tc.opts...)
+			if err := r.handleJobWithNoWorkload(ctx, tc.job); err != nil {
+				t.Fatalf("Failed handling the job: %v", err)
+			}
+			var workloads kueue.WorkloadList
+			if err := cl.List(ctx, &workloads); err != nil {
+				t.Fatalf("Failed listing the workloads: %v", err)
+			}
+			if got := len(workloads.Items) == 1; got != tc.wantWorkload {
+				t.Errorf("Workload created: %t, want %t", got, tc.wantWorkload)
+			}
+			if len(workloads.Items) == 1 {
+				if diff := cmp.Diff(tc.wantAnnotations, workloads.Items[0].Annotations); diff != "" {
+					t.Errorf("Unexpected workload annotations (-want,+got):\n%s", diff)
+				}
+			}
+			if got := <-recorder.Events; got != tc.wantEvent {
+				t.Errorf("Got event %q, want %q", got, tc.wantEvent)
+			}
+		})
+	}
+}
+
+func TestReconcileFailedWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{batchv1.AddToScheme, schedulingv1.AddToScheme, kueue.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("Failed adding to the scheme: %v", err)
+		}
+	}
+	ctx := context.Background()
+	job := builder.MakeJob("job", "ns").Queue("main").Obj()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job).Build()
+	wl, err := ConstructWorkloadFor(ctx, cl, job, scheme)
+	if err != nil {
+		t.Fatalf("Failed constructing the workload: %v", err)
+	}
+	for _, condType := range []string{kueue.WorkloadFinished, kueue.WorkloadFailed} {
+		wl.Status.Conditions = append(wl.Status.Conditions, metav1.Condition{
+			Type:    condType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "DeadlineExceeded",
+			Message: "The workload exceeded its activeDeadlineSeconds",
+		})
+	}
+	if err := cl.Create(ctx, wl); err != nil {
+		t.Fatalf("Failed creating the workload: %v", err)
+	}
+	r := NewReconciler(scheme, cl, record.NewFakeRecorder(10))
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "job", Namespace: "ns"}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Failed reconciling the job: %v", err)
+	}
+	var gotJob batchv1.Job
+	if err := cl.Get(ctx, req.NamespacedName, &gotJob); err != nil {
+		t.Fatalf("Failed obtaining the job: %v", err)
+	}
+	wantConditions := []batchv1.JobCondition{{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "DeadlineExceeded",
+		Message: "The workload exceeded its activeDeadlineSeconds",
+	}}
+	if diff := cmp.Diff(wantConditions, gotJob.Status.Conditions, cmpopts.IgnoreFields(batchv1.JobCondition{}, "LastProbeTime", "LastTransitionTime")); diff != "" {
+		t.Errorf("Unexpected job conditions (-want,+got):\n%s", diff)
+	}
+
+	// The workload isn't created again for the failed job, once it's deleted.
+	if err := cl.Delete(ctx, wl); err != nil {
+		t.Fatalf("Failed deleting the workload: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Failed reconciling the job: %v", err)
+	}
+	var workloads kueue.WorkloadList
+	if err := cl.List(ctx, &workloads); err != nil {
+		t.Fatalf("Failed listing the workloads: %v", err)
+	}
+	if len(workloads.Items) != 0 {
+		t.Errorf("Got %d workloads for the failed job, want 0", len(workloads.Items))
+	}
+}
+
+func TestCopyMetadata(t *testing.T) {
+	src := map[string]string{
+		"example.com/team":        "ml",
+		"example.com/cost-center": "1234",
+		"example.org/team":        "infra",
+		"app":                     "trainer",
+	}
+	cases := map[string]struct {
+		dst  map[string]string
+		keys []string
+		want map[string]string
+	}{
+		"no keys": {},
+		"exact keys": {
+			keys: []string{"app", "example.com/team", "missing"},
+			want: map[string]string{
+				"app":              "trainer",
+				"example.com/team": "ml",
+			},
+		},
+		"prefix": {
+			dst:  map[string]string{"kueue.x-k8s.io/queue-name": "main"},
+			keys: []string{"example.com/*"},
+			want: map[string]string{
+				"kueue.x-k8s.io/queue-name": "main",
+				"example.com/team":          "ml",
+				"example.com/cost-center":   "1234",
+			},
+		},
+		"all keys": {
+			keys: []string{"*"},
+			want: src,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := copyMetadata(tc.dst, src, tc.keys)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected metadata (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAppendFinishedCondition(t *testing.T) {
+	cases := map[string]struct {
+		conds     []metav1.Condition
+		jobCond   batchv1.JobCondition
+		wantAdded bool
+		want      []metav1.Condition
+	}{
+		"succeeded": {
+			jobCond:   batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			wantAdded: true,
+			want: []metav1.Condition{{
+				Type:    kueue.WorkloadFinished,
+				Status:  metav1.ConditionTrue,
+				Reason:  "JobFinished",
+				Message: "Job finished successfully",
+			}},
+		},
+		"backoff limit exceeded": {
+			jobCond: batchv1.JobCondition{
+				Type:    batchv1.JobFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "BackoffLimitExceeded",
+				Message: "Job has reached the specified backoff limit",
+			},
+			wantAdded: true,
+			want: []metav1.Condition{
+				{
+					Type:    kueue.WorkloadFinished,
+					Status:  metav1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job failed: Job has reached the specified backoff limit",
+				},
+				{
+					Type:    kueue.WorkloadFailed,
+					Status:  metav1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job failed: Job has reached the specified backoff limit",
+				},
+			},
+		},
+		"already finished": {
+			conds: []metav1.Condition{{
+				Type:   kueue.WorkloadFinished,
+				Status: metav1.ConditionTrue,
+				Reason: "JobFinished",
+			}},
+			jobCond: batchv1.JobCondition{
+				Type:   batchv1.JobFailed,
+				Status: corev1.ConditionTrue,
+				Reason: "DeadlineExceeded",
+			},
+			want: []metav1.Condition{{
+				Type:   kueue.WorkloadFinished,
+				Status: metav1.ConditionTrue,
+				Reason: "JobFinished",
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			conds, added := appendFinishedConditionIfNotExists(tc.conds, tc.jobCond)
+			if added != tc.wantAdded {
+				t.Errorf("appendFinishedConditionIfNotExists returned added=%t, want %t", added, tc.wantAdded)
+			}
+			if diff := cmp.Diff(tc.want, conds, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Unexpected conditions (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
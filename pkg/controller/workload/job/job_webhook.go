@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+var jobWebhookLog = ctrl.Log.WithName("job-webhook")
+
+type JobWebhook struct {
+	client client.Client
+
+	// optionsLock guards the options, which can be updated at runtime.
+	optionsLock sync.RWMutex
+	options     options
+}
+
+func NewWebhook(client client.Client, opts ...Option) *JobWebhook {
+	return &JobWebhook{
+		client:  client,
+		options: newOptions(opts),
+	}
+}
+
+// SetupWithManager configures the webhook for batch/v1.Jobs.
+func (w *JobWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&batchv1.Job{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// UpdateOptions replaces the options of the webhook. The options not given
+// are reset to their defaults.
+func (w *JobWebhook) UpdateOptions(opts ...Option) {
+	options := newOptions(opts)
+	w.optionsLock.Lock()
+	defer w.optionsLock.Unlock()
+	w.options = options
+}
+
+func (w *JobWebhook) currentOptions() options {
+	w.optionsLock.RLock()
+	defer w.optionsLock.RUnlock()
+	return w.options
+}
+
+// Like the validating webhook, the defaulting webhook doesn't block the Jobs
+// when Kueue is unavailable.
+// +kubebuilder:webhook:path=/mutate-batch-v1-job,mutating=true,failurePolicy=ignore,sideEffects=None,groups=batch,resources=jobs,verbs=create,versions=v1,name=mjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &JobWebhook{}
+
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+// It sets the queue name of the Jobs created without one to the queue name of
+// the CronJob that created them or, otherwise, to the default queue name of
+// their namespace. Those Jobs are suspended so that they don't start before
+// they're admitted.
+func (w *JobWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	job := obj.(*batchv1.Job)
+	jobWebhookLog.V(5).Info("Applying defaults", "job", klog.KObj(job))
+	if queueName(job) != "" {
+		return nil
+	}
+	name := w.cronJobQueueName(ctx, job)
+	if name == "" {
+		name = w.namespaceDefaultQueueName(ctx, job)
+	}
+	if name == "" {
+		return nil
+	}
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string, 1)
+	}
+	job.Annotations[constants.QueueAnnotation] = name
+	job.Spec.Suspend = pointer.Bool(true)
+	return nil
+}
+
+// cronJobQueueName returns the queue name of the CronJob that controls the
+// job, from its queue name annotation or, otherwise, its label with the same
+// key. It returns an empty string if the job isn't controlled by a CronJob, or
+// the CronJob can't be read from the cache of the manager.
+func (w *JobWebhook) cronJobQueueName(ctx context.Context, job *batchv1.Job) string {
+	owner := metav1.GetControllerOf(job)
+	if owner == nil || owner.APIVersion != "batch/v1" || owner.Kind != "CronJob" {
+		return ""
+	}
+	var cronJob batchv1.CronJob
+	if err := w.client.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: owner.Name}, &cronJob); err != nil {
+		if !apierrors.IsNotFound(err) {
+			jobWebhookLog.Error(err, "Obtaining the queue name of the CronJob", "job", klog.KObj(job))
+		}
+		return ""
+	}
+	if name := cronJob.Annotations[constants.QueueAnnotation]; name != "" {
+		return name
+	}
+	return cronJob.Labels[constants.QueueAnnotation]
+}
+
+// namespaceDefaultQueueName returns the default queue name of the namespace of
+// the job, if any. The namespace is read from the cache of the manager. If it
+// can't be read, the job is created without a default queue name, like when
+// the webhook is unavailable.
+func (w *JobWebhook) namespaceDefaultQueueName(ctx context.Context, job *batchv1.Job) string {
+	var ns corev1.Namespace
+	if err := w.client.Get(ctx, types.NamespacedName{Name: job.Namespace}, &ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			jobWebhookLog.Error(err, "Obtaining the default queue name of the namespace", "job", klog.KObj(job))
+		}
+		return ""
+	}
+	return ns.Annotations[constants.DefaultQueueAnnotation]
+}
+
+// The webhook intercepts the creation of all the Jobs in the cluster, so it
+// doesn't block them when Kueue is unavailable.
+// +kubebuilder:webhook:path=/validate-batch-v1-job,mutating=false,failurePolicy=ignore,sideEffects=None,groups=batch,resources=jobs,verbs=create,versions=v1,name=vjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &JobWebhook{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *JobWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	job := obj.(*batchv1.Job)
+	jobWebhookLog.V(5).Info("Validating create", "job", klog.KObj(job))
+	options := w.currentOptions()
+	if queueName(job) == "" && !options.manageJobsWithoutQueueName {
+		return nil
+	}
+	allErrs := validateParallelism(job)
+	if options.requireExistingLocalQueue {
+		queueErrs, err := w.validateLocalQueueExists(ctx, job)
+		if err != nil {
+			return err
+		}
+		allErrs = append(allErrs, queueErrs...)
+	}
+	return allErrs.ToAggregate()
+}
+
+// validateParallelism returns an error in the list if the job runs more pods
+// in parallel than a workload can have.
+func validateParallelism(job *batchv1.Job) field.ErrorList {
+	if parallelism := pointer.Int32Deref(job.Spec.Parallelism, 1); parallelism > kueue.MaxPodsPerWorkload {
+		path := field.NewPath("spec", "parallelism")
+		return field.ErrorList{field.Invalid(path, parallelism, fmt.Sprintf("must be at most %d", kueue.MaxPodsPerWorkload))}
+	}
+	return nil
+}
+
+// validateLocalQueueExists returns an error in the list if the LocalQueue set
+// in the queue name annotation doesn't exist in the namespace of the job. It
+// returns the errors obtaining the LocalQueue separately.
+func (w *JobWebhook) validateLocalQueueExists(ctx context.Context, job *batchv1.Job) (field.ErrorList, error) {
+	name := queueName(job)
+	if name == "" {
+		return nil, nil
+	}
+	var q kueue.LocalQueue
+	err := w.client.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: name}, &q)
+	if apierrors.IsNotFound(err) {
+		path := field.NewPath("metadata", "annotations").Key(constants.QueueAnnotation)
+		return field.ErrorList{field.NotFound(path, name)}, nil
+	}
+	return nil, err
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// The webhook isn't registered for updates: the Job reconciler doesn't create
+// a workload for a job whose parallelism grows above the limit.
+func (w *JobWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (w *JobWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
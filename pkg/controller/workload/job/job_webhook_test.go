@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+func TestValidateCreate(t *testing.T) {
+	queuePath := field.NewPath("metadata", "annotations").Key(constants.QueueAnnotation)
+	cases := map[string]struct {
+		queue   string
+		wantErr field.ErrorList
+	}{
+		"missing queue": {
+			queue: "missing",
+			wantErr: field.ErrorList{
+				field.NotFound(queuePath, "missing"),
+			},
+		},
+		"existing queue": {
+			queue: "main",
+		},
+		"no queue": {},
+	}
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(builder.MakeLocalQueue("main", "ns").ClusterQueue("cq").Obj()).
+		Build()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := builder.MakeJob("job", "ns").Obj()
+			if tc.queue != "" {
+				job.Annotations = map[string]string{constants.QueueAnnotation: tc.queue}
+			}
+			w := NewWebhook(cl, WithRequireExistingLocalQueue(true))
+			gotErr, err := w.validateLocalQueueExists(context.Background(), job)
+			if err != nil {
+				t.Fatalf("Failed obtaining the LocalQueue: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantErr, gotErr, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("Unexpected errors (-want,+got):\n%s", diff)
+			}
+
+			// Jobs are always allowed when the LocalQueue isn't required to exist.
+			w.UpdateOptions()
+			if err := w.ValidateCreate(context.Background(), job); err != nil {
+				t.Errorf("Unexpected error when the LocalQueue isn't required to exist: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateParallelism(t *testing.T) {
+	cases := map[string]struct {
+		job     *batchv1.Job
+		wantErr bool
+	}{
+		"parallelism within the limit": {
+			job: builder.MakeJob("job", "ns").Queue("main").Parallelism(kueue.MaxPodsPerWorkload).Obj(),
+		},
+		"parallelism above the limit": {
+			job:     builder.MakeJob("job", "ns").Queue("main").Parallelism(kueue.MaxPodsPerWorkload + 1).Obj(),
+			wantErr: true,
+		},
+		"without queue name": {
+			job: builder.MakeJob("job", "ns").Parallelism(kueue.MaxPodsPerWorkload + 1).Obj(),
+		},
+	}
+	w := NewWebhook(fake.NewClientBuilder().Build())
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := w.ValidateCreate(context.Background(), tc.job)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("ValidateCreate returned error %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	cases := map[string]struct {
+		job       *batchv1.Job
+		namespace string
+		want      *batchv1.Job
+	}{
+		"namespace with default queue": {
+			job:       builder.MakeJob("job", "team-a").Suspend(false).Obj(),
+			namespace: "team-a",
+			want:      builder.MakeJob("job", "team-a").Queue("main").Obj(),
+		},
+		"job with queue": {
+			job:       builder.MakeJob("job", "team-a").Queue("other").Suspend(false).Obj(),
+			namespace: "team-a",
+			want:      builder.MakeJob("job", "team-a").Queue("other").Suspend(false).Obj(),
+		},
+		"namespace without default queue": {
+			job:       builder.MakeJob("job", "team-b").Suspend(false).Obj(),
+			namespace: "team-b",
+			want:      builder.MakeJob("job", "team-b").Suspend(false).Obj(),
+		},
+		"missing namespace": {
+			job:  builder.MakeJob("job", "team-c").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-c").Suspend(false).Obj(),
+		},
+		"cronjob with queue annotation": {
+			job:  builder.MakeJob("job", "team-a").ControlledBy("batch/v1", "CronJob", "annotated").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-a").ControlledBy("batch/v1", "CronJob", "annotated").Queue("nightly").Obj(),
+		},
+		"cronjob with queue label": {
+			job:  builder.MakeJob("job", "team-b").ControlledBy("batch/v1", "CronJob", "labeled").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-b").ControlledBy("batch/v1", "CronJob", "labeled").Queue("hourly").Obj(),
+		},
+		"cronjob without queue": {
+			job:  builder.MakeJob("job", "team-a").ControlledBy("batch/v1", "CronJob", "plain").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-a").ControlledBy("batch/v1", "CronJob", "plain").Queue("main").Obj(),
+		},
+		"missing cronjob": {
+			job:  builder.MakeJob("job", "team-b").ControlledBy("batch/v1", "CronJob", "deleted").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-b").ControlledBy("batch/v1", "CronJob", "deleted").Suspend(false).Obj(),
+		},
+		"other controller": {
+			job:  builder.MakeJob("job", "team-b").ControlledBy("example.com/v1", "CronJob", "labeled").Suspend(false).Obj(),
+			want: builder.MakeJob("job", "team-b").ControlledBy("example.com/v1", "CronJob", "labeled").Suspend(false).Obj(),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding core scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding batch scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "team-a",
+				Annotations: map[string]string{constants.DefaultQueueAnnotation: "main"},
+			}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{
+				Name:        "annotated",
+				Namespace:   "team-a",
+				Annotations: map[string]string{constants.QueueAnnotation: "nightly"},
+			}},
+			&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{
+				Name:      "labeled",
+				Namespace: "team-b",
+				Labels:    map[string]string{constants.QueueAnnotation: "hourly"},
+			}},
+			&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "team-a"}},
+		).
+		Build()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewWebhook(cl)
+			if err := w.Default(context.Background(), tc.job); err != nil {
+				t.Fatalf("Failed applying defaults: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, tc.job); diff != "" {
+				t.Errorf("Unexpected job (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefaultUnreadableObjects(t *testing.T) {
+	cases := map[string]*batchv1.Job{
+		"namespace": builder.MakeJob("job", "team-a").Suspend(false).Obj(),
+		"cronjob":   builder.MakeJob("job", "team-a").ControlledBy("batch/v1", "CronJob", "annotated").Suspend(false).Obj(),
+	}
+	// The Namespace and CronJob kinds aren't registered in the scheme of the
+	// client.
+	w := NewWebhook(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	for name, job := range cases {
+		t.Run(name, func(t *testing.T) {
+			want := job.DeepCopy()
+			if err := w.Default(context.Background(), job); err != nil {
+				t.Fatalf("Failed applying defaults: %v", err)
+			}
+			if diff := cmp.Diff(want, job); diff != "" {
+				t.Errorf("Unexpected job (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// nodeFailures holds, for each job, a message describing the last pod it lost
+// because of a node failure, until the job is reconciled.
+type nodeFailures struct {
+	sync.Mutex
+	messages map[types.NamespacedName]string
+}
+
+func newNodeFailures() *nodeFailures {
+	return &nodeFailures{messages: make(map[types.NamespacedName]string)}
+}
+
+func (f *nodeFailures) add(job types.NamespacedName, msg string) {
+	f.Lock()
+	defer f.Unlock()
+	f.messages[job] = msg
+}
+
+// take returns the message of the node failure of the job, if any, and
+// forgets it.
+func (f *nodeFailures) take(job types.NamespacedName) (string, bool) {
+	f.Lock()
+	defer f.Unlock()
+	msg, ok := f.messages[job]
+	delete(f.messages, job)
+	return msg, ok
+}
+
+// jobPodLabel is the label that the Job controller sets on the pods of a Job,
+// with the UID of the Job.
+const jobPodLabel = "controller-uid"
+
+// CacheSelectors returns the selectors of the objects that the manager caches
+// for the reconciler. Only the pods of Jobs are cached, so that the pods of
+// other workloads aren't watched for node failures.
+func CacheSelectors() cache.SelectorsByObject {
+	req, err := labels.NewRequirement(jobPodLabel, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+	return cache.SelectorsByObject{
+		&corev1.Pod{}: {Label: labels.NewSelector().Add(*req)},
+	}
+}
+
+// podHandler records the pods of jobs that are deleted from nodes that
+// aren't ready, or no longer exist, and signals the controller to reconcile
+// their jobs.
+type podHandler struct {
+	client   client.Client
+	failures *nodeFailures
+}
+
+func (h *podHandler) Create(event.CreateEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *podHandler) Update(event.UpdateEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *podHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	pod, ok := e.Object.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	job, ok := h.lostJob(context.Background(), pod)
+	if !ok {
+		return
+	}
+	h.failures.add(job, fmt.Sprintf("Pod %s was deleted from node %s, which isn't ready", pod.Name, pod.Spec.NodeName))
+	q.Add(reconcile.Request{NamespacedName: job})
+}
+
+func (h *podHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+// lostJob returns the job of the pod if the pod was running, or about to run,
+// on a node that isn't ready or no longer exists.
+func (h *podHandler) lostJob(ctx context.Context, pod *corev1.Pod) (types.NamespacedName, bool) {
+	if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return types.NamespacedName{}, false
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.APIVersion != "batch/v1" || owner.Kind != "Job" {
+		return types.NamespacedName{}, false
+	}
+	var node corev1.Node
+	if err := h.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		if !apierrors.IsNotFound(err) {
+			ctrl.Log.WithName("job-reconciler").Error(err, "Failed to get the node of a deleted pod", "pod", klog.KObj(pod))
+			return types.NamespacedName{}, false
+		}
+	} else if nodeReady(&node) {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Name: owner.Name, Namespace: pod.Namespace}, true
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLostJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding core scheme: %v", err)
+	}
+	node := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		node("ready", corev1.ConditionTrue),
+		node("not-ready", corev1.ConditionUnknown),
+	).Build()
+	jobOwner := []metav1.OwnerReference{{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       "job",
+		Controller: pointer.Bool(true),
+	}}
+	pod := func(nodeName string, phase corev1.PodPhase, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns", OwnerReferences: owners},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	cases := map[string]struct {
+		pod    *corev1.Pod
+		wantOk bool
+	}{
+		"node ready": {
+			pod: pod("ready", corev1.PodRunning, jobOwner),
+		},
+		"node not ready": {
+			pod:    pod("not-ready", corev1.PodRunning, jobOwner),
+			wantOk: true,
+		},
+		"node deleted": {
+			pod:    pod("deleted", corev1.PodRunning, jobOwner),
+			wantOk: true,
+		},
+		"pod not scheduled": {
+			pod: pod("", corev1.PodPending, jobOwner),
+		},
+		"pod finished": {
+			pod: pod("not-ready", corev1.PodSucceeded, jobOwner),
+		},
+		"pod not owned by a job": {
+			pod: pod("not-ready", corev1.PodRunning, nil),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &podHandler{client: cl, failures: newNodeFailures()}
+			job, ok := h.lostJob(context.Background(), tc.pod)
+			if ok != tc.wantOk {
+				t.Fatalf("lostJob returned %t, want %t", ok, tc.wantOk)
+			}
+			if want := (types.NamespacedName{Name: "job", Namespace: "ns"}); ok && job != want {
+				t.Errorf("lostJob returned job %v, want %v", job, want)
+			}
+		})
+	}
+}
+
+func TestCacheSelectors(t *testing.T) {
+	selectors := CacheSelectors()
+	if len(selectors) != 1 {
+		t.Fatalf("Got %d selectors, want 1", len(selectors))
+	}
+	for obj, selector := range selectors {
+		if _, ok := obj.(*corev1.Pod); !ok {
+			t.Fatalf("Got selector for %T, want *v1.Pod", obj)
+		}
+		cases := map[string]struct {
+			labels labels.Set
+			want   bool
+		}{
+			"pod of a job": {
+				labels: labels.Set{"controller-uid": "uid", "job-name": "job"},
+				want:   true,
+			},
+			"other pod": {
+				labels: labels.Set{"app": "web"},
+			},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				if got := selector.Label.Matches(tc.labels); got != tc.want {
+					t.Errorf("Selector matches the pod: %t, want %t", got, tc.want)
+				}
+			})
+		}
+	}
+}
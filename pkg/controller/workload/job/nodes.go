@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+const (
+	// defaultNodeProvisioningTimeout is used for flavors with nodeProvisioning
+	// that don't set a timeout.
+	defaultNodeProvisioningTimeout = 5 * time.Minute
+
+	// nodeProvisioningPollInterval is how often the nodes are checked while
+	// waiting for them to be provisioned.
+	nodeProvisioningPollInterval = 15 * time.Second
+
+	// podNodeNameKey is the field selector of the pods bound to a node.
+	podNodeNameKey = "spec.nodeName"
+)
+
+// waitForNodes returns how long to wait before checking again whether the
+// nodes of the flavors assigned to the workload, that are provisioned on
+// demand, have enough free resources for it. Zero means that the job can be
+// started.
+// The timeout of the flavors counts from the admission time recorded in the
+// scheduling stats of the workload.
+func (r *JobReconciler) waitForNodes(ctx context.Context, w *kueue.Workload) (time.Duration, error) {
+	elapsed := time.Duration(0)
+	if stats := w.Status.SchedulingStats; stats != nil && stats.AdmittedAt != nil {
+		elapsed = r.clock.Since(stats.AdmittedAt.Time)
+	}
+	var wait time.Duration
+	for flvName, requests := range flavorRequests(workload.NewInfo(w)) {
+		var flv kueue.ResourceFlavor
+		if err := r.client.Get(ctx, types.NamespacedName{Name: flvName}, &flv); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return 0, err
+		}
+		if flv.NodeProvisioning == nil {
+			continue
+		}
+		timeout := defaultNodeProvisioningTimeout
+		if flv.NodeProvisioning.Timeout != nil {
+			timeout = flv.NodeProvisioning.Timeout.Duration
+		}
+		remaining := timeout - elapsed
+		if remaining <= 0 {
+			continue
+		}
+		fit, err := r.nodesFit(ctx, flv.Labels, requests)
+		if err != nil {
+			return 0, err
+		}
+		if fit {
+			continue
+		}
+		if remaining > nodeProvisioningPollInterval {
+			remaining = nodeProvisioningPollInterval
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// flavorRequests returns the total requests of the workload for each of the
+// flavors it was assigned.
+func flavorRequests(info *workload.Info) map[string]workload.Requests {
+	res := make(map[string]workload.Requests)
+	for _, ps := range info.TotalRequests {
+		for name, v := range ps.Requests {
+			flv, ok := ps.Flavors[name]
+			if !ok {
+				continue
+			}
+			if res[flv] == nil {
+				res[flv] = make(workload.Requests)
+			}
+			res[flv][name] += v
+		}
+	}
+	return res
+}
+
+// nodesFit returns whether the free resources of the ready and schedulable
+// nodes with the labels cover the requests. The free resources of a node are
+// its allocatable resources minus the requests of the pods bound to it that
+// didn't terminate.
+// The pods of each node are listed from the API server, selected by node name,
+// so that the manager doesn't need to cache all the pods of the cluster.
+func (r *JobReconciler) nodesFit(ctx context.Context, nodeLabels map[string]string, requests workload.Requests) (bool, error) {
+	var nodes corev1.NodeList
+	if err := r.client.List(ctx, &nodes, client.MatchingLabels(nodeLabels)); err != nil {
+		return false, err
+	}
+	free := make(workload.Requests)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		var pods corev1.PodList
+		if err := r.podReader.List(ctx, &pods, client.MatchingFields{podNodeNameKey: node.Name}); err != nil {
+			return false, err
+		}
+		used := podsUsage(pods.Items, node.Name)
+		for name, q := range node.Status.Allocatable {
+			if v := workload.ResourceValue(name, q) - used[name]; v > 0 {
+				free[name] += v
+			}
+		}
+	}
+	for name, v := range requests {
+		if free[name] < v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// podsUsage returns the requests of the pods bound to the node, skipping the
+// pods that terminated.
+func podsUsage(pods []corev1.Pod, nodeName string) workload.Requests {
+	used := make(workload.Requests)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for name, v := range workload.PodRequests(&pod.Spec) {
+			used[name] += v
+		}
+	}
+	return used
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+func TestWaitForNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding core scheme: %v", err)
+	}
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	node := func(name, cpu string, ready bool) *corev1.Node {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"type": "autoscaled"}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			},
+		}
+	}
+	pod := func(name, nodeName, cpu string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "other"},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{
+					Name: "c",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	flavors := []runtime.Object{
+		&kueue.ResourceFlavor{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&kueue.ResourceFlavor{
+			ObjectMeta:       metav1.ObjectMeta{Name: "autoscaled", Labels: map[string]string{"type": "autoscaled"}},
+			NodeProvisioning: &kueue.NodeProvisioning{Timeout: &metav1.Duration{Duration: time.Minute}},
+		},
+	}
+	admitted := func(flavor string, at time.Time) *kueue.Workload {
+		wl := builder.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "1").Obj()
+		wl.Spec.PodSets[0].Count = 4
+		wl.Spec.Admission = &kueue.Admission{
+			ClusterQueue: "cq",
+			PodSetFlavors: []kueue.PodSetFlavors{{
+				Name:    "main",
+				Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: flavor},
+			}},
+		}
+		wl.Status.SchedulingStats = &kueue.SchedulingStats{AdmittedAt: &metav1.Time{Time: at}}
+		return wl
+	}
+	now := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		wl       *kueue.Workload
+		nodes    []runtime.Object
+		wantWait time.Duration
+	}{
+		"flavor without node provisioning": {
+			wl: admitted("default", now),
+		},
+		"not enough ready nodes": {
+			wl:       admitted("autoscaled", now),
+			nodes:    []runtime.Object{node("a", "2", true), node("b", "2", false)},
+			wantWait: nodeProvisioningPollInterval,
+		},
+		"not enough ready nodes, close to the timeout": {
+			wl:       admitted("autoscaled", now.Add(-50*time.Second)),
+			nodes:    []runtime.Object{node("a", "2", true)},
+			wantWait: 10 * time.Second,
+		},
+		"nodes used by other pods": {
+			wl: admitted("autoscaled", now),
+			nodes: []runtime.Object{
+				node("a", "2", true),
+				node("b", "2", true),
+				pod("p", "b", "1", corev1.PodRunning),
+			},
+			wantWait: nodeProvisioningPollInterval,
+		},
+		"nodes used by terminated pods": {
+			wl: admitted("autoscaled", now),
+			nodes: []runtime.Object{
+				node("a", "2", true),
+				node("b", "2", true),
+				pod("p", "b", "1", corev1.PodSucceeded),
+				pod("q", "", "1", corev1.PodPending),
+			},
+		},
+		"nodes without the flavor labels": {
+			wl: admitted("autoscaled", now),
+			nodes: []runtime.Object{
+				node("a", "2", true),
+				func() *corev1.Node {
+					n := node("b", "2", true)
+					n.Labels = nil
+					return n
+				}(),
+			},
+			wantWait: nodeProvisioningPollInterval,
+		},
+		"admission time not recorded yet": {
+			wl: func() *kueue.Workload {
+				wl := admitted("autoscaled", now.Add(-2*time.Minute))
+				wl.Status.SchedulingStats = nil
+				return wl
+			}(),
+			nodes:    []runtime.Object{node("a", "2", true)},
+			wantWait: nodeProvisioningPollInterval,
+		},
+		"nodes provisioned": {
+			wl:    admitted("autoscaled", now),
+			nodes: []runtime.Object{node("a", "2", true), node("b", "2", true)},
+		},
+		"timeout expired": {
+			wl:    admitted("autoscaled", now.Add(-2*time.Minute)),
+			nodes: []runtime.Object{node("a", "2", true)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme).
+				WithRuntimeObjects(flavors...).
+				WithRuntimeObjects(tc.nodes...).
+				Build()
+			r := &JobReconciler{client: cl, podReader: cl, clock: testingclock.NewFakeClock(now)}
+			wait, err := r.waitForNodes(context.Background(), tc.wl)
+			if err != nil {
+				t.Fatalf("waitForNodes failed: %v", err)
+			}
+			if wait != tc.wantWait {
+				t.Errorf("waitForNodes returned %v, want %v", wait, tc.wantWait)
+			}
+		})
+	}
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package metrics
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
@@ -95,11 +97,38 @@ The label 'result' can have the following values:
 		}, []string{"cluster_queue"},
 	)
 
+	EvictedWorkloadsOnceTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "evicted_workloads_once_total",
+			Help: `The number of workloads evicted at least once, per 'cluster_queue' and 'reason'.
+A workload is only counted the first time it's evicted for a reason, so that repeated evictions of the same workload don't inflate the count.`,
+		}, []string{"cluster_queue", "reason"},
+	)
+
+	StarvingWorkloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "starving_workloads_total",
+			Help: `The number of times a pending workload was found starving, per 'cluster_queue'.
+A workload is starving when it has been pending for longer than the threshold of the starvation watchdog while other workloads were admitted in its cohort.`,
+		}, []string{"cluster_queue"},
+	)
+
+	quotaReservedWaitTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: constants.KueueName,
+			Name:      "quota_reserved_wait_time_seconds",
+			Help:      "The time between a Workload was created until it reserved quota, per 'cluster_queue'",
+		}, []string{"cluster_queue"},
+	)
+
 	admissionWaitTime = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: constants.KueueName,
 			Name:      "admission_wait_time_seconds",
-			Help:      "The time between a Workload was created until it was admitted, per 'cluster_queue'",
+			Help: `The time between a Workload was created until it was admitted, per 'cluster_queue'.
+A Workload is admitted once it reserved quota and all the admission checks of the ClusterQueue are ready.`,
 		}, []string{"cluster_queue"},
 	)
 
@@ -117,20 +146,138 @@ The label 'result' can have the following values:
 		prometheus.GaugeOpts{
 			Subsystem: constants.KueueName,
 			Name:      "cluster_queue_status",
-			Help: `Reports 'cluster_queue' with its 'status' (with possible values 'pending', 'active' or 'terminated').
+			Help: `Reports 'cluster_queue' with its 'status' (with possible values 'pending', 'active' or 'terminating').
+'pending' means that the ClusterQueue can't admit workloads, for example, because a ResourceFlavor it references doesn't exist.
 For a ClusterQueue, the metric only reports a value of 1 for one of the statuses.`,
 		}, []string{"cluster_queue", "status"},
 	)
+
+	ClusterQueueInactiveTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "cluster_queue_inactive_total",
+			Help: `The number of times a 'cluster_queue' went from active to pending, per 'reason'.
+'reason' can have the following values:
+- "ResourceFlavorDeleted" means that a ResourceFlavor referenced by the ClusterQueue was deleted.
+- "ResourceFlavorNotFound" means that the ClusterQueue was updated to reference a ResourceFlavor that doesn't exist.`,
+		}, []string{"cluster_queue", "reason"},
+	)
+
+	// Metrics tied to LocalQueues. They are only reported when enabled in the
+	// configuration.
+
+	LocalQueuePendingWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: constants.KueueName,
+			Name:      "local_queue_pending_workloads",
+			Help:      "The number of pending workloads, per 'local_queue' and 'namespace'",
+		}, []string{"local_queue", "namespace"},
+	)
+
+	LocalQueueAdmittedActiveWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: constants.KueueName,
+			Name:      "local_queue_admitted_active_workloads",
+			Help:      "The number of admitted Workloads that are active (unsuspended and not finished), per 'local_queue' and 'namespace'",
+		}, []string{"local_queue", "namespace"},
+	)
+
+	LocalQueueResourceUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: constants.KueueName,
+			Name:      "local_queue_resource_usage",
+			Help:      "The quantity of resources used by the admitted Workloads, per 'local_queue', 'namespace', 'flavor' and 'resource'",
+		}, []string{"local_queue", "namespace", "flavor", "resource"},
+	)
+
+	// Metrics per the values of workload labels. They are only reported when
+	// the labels are set in the configuration.
+
+	// workloadLabelKeys are the keys of the workload labels reported in the
+	// metrics below.
+	workloadLabelKeys []string
+
+	AdmittedWorkloadsByLabelsTotal *prometheus.CounterVec
+
+	EvictedWorkloadsByLabelsTotal *prometheus.CounterVec
+
+	invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 )
 
+// initWorkloadLabelMetrics creates the metrics per the values of the workload
+// labels with the given keys.
+func initWorkloadLabelMetrics(keys []string) {
+	workloadLabelKeys = keys
+	labelNames := make([]string, len(keys))
+	for i, k := range keys {
+		labelNames[i] = WorkloadLabelName(k)
+	}
+	AdmittedWorkloadsByLabelsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "admitted_workloads_by_labels_total",
+			Help:      "The total number of admitted workloads per 'cluster_queue' and the values of the workload labels set in the configuration",
+		}, append([]string{"cluster_queue"}, labelNames...),
+	)
+	EvictedWorkloadsByLabelsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: constants.KueueName,
+			Name:      "evicted_workloads_by_labels_total",
+			Help:      "The total number of evictions of workloads per 'cluster_queue', 'reason' and the values of the workload labels set in the configuration",
+		}, append([]string{"cluster_queue", "reason"}, labelNames...),
+	)
+}
+
+// WorkloadLabelName returns the name of the metric label for the workload
+// label with the given key. For example, the name for example.com/team is
+// label_example_com_team.
+func WorkloadLabelName(key string) string {
+	return "label_" + invalidLabelNameChars.ReplaceAllString(key, "_")
+}
+
+func workloadLabelValues(wlLabels map[string]string) []string {
+	values := make([]string, len(workloadLabelKeys))
+	for i, k := range workloadLabelKeys {
+		values[i] = wlLabels[k]
+	}
+	return values
+}
+
 func AdmissionAttempt(result AdmissionResult, duration time.Duration) {
 	admissionAttemptsTotal.WithLabelValues(string(result)).Inc()
 	admissionAttemptDuration.WithLabelValues(string(result)).Observe(duration.Seconds())
 }
 
-func AdmittedWorkload(cqName kueue.ClusterQueueReference, waitTime time.Duration) {
+// AdmittedWorkload reports the quota reservation of a workload with the
+// given labels, after waiting for waitTime since it was created.
+func AdmittedWorkload(cqName kueue.ClusterQueueReference, waitTime time.Duration, wlLabels map[string]string) {
 	AdmittedWorkloadsTotal.WithLabelValues(string(cqName)).Inc()
-	admissionWaitTime.WithLabelValues(string(cqName)).Observe(waitTime.Seconds())
+	quotaReservedWaitTime.WithLabelValues(string(cqName)).Observe(waitTime.Seconds())
+	if AdmittedWorkloadsByLabelsTotal != nil {
+		AdmittedWorkloadsByLabelsTotal.WithLabelValues(append([]string{string(cqName)}, workloadLabelValues(wlLabels)...)...).Inc()
+	}
+}
+
+// ReportAdmissionWaitTime reports the time that a workload waited since it was
+// created until it was admitted, once its admission checks were ready.
+func ReportAdmissionWaitTime(cqName string, waitTime time.Duration) {
+	admissionWaitTime.WithLabelValues(cqName).Observe(waitTime.Seconds())
+}
+
+func ReportEvictedWorkloadOnce(cqName, reason string) {
+	EvictedWorkloadsOnceTotal.WithLabelValues(cqName, reason).Inc()
+}
+
+// ReportEvictedWorkload reports an eviction of a workload with the given
+// labels, if the metrics per workload labels are enabled.
+func ReportEvictedWorkload(cqName, reason string, wlLabels map[string]string) {
+	if EvictedWorkloadsByLabelsTotal != nil {
+		EvictedWorkloadsByLabelsTotal.WithLabelValues(append([]string{cqName, reason}, workloadLabelValues(wlLabels)...)...).Inc()
+	}
+}
+
+func ReportStarvingWorkload(cqName string) {
+	StarvingWorkloadsTotal.WithLabelValues(cqName).Inc()
 }
 
 func ReportPendingWorkloads(cqName string, active, inadmissible int) {
@@ -142,7 +289,14 @@ func ClearQueueSystemMetrics(cqName string) {
 	PendingWorkloads.DeleteLabelValues(cqName, PendingStatusActive)
 	PendingWorkloads.DeleteLabelValues(cqName, PendingStatusInadmissible)
 	AdmittedWorkloadsTotal.DeleteLabelValues(cqName)
+	EvictedWorkloadsOnceTotal.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
+	StarvingWorkloadsTotal.DeleteLabelValues(cqName)
+	quotaReservedWaitTime.DeleteLabelValues(cqName)
 	admissionWaitTime.DeleteLabelValues(cqName)
+	if AdmittedWorkloadsByLabelsTotal != nil {
+		AdmittedWorkloadsByLabelsTotal.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
+		EvictedWorkloadsByLabelsTotal.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
+	}
 }
 
 func ReportClusterQueueStatus(cqName string, cqStatus ClusterQueueStatus) {
@@ -155,20 +309,67 @@ func ReportClusterQueueStatus(cqName string, cqStatus ClusterQueueStatus) {
 	}
 }
 
+// ReportClusterQueueInactive reports that the ClusterQueue went from active
+// to pending for the given reason.
+func ReportClusterQueueInactive(cqName, reason string) {
+	ClusterQueueInactiveTotal.WithLabelValues(cqName, reason).Inc()
+}
+
 func ClearCacheMetrics(cqName string) {
 	AdmittedActiveWorkloads.DeleteLabelValues(cqName)
+	ClusterQueueInactiveTotal.DeletePartialMatch(prometheus.Labels{"cluster_queue": cqName})
 	for _, status := range CQStatuses {
 		ClusterQueueByStatus.DeleteLabelValues(cqName, string(status))
 	}
 }
 
-func Register() {
+func ReportLocalQueueStatus(name, namespace string, pending, admitted int) {
+	LocalQueuePendingWorkloads.WithLabelValues(name, namespace).Set(float64(pending))
+	LocalQueueAdmittedActiveWorkloads.WithLabelValues(name, namespace).Set(float64(admitted))
+}
+
+// ReportLocalQueueResourceUsage reports the usage of the LocalQueue, per
+// resource and flavor. The series of flavors that are no longer used are
+// removed.
+func ReportLocalQueueResourceUsage(name, namespace string, usage map[corev1.ResourceName]map[string]float64) {
+	LocalQueueResourceUsage.DeletePartialMatch(prometheus.Labels{"local_queue": name, "namespace": namespace})
+	for rName, flavors := range usage {
+		for flavor, v := range flavors {
+			LocalQueueResourceUsage.WithLabelValues(name, namespace, flavor, string(rName)).Set(v)
+		}
+	}
+}
+
+func ClearLocalQueueMetrics(name, namespace string) {
+	LocalQueuePendingWorkloads.DeleteLabelValues(name, namespace)
+	LocalQueueAdmittedActiveWorkloads.DeleteLabelValues(name, namespace)
+	LocalQueueResourceUsage.DeletePartialMatch(prometheus.Labels{"local_queue": name, "namespace": namespace})
+}
+
+// Register registers the metrics. If workloadLabelKeys is not empty, it also
+// registers the metrics per the values of the workload labels with those keys.
+func Register(workloadLabelKeys ...string) {
+	if len(workloadLabelKeys) > 0 {
+		initWorkloadLabelMetrics(workloadLabelKeys)
+		metrics.Registry.MustRegister(
+			AdmittedWorkloadsByLabelsTotal,
+			EvictedWorkloadsByLabelsTotal,
+		)
+	}
 	metrics.Registry.MustRegister(
 		admissionAttemptsTotal,
 		admissionAttemptDuration,
 		PendingWorkloads,
 		AdmittedActiveWorkloads,
 		AdmittedWorkloadsTotal,
+		EvictedWorkloadsOnceTotal,
+		StarvingWorkloadsTotal,
+		quotaReservedWaitTime,
 		admissionWaitTime,
+		ClusterQueueByStatus,
+		ClusterQueueInactiveTotal,
+		LocalQueuePendingWorkloads,
+		LocalQueueAdmittedActiveWorkloads,
+		LocalQueueResourceUsage,
 	)
 }
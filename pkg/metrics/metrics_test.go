@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestReportClusterQueueStatus(t *testing.T) {
+	ReportClusterQueueStatus("cq", CQStatusPending)
+	ReportClusterQueueStatus("cq", CQStatusActive)
+	for _, status := range CQStatuses {
+		want := 0.0
+		if status == CQStatusActive {
+			want = 1
+		}
+		if got := testutil.ToFloat64(ClusterQueueByStatus.WithLabelValues("cq", string(status))); got != want {
+			t.Errorf("Got %v for status %q, want %v", got, status, want)
+		}
+	}
+
+	ClearCacheMetrics("cq")
+	if got := testutil.CollectAndCount(ClusterQueueByStatus); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+}
+
+func TestReportClusterQueueInactive(t *testing.T) {
+	ReportClusterQueueInactive("cq", "ResourceFlavorDeleted")
+	ReportClusterQueueInactive("cq", "ResourceFlavorDeleted")
+	ReportClusterQueueInactive("cq", "ResourceFlavorNotFound")
+	if got := testutil.ToFloat64(ClusterQueueInactiveTotal.WithLabelValues("cq", "ResourceFlavorDeleted")); got != 2 {
+		t.Errorf("Got %v transitions for a deleted flavor, want 2", got)
+	}
+
+	ClearCacheMetrics("cq")
+	if got := testutil.CollectAndCount(ClusterQueueInactiveTotal); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+}
+
+func TestReportLocalQueueResourceUsage(t *testing.T) {
+	ReportLocalQueueResourceUsage("lq", "ns", map[corev1.ResourceName]map[string]float64{
+		corev1.ResourceCPU: {"on-demand": 2, "spot": 3},
+	})
+	ReportLocalQueueResourceUsage("lq", "ns", map[corev1.ResourceName]map[string]float64{
+		corev1.ResourceCPU: {"spot": 1},
+	})
+	if got := testutil.CollectAndCount(LocalQueueResourceUsage); got != 1 {
+		t.Errorf("Got %d series, want 1", got)
+	}
+	if got := testutil.ToFloat64(LocalQueueResourceUsage.WithLabelValues("lq", "ns", "spot", "cpu")); got != 1 {
+		t.Errorf("Got usage %v, want 1", got)
+	}
+
+	ClearLocalQueueMetrics("lq", "ns")
+	if got := testutil.CollectAndCount(LocalQueueResourceUsage); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+}
+
+func TestWorkloadLabelMetrics(t *testing.T) {
+	initWorkloadLabelMetrics([]string{"example.com/team", "tier"})
+	defer func() {
+		workloadLabelKeys = nil
+		AdmittedWorkloadsByLabelsTotal = nil
+		EvictedWorkloadsByLabelsTotal = nil
+	}()
+
+	AdmittedWorkload("cq", time.Second, map[string]string{"example.com/team": "ml", "user": "alice"})
+	AdmittedWorkload("cq", time.Second, map[string]string{"example.com/team": "ml"})
+	ReportEvictedWorkload("cq", "Inactive", map[string]string{"tier": "batch"})
+	if got := testutil.ToFloat64(AdmittedWorkloadsByLabelsTotal.WithLabelValues("cq", "ml", "")); got != 2 {
+		t.Errorf("Got %v admitted workloads, want 2", got)
+	}
+	if got := testutil.ToFloat64(EvictedWorkloadsByLabelsTotal.WithLabelValues("cq", "Inactive", "", "batch")); got != 1 {
+		t.Errorf("Got %v evicted workloads, want 1", got)
+	}
+	if got, want := WorkloadLabelName("example.com/team"), "label_example_com_team"; got != want {
+		t.Errorf("Got label name %q, want %q", got, want)
+	}
+
+	ClearQueueSystemMetrics("cq")
+	if got := testutil.CollectAndCount(AdmittedWorkloadsByLabelsTotal); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+	if got := testutil.CollectAndCount(EvictedWorkloadsByLabelsTotal); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+}
+
+func TestWaitTimeMetrics(t *testing.T) {
+	AdmittedWorkload("cq", time.Second, nil)
+	AdmittedWorkload("cq", time.Second, nil)
+	ReportAdmissionWaitTime("cq", time.Minute)
+	if got := testutil.CollectAndCount(quotaReservedWaitTime); got != 1 {
+		t.Errorf("Got %d series of the quota reservation wait time, want 1", got)
+	}
+	if got := testutil.CollectAndCount(admissionWaitTime); got != 1 {
+		t.Errorf("Got %d series of the admission wait time, want 1", got)
+	}
+
+	ClearQueueSystemMetrics("cq")
+	if got := testutil.CollectAndCount(quotaReservedWaitTime); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+	if got := testutil.CollectAndCount(admissionWaitTime); got != 0 {
+		t.Errorf("Got %d series after clearing the metrics, want 0", got)
+	}
+}
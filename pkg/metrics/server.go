@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Path is the path at which the metrics are served.
+const Path = "/metrics"
+
+// reviewTimeout is the timeout of the requests to the apiserver to
+// authenticate and authorize a request for the metrics.
+const reviewTimeout = 10 * time.Second
+
+// SecureServer serves the metrics over HTTPS, with the certificate and key in
+// a directory, which are reloaded when they change. Optionally, it only
+// serves the requests with a bearer token of a user or service account that
+// is allowed to get the metrics path.
+type SecureServer struct {
+	log      logr.Logger
+	addr     string
+	certFile string
+	keyFile  string
+	// client authenticates and authorizes the requests. It's nil when the
+	// requests aren't authorized.
+	client client.Client
+}
+
+// NewSecureServer returns a SecureServer that listens at addr. If client is
+// not nil, the server authenticates the bearer tokens of the requests with
+// TokenReviews and authorizes the users with SubjectAccessReviews.
+func NewSecureServer(addr, certDir, certName, keyName string, client client.Client) *SecureServer {
+	return &SecureServer{
+		log:      ctrl.Log.WithName("metrics-server"),
+		addr:     addr,
+		certFile: filepath.Join(certDir, certName),
+		keyFile:  filepath.Join(certDir, keyName),
+		client:   client,
+	}
+}
+
+// Start implements manager.Runnable. It serves the metrics until the context
+// is done.
+func (s *SecureServer) Start(ctx context.Context) error {
+	watcher, err := certwatcher.New(s.certFile, s.keyFile)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			s.log.Error(err, "Failed watching the serving certificate")
+		}
+	}()
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 30 * time.Second,
+		TLSConfig: &tls.Config{
+			GetCertificate: watcher.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			s.log.Error(err, "Failed shutting down the metrics server")
+		}
+	}()
+	s.log.Info("Serving metrics over HTTPS", "address", s.addr, "authorization", s.client != nil)
+	if err := srv.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The metrics
+// are served by every replica.
+func (s *SecureServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *SecureServer) handler() http.Handler {
+	var handler http.Handler = promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	})
+	if s.client != nil {
+		handler = s.authorize(handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, handler)
+	return mux
+}
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// authorize wraps the handler so that it only serves the requests with a
+// bearer token of a user that is allowed to use the method of the request on
+// the path, as a non-resource URL.
+func (s *SecureServer) authorize(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), reviewTimeout)
+		defer cancel()
+		tr := authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}
+		if err := s.client.Create(ctx, &tr); err != nil {
+			s.log.Error(err, "Failed reviewing the token of a request for the metrics")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !tr.Status.Authenticated {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		user := tr.Status.User
+		sar := authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+					Path: r.URL.Path,
+					Verb: strings.ToLower(r.Method),
+				},
+			},
+		}
+		if len(user.Extra) > 0 {
+			sar.Spec.Extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+			for k, v := range user.Extra {
+				sar.Spec.Extra[k] = authorizationv1.ExtraValue(v)
+			}
+		}
+		if err := s.client.Create(ctx, &sar); err != nil {
+			s.log.Error(err, "Failed authorizing a request for the metrics", "user", user.Username)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !sar.Status.Allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the bearer token in the Authorization header of the
+// request, or an empty string if there is none.
+func bearerToken(r *http.Request) string {
+	const prefix = "bearer "
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(auth[len(prefix):])
+}
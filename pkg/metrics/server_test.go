@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeReviewer authenticates the token "valid" as the user "reader", and
+// authorizes the users in allowed.
+type fakeReviewer struct {
+	client.Client
+	allowed map[string]bool
+}
+
+func (f *fakeReviewer) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	switch o := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if o.Spec.Token == "valid" {
+			o.Status.Authenticated = true
+			o.Status.User = authenticationv1.UserInfo{Username: "reader"}
+		}
+	case *authorizationv1.SubjectAccessReview:
+		attrs := o.Spec.NonResourceAttributes
+		o.Status.Allowed = f.allowed[o.Spec.User] && attrs.Path == Path && attrs.Verb == "get"
+	}
+	return nil
+}
+
+func TestSecureServerAuthorization(t *testing.T) {
+	cases := map[string]struct {
+		authorization bool
+		allowed       map[string]bool
+		header        string
+		wantStatus    int
+	}{
+		"authorization disabled": {
+			wantStatus: http.StatusOK,
+		},
+		"no token": {
+			authorization: true,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		"invalid token": {
+			authorization: true,
+			header:        "Bearer invalid",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		"user not allowed": {
+			authorization: true,
+			header:        "Bearer valid",
+			wantStatus:    http.StatusForbidden,
+		},
+		"user allowed": {
+			authorization: true,
+			allowed:       map[string]bool{"reader": true},
+			header:        "Bearer valid",
+			wantStatus:    http.StatusOK,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var cl client.Client
+			if tc.authorization {
+				cl = &fakeReviewer{allowed: tc.allowed}
+			}
+			s := NewSecureServer(":0", "", "tls.crt", "tls.key", cl)
+			req := httptest.NewRequest(http.MethodGet, Path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			s.handler().ServeHTTP(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Got status %d, want %d", rec.Code, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -31,8 +31,8 @@ var _ ClusterQueue = &ClusterQueueBestEffortFIFO{}
 
 const BestEffortFIFO = kueue.BestEffortFIFO
 
-func newClusterQueueBestEffortFIFO(cq *kueue.ClusterQueue) (ClusterQueue, error) {
-	cqImpl := newClusterQueueImpl(keyFunc, byCreationTime)
+func newClusterQueueBestEffortFIFO(cq *kueue.ClusterQueue, wo workload.Ordering) (ClusterQueue, error) {
+	cqImpl := newClusterQueueImpl(keyFunc, queueOrdering(wo))
 	cqBE := &ClusterQueueBestEffortFIFO{
 		ClusterQueueImpl: cqImpl,
 	}
@@ -22,7 +22,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -47,8 +47,8 @@ func TestBestEffortFIFORequeueIfNotPresent(t *testing.T) {
 				Spec: kueue.ClusterQueueSpec{
 					QueueingStrategy: kueue.StrictFIFO,
 				},
-			})
-			wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
+			}, workload.Ordering{})
+			wl := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
 			if ok := cq.RequeueIfNotPresent(workload.NewInfo(wl), reason); !ok {
 				t.Error("failed to requeue nonexistent workload")
 			}
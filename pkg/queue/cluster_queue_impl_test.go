@@ -29,7 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -38,8 +38,8 @@ const (
 )
 
 func Test_PushOrUpdate(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if cq.Pending() != 0 {
 		t.Error("ClusterQueue should be empty")
 	}
@@ -58,10 +58,10 @@ func Test_PushOrUpdate(t *testing.T) {
 }
 
 func Test_Pop(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
 	now := time.Now()
-	wl1 := workload.NewInfo(utiltesting.MakeWorkload("workload-1", defaultNamespace).Creation(now).Obj())
-	wl2 := workload.NewInfo(utiltesting.MakeWorkload("workload-2", defaultNamespace).Creation(now.Add(time.Second)).Obj())
+	wl1 := workload.NewInfo(builder.MakeWorkload("workload-1", defaultNamespace).Creation(now).Obj())
+	wl2 := workload.NewInfo(builder.MakeWorkload("workload-2", defaultNamespace).Creation(now.Add(time.Second)).Obj())
 	if cq.Pop() != nil {
 		t.Error("ClusterQueue should be empty")
 	}
@@ -81,9 +81,9 @@ func Test_Pop(t *testing.T) {
 }
 
 func Test_Delete(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl1 := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
-	wl2 := utiltesting.MakeWorkload("workload-2", defaultNamespace).Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl1 := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
+	wl2 := builder.MakeWorkload("workload-2", defaultNamespace).Obj()
 	cq.PushOrUpdate(workload.NewInfo(wl1))
 	cq.PushOrUpdate(workload.NewInfo(wl2))
 	if cq.Pending() != 2 {
@@ -102,9 +102,9 @@ func Test_Delete(t *testing.T) {
 }
 
 func Test_Dump(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl1 := workload.NewInfo(utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj())
-	wl2 := workload.NewInfo(utiltesting.MakeWorkload("workload-2", defaultNamespace).Obj())
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl1 := workload.NewInfo(builder.MakeWorkload("workload-1", defaultNamespace).Obj())
+	wl2 := workload.NewInfo(builder.MakeWorkload("workload-2", defaultNamespace).Obj())
 	if _, ok := cq.Dump(); ok {
 		t.Error("ClusterQueue should be empty")
 	}
@@ -116,8 +116,8 @@ func Test_Dump(t *testing.T) {
 }
 
 func Test_Info(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if info := cq.Info(keyFunc(workload.NewInfo(wl))); info != nil {
 		t.Error("workload doesn't exist")
 	}
@@ -128,8 +128,8 @@ func Test_Info(t *testing.T) {
 }
 
 func Test_AddFromLocalQueue(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
 	queue := &LocalQueue{
 		items: map[string]*workload.Info{
 			wl.Name: workload.NewInfo(wl),
@@ -146,13 +146,13 @@ func Test_AddFromLocalQueue(t *testing.T) {
 }
 
 func Test_DeleteFromLocalQueue(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	q := utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	q := builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj()
 	qImpl := newLocalQueue(q)
-	wl1 := utiltesting.MakeWorkload("wl1", "").Queue(q.Name).Obj()
-	wl2 := utiltesting.MakeWorkload("wl2", "").Queue(q.Name).Obj()
-	wl3 := utiltesting.MakeWorkload("wl3", "").Queue(q.Name).Obj()
-	wl4 := utiltesting.MakeWorkload("wl4", "").Queue(q.Name).Obj()
+	wl1 := builder.MakeWorkload("wl1", "").Queue(q.Name).Obj()
+	wl2 := builder.MakeWorkload("wl2", "").Queue(q.Name).Obj()
+	wl3 := builder.MakeWorkload("wl3", "").Queue(q.Name).Obj()
+	wl4 := builder.MakeWorkload("wl4", "").Queue(q.Name).Obj()
 	admissibleworkloads := []*kueue.Workload{wl1, wl2}
 	inadmissibleWorkloads := []*kueue.Workload{wl3, wl4}
 
@@ -183,8 +183,8 @@ func Test_DeleteFromLocalQueue(t *testing.T) {
 }
 
 func Test_RequeueIfNotPresent(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
-	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
+	cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
+	wl := builder.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if ok := cq.RequeueIfNotPresent(workload.NewInfo(wl), RequeueReasonGeneric); !ok {
 		t.Error("failed to requeue nonexistent workload")
 	}
@@ -207,9 +207,9 @@ func TestClusterQueueImpl(t *testing.T) {
 	cl := clientBuilder.Build()
 
 	var workloads = []*kueue.Workload{
-		utiltesting.MakeWorkload("w1", "ns1").Queue("q1").Obj(),
-		utiltesting.MakeWorkload("w2", "ns2").Queue("q2").Obj(),
-		utiltesting.MakeWorkload("w3", "ns3").Queue("q3").Obj(),
+		builder.MakeWorkload("w1", "ns1").Queue("q1").Obj(),
+		builder.MakeWorkload("w2", "ns2").Queue("q2").Obj(),
+		builder.MakeWorkload("w3", "ns3").Queue("q3").Obj(),
 	}
 	var updatedWorkloads = make([]*kueue.Workload, len(workloads))
 
@@ -284,9 +284,9 @@ func TestClusterQueueImpl(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			cq := newClusterQueueImpl(keyFunc, byCreationTime)
+			cq := newClusterQueueImpl(keyFunc, queueOrdering(workload.Ordering{}))
 
-			err := cq.Update(utiltesting.MakeClusterQueue("cq").
+			err := cq.Update(builder.MakeClusterQueue("cq").
 				NamespaceSelector(&metav1.LabelSelector{
 					MatchExpressions: []metav1.LabelSelectorRequirement{
 						{
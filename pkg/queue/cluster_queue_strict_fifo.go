@@ -32,8 +32,8 @@ var _ ClusterQueue = &ClusterQueueStrictFIFO{}
 
 const StrictFIFO = kueue.StrictFIFO
 
-func newClusterQueueStrictFIFO(cq *kueue.ClusterQueue) (ClusterQueue, error) {
-	cqImpl := newClusterQueueImpl(keyFunc, byCreationTime)
+func newClusterQueueStrictFIFO(cq *kueue.ClusterQueue, wo workload.Ordering) (ClusterQueue, error) {
+	cqImpl := newClusterQueueImpl(keyFunc, queueOrdering(wo))
 	cqStrict := &ClusterQueueStrictFIFO{
 		ClusterQueueImpl: cqImpl,
 	}
@@ -42,17 +42,25 @@ func newClusterQueueStrictFIFO(cq *kueue.ClusterQueue) (ClusterQueue, error) {
 	return cqStrict, err
 }
 
-// byCreationTime is the function used by the clusterQueue heap algorithm to sort
-// workloads. It sorts workloads based on their priority.
-// When priorities are equal, it uses workloads.creationTimestamp.
-func byCreationTime(a, b interface{}) bool {
-	objA := a.(*workload.Info)
-	objB := b.(*workload.Info)
-	p1 := utilpriority.Priority(objA.Obj)
-	p2 := utilpriority.Priority(objB.Obj)
-
-	if p1 != p2 {
-		return p1 > p2
+// queueOrdering returns the function used by the clusterQueue heap algorithm
+// to sort workloads. It sorts workloads based on their priority.
+// When priorities are equal, it uses the round of the workloads, which is
+// only set when the workloads are ordered fairly, and then the
+// queue order timestamp of the workloads, which is the creationTimestamp
+// unless the ordering says otherwise for evicted workloads.
+func queueOrdering(wo workload.Ordering) func(a, b interface{}) bool {
+	return func(a, b interface{}) bool {
+		objA := a.(*workload.Info)
+		objB := b.(*workload.Info)
+		p1 := utilpriority.Priority(objA.Obj)
+		p2 := utilpriority.Priority(objB.Obj)
+
+		if p1 != p2 {
+			return p1 > p2
+		}
+		if objA.Round != objB.Round {
+			return objA.Round < objB.Round
+		}
+		return wo.QueueOrderTimestamp(objA.Obj).Before(wo.QueueOrderTimestamp(objB.Obj))
 	}
-	return objA.Obj.CreationTimestamp.Before(&objB.Obj.CreationTimestamp)
 }
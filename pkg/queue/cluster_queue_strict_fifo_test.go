@@ -37,7 +37,7 @@ func TestFIFOClusterQueue(t *testing.T) {
 		Spec: kueue.ClusterQueueSpec{
 			QueueingStrategy: kueue.StrictFIFO,
 		},
-	})
+	}, workload.Ordering{})
 	if err != nil {
 		t.Fatalf("Failed creating ClusterQueue %v", err)
 	}
@@ -175,7 +175,7 @@ func TestStrictFIFO(t *testing.T) {
 				Spec: kueue.ClusterQueueSpec{
 					QueueingStrategy: kueue.StrictFIFO,
 				},
-			})
+			}, workload.Ordering{})
 			if err != nil {
 				t.Fatalf("Failed creating ClusterQueue %v", err)
 			}
@@ -193,3 +193,60 @@ func TestStrictFIFO(t *testing.T) {
 		})
 	}
 }
+
+func TestStrictFIFORequeuingTimestamp(t *testing.T) {
+	now := time.Now()
+	evicted := &kueue.Workload{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "evicted",
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+		},
+		Status: kueue.WorkloadStatus{
+			Conditions: []metav1.Condition{{
+				Type:               kueue.WorkloadEvicted,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now),
+			}},
+		},
+	}
+	pending := &kueue.Workload{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pending",
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+		},
+	}
+	for name, tc := range map[string]struct {
+		ordering workload.Ordering
+		expected string
+	}{
+		"by creation time": {
+			expected: "evicted",
+		},
+		"by eviction time": {
+			ordering: workload.Ordering{RequeueByEvictionTime: true},
+			expected: "pending",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			q, err := newClusterQueue(&kueue.ClusterQueue{
+				Spec: kueue.ClusterQueueSpec{
+					QueueingStrategy: kueue.StrictFIFO,
+				},
+			}, tc.ordering)
+			if err != nil {
+				t.Fatalf("Failed creating ClusterQueue %v", err)
+			}
+
+			q.PushOrUpdate(workload.NewInfo(evicted))
+			q.PushOrUpdate(workload.NewInfo(pending))
+
+			got := q.Pop()
+			if got == nil {
+				t.Fatal("Queue is empty")
+			}
+			if got.Obj.Name != tc.expected {
+				t.Errorf("Popped workload %q want %q", got.Obj.Name, tc.expected)
+			}
+		})
+	}
+}
@@ -39,12 +39,19 @@ type LocalQueue struct {
 	ClusterQueue string
 
 	items map[string]*workload.Info
+
+	// The following fields are used when the workloads are ordered fairly.
+
+	weight int
+	// nextRounds is the round for the next workload of each user.
+	nextRounds map[string]int
 }
 
 func newLocalQueue(q *kueue.LocalQueue) *LocalQueue {
 	qImpl := &LocalQueue{
-		Key:   Key(q),
-		items: make(map[string]*workload.Info),
+		Key:        Key(q),
+		items:      make(map[string]*workload.Info),
+		nextRounds: make(map[string]int),
 	}
 	qImpl.update(q)
 	return qImpl
@@ -52,6 +59,10 @@ func newLocalQueue(q *kueue.LocalQueue) *LocalQueue {
 
 func (q *LocalQueue) update(apiQueue *kueue.LocalQueue) {
 	q.ClusterQueue = string(apiQueue.Spec.ClusterQueue)
+	q.weight = 1
+	if apiQueue.Spec.Weight != nil {
+		q.weight = int(*apiQueue.Spec.Weight)
+	}
 }
 
 func (q *LocalQueue) AddOrUpdate(info *workload.Info) {
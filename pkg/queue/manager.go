@@ -20,15 +20,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
 	"sigs.k8s.io/kueue/pkg/metrics"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -54,15 +57,100 @@ type Manager struct {
 
 	// Key is cohort's name. Value is a set of associated ClusterQueue names.
 	cohorts map[string]sets.String
+
+	workloadOrdering workload.Ordering
+
+	fairSharing bool
+	// userLabel is the label of the workloads that identifies their users,
+	// when the workloads of each LocalQueue are also ordered fairly among
+	// users.
+	userLabel string
+	// Key is the ClusterQueue's name. Value is the round of the last workload
+	// popped from the ClusterQueue.
+	rounds map[string]int
+
+	// sharded indicates that the cohorts are split among several shards. Only
+	// the LocalQueues of the ClusterQueues of this shard are tracked.
+	sharded bool
+
+	clock clock.Clock
+}
+
+// roundSize is the increase in the round of the workloads of a LocalQueue
+// with weight 1.
+const roundSize = 1000
+
+type options struct {
+	workloadOrdering workload.Ordering
+	fairSharing      bool
+	userLabel        string
+	shard            sharding.Shard
+	clock            clock.Clock
+}
+
+// Option configures the manager.
+type Option func(*options)
+
+// WithWorkloadOrdering sets how the workloads are ordered in the
+// ClusterQueues. By default, evicted workloads are ordered by the time of
+// their last eviction.
+func WithWorkloadOrdering(wo workload.Ordering) Option {
+	return func(o *options) {
+		o.workloadOrdering = wo
+	}
+}
+
+// WithFairSharing indicates that the pending workloads of each ClusterQueue
+// with the same priority are ordered fairly among its LocalQueues, according
+// to their weights, instead of in FIFO order. If userLabel is not empty, the
+// workloads of each LocalQueue are also ordered fairly among the users
+// identified by the label.
+func WithFairSharing(userLabel string) Option {
+	return func(o *options) {
+		o.fairSharing = true
+		o.userLabel = userLabel
+	}
+}
+
+// WithShard sets the shard of the cohorts that the manager queues. The
+// LocalQueues of the ClusterQueues of other shards are added along with their
+// ClusterQueue, if it moves to this shard.
+func WithShard(s sharding.Shard) Option {
+	return func(o *options) {
+		o.shard = s
+	}
 }
 
-func NewManager(client client.Client, checker StatusChecker) *Manager {
+// WithClock sets the clock used to check whether the workloads are waiting to
+// be requeued after a backoff. It's meant to be replaced in tests.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var defaultOptions = options{
+	workloadOrdering: workload.Ordering{RequeueByEvictionTime: true},
+	clock:            clock.RealClock{},
+}
+
+func NewManager(client client.Client, checker StatusChecker, opts ...Option) *Manager {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	m := &Manager{
-		client:        client,
-		statusChecker: checker,
-		localQueues:   make(map[string]*LocalQueue),
-		clusterQueues: make(map[string]ClusterQueue),
-		cohorts:       make(map[string]sets.String),
+		client:           client,
+		statusChecker:    checker,
+		localQueues:      make(map[string]*LocalQueue),
+		clusterQueues:    make(map[string]ClusterQueue),
+		cohorts:          make(map[string]sets.String),
+		workloadOrdering: options.workloadOrdering,
+		fairSharing:      options.fairSharing,
+		userLabel:        options.userLabel,
+		sharded:          options.shard.Enabled(),
+		clock:            options.clock,
+		rounds:           make(map[string]int),
 	}
 	m.cond.L = &m.RWMutex
 	return m
@@ -76,7 +164,7 @@ func (m *Manager) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) e
 		return errClusterQueueAlreadyExists
 	}
 
-	cqImpl, err := newClusterQueue(cq)
+	cqImpl, err := newClusterQueue(cq, m.workloadOrdering)
 	if err != nil {
 		return err
 	}
@@ -100,10 +188,19 @@ func (m *Manager) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) e
 			continue
 		}
 		qImpl := m.localQueues[Key(&q)]
-		if qImpl != nil {
-			added := cqImpl.AddFromLocalQueue(qImpl)
-			addedWorkloads = addedWorkloads || added
+		if qImpl == nil && m.sharded {
+			// With sharding, the LocalQueues of the ClusterQueues that belong to
+			// other shards aren't tracked until their ClusterQueue moves here.
+			q := q
+			if qImpl, err = m.addLocalQueue(ctx, &q); err != nil {
+				return err
+			}
+		}
+		if qImpl == nil {
+			continue
 		}
+		added := cqImpl.AddFromLocalQueue(qImpl)
+		addedWorkloads = addedWorkloads || added
 	}
 
 	queued := m.queueAllInadmissibleWorkloadsInCohort(ctx, cqImpl)
@@ -144,11 +241,30 @@ func (m *Manager) UpdateClusterQueue(ctx context.Context, cq *kueue.ClusterQueue
 func (m *Manager) DeleteClusterQueue(cq *kueue.ClusterQueue) {
 	m.Lock()
 	defer m.Unlock()
+	m.deleteClusterQueue(cq)
+}
+
+// ReleaseClusterQueue deletes the ClusterQueue along with its LocalQueues and
+// their pending workloads. It is used when the ClusterQueue moves to another
+// shard, which becomes responsible for its LocalQueues.
+func (m *Manager) ReleaseClusterQueue(cq *kueue.ClusterQueue) {
+	m.Lock()
+	defer m.Unlock()
+	for key, q := range m.localQueues {
+		if q.ClusterQueue == cq.Name {
+			delete(m.localQueues, key)
+		}
+	}
+	m.deleteClusterQueue(cq)
+}
+
+func (m *Manager) deleteClusterQueue(cq *kueue.ClusterQueue) {
 	cqImpl := m.clusterQueues[cq.Name]
 	if cqImpl == nil {
 		return
 	}
 	delete(m.clusterQueues, cq.Name)
+	delete(m.rounds, cq.Name)
 	metrics.ClearQueueSystemMetrics(cq.Name)
 
 	cohort := cq.Spec.Cohort
@@ -163,27 +279,44 @@ func (m *Manager) AddLocalQueue(ctx context.Context, q *kueue.LocalQueue) error
 	if _, ok := m.localQueues[key]; ok {
 		return fmt.Errorf("queue %q already exists", q.Name)
 	}
+	qImpl, err := m.addLocalQueue(ctx, q)
+	if err != nil {
+		return err
+	}
+	cq := m.clusterQueues[qImpl.ClusterQueue]
+	if cq != nil && cq.AddFromLocalQueue(qImpl) {
+		m.Broadcast()
+	}
+	return nil
+}
+
+// addLocalQueue starts tracking the LocalQueue and its pending workloads.
+func (m *Manager) addLocalQueue(ctx context.Context, q *kueue.LocalQueue) (*LocalQueue, error) {
 	qImpl := newLocalQueue(q)
-	m.localQueues[key] = qImpl
+	m.localQueues[Key(q)] = qImpl
 	// Iterate through existing workloads, as workloads corresponding to this
 	// queue might have been added earlier.
 	var workloads kueue.WorkloadList
 	if err := m.client.List(ctx, &workloads, client.MatchingFields{workloadQueueKey: q.Name}, client.InNamespace(q.Namespace)); err != nil {
-		return fmt.Errorf("listing workloads that match the queue: %w", err)
+		return nil, fmt.Errorf("listing workloads that match the queue: %w", err)
+	}
+	if m.fairSharing {
+		// The rounds of the workloads of each user follow their queue order.
+		sort.Slice(workloads.Items, func(i, j int) bool {
+			return m.workloadOrdering.QueueOrderTimestamp(&workloads.Items[i]).Before(m.workloadOrdering.QueueOrderTimestamp(&workloads.Items[j]))
+		})
 	}
 	for _, w := range workloads.Items {
 		w := w
 		// Checking queue name again because the field index is not available in tests.
-		if w.Spec.QueueName != q.Name || w.Spec.Admission != nil {
+		if w.Spec.QueueName != q.Name || w.Spec.Admission != nil || !m.canBeQueued(&w) {
 			continue
 		}
-		qImpl.AddOrUpdate(workload.NewInfo(&w))
-	}
-	cq := m.clusterQueues[qImpl.ClusterQueue]
-	if cq != nil && cq.AddFromLocalQueue(qImpl) {
-		m.Broadcast()
+		wInfo := workload.NewInfo(&w)
+		m.setRound(qImpl, wInfo)
+		qImpl.AddOrUpdate(wInfo)
 	}
-	return nil
+	return qImpl, nil
 }
 
 func (m *Manager) UpdateLocalQueue(q *kueue.LocalQueue) error {
@@ -276,7 +409,13 @@ func (m *Manager) addOrUpdateWorkload(w *kueue.Workload) bool {
 	if q == nil {
 		return false
 	}
+	if !m.canBeQueued(w) {
+		// The workload is inactive or waiting for its backoff to expire.
+		m.deleteWorkloadFromQueueAndClusterQueue(w, qKey)
+		return true
+	}
 	wInfo := workload.NewInfo(w)
+	m.setRound(q, wInfo)
 	q.AddOrUpdate(wInfo)
 	cq := m.clusterQueues[q.ClusterQueue]
 	if cq == nil {
@@ -288,6 +427,35 @@ func (m *Manager) addOrUpdateWorkload(w *kueue.Workload) bool {
 	return true
 }
 
+// setRound assigns a round to a workload that is new in the LocalQueue, so
+// that the workloads of a LocalQueue, or of a user, that submits many of them
+// are ordered after the workloads of others that submitted fewer. This is
+// start-time fair queueing, with rounds as virtual time: the round of a
+// workload is the latest between the round of the last workload popped from
+// the ClusterQueue and the round for the next workload of the user in the
+// LocalQueue. The latter increases with each workload, inversely to the weight
+// of the LocalQueue.
+// Workloads keep their round while they are updated or requeued.
+func (m *Manager) setRound(q *LocalQueue, wInfo *workload.Info) {
+	if !m.fairSharing {
+		return
+	}
+	if old, ok := q.items[workload.Key(wInfo.Obj)]; ok {
+		wInfo.Round = old.Round
+		return
+	}
+	var user string
+	if m.userLabel != "" {
+		user = wInfo.Obj.Labels[m.userLabel]
+	}
+	round := m.rounds[q.ClusterQueue]
+	if next := q.nextRounds[user]; next > round {
+		round = next
+	}
+	q.nextRounds[user] = round + roundSize/q.weight
+	wInfo.Round = round
+}
+
 // RequeueWorkload requeues the workload ensuring that the queue and the
 // workload still exist in the client cache and it's not admitted. It won't
 // requeue if the workload is already in the queue (possible if the workload was updated).
@@ -299,7 +467,7 @@ func (m *Manager) RequeueWorkload(ctx context.Context, info *workload.Info, reas
 	// Always get the newest workload to avoid requeuing the out-of-date obj.
 	err := m.client.Get(ctx, client.ObjectKeyFromObject(info.Obj), &w)
 	// Since the client is cached, the only possible error is NotFound
-	if apierrors.IsNotFound(err) || w.Spec.Admission != nil {
+	if apierrors.IsNotFound(err) || w.Spec.Admission != nil || !m.canBeQueued(&w) {
 		return false
 	}
 
@@ -322,6 +490,12 @@ func (m *Manager) RequeueWorkload(ctx context.Context, info *workload.Info, reas
 	return added
 }
 
+// canBeQueued returns whether the workload is active and not waiting to be
+// requeued after a retry.
+func (m *Manager) canBeQueued(w *kueue.Workload) bool {
+	return workload.IsActive(w) && workload.RequeueAfter(w, m.clock.Now()) == 0
+}
+
 func (m *Manager) DeleteWorkload(w *kueue.Workload) {
 	m.Lock()
 	m.deleteWorkloadFromQueueAndClusterQueue(w, workload.QueueKey(w))
@@ -341,19 +515,26 @@ func (m *Manager) deleteWorkloadFromQueueAndClusterQueue(w *kueue.Workload, qKey
 	}
 }
 
-// QueueAssociatedInadmissibleWorkloads moves all associated workloads from
-// inadmissibleWorkloads to heap. If at least one workload is moved,
-// returns true. Otherwise returns false.
+// QueueAssociatedInadmissibleWorkloads moves the inadmissible workloads that
+// could use the quota released by the workload to the heaps: the ones of the
+// ClusterQueue that admitted it and of its cohort. If the workload isn't
+// admitted, the ClusterQueue of its LocalQueue is used.
 func (m *Manager) QueueAssociatedInadmissibleWorkloads(ctx context.Context, w *kueue.Workload) {
 	m.Lock()
 	defer m.Unlock()
 
-	q := m.localQueues[workload.QueueKey(w)]
-	if q == nil {
-		return
+	var cqName string
+	if w.Spec.Admission != nil {
+		cqName = string(w.Spec.Admission.ClusterQueue)
+	} else {
+		q := m.localQueues[workload.QueueKey(w)]
+		if q == nil {
+			return
+		}
+		cqName = q.ClusterQueue
 	}
 
-	cq := m.clusterQueues[q.ClusterQueue]
+	cq := m.clusterQueues[cqName]
 	if cq == nil {
 		return
 	}
@@ -511,6 +692,11 @@ func (m *Manager) heads() []workload.Info {
 		workloads = append(workloads, wlCopy)
 		q := m.localQueues[workload.QueueKey(wl.Obj)]
 		delete(q.items, workload.Key(wl.Obj))
+		// A requeued workload keeps the round it got when it was added, which
+		// can be behind the round of the ClusterQueue.
+		if m.fairSharing && wl.Round > m.rounds[cqName] {
+			m.rounds[cqName] = wl.Round
+		}
 	}
 	return workloads
 }
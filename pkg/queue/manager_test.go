@@ -24,19 +24,33 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/util/sharding"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const headsTimeout = 3 * time.Second
 
+// TestDefaultWorkloadOrdering verifies that, by default, evicted workloads
+// are ordered by the time of their last eviction, as in the default
+// configuration of the manager.
+func TestDefaultWorkloadOrdering(t *testing.T) {
+	manager := NewManager(fake.NewClientBuilder().Build(), nil)
+	if want := (workload.Ordering{RequeueByEvictionTime: true}); manager.workloadOrdering != want {
+		t.Errorf("Unexpected default workload ordering %+v, want %+v", manager.workloadOrdering, want)
+	}
+}
+
 // TestAddLocalQueueOrphans verifies that pods added before adding the queue are
 // present when the queue is added.
 func TestAddLocalQueueOrphans(t *testing.T) {
@@ -45,15 +59,15 @@ func TestAddLocalQueueOrphans(t *testing.T) {
 		t.Fatalf("Failed adding kueue scheme: %s", err)
 	}
 	kClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
-		utiltesting.MakeWorkload("a", "earth").Queue("foo").Obj(),
-		utiltesting.MakeWorkload("b", "earth").Queue("bar").Obj(),
-		utiltesting.MakeWorkload("c", "earth").Queue("foo").Obj(),
-		utiltesting.MakeWorkload("d", "earth").Queue("foo").
-			Admit(utiltesting.MakeAdmission("cq").Obj()).Obj(),
-		utiltesting.MakeWorkload("a", "moon").Queue("foo").Obj(),
+		builder.MakeWorkload("a", "earth").Queue("foo").Obj(),
+		builder.MakeWorkload("b", "earth").Queue("bar").Obj(),
+		builder.MakeWorkload("c", "earth").Queue("foo").Obj(),
+		builder.MakeWorkload("d", "earth").Queue("foo").
+			Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+		builder.MakeWorkload("a", "moon").Queue("foo").Obj(),
 	).Build()
 	manager := NewManager(kClient, nil)
-	q := utiltesting.MakeLocalQueue("foo", "earth").Obj()
+	q := builder.MakeLocalQueue("foo", "earth").Obj()
 	if err := manager.AddLocalQueue(context.Background(), q); err != nil {
 		t.Fatalf("Failed adding queue: %v", err)
 	}
@@ -73,15 +87,15 @@ func TestAddClusterQueueOrphans(t *testing.T) {
 	}
 	now := time.Now()
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
-		utiltesting.MakeLocalQueue("bar", "").ClusterQueue("cq").Obj(),
+		builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+		builder.MakeLocalQueue("bar", "").ClusterQueue("cq").Obj(),
 	}
 	kClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
-		utiltesting.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
-		utiltesting.MakeWorkload("b", "").Queue("bar").Creation(now).Obj(),
-		utiltesting.MakeWorkload("c", "").Queue("foo").
-			Admit(utiltesting.MakeAdmission("cq").Obj()).Obj(),
-		utiltesting.MakeWorkload("d", "").Queue("baz").Obj(),
+		builder.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
+		builder.MakeWorkload("b", "").Queue("bar").Creation(now).Obj(),
+		builder.MakeWorkload("c", "").Queue("foo").
+			Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+		builder.MakeWorkload("d", "").Queue("baz").Obj(),
 		queues[0],
 		queues[1],
 	).Build()
@@ -92,7 +106,7 @@ func TestAddClusterQueueOrphans(t *testing.T) {
 			t.Fatalf("Failed adding queue %s: %v", q.Name, err)
 		}
 	}
-	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	cq := builder.MakeClusterQueue("cq").Obj()
 	if err := manager.AddClusterQueue(ctx, cq); err != nil {
 		t.Fatalf("Failed adding cluster queue %s: %v", cq.Name, err)
 	}
@@ -103,21 +117,58 @@ func TestAddClusterQueueOrphans(t *testing.T) {
 	}
 }
 
+// TestAddClusterQueueSharded verifies that, with sharding, the queues of a
+// clusterQueue that weren't tracked are added along with the clusterQueue, and
+// removed when it's released to another shard.
+func TestAddClusterQueueSharded(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	now := time.Now()
+	kClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		builder.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
+		builder.MakeWorkload("b", "").Queue("bar").Creation(now).Obj(),
+		builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+		builder.MakeLocalQueue("bar", "").ClusterQueue("cq").Obj(),
+	).Build()
+	ctx := context.Background()
+	manager := NewManager(kClient, nil, WithShard(sharding.Shard{Index: 0, Count: 2}))
+	cq := builder.MakeClusterQueue("cq").Obj()
+	if err := manager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding cluster queue %s: %v", cq.Name, err)
+	}
+	if diff := cmp.Diff(2, len(manager.localQueues)); diff != "" {
+		t.Errorf("Unexpected number of queues (-want,+got):\n%s", diff)
+	}
+	workloads := popNamesFromCQ(manager.clusterQueues[cq.Name])
+	wantWorkloads := []string{"/b", "/a"}
+	if diff := cmp.Diff(wantWorkloads, workloads); diff != "" {
+		t.Errorf("Workloads popped in the wrong order from clusterQueue:\n%s", diff)
+	}
+
+	manager.ReleaseClusterQueue(cq)
+	if len(manager.localQueues) != 0 || len(manager.clusterQueues) != 0 {
+		t.Errorf("Queues left after releasing the clusterQueue: %d localQueues, %d clusterQueues",
+			len(manager.localQueues), len(manager.clusterQueues))
+	}
+}
+
 // TestUpdateQueue tests that workloads are transferred between clusterQueues
 // when the queue points to a different clusterQueue.
 func TestUpdateQueue(t *testing.T) {
 	clusterQueues := []*kueue.ClusterQueue{
-		utiltesting.MakeClusterQueue("cq1").Obj(),
-		utiltesting.MakeClusterQueue("cq2").Obj(),
+		builder.MakeClusterQueue("cq1").Obj(),
+		builder.MakeClusterQueue("cq2").Obj(),
 	}
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq1").Obj(),
-		utiltesting.MakeLocalQueue("bar", "").ClusterQueue("cq2").Obj(),
+		builder.MakeLocalQueue("foo", "").ClusterQueue("cq1").Obj(),
+		builder.MakeLocalQueue("bar", "").ClusterQueue("cq2").Obj(),
 	}
 	now := time.Now()
 	workloads := []*kueue.Workload{
-		utiltesting.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
-		utiltesting.MakeWorkload("b", "").Queue("bar").Creation(now).Obj(),
+		builder.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
+		builder.MakeWorkload("b", "").Queue("bar").Creation(now).Obj(),
 	}
 	// Setup.
 	scheme := runtime.NewScheme()
@@ -166,13 +217,13 @@ func TestAddWorkload(t *testing.T) {
 		t.Fatalf("Failed adding kueue scheme: %s", err)
 	}
 	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil)
-	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	cq := builder.MakeClusterQueue("cq").Obj()
 	if err := manager.AddClusterQueue(context.Background(), cq); err != nil {
 		t.Fatalf("Failed adding clusterQueue %s: %v", cq.Name, err)
 	}
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("foo", "earth").ClusterQueue("cq").Obj(),
-		utiltesting.MakeLocalQueue("bar", "mars").Obj(),
+		builder.MakeLocalQueue("foo", "earth").ClusterQueue("cq").Obj(),
+		builder.MakeLocalQueue("bar", "mars").Obj(),
 	}
 	for _, q := range queues {
 		if err := manager.AddLocalQueue(context.Background(), q); err != nil {
@@ -230,6 +281,74 @@ func TestAddWorkload(t *testing.T) {
 	}
 }
 
+func TestAddWorkloadNotQueueable(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	clock := testingclock.NewFakeClock(time.Now())
+	now := clock.Now()
+	cases := map[string]struct {
+		workload   *kueue.Workload
+		wantQueued bool
+	}{
+		"active": {
+			workload:   builder.MakeWorkload("a", "").Queue("foo").Obj(),
+			wantQueued: true,
+		},
+		"inactive": {
+			workload: builder.MakeWorkload("a", "").Queue("foo").Active(false).Obj(),
+		},
+		"waiting for backoff": {
+			workload: builder.MakeWorkload("a", "").Queue("foo").RequeueAt(now.Add(time.Minute)).Obj(),
+		},
+		"backoff expires in a second": {
+			workload: builder.MakeWorkload("a", "").Queue("foo").RequeueAt(now.Add(time.Second)).Obj(),
+		},
+		"backoff expires now": {
+			workload:   builder.MakeWorkload("a", "").Queue("foo").RequeueAt(now).Obj(),
+			wantQueued: true,
+		},
+		"backoff expired": {
+			workload:   builder.MakeWorkload("a", "").Queue("foo").RequeueAt(now.Add(-time.Minute)).Obj(),
+			wantQueued: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil, WithClock(clock))
+			ctx := context.Background()
+			if err := manager.AddClusterQueue(ctx, builder.MakeClusterQueue("cq").Obj()); err != nil {
+				t.Fatalf("Failed adding clusterQueue: %v", err)
+			}
+			if err := manager.AddLocalQueue(ctx, builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj()); err != nil {
+				t.Fatalf("Failed adding queue: %v", err)
+			}
+			// Add the workload as queueable first, to verify that the update
+			// removes it from the queues.
+			queueable := tc.workload.DeepCopy()
+			queueable.Spec.Active = nil
+			queueable.Status.RequeueState = nil
+			manager.AddOrUpdateWorkload(queueable)
+
+			if !manager.UpdateWorkload(queueable, tc.workload) {
+				t.Error("UpdateWorkload returned false, want true")
+			}
+			wantPending := 0
+			if tc.wantQueued {
+				wantPending = 1
+			}
+			if pending := manager.Pending(builder.MakeClusterQueue("cq").Obj()); pending != wantPending {
+				t.Errorf("Got %d pending workloads in clusterQueue, want %d", pending, wantPending)
+			}
+			q := manager.localQueues[workload.QueueKey(tc.workload)]
+			if _, queued := q.items[workload.Key(tc.workload)]; queued != tc.wantQueued {
+				t.Errorf("Workload in queue: %t, want %t", queued, tc.wantQueued)
+			}
+		})
+	}
+}
+
 func TestStatus(t *testing.T) {
 	ctx := context.Background()
 	scheme := runtime.NewScheme()
@@ -333,10 +452,10 @@ func TestRequeueWorkloadStrictFIFO(t *testing.T) {
 	if err := kueue.AddToScheme(scheme); err != nil {
 		t.Fatalf("Failed adding kueue scheme: %s", err)
 	}
-	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	cq := builder.MakeClusterQueue("cq").Obj()
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
-		utiltesting.MakeLocalQueue("bar", "").Obj(),
+		builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+		builder.MakeLocalQueue("bar", "").Obj(),
 	}
 	cases := []struct {
 		workload     *kueue.Workload
@@ -423,6 +542,44 @@ func TestRequeueWorkloadStrictFIFO(t *testing.T) {
 	}
 }
 
+// TestQueueAssociatedInadmissibleWorkloads verifies that only the inadmissible
+// workloads of the ClusterQueue that admitted the workload are queued again,
+// even if its LocalQueue now points to another ClusterQueue.
+func TestQueueAssociatedInadmissibleWorkloads(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding core scheme: %s", err)
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build(), nil)
+	ctx := context.Background()
+	for _, name := range []string{"a", "b"} {
+		if err := manager.AddClusterQueue(ctx, builder.MakeClusterQueue(name).Obj()); err != nil {
+			t.Fatalf("Failed adding clusterQueue %s: %v", name, err)
+		}
+		q := builder.MakeLocalQueue(name, "default").ClusterQueue(name).Obj()
+		if err := manager.AddLocalQueue(ctx, q); err != nil {
+			t.Fatalf("Failed adding queue %s: %v", name, err)
+		}
+		wl := builder.MakeWorkload("pending-"+name, "default").Queue(name).Obj()
+		manager.clusterQueues[name].RequeueIfNotPresent(workload.NewInfo(wl), RequeueReasonNamespaceMismatch)
+	}
+
+	finished := builder.MakeWorkload("finished", "default").Queue("b").
+		Admit(builder.MakeAdmission("a").Obj()).Obj()
+	manager.QueueAssociatedInadmissibleWorkloads(ctx, finished)
+
+	wantInadmissible := map[string]int{"a": 0, "b": 1}
+	for name, want := range wantInadmissible {
+		if got := manager.clusterQueues[name].PendingInadmissible(); got != want {
+			t.Errorf("Got %d inadmissible workloads in clusterQueue %s, want %d", got, name, want)
+		}
+	}
+}
+
 func TestUpdateWorkload(t *testing.T) {
 	scheme := runtime.NewScheme()
 	if err := kueue.AddToScheme(scheme); err != nil {
@@ -440,14 +597,14 @@ func TestUpdateWorkload(t *testing.T) {
 	}{
 		"in queue": {
 			clusterQueues: []*kueue.ClusterQueue{
-				utiltesting.MakeClusterQueue("cq").Obj(),
+				builder.MakeClusterQueue("cq").Obj(),
 			},
 			queues: []*kueue.LocalQueue{
-				utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
 			},
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Queue("foo").Creation(now).Obj(),
-				utiltesting.MakeWorkload("b", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
+				builder.MakeWorkload("a", "").Queue("foo").Creation(now).Obj(),
+				builder.MakeWorkload("b", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
 			},
 			update: func(w *kueue.Workload) {
 				w.CreationTimestamp = metav1.NewTime(now.Add(time.Minute))
@@ -460,16 +617,38 @@ func TestUpdateWorkload(t *testing.T) {
 				"/foo": sets.NewString("/a", "/b"),
 			},
 		},
+		"priority": {
+			clusterQueues: []*kueue.ClusterQueue{
+				builder.MakeClusterQueue("cq").Obj(),
+			},
+			queues: []*kueue.LocalQueue{
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+			},
+			workloads: []*kueue.Workload{
+				builder.MakeWorkload("a", "").Queue("foo").Creation(now.Add(time.Second)).Obj(),
+				builder.MakeWorkload("b", "").Queue("foo").Creation(now).Obj(),
+			},
+			update: func(w *kueue.Workload) {
+				w.Spec.Priority = pointer.Int32(100)
+			},
+			wantUpdated: true,
+			wantQueueOrder: map[string][]string{
+				"cq": {"/a", "/b"},
+			},
+			wantQueueMembers: map[string]sets.String{
+				"/foo": sets.NewString("/a", "/b"),
+			},
+		},
 		"between queues": {
 			clusterQueues: []*kueue.ClusterQueue{
-				utiltesting.MakeClusterQueue("cq").Obj(),
+				builder.MakeClusterQueue("cq").Obj(),
 			},
 			queues: []*kueue.LocalQueue{
-				utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
-				utiltesting.MakeLocalQueue("bar", "").ClusterQueue("cq").Obj(),
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+				builder.MakeLocalQueue("bar", "").ClusterQueue("cq").Obj(),
 			},
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Queue("foo").Obj(),
+				builder.MakeWorkload("a", "").Queue("foo").Obj(),
 			},
 			update: func(w *kueue.Workload) {
 				w.Spec.QueueName = "bar"
@@ -485,15 +664,15 @@ func TestUpdateWorkload(t *testing.T) {
 		},
 		"between cluster queues": {
 			clusterQueues: []*kueue.ClusterQueue{
-				utiltesting.MakeClusterQueue("cq1").Obj(),
-				utiltesting.MakeClusterQueue("cq2").Obj(),
+				builder.MakeClusterQueue("cq1").Obj(),
+				builder.MakeClusterQueue("cq2").Obj(),
 			},
 			queues: []*kueue.LocalQueue{
-				utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq1").Obj(),
-				utiltesting.MakeLocalQueue("bar", "").ClusterQueue("cq2").Obj(),
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq1").Obj(),
+				builder.MakeLocalQueue("bar", "").ClusterQueue("cq2").Obj(),
 			},
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Queue("foo").Obj(),
+				builder.MakeWorkload("a", "").Queue("foo").Obj(),
 			},
 			update: func(w *kueue.Workload) {
 				w.Spec.QueueName = "bar"
@@ -510,13 +689,13 @@ func TestUpdateWorkload(t *testing.T) {
 		},
 		"to non existent queue": {
 			clusterQueues: []*kueue.ClusterQueue{
-				utiltesting.MakeClusterQueue("cq").Obj(),
+				builder.MakeClusterQueue("cq").Obj(),
 			},
 			queues: []*kueue.LocalQueue{
-				utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
 			},
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Queue("foo").Obj(),
+				builder.MakeWorkload("a", "").Queue("foo").Obj(),
 			},
 			update: func(w *kueue.Workload) {
 				w.Spec.QueueName = "bar"
@@ -530,13 +709,13 @@ func TestUpdateWorkload(t *testing.T) {
 		},
 		"from non existing queue": {
 			clusterQueues: []*kueue.ClusterQueue{
-				utiltesting.MakeClusterQueue("cq").Obj(),
+				builder.MakeClusterQueue("cq").Obj(),
 			},
 			queues: []*kueue.LocalQueue{
-				utiltesting.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
+				builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj(),
 			},
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Queue("bar").Obj(),
+				builder.MakeWorkload("a", "").Queue("bar").Obj(),
 			},
 			update: func(w *kueue.Workload) {
 				w.Spec.QueueName = "foo"
@@ -617,14 +796,14 @@ func TestHeads(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 
 	clusterQueues := []*kueue.ClusterQueue{
-		utiltesting.MakeClusterQueue("active-fooCq").Obj(),
-		utiltesting.MakeClusterQueue("active-barCq").Obj(),
-		utiltesting.MakeClusterQueue("pending-bazCq").Obj(),
+		builder.MakeClusterQueue("active-fooCq").Obj(),
+		builder.MakeClusterQueue("active-barCq").Obj(),
+		builder.MakeClusterQueue("pending-bazCq").Obj(),
 	}
 	queues := []*kueue.LocalQueue{
-		utiltesting.MakeLocalQueue("foo", "").ClusterQueue("active-fooCq").Obj(),
-		utiltesting.MakeLocalQueue("bar", "").ClusterQueue("active-barCq").Obj(),
-		utiltesting.MakeLocalQueue("baz", "").ClusterQueue("pending-bazCq").Obj(),
+		builder.MakeLocalQueue("foo", "").ClusterQueue("active-fooCq").Obj(),
+		builder.MakeLocalQueue("bar", "").ClusterQueue("active-barCq").Obj(),
+		builder.MakeLocalQueue("baz", "").ClusterQueue("pending-bazCq").Obj(),
 	}
 	tests := []struct {
 		name          string
@@ -639,26 +818,26 @@ func TestHeads(t *testing.T) {
 		{
 			name: "active clusterQueues",
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Creation(now).Queue("foo").Obj(),
-				utiltesting.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
+				builder.MakeWorkload("a", "").Creation(now).Queue("foo").Obj(),
+				builder.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
 			},
 			wantWorkloads: sets.NewString("a", "b"),
 		},
 		{
 			name: "active clusterQueues with multiple workloads",
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a1", "").Creation(now).Queue("foo").Obj(),
-				utiltesting.MakeWorkload("a2", "").Creation(now.Add(time.Hour)).Queue("foo").Obj(),
-				utiltesting.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
+				builder.MakeWorkload("a1", "").Creation(now).Queue("foo").Obj(),
+				builder.MakeWorkload("a2", "").Creation(now.Add(time.Hour)).Queue("foo").Obj(),
+				builder.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
 			},
 			wantWorkloads: sets.NewString("a1", "b"),
 		},
 		{
 			name: "inactive clusterQueues",
 			workloads: []*kueue.Workload{
-				utiltesting.MakeWorkload("a", "").Creation(now).Queue("foo").Obj(),
-				utiltesting.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
-				utiltesting.MakeWorkload("c", "").Creation(now.Add(time.Hour)).Queue("baz").Obj(),
+				builder.MakeWorkload("a", "").Creation(now).Queue("foo").Obj(),
+				builder.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
+				builder.MakeWorkload("c", "").Creation(now.Add(time.Hour)).Queue("baz").Obj(),
 			},
 			wantWorkloads: sets.NewString("a", "b"),
 		},
@@ -697,6 +876,136 @@ func TestHeads(t *testing.T) {
 	}
 }
 
+// TestHeadsFairSharing ensures that the workloads of a LocalQueue are popped
+// fairly among users.
+func TestHeadsFairSharing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), headsTimeout)
+	defer cancel()
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil, WithFairSharing("user"))
+	if err := manager.AddClusterQueue(ctx, builder.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding clusterQueue: %v", err)
+	}
+	if err := manager.AddLocalQueue(ctx, builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding queue: %v", err)
+	}
+	go manager.CleanUpOnContext(ctx)
+
+	workloads := []*kueue.Workload{
+		builder.MakeWorkload("a1", "").Creation(now).Queue("foo").Label("user", "a").Obj(),
+		builder.MakeWorkload("a2", "").Creation(now.Add(time.Second)).Queue("foo").Label("user", "a").Obj(),
+		builder.MakeWorkload("a3", "").Creation(now.Add(2*time.Second)).Queue("foo").Label("user", "a").Obj(),
+		builder.MakeWorkload("b1", "").Creation(now.Add(3*time.Second)).Queue("foo").Label("user", "b").Obj(),
+		builder.MakeWorkload("high", "").Creation(now.Add(4*time.Second)).Queue("foo").Label("user", "h").
+			Priority(pointer.Int32(100)).Obj(),
+	}
+	for _, wl := range workloads {
+		manager.AddOrUpdateWorkload(wl)
+	}
+	var got []string
+	for i := 0; i < 3; i++ {
+		got = append(got, manager.Heads(ctx)[0].Obj.Name)
+	}
+	// A user that arrives later doesn't get ahead of the workloads that were
+	// already popped.
+	manager.AddOrUpdateWorkload(builder.MakeWorkload("c1", "").Creation(now.Add(5*time.Second)).Queue("foo").Label("user", "c").Obj())
+	for i := 0; i < 3; i++ {
+		got = append(got, manager.Heads(ctx)[0].Obj.Name)
+	}
+	want := []string{"high", "a1", "b1", "c1", "a2", "a3"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Heads returned workloads in the wrong order (-want,+got):\n%s", diff)
+	}
+}
+
+// TestHeadsFairSharingRequeued ensures that popping a requeued workload, which
+// keeps its round, doesn't move the round of the ClusterQueue backwards.
+func TestHeadsFairSharingRequeued(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), headsTimeout)
+	defer cancel()
+	workloads := []*kueue.Workload{
+		builder.MakeWorkload("a1", "").Creation(now).Queue("foo").Label("user", "a").Obj(),
+		builder.MakeWorkload("a2", "").Creation(now.Add(time.Second)).Queue("foo").Label("user", "a").Obj(),
+		builder.MakeWorkload("a3", "").Creation(now.Add(2*time.Second)).Queue("foo").Label("user", "a").Obj(),
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workloads[0]).Build()
+	manager := NewManager(cl, nil, WithFairSharing("user"))
+	if err := manager.AddClusterQueue(ctx, builder.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding clusterQueue: %v", err)
+	}
+	if err := manager.AddLocalQueue(ctx, builder.MakeLocalQueue("foo", "").ClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding queue: %v", err)
+	}
+	go manager.CleanUpOnContext(ctx)
+
+	for _, wl := range workloads {
+		manager.AddOrUpdateWorkload(wl)
+	}
+	first := manager.Heads(ctx)[0]
+	if second := manager.Heads(ctx)[0]; second.Obj.Name != "a2" {
+		t.Fatalf("Heads returned %s, want a2", second.Obj.Name)
+	}
+	if !manager.RequeueWorkload(ctx, &first, RequeueReasonFailedAfterNomination) {
+		t.Fatalf("Failed requeueing %s", first.Obj.Name)
+	}
+	if got := manager.Heads(ctx)[0]; got.Obj.Name != "a1" {
+		t.Fatalf("Heads returned %s, want a1", got.Obj.Name)
+	}
+	if got, want := manager.rounds["cq"], roundSize; got != want {
+		t.Errorf("Got round %d for the ClusterQueue, want %d", got, want)
+	}
+}
+
+// TestHeadsFairSharingWeights ensures that the workloads of a ClusterQueue
+// are popped according to the weights of their LocalQueues.
+func TestHeadsFairSharingWeights(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), headsTimeout)
+	defer cancel()
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil, WithFairSharing(""))
+	if err := manager.AddClusterQueue(ctx, builder.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding clusterQueue: %v", err)
+	}
+	queues := []*kueue.LocalQueue{
+		builder.MakeLocalQueue("prod", "").ClusterQueue("cq").Weight(4).Obj(),
+		builder.MakeLocalQueue("dev", "").ClusterQueue("cq").Obj(),
+	}
+	for _, q := range queues {
+		if err := manager.AddLocalQueue(ctx, q); err != nil {
+			t.Fatalf("Failed adding queue %s: %v", q.Name, err)
+		}
+	}
+	go manager.CleanUpOnContext(ctx)
+
+	for i, name := range []string{"d1", "d2", "d3"} {
+		manager.AddOrUpdateWorkload(builder.MakeWorkload(name, "").Creation(now.Add(time.Duration(i) * time.Second)).Queue("dev").Obj())
+	}
+	for i, name := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		manager.AddOrUpdateWorkload(builder.MakeWorkload(name, "").Creation(now.Add(time.Duration(10+i) * time.Second)).Queue("prod").Obj())
+	}
+	want := []string{"d1", "p1", "p2", "p3", "p4", "d2", "p5", "p6", "d3"}
+	var got []string
+	for range want {
+		got = append(got, manager.Heads(ctx)[0].Obj.Name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Heads returned workloads in the wrong order (-want,+got):\n%s", diff)
+	}
+}
+
 var ignoreTypeMeta = cmpopts.IgnoreTypes(metav1.TypeMeta{})
 
 // TestHeadAsync ensures that Heads call is blocked until the queues are filled
@@ -708,8 +1017,8 @@ func TestHeadsAsync(t *testing.T) {
 	}
 	now := time.Now().Truncate(time.Second)
 	clusterQueues := []*kueue.ClusterQueue{
-		utiltesting.MakeClusterQueue("fooCq").Obj(),
-		utiltesting.MakeClusterQueue("barCq").Obj(),
+		builder.MakeClusterQueue("fooCq").Obj(),
+		builder.MakeClusterQueue("barCq").Obj(),
 	}
 	wl := kueue.Workload{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+)
+
+// costSearchLimit bounds the number of steps that the search for the
+// cheapest flavors visits, so that workloads with many podSets and flavors
+// don't stall the scheduling cycle.
+const costSearchLimit = 1000
+
+// cheapestFlavors returns, for each step, the flavor that minimizes the total
+// cost of the workload, along with the total cost.
+// The search tries the flavors of each step from the cheapest, and it prunes
+// the assignments that can't be cheaper than the best one found so far. If the
+// search reaches the limit, it returns the best assignment found so far.
+// The assigner is left unmodified.
+func (a *flavorAssigner) cheapestFlavors(steps []assignStep) ([]string, int64, *admissionStatus) {
+	s := costSearch{
+		a:      a,
+		steps:  steps,
+		chosen: make([]string, len(steps)),
+		left:   costSearchLimit,
+	}
+	if status := s.visit(0, 0); status != nil {
+		return nil, 0, status
+	}
+	if s.best == nil {
+		return nil, 0, s.status
+	}
+	return s.best, s.bestCost, nil
+}
+
+// sameFlavorsAssignment returns, for each step, the flavor to use when all the
+// podSets are required to use the same flavors. The flavors of each step are
+// tried in the order that they would be assigned one podSet at a time, but the
+// search goes back to the previous steps when the flavors that they chose
+// can't be used by the next ones. It returns the first assignment that fits.
+// The assigner is left unmodified.
+func (a *flavorAssigner) sameFlavorsAssignment(steps []assignStep) ([]string, *admissionStatus) {
+	s := costSearch{
+		a:        a,
+		steps:    steps,
+		chosen:   make([]string, len(steps)),
+		left:     costSearchLimit,
+		firstFit: true,
+	}
+	if status := s.visit(0, 0); status != nil {
+		return nil, status
+	}
+	if s.best == nil {
+		return nil, s.status
+	}
+	return s.best, nil
+}
+
+type costSearch struct {
+	a        *flavorAssigner
+	steps    []assignStep
+	chosen   []string
+	best     []string
+	bestCost int64
+	left     int
+	// firstFit stops the search at the first assignment that fits, instead of
+	// looking for the cheapest one.
+	firstFit bool
+	// status holds the reasons why a step couldn't be assigned a flavor, for
+	// the first step that didn't fit. Since the search tries the cheapest
+	// flavors first, it's the step that would have failed when assigning the
+	// flavors one podSet at a time.
+	status *admissionStatus
+}
+
+// visit searches the flavors for the steps from i, given the cost of the
+// flavors chosen for the previous steps. It only returns a status on errors.
+func (s *costSearch) visit(i int, cost int64) *admissionStatus {
+	if s.best != nil && (s.firstFit || cost >= s.bestCost) {
+		return nil
+	}
+	if i == len(s.steps) {
+		s.best = append(make([]string, 0, len(s.chosen)), s.chosen...)
+		s.bestCost = cost
+		return nil
+	}
+	a := s.a
+	step := &s.steps[i]
+	if s.left == 0 {
+		if s.status == nil {
+			s.status = &admissionStatus{
+				podSet:  step.podSetName,
+				reasons: []string{fmt.Sprintf("no flavors found within %d steps of the search", costSearchLimit)},
+			}
+		}
+		return nil
+	}
+	s.left--
+	fits, status := fittingFlavors(a.log, step.requests, a.wUsed, a.resourceFlavors, a.cq, a.namespace, a.nsLabels, step.spec, a.requiredFlavor(step), a.nodeCaps, step.podRequests, a.evictions)
+	if status.IsError() {
+		status.podSet = step.podSetName
+		return status
+	}
+	if !status.IsSuccess() {
+		if s.status == nil {
+			status.podSet = step.podSetName
+			s.status = status
+		}
+		return nil
+	}
+	sort.SliceStable(fits, func(x, y int) bool {
+		return fits[x].cost < fits[y].cost
+	})
+	for _, f := range fits {
+		required := a.useFlavor(step.requests, f.name)
+		s.chosen[i] = f.name
+		status := s.visit(i+1, cost+f.cost)
+		a.releaseFlavor(step.requests, f.name, required)
+		if status != nil {
+			return status
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// nodeCapacities holds, for each flavor, the allocatable resources of the
+// schedulable nodes that have the labels of the flavor.
+type nodeCapacities map[string][]workload.Requests
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// listNodeCapacities lists the nodes and groups their allocatable resources by
+// the flavors whose labels they match.
+func (s *Scheduler) listNodeCapacities(ctx context.Context, flavors map[string]*kueue.ResourceFlavor) (nodeCapacities, error) {
+	var nodes corev1.NodeList
+	if err := s.client.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	caps := make(nodeCapacities, len(flavors))
+	for name, flavor := range flavors {
+		selector := labels.SelectorFromSet(flavor.Labels)
+		caps[name] = []workload.Requests{}
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if node.Spec.Unschedulable || !selector.Matches(labels.Set(node.Labels)) {
+				continue
+			}
+			allocatable := make(workload.Requests, len(node.Status.Allocatable))
+			for r, q := range node.Status.Allocatable {
+				allocatable[r] = workload.ResourceValue(r, q)
+			}
+			caps[name] = append(caps[name], allocatable)
+		}
+	}
+	return caps, nil
+}
+
+// fitsAnyNode returns whether a single pod, with the given requests, fits in
+// the allocatable resources of at least one node of the flavor.
+// It returns true if the capacities are unknown.
+func (c nodeCapacities) fitsAnyNode(flavor string, podRequests workload.Requests) bool {
+	if c == nil {
+		return true
+	}
+	for _, allocatable := range c[flavor] {
+		fits := true
+		for r, v := range podRequests {
+			if v > allocatable[r] {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestListNodeCapacities(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding core scheme: %v", err)
+	}
+	node := func(name string, labels map[string]string, cpu string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		node("a", map[string]string{"type": "spot"}, "2", false),
+		node("b", map[string]string{"type": "spot"}, "8", true),
+		node("c", map[string]string{"type": "on-demand"}, "4", false),
+	).Build()
+	flavors := map[string]*kueue.ResourceFlavor{
+		"default": {ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		"spot": {ObjectMeta: metav1.ObjectMeta{
+			Name:   "spot",
+			Labels: map[string]string{"type": "spot"},
+		}},
+		"gpu": {ObjectMeta: metav1.ObjectMeta{
+			Name:   "gpu",
+			Labels: map[string]string{"type": "gpu"},
+		}},
+	}
+	s := &Scheduler{client: cl}
+	got, err := s.listNodeCapacities(context.Background(), flavors)
+	if err != nil {
+		t.Fatalf("listNodeCapacities failed: %v", err)
+	}
+	want := nodeCapacities{
+		"default": {{corev1.ResourceCPU: 2_000}, {corev1.ResourceCPU: 4_000}},
+		"spot":    {{corev1.ResourceCPU: 2_000}},
+		"gpu":     {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected node capacities (-want,+got):\n%s", diff)
+	}
+}
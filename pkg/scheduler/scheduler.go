@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -32,53 +33,189 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
 	"sigs.k8s.io/kueue/pkg/util/api"
+	"sigs.k8s.io/kueue/pkg/util/events"
+	"sigs.k8s.io/kueue/pkg/util/logging"
 	"sigs.k8s.io/kueue/pkg/util/routine"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const (
 	errCouldNotAdmitWL = "Could not admit Workload and assign flavors in apiserver"
+
+	// pendingEventsPeriod is the minimum time between two identical Pending
+	// events for the same workload.
+	pendingEventsPeriod = 5 * time.Minute
+
+	// defaultAdmissionConcurrency is the maximum number of admissions applied
+	// in the apiserver at the same time, unless configured otherwise.
+	defaultAdmissionConcurrency = 10
 )
 
+// admissionBackoff is the backoff between the attempts to apply an admission
+// that failed with a transient error.
+var admissionBackoff = retry.DefaultBackoff
+
 type Scheduler struct {
 	queues                  *queue.Manager
 	cache                   *cache.Cache
 	client                  client.Client
 	recorder                record.EventRecorder
+	pendingEvents           *events.Throttler
 	admissionRoutineWrapper routine.Wrapper
+	nodeCapacityCheck       bool
+	workloadOrdering        workload.Ordering
+	reportedWorkloadLabels  []string
+	auditSink               audit.Sink
+	clock                   clock.Clock
+	// starvation detects the starving workloads, if enabled.
+	starvation *starvationWatchdog
+	// requests memoizes the requests of the workloads computed with the
+	// policies of their ClusterQueues, across scheduling cycles.
+	requests *workload.RequestsCache
+
+	// admissions tracks the admissions being applied, so that the scheduler
+	// doesn't stop before they complete.
+	admissions sync.WaitGroup
 
 	// Stubs.
 	applyAdmission func(context.Context, *kueue.Workload) error
 }
 
-func New(queues *queue.Manager, cache *cache.Cache, cl client.Client, recorder record.EventRecorder) *Scheduler {
+type options struct {
+	nodeCapacityCheck      bool
+	workloadOrdering       workload.Ordering
+	reportedWorkloadLabels []string
+	auditSink              audit.Sink
+	starvationThreshold    time.Duration
+	escalateStarving       bool
+	admissionConcurrency   int
+	clock                  clock.Clock
+}
+
+// Option configures the scheduler.
+type Option func(*options)
+
+// WithNodeCapacityCheck indicates if the scheduler should verify that each
+// pod of a workload fits in at least one node of the assigned flavors.
+func WithNodeCapacityCheck(f bool) Option {
+	return func(o *options) {
+		o.nodeCapacityCheck = f
+	}
+}
+
+// WithWorkloadOrdering sets how the workloads are ordered when several of
+// them can be admitted in the same cycle. By default, evicted workloads are
+// ordered by the time of their last eviction.
+func WithWorkloadOrdering(wo workload.Ordering) Option {
+	return func(o *options) {
+		o.workloadOrdering = wo
+	}
+}
+
+// WithReportedWorkloadLabels sets the keys of the workload labels that are
+// attached to the admission events and metrics.
+func WithReportedWorkloadLabels(keys ...string) Option {
+	return func(o *options) {
+		o.reportedWorkloadLabels = keys
+	}
+}
+
+// WithAuditSink sets the sink where the admissions are recorded.
+func WithAuditSink(sink audit.Sink) Option {
+	return func(o *options) {
+		o.auditSink = sink
+	}
+}
+
+// WithStarvationWatchdog enables the detection of the workloads that have
+// been pending for longer than threshold while other workloads were admitted
+// in their cohort. If escalate is true, the starving workloads are evaluated
+// before the other workloads in each scheduling cycle.
+func WithStarvationWatchdog(threshold time.Duration, escalate bool) Option {
+	return func(o *options) {
+		o.starvationThreshold = threshold
+		o.escalateStarving = escalate
+	}
+}
+
+// WithAdmissionConcurrency sets the maximum number of admissions that are
+// applied in the apiserver at the same time, so that the bursts of workloads
+// admitted in a single cycle don't flood the apiserver. Values lower than 1
+// keep the default.
+func WithAdmissionConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.admissionConcurrency = n
+		}
+	}
+}
+
+// WithClock sets the clock used to time the scheduling cycles and the
+// pending workloads. It's meant to be replaced in tests.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var defaultOptions = options{
+	workloadOrdering:     workload.Ordering{RequeueByEvictionTime: true},
+	admissionConcurrency: defaultAdmissionConcurrency,
+	clock:                clock.RealClock{},
+}
+
+func New(queues *queue.Manager, cache *cache.Cache, cl client.Client, recorder record.EventRecorder, opts ...Option) *Scheduler {
+	options := defaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	s := &Scheduler{
 		queues:                  queues,
 		cache:                   cache,
 		client:                  cl,
 		recorder:                recorder,
-		admissionRoutineWrapper: routine.DefaultWrapper,
+		pendingEvents:           events.NewThrottler(recorder, pendingEventsPeriod, options.clock),
+		admissionRoutineWrapper: routine.NewBoundedWrapper(options.admissionConcurrency),
+		nodeCapacityCheck:       options.nodeCapacityCheck,
+		workloadOrdering:        options.workloadOrdering,
+		reportedWorkloadLabels:  options.reportedWorkloadLabels,
+		auditSink:               options.auditSink,
+		clock:                   options.clock,
+		requests:                workload.NewRequestsCache(),
+	}
+	if options.starvationThreshold > 0 {
+		s.starvation = newStarvationWatchdog(options.starvationThreshold, options.escalateStarving)
 	}
 	s.applyAdmission = s.applyAdmissionWithSSA
 	return s
 }
 
-func (s *Scheduler) Start(ctx context.Context) {
+// Start runs scheduling cycles until the context is done. Then, it waits for
+// the in-flight cycle to finish applying the admissions it already computed,
+// so that none of them is left half-applied.
+func (s *Scheduler) Start(ctx context.Context) error {
 	log := ctrl.LoggerFrom(ctx).WithName("scheduler")
 	ctx = ctrl.LoggerInto(ctx, log)
+	go s.queues.CleanUpOnContext(ctx)
 	wait.UntilWithContext(ctx, s.schedule, 0)
+	log.Info("Waiting for the in-flight admissions before stopping")
+	s.admissions.Wait()
+	return nil
 }
 
 func (s *Scheduler) setAdmissionRoutineWrapper(wrapper routine.Wrapper) {
@@ -95,7 +232,10 @@ func (s *Scheduler) schedule(ctx context.Context) {
 	if len(headWorkloads) == 0 {
 		return
 	}
-	startTime := time.Now()
+	// The rest of the cycle isn't canceled when the scheduler stops, so that
+	// the admissions computed in the cycle are applied.
+	ctx = ctrl.LoggerInto(context.Background(), log)
+	startTime := s.clock.Now()
 
 	// 2. Take a snapshot of the cache.
 	snapshot := s.cache.Snapshot()
@@ -103,9 +243,20 @@ func (s *Scheduler) schedule(ctx context.Context) {
 	// 3. Calculate requirements for admitting workloads (resource flavors, borrowing).
 	// (resource flavors, borrowing).
 	entries := s.nominate(ctx, headWorkloads, snapshot)
+	s.requests.Sweep()
+	if s.starvation != nil {
+		for i := range entries {
+			e := &entries[i]
+			e.starving = s.starvation.isStarving(&e.Info, s.workloadOrdering, cohortKey(&snapshot, e.ClusterQueue), startTime)
+		}
+	}
 
-	// 4. Sort entries based on borrowing and timestamps.
-	sort.Sort(entryOrdering(entries))
+	// 4. Sort entries based on starvation, borrowing and timestamps.
+	sort.Sort(entryOrdering{
+		entries:          entries,
+		workloadOrdering: s.workloadOrdering,
+		escalateStarving: s.starvation != nil && s.starvation.escalate,
+	})
 
 	// 5. Admit entries, ensuring that no more than one workload gets
 	// admitted by a cohort (if borrowing).
@@ -124,7 +275,7 @@ func (s *Scheduler) schedule(ctx context.Context) {
 			e.inadmissibleMsg = "cohort used in this cycle"
 			continue
 		}
-		log := log.WithValues("workload", klog.KObj(e.Obj), "clusterQueue", klog.KRef("", e.ClusterQueue))
+		log := logging.ForObject(log.WithValues("workload", klog.KObj(e.Obj), "clusterQueue", klog.KRef("", e.ClusterQueue)), e.Obj)
 		if err := s.admit(ctrl.LoggerInto(ctx, log), e); err == nil {
 			e.status = assumed
 		} else {
@@ -140,6 +291,7 @@ func (s *Scheduler) schedule(ctx context.Context) {
 	// 6. Requeue the heads that were not scheduled.
 	result := metrics.AdmissionResultInadmissible
 	for _, e := range entries {
+		log := logging.ForObject(log, e.Obj)
 		log.V(3).Info("Workload evaluated for admission",
 			"workload", klog.KObj(e.Obj),
 			"clusterQueue", klog.KRef("", e.ClusterQueue),
@@ -147,11 +299,17 @@ func (s *Scheduler) schedule(ctx context.Context) {
 			"reason", e.inadmissibleMsg)
 		if e.status != assumed {
 			s.requeueAndUpdate(log, ctx, e)
+			if e.starving && s.starvation.shouldReport(&e.Info, startTime) {
+				s.reportStarving(log, &e, cohortKey(&snapshot, e.ClusterQueue))
+			}
 		} else {
 			result = metrics.AdmissionResultSuccess
+			if s.starvation != nil {
+				s.starvation.admitted(&e.Info, cohortKey(&snapshot, e.ClusterQueue), startTime)
+			}
 		}
 	}
-	metrics.AdmissionAttempt(result, time.Since(startTime))
+	metrics.AdmissionAttempt(result, s.clock.Since(startTime))
 }
 
 type entryStatus string
@@ -174,7 +332,13 @@ type entry struct {
 	workload.Info
 	// borrows is the resources that the workload would need to borrow from the
 	// cohort if it was scheduled in the clusterQueue.
-	borrows         cache.ResourceQuantities
+	borrows cache.ResourceQuantities
+	// cost is the total cost of the flavors assigned to the workload, when the
+	// clusterQueue minimizes it.
+	cost *int64
+	// starving indicates that the workload has been pending for too long
+	// while other workloads were admitted in its cohort.
+	starving        bool
 	status          entryStatus
 	inadmissibleMsg string
 	requeueReason   queue.RequeueReason
@@ -185,21 +349,36 @@ type entry struct {
 func (s *Scheduler) nominate(ctx context.Context, workloads []workload.Info, snap cache.Snapshot) []entry {
 	log := ctrl.LoggerFrom(ctx)
 	entries := make([]entry, 0, len(workloads))
+	var nodeCaps nodeCapacities
+	if s.nodeCapacityCheck {
+		var err error
+		if nodeCaps, err = s.listNodeCapacities(ctx, snap.ResourceFlavors); err != nil {
+			log.Error(err, "Skipping the node capacity check")
+		}
+	}
 	for _, w := range workloads {
-		log := log.WithValues("workload", klog.KObj(w.Obj), "clusterQueue", klog.KRef("", w.ClusterQueue))
+		log := logging.ForObject(log.WithValues("workload", klog.KObj(w.Obj), "clusterQueue", klog.KRef("", w.ClusterQueue)), w.Obj)
 		cq := snap.ClusterQueues[w.ClusterQueue]
 		ns := corev1.Namespace{}
 		e := entry{Info: w}
+		if cq != nil {
+			if opts := cq.WorkloadInfoOptions(); len(opts) > 0 {
+				// The requests depend on the policies of the ClusterQueue.
+				e.TotalRequests = s.requests.TotalRequests(w.Obj, opts...)
+			}
+		}
 		if snap.InactiveClusterQueueSets.Has(w.ClusterQueue) {
 			e.inadmissibleMsg = fmt.Sprintf("ClusterQueue %s is inactive", w.ClusterQueue)
 		} else if cq == nil {
 			e.inadmissibleMsg = fmt.Sprintf("ClusterQueue %s not found", w.ClusterQueue)
+		} else if err := workload.CheckLimits(w.Obj); err != nil {
+			e.inadmissibleMsg = fmt.Sprintf("Workload is too large: %v", err)
 		} else if err := s.client.Get(ctx, types.NamespacedName{Name: w.Obj.Namespace}, &ns); err != nil {
 			e.inadmissibleMsg = fmt.Sprintf("Could not obtain workload namespace: %v", err)
 		} else if !cq.NamespaceSelector.Matches(labels.Set(ns.Labels)) {
 			e.inadmissibleMsg = "Workload namespace doesn't match ClusterQueue selector"
 			e.requeueReason = queue.RequeueReasonNamespaceMismatch
-		} else if status := e.assignFlavors(log, snap.ResourceFlavors, cq); !status.IsSuccess() {
+		} else if status := e.assignFlavors(log, snap.ResourceFlavors, cq, ns.Labels, nodeCaps); !status.IsSuccess() {
 			e.inadmissibleMsg = api.TruncateEventMessage(status.Message())
 		} else {
 			e.status = nominated
@@ -253,78 +432,246 @@ func asStatus(err error) *admissionStatus {
 // assignFlavors calculates the flavors that should be assigned to this entry
 // if admitted by this clusterQueue, including details of how much it needs to
 // borrow from the cohort.
+// The quota that the ClusterQueue, or its cohort, reserves for namespaces that
+// don't match nsLabels, the labels of the namespace of the workload, isn't used.
+// If nodeCaps is not nil, each pod must fit in at least one node of the flavors.
 // It returns admissionStatus indicating whether the entry fits. If it doesn't fit,
 // the entry is unmodified.
-func (e *entry) assignFlavors(log logr.Logger, resourceFlavors map[string]*kueue.ResourceFlavor, cq *cache.ClusterQueue) *admissionStatus {
-	flavoredRequests := make([]workload.PodSetResources, 0, len(e.TotalRequests))
-	wUsed := make(cache.ResourceQuantities)
-	wBorrows := make(cache.ResourceQuantities)
+func (e *entry) assignFlavors(log logr.Logger, resourceFlavors map[string]*kueue.ResourceFlavor, cq *cache.ClusterQueue, nsLabels map[string]string, nodeCaps nodeCapacities) *admissionStatus {
+	a := flavorAssigner{
+		log:             log,
+		resourceFlavors: resourceFlavors,
+		cq:              cq,
+		namespace:       e.Obj.Namespace,
+		nsLabels:        nsLabels,
+		nodeCaps:        nodeCaps,
+		wUsed:           make(cache.ResourceQuantities),
+		wBorrows:        make(cache.ResourceQuantities),
+		evictions:       workload.FlavorEvictions(e.Obj),
+	}
+	if e.Obj.Spec.PodSetFlavorPolicy == kueue.PodSetFlavorSame {
+		a.sameFlavors = make(map[corev1.ResourceName]string)
+	}
+	steps, status := a.steps(e)
+	if !status.IsSuccess() {
+		return status
+	}
+	var (
+		chosen []string
+		cost   *int64
+	)
+	if cq.FlavorAssignmentPolicy == kueue.FlavorAssignmentMinimizeCost {
+		var c int64
+		chosen, c, status = a.cheapestFlavors(steps)
+		cost = &c
+	} else if a.sameFlavors != nil {
+		chosen, status = a.sameFlavorsAssignment(steps)
+	}
+	if !status.IsSuccess() {
+		return status
+	}
+	flavoredRequests := make([]workload.PodSetResources, len(e.TotalRequests))
 	for i, podSet := range e.TotalRequests {
-		assignedFlavors := make(map[corev1.ResourceName]string, len(podSet.Requests))
-		for resName := range podSet.Requests {
-			if assignedFlavors[resName] != "" {
-				// This resource got assigned the same flavor as a codependent resource.
-				// No need to compute again.
-				continue
-			}
-			if _, ok := cq.RequestableResources[resName]; !ok {
-				return &admissionStatus{
-					reasons: []string{fmt.Sprintf("resource %s unavailable in ClusterQueue", resName)},
-				}
+		flavoredRequests[i] = workload.PodSetResources{
+			Name:     podSet.Name,
+			Requests: podSet.Requests,
+			Flavors:  make(map[corev1.ResourceName]string, len(podSet.Requests)),
+		}
+	}
+	for i := range steps {
+		step := &steps[i]
+		var requiredFlavor string
+		if chosen != nil {
+			requiredFlavor = chosen[i]
+		}
+		rFlavor, status := a.assign(step, requiredFlavor)
+		if !status.IsSuccess() {
+			status.podSet = step.podSetName
+			return status
+		}
+		for resName := range step.requests {
+			flavoredRequests[step.podSet].Flavors[resName] = rFlavor
+		}
+	}
+	e.TotalRequests = flavoredRequests
+	if len(a.wBorrows) > 0 {
+		e.borrows = a.wBorrows
+	}
+	e.cost = cost
+	return nil
+}
+
+// flavorAssigner keeps track of the flavors assigned to the podSets of a
+// workload, so that the assignment of each podSet considers the usage of the
+// previous ones.
+type flavorAssigner struct {
+	log             logr.Logger
+	resourceFlavors map[string]*kueue.ResourceFlavor
+	cq              *cache.ClusterQueue
+	namespace       string
+	nsLabels        labels.Set
+	nodeCaps        nodeCapacities
+	wUsed           cache.ResourceQuantities
+	wBorrows        cache.ResourceQuantities
+	// evictions is the number of times the workload was evicted while using
+	// each flavor.
+	evictions map[string]int32
+	// sameFlavors holds the flavors that all the podSets are required to use,
+	// when they need to be assigned the same flavor.
+	sameFlavors map[corev1.ResourceName]string
+}
+
+// assignStep is the assignment of a flavor to the codependent resources
+// requested by a podSet.
+type assignStep struct {
+	podSet     int
+	podSetName string
+	requests   workload.Requests
+	// podRequests are the requests of a single pod, if the pods need to fit in
+	// the nodes of the flavor.
+	podRequests workload.Requests
+	spec        *corev1.PodSpec
+}
+
+// steps returns the flavor assignments needed for the podSets of the
+// workload, in order.
+func (a *flavorAssigner) steps(e *entry) ([]assignStep, *admissionStatus) {
+	var steps []assignStep
+	for i, podSet := range e.TotalRequests {
+		base := assignStep{
+			podSet:     i,
+			podSetName: e.Obj.Spec.PodSets[i].Name,
+			spec:       &e.Obj.Spec.PodSets[i].Spec,
+		}
+		var status *admissionStatus
+		steps, status = a.appendSteps(steps, base, podSet.Requests, workload.PodsNeeded(e.Obj, &e.Obj.Spec.PodSets[i]))
+		if !status.IsSuccess() {
+			status.podSet = base.podSetName
+			return nil, status
+		}
+	}
+	return steps, nil
+}
+
+// appendSteps appends a step, based on the given one, for each group of
+// codependent resources in requests, with the given number of pods.
+func (a *flavorAssigner) appendSteps(steps []assignStep, base assignStep, requests workload.Requests, count int32) ([]assignStep, *admissionStatus) {
+	resNames := make([]string, 0, len(requests))
+	for resName := range requests {
+		resNames = append(resNames, string(resName))
+	}
+	sort.Strings(resNames)
+	grouped := sets.NewString()
+	for _, name := range resNames {
+		if grouped.Has(name) {
+			// This resource is in the same step as a codependent resource.
+			continue
+		}
+		resName := corev1.ResourceName(name)
+		res, ok := a.cq.RequestableResources[resName]
+		if !ok {
+			return nil, &admissionStatus{
+				reasons: []string{fmt.Sprintf("resource %s unavailable in ClusterQueue", resName)},
 			}
-			codepResources := cq.RequestableResources[resName].CodependentResources
-			if codepResources.Len() == 0 {
-				codepResources = sets.NewString(string(resName))
+		}
+		codepResources := res.CodependentResources
+		if codepResources.Len() == 0 {
+			codepResources = sets.NewString(name)
+		}
+		step := base
+		step.requests = filterRequestedResources(requests, codepResources)
+		for r := range step.requests {
+			grouped.Insert(string(r))
+		}
+		if a.nodeCaps != nil && count > 0 {
+			step.podRequests = make(workload.Requests, len(step.requests))
+			for r, v := range step.requests {
+				step.podRequests[r] = v / int64(count)
 			}
-			codepReq := filterRequestedResources(podSet.Requests, codepResources)
-			rFlavor, borrows, status := findFlavorForCodepResources(log, codepReq, wUsed, resourceFlavors, cq, &e.Obj.Spec.PodSets[i].Spec)
-			if !status.IsSuccess() {
-				status.podSet = e.Obj.Spec.PodSets[i].Name
-				return status
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// requiredFlavor returns the flavor that the step is required to use, if any.
+func (a *flavorAssigner) requiredFlavor(step *assignStep) string {
+	return requiredFlavorFor(step.requests, a.sameFlavors)
+}
+
+// assign finds the flavor for the step, considering the usage of the previous
+// steps, and accounts for the usage of the step.
+// If requiredFlavor is empty, the flavor required by the step or the previous
+// podSets is used, if any.
+func (a *flavorAssigner) assign(step *assignStep, requiredFlavor string) (string, *admissionStatus) {
+	if len(requiredFlavor) == 0 {
+		requiredFlavor = a.requiredFlavor(step)
+	}
+	rFlavor, borrows, status := findFlavorForCodepResources(a.log, step.requests, a.wUsed, a.resourceFlavors, a.cq, a.namespace, a.nsLabels, step.spec, requiredFlavor, a.nodeCaps, step.podRequests, a.evictions)
+	if !status.IsSuccess() {
+		a.log.V(4).Info("No flavor fits the podSet", "podSet", step.podSetName, "requests", step.requests, "reasons", status.reasons)
+		return "", status
+	}
+	a.log.V(4).Info("Flavor assigned to the podSet", "podSet", step.podSetName, "requests", step.requests, "flavor", rFlavor, "borrows", borrows)
+	for codepRes := range step.requests {
+		if b := borrows[codepRes]; b > 0 {
+			if a.wBorrows[codepRes] == nil {
+				a.wBorrows[codepRes] = make(map[string]int64)
 			}
-			for codepRes, codepVal := range codepReq {
-				if b := borrows[codepRes]; b > 0 {
-					if wBorrows[codepRes] == nil {
-						wBorrows[codepRes] = make(map[string]int64)
-					}
-					// Don't accumulate borrowing. The returned `borrow` already considers
-					// usage from previous pod sets.
-					wBorrows[codepRes][rFlavor] = b
-				}
-				if wUsed[codepRes] == nil {
-					wUsed[codepRes] = make(map[string]int64)
-				}
-				wUsed[codepRes][rFlavor] += codepVal
-				assignedFlavors[codepRes] = rFlavor
+			// Don't accumulate borrowing. The returned `borrow` already considers
+			// usage from previous pod sets.
+			a.wBorrows[codepRes][rFlavor] = b
+		}
+	}
+	a.useFlavor(step.requests, rFlavor)
+	return rFlavor, nil
+}
+
+// useFlavor accounts for the requests using the flavor. It returns the
+// resources that got required to use the flavor in the next podSets.
+func (a *flavorAssigner) useFlavor(requests workload.Requests, flavor string) []corev1.ResourceName {
+	var required []corev1.ResourceName
+	for codepRes, codepVal := range requests {
+		if a.wUsed[codepRes] == nil {
+			a.wUsed[codepRes] = make(map[string]int64)
+		}
+		a.wUsed[codepRes][flavor] += codepVal
+		if a.sameFlavors != nil {
+			if _, ok := a.sameFlavors[codepRes]; !ok {
+				a.sameFlavors[codepRes] = flavor
+				required = append(required, codepRes)
 			}
 		}
-		flavoredRequests = append(flavoredRequests, workload.PodSetResources{
-			Name:     podSet.Name,
-			Requests: podSet.Requests,
-			Flavors:  assignedFlavors,
-		})
 	}
-	e.TotalRequests = flavoredRequests
-	if len(wBorrows) > 0 {
-		e.borrows = wBorrows
+	return required
+}
+
+// releaseFlavor reverts useFlavor.
+func (a *flavorAssigner) releaseFlavor(requests workload.Requests, flavor string, required []corev1.ResourceName) {
+	for codepRes, codepVal := range requests {
+		a.wUsed[codepRes][flavor] -= codepVal
+	}
+	for _, codepRes := range required {
+		delete(a.sameFlavors, codepRes)
 	}
-	return nil
 }
 
 // admit sets the admitting clusterQueue and flavors into the workload of
 // the entry, and asynchronously updates the object in the apiserver after
-// assuming it in the cache.
+// assuming it in the cache. The updates of the workloads admitted in the same
+// cycle are applied concurrently, up to the admission concurrency.
 func (s *Scheduler) admit(ctx context.Context, e *entry) error {
 	log := ctrl.LoggerFrom(ctx)
 	newWorkload := e.Obj.DeepCopy()
 	admission := &kueue.Admission{
 		ClusterQueue:  kueue.ClusterQueueReference(e.ClusterQueue),
 		PodSetFlavors: make([]kueue.PodSetFlavors, len(e.TotalRequests)),
+		Cost:          e.cost,
 	}
-	for i := range e.TotalRequests {
+	for i, ps := range e.TotalRequests {
 		admission.PodSetFlavors[i] = kueue.PodSetFlavors{
 			Name:    e.Obj.Spec.PodSets[i].Name,
-			Flavors: e.TotalRequests[i].Flavors,
+			Flavors: ps.Flavors,
 		}
 	}
 	newWorkload.Spec.Admission = admission
@@ -333,12 +680,21 @@ func (s *Scheduler) admit(ctx context.Context, e *entry) error {
 	}
 	log.V(2).Info("Workload assumed in the cache")
 
+	s.admissions.Add(1)
 	s.admissionRoutineWrapper.Run(func() {
-		err := s.applyAdmission(ctx, workloadAdmissionFrom(newWorkload))
+		defer s.admissions.Done()
+		err := s.applyAdmissionWithRetries(ctx, workloadAdmissionFrom(newWorkload))
 		if err == nil {
-			waitTime := time.Since(e.Obj.CreationTimestamp.Time)
-			s.recorder.Eventf(newWorkload, corev1.EventTypeNormal, "Admitted", "Admitted by ClusterQueue %v, wait time was %.3fs", admission.ClusterQueue, waitTime.Seconds())
-			metrics.AdmittedWorkload(admission.ClusterQueue, waitTime)
+			waitTime := s.clock.Since(e.Obj.CreationTimestamp.Time)
+			wlLabels := workload.SelectLabels(newWorkload, s.reportedWorkloadLabels)
+			msg := fmt.Sprintf("Admitted by ClusterQueue %v, wait time was %.3fs", admission.ClusterQueue, waitTime.Seconds())
+			s.recorder.AnnotatedEventf(newWorkload, wlLabels, corev1.EventTypeNormal, "Admitted", msg)
+			metrics.AdmittedWorkload(admission.ClusterQueue, waitTime, wlLabels)
+			if s.auditSink != nil {
+				if err := s.auditSink.Record(ctx, audit.NewRecord(audit.Admission, newWorkload, "Admitted", msg, s.clock.Now())); err != nil {
+					log.Error(err, "Failed recording the admission in the audit sink")
+				}
+			}
 			log.V(2).Info("Workload successfully admitted and assigned flavors")
 			return
 		}
@@ -357,6 +713,22 @@ func (s *Scheduler) admit(ctx context.Context, e *entry) error {
 	return nil
 }
 
+// applyAdmissionWithRetries applies the admission, retrying with backoff
+// while it fails with transient errors. Other errors, such as conflicts
+// because the workload changed, are returned immediately.
+func (s *Scheduler) applyAdmissionWithRetries(ctx context.Context, w *kueue.Workload) error {
+	return retry.OnError(admissionBackoff, isTransient, func() error {
+		return s.applyAdmission(ctx, w)
+	})
+}
+
+// isTransient returns whether an error of the apiserver is likely to go away
+// when the request is retried.
+func isTransient(err error) bool {
+	return errors.IsServerTimeout(err) || errors.IsTimeout(err) || errors.IsTooManyRequests(err) ||
+		errors.IsServiceUnavailable(err) || errors.IsInternalError(err)
+}
+
 func (s *Scheduler) applyAdmissionWithSSA(ctx context.Context, w *kueue.Workload) error {
 	return s.client.Patch(ctx, w, client.Apply, client.FieldOwner(constants.AdmissionName))
 }
@@ -385,8 +757,24 @@ func workloadAdmissionFrom(w *kueue.Workload) *kueue.Workload {
 	return wlCopy
 }
 
+// requiredFlavorFor returns the flavor that was assigned to previous podSets
+// for any of the requested resources, if any.
+func requiredFlavorFor(requests workload.Requests, requiredFlavors map[corev1.ResourceName]string) string {
+	for name := range requests {
+		if f, ok := requiredFlavors[name]; ok {
+			return f
+		}
+	}
+	return ""
+}
+
 // findFlavorForCodepResources returns a flavor which can satisfy the resource request,
 // given that wUsed is the usage of flavors by previous podsets.
+// Among the flavors that satisfy it, it returns the first with the lowest cost.
+// If requiredFlavor is not empty, only that flavor is considered.
+// The flavor must have a node where a single pod, requesting podRequests, fits.
+// The flavors for which the workload reached the eviction limit are skipped,
+// given how many times it was evicted while using each flavor.
 // If it finds a flavor, also returns any borrowing required.
 func findFlavorForCodepResources(
 	log logr.Logger,
@@ -394,7 +782,58 @@ func findFlavorForCodepResources(
 	wUsed cache.ResourceQuantities,
 	resourceFlavors map[string]*kueue.ResourceFlavor,
 	cq *cache.ClusterQueue,
-	spec *corev1.PodSpec) (string, map[corev1.ResourceName]int64, *admissionStatus) {
+	namespace string,
+	nsLabels labels.Set,
+	spec *corev1.PodSpec,
+	requiredFlavor string,
+	nodeCaps nodeCapacities,
+	podRequests workload.Requests,
+	evictions map[string]int32) (string, map[corev1.ResourceName]int64, *admissionStatus) {
+	fits, status := fittingFlavors(log, requests, wUsed, resourceFlavors, cq, namespace, nsLabels, spec, requiredFlavor, nodeCaps, podRequests, evictions)
+	if !status.IsSuccess() {
+		return "", nil, status
+	}
+	best := fits[0]
+	for _, f := range fits[1:] {
+		if f.cost < best.cost {
+			best = f
+		}
+	}
+	return best.name, best.borrows, nil
+}
+
+// flavorFit is a flavor that can satisfy the requests of codependent
+// resources.
+type flavorFit struct {
+	name string
+	// borrows is the borrowing required for each resource.
+	borrows map[corev1.ResourceName]int64
+	// cost is the sum of the costs of the flavor for each resource.
+	cost int64
+}
+
+// fittingFlavors returns the flavors which can satisfy the resource request,
+// in the order they are listed in the ClusterQueue, given that wUsed is the
+// usage of flavors by previous podsets.
+// If requiredFlavor is not empty, only that flavor is considered.
+// The flavors must have a node where a single pod, requesting podRequests, fits.
+// The flavors for which the workload reached the eviction limit are skipped.
+// The flavors must also fit within the share of the quota of the namespace of
+// the workload, if the ClusterQueue limits the namespaces.
+// If no flavor fits, it returns the reasons in the admissionStatus.
+func fittingFlavors(
+	log logr.Logger,
+	requests workload.Requests,
+	wUsed cache.ResourceQuantities,
+	resourceFlavors map[string]*kueue.ResourceFlavor,
+	cq *cache.ClusterQueue,
+	namespace string,
+	nsLabels labels.Set,
+	spec *corev1.PodSpec,
+	requiredFlavor string,
+	nodeCaps nodeCapacities,
+	podRequests workload.Requests,
+	evictions map[string]int32) ([]flavorFit, *admissionStatus) {
 	var status admissionStatus
 
 	// Keep any resource name as an anchor to gather flavors for.
@@ -406,7 +845,11 @@ func findFlavorForCodepResources(
 	// We will only check against the flavors' labels for the resource.
 	// Since all the resources share the same flavors, they use the same selector.
 	selector := flavorSelector(spec, cq.LabelKeys[rName])
+	var fits []flavorFit
 	for i, flvLimit := range cq.RequestableResources[rName].Flavors {
+		if len(requiredFlavor) > 0 && flvLimit.Name != requiredFlavor {
+			continue
+		}
 		flavor, exist := resourceFlavors[flvLimit.Name]
 		if !exist {
 			log.Error(nil, "Flavor not found", "Flavor", flvLimit.Name)
@@ -422,33 +865,50 @@ func findFlavorForCodepResources(
 		}
 		if match, err := selector.Match(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: flavor.Labels}}); !match || err != nil {
 			if err != nil {
-				return "", nil, asStatus(fmt.Errorf("matching affinity flavor %s: %w", flvLimit.Name, err))
+				return nil, asStatus(fmt.Errorf("matching affinity flavor %s: %w", flvLimit.Name, err))
 			}
 			status.AppendReason(fmt.Sprintf("flavor %s doesn't match with node affinity", flvLimit.Name))
 			continue
 		}
+		if !nodeCaps.fitsAnyNode(flvLimit.Name, podRequests) {
+			status.AppendReason(fmt.Sprintf("no node in flavor %s has enough resources for a single pod", flvLimit.Name))
+			continue
+		}
 
 		fitsAll := true
 		borrows := make(map[corev1.ResourceName]int64, len(requests))
+		var cost int64
 		for name, val := range requests {
 			codepFlvLimit := cq.RequestableResources[name].Flavors[i]
+			cost += int64(codepFlvLimit.Cost)
+			if limit := codepFlvLimit.EvictionLimit; limit > 0 && evictions[flavor.Name] >= limit {
+				fitsAll = false
+				status.AppendReason(fmt.Sprintf("workload was evicted %d times while using flavor %s", evictions[flavor.Name], flavor.Name))
+				break
+			}
 			// Check considering the flavor usage by previous pod sets.
-			borrow, s := fitsFlavorLimits(name, val+wUsed[name][flavor.Name], cq, &codepFlvLimit)
-			if s.IsError() {
-				return "", nil, s
+			borrow, err := cq.FitsFlavorLimits(name, val+wUsed[name][flavor.Name], nsLabels, &codepFlvLimit)
+			if err == nil {
+				err = cq.FitsNamespaceLimit(name, val+wUsed[name][flavor.Name], namespace, &codepFlvLimit)
 			}
-			if !s.IsSuccess() {
+			if err != nil {
 				fitsAll = false
-				status.AppendReason(s.reasons...)
+				status.AppendReason(err.Error())
 				break
 			}
 			borrows[name] = borrow
 		}
 		if fitsAll {
-			return flavor.Name, borrows, nil
+			fits = append(fits, flavorFit{name: flavor.Name, borrows: borrows, cost: cost})
 		}
 	}
-	return "", nil, &status
+	if len(fits) > 0 {
+		return fits, nil
+	}
+	if len(requiredFlavor) > 0 {
+		status.AppendReason(fmt.Sprintf("flavor %s, assigned to other podSets, can't be used", requiredFlavor))
+	}
+	return nil, &status
 }
 
 func flavorSelector(spec *corev1.PodSpec, allowedKeys sets.String) nodeaffinity.RequiredNodeAffinity {
@@ -497,56 +957,31 @@ func flavorSelector(spec *corev1.PodSpec, allowedKeys sets.String) nodeaffinity.
 	return nodeaffinity.GetRequiredNodeAffinity(&corev1.Pod{Spec: specCopy})
 }
 
-// fitsFlavorLimits returns whether a requested resource fits in a specific flavor's quota limits.
-// If it fits, also returns any borrowing required.
-func fitsFlavorLimits(rName corev1.ResourceName, val int64, cq *cache.ClusterQueue, flavor *cache.FlavorLimits) (int64, *admissionStatus) {
-	var status admissionStatus
-	used := cq.UsedResources[rName][flavor.Name]
-	if flavor.Max != nil && used+val > *flavor.Max {
-		status.AppendReason(fmt.Sprintf("borrowing limit for %s flavor %s exceeded", rName, flavor.Name))
-		return 0, &status
-	}
-	cohortUsed := used
-	cohortTotal := flavor.Min
-	if cq.Cohort != nil {
-		cohortUsed = cq.Cohort.UsedResources[rName][flavor.Name]
-		cohortTotal = cq.Cohort.RequestableResources[rName][flavor.Name]
-	}
-	borrow := used + val - flavor.Min
-	if borrow < 0 {
-		borrow = 0
-	}
-
-	lack := cohortUsed + val - cohortTotal
-	if lack > 0 {
-		lackQuantity := workload.ResourceQuantity(rName, lack)
-		if cq.Cohort == nil {
-			status.AppendReason(fmt.Sprintf("insufficient quota for %s flavor %s, %s more needed", rName, flavor.Name, &lackQuantity))
-		} else {
-			status.AppendReason(fmt.Sprintf("insufficient quota for %s flavor %s, %s more needed after borrowing", rName, flavor.Name, &lackQuantity))
-		}
-		// TODO(PostMVP): preemption could help if borrow == 0
-		return 0, &status
-	}
-	return borrow, nil
+type entryOrdering struct {
+	entries          []entry
+	workloadOrdering workload.Ordering
+	escalateStarving bool
 }
 
-type entryOrdering []entry
-
 func (e entryOrdering) Len() int {
-	return len(e)
+	return len(e.entries)
 }
 
 func (e entryOrdering) Swap(i, j int) {
-	e[i], e[j] = e[j], e[i]
+	e.entries[i], e.entries[j] = e.entries[j], e.entries[i]
 }
 
 // Less is the ordering criteria:
+// 0. starving workloads first, if they are escalated.
 // 1. request under min quota before borrowing.
-// 2. FIFO on creation timestamp.
+// 2. FIFO on the queue order timestamp.
 func (e entryOrdering) Less(i, j int) bool {
-	a := e[i]
-	b := e[j]
+	a := e.entries[i]
+	b := e.entries[j]
+	// 0. Starving workloads.
+	if e.escalateStarving && a.starving != b.starving {
+		return a.starving
+	}
 	// 1. Request under min quota.
 	aMin := len(a.borrows) == 0
 	bMin := len(b.borrows) == 0
@@ -554,7 +989,7 @@ func (e entryOrdering) Less(i, j int) bool {
 		return aMin
 	}
 	// 2. FIFO.
-	return a.Obj.CreationTimestamp.Before(&b.Obj.CreationTimestamp)
+	return e.workloadOrdering.QueueOrderTimestamp(a.Obj).Before(e.workloadOrdering.QueueOrderTimestamp(b.Obj))
 }
 
 func (s *Scheduler) requeueAndUpdate(log logr.Logger, ctx context.Context, e entry) {
@@ -570,10 +1005,20 @@ func (s *Scheduler) requeueAndUpdate(log logr.Logger, ctx context.Context, e ent
 		if err != nil {
 			log.Error(err, "Could not update Workload status")
 		}
-		s.recorder.Eventf(e.Obj, corev1.EventTypeNormal, "Pending", e.inadmissibleMsg)
+		s.pendingEvents.Eventf(e.Obj, corev1.EventTypeNormal, "Pending", e.inadmissibleMsg)
 	}
 }
 
+// reportStarving records an event and a metric for a workload that was found
+// starving.
+func (s *Scheduler) reportStarving(log logr.Logger, e *entry, cohort string) {
+	pending := s.clock.Since(s.workloadOrdering.QueueOrderTimestamp(e.Obj).Time).Truncate(time.Second)
+	log.V(2).Info("Workload is starving", "workload", klog.KObj(e.Obj), "clusterQueue", e.ClusterQueue, "pending", pending)
+	s.recorder.Eventf(e.Obj, corev1.EventTypeWarning, "Starving",
+		"Pending for %s while other workloads were admitted in cohort %s: %s", pending, cohort, e.inadmissibleMsg)
+	metrics.ReportStarvingWorkload(e.ClusterQueue)
+}
+
 func filterRequestedResources(req workload.Requests, allowList sets.String) workload.Requests {
 	filtered := make(workload.Requests)
 	for n, v := range req {
@@ -28,16 +28,20 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/queue"
@@ -250,8 +254,10 @@ func TestSchedule(t *testing.T) {
 			},
 		},
 	}
+	now := time.Now()
 	cases := map[string]struct {
-		workloads []kueue.Workload
+		workloads    []kueue.Workload
+		reservations []*kueue.Reservation
 		// wantAssignments is a summary of all the admissions in the cache after this cycle.
 		wantAssignments map[string]kueue.Admission
 		// wantScheduled is the subset of workloads that got scheduled/admitted in this cycle.
@@ -297,6 +303,77 @@ func TestSchedule(t *testing.T) {
 			},
 			wantScheduled: []string{"sales/foo"},
 		},
+		"reserved quota is not available": {
+			workloads: []kueue.Workload{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "sales",
+						Name:      "foo",
+					},
+					Spec: kueue.WorkloadSpec{
+						QueueName: "main",
+						PodSets: []kueue.PodSet{
+							{
+								Name:  "one",
+								Count: 10,
+								Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+									corev1.ResourceCPU: "1",
+								}),
+							},
+						},
+					},
+				},
+			},
+			reservations: []*kueue.Reservation{
+				builder.MakeReservation("maintenance", "sales", now.Add(-time.Hour), now.Add(time.Hour)).
+					Resource(corev1.ResourceCPU, "default", "45").
+					Obj(),
+			},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("foo"),
+			},
+		},
+		"future reservation doesn't hold quota": {
+			workloads: []kueue.Workload{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "sales",
+						Name:      "foo",
+					},
+					Spec: kueue.WorkloadSpec{
+						QueueName: "main",
+						PodSets: []kueue.PodSet{
+							{
+								Name:  "one",
+								Count: 10,
+								Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+									corev1.ResourceCPU: "1",
+								}),
+							},
+						},
+					},
+				},
+			},
+			reservations: []*kueue.Reservation{
+				builder.MakeReservation("maintenance", "sales", now.Add(time.Hour), now.Add(2*time.Hour)).
+					Resource(corev1.ResourceCPU, "default", "45").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/foo": {
+					ClusterQueue: "sales",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "one",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "default",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"sales/foo"},
+		},
 		"single clusterQueue full": {
 			workloads: []kueue.Workload{
 				{
@@ -817,6 +894,9 @@ func TestSchedule(t *testing.T) {
 					t.Fatalf("Inserting clusterQueue %s in manager: %v", cq.Name, err)
 				}
 			}
+			for _, r := range tc.reservations {
+				cqCache.AddOrUpdateReservation(r)
+			}
 			scheduler := New(qManager, cqCache, cl, recorder)
 			gotScheduled := make(map[string]kueue.Admission)
 			var mu sync.Mutex
@@ -919,12 +999,17 @@ func TestEntryAssignFlavors(t *testing.T) {
 	}
 
 	cases := map[string]struct {
-		wlPods       []kueue.PodSet
-		clusterQueue cache.ClusterQueue
-		wantFits     bool
-		wantFlavors  map[string]map[corev1.ResourceName]string
-		wantBorrows  cache.ResourceQuantities
-		wantMsg      string
+		wlPods             []kueue.PodSet
+		podSetFlavorPolicy kueue.PodSetFlavorPolicy
+		flavorEvictions    []kueue.FlavorEvictions
+		clusterQueue       cache.ClusterQueue
+		nsLabels           map[string]string
+		wantFits           bool
+		wantFlavors        map[string]map[corev1.ResourceName]string
+		nodeCapacities     nodeCapacities
+		wantBorrows        cache.ResourceQuantities
+		wantCost           *int64
+		wantMsg            string
 	}{
 		"single flavor, fits": {
 			wlPods: []kueue.PodSet{
@@ -1009,6 +1094,219 @@ func TestEntryAssignFlavors(t *testing.T) {
 			},
 			wantMsg: "insufficient quota for cpu flavor default, 1 more needed",
 		},
+		"budget of the first flavor exhausted": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 2000},
+							{Name: "two", Min: 2000},
+						},
+					},
+				},
+				BudgetExhausted: map[corev1.ResourceName]sets.String{
+					corev1.ResourceCPU: sets.NewString("one"),
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"budget exhausted, doesn't fit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {Flavors: []cache.FlavorLimits{{Name: "default", Min: 4000}}},
+				},
+				BudgetExhausted: map[corev1.ResourceName]sets.String{
+					corev1.ResourceCPU: sets.NewString("default"),
+				},
+			},
+			wantMsg: "budget for cpu flavor default exhausted",
+		},
+		"first flavor reserved for the namespace": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "2",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{
+								Name:        "one",
+								Min:         4000,
+								Reserved:    3000,
+								ReservedFor: labels.SelectorFromSet(labels.Set{"team": "a"}),
+							},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+			},
+			nsLabels: map[string]string{"team": "a"},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "one",
+				},
+			},
+		},
+		"first flavor reserved for other namespaces": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "2",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{
+								Name:        "one",
+								Min:         4000,
+								Reserved:    3000,
+								ReservedFor: labels.SelectorFromSet(labels.Set{"team": "a"}),
+							},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+			},
+			nsLabels: map[string]string{"team": "b"},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"first flavor reserved for other namespaces that use part of it": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{
+								Name:        "one",
+								Min:         4000,
+								Reserved:    3000,
+								ReservedFor: labels.SelectorFromSet(labels.Set{"team": "a"}),
+							},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+				UsedResources: cache.ResourceQuantities{
+					corev1.ResourceCPU: {"one": 2000},
+				},
+				ReservedUsage: cache.ResourceQuantities{
+					corev1.ResourceCPU: {"one": 2000},
+				},
+			},
+			nsLabels: map[string]string{"team": "b"},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "one",
+				},
+			},
+		},
+		"namespace share of the first flavor used": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 4000},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+				UsedResources: cache.ResourceQuantities{
+					corev1.ResourceCPU: {"one": 2_000},
+				},
+				MaxNamespaceUsagePercentage: pointer.Int32(50),
+				NamespaceUsage: map[string]cache.ResourceQuantities{
+					"ns": {corev1.ResourceCPU: {"one": 2_000}},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"namespace share exceeded, doesn't fit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "2",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {Flavors: []cache.FlavorLimits{{Name: "default", Min: 4000}}},
+				},
+				UsedResources: cache.ResourceQuantities{
+					corev1.ResourceCPU: {"default": 1_000},
+				},
+				MaxNamespaceUsagePercentage: pointer.Int32(50),
+				NamespaceUsage: map[string]cache.ResourceQuantities{
+					"ns": {corev1.ResourceCPU: {"default": 1_000}},
+				},
+			},
+			wantMsg: "namespace ns would exceed its 50% share of the quota for cpu flavor default",
+		},
 		"multiple independent flavors, fits": {
 			wlPods: []kueue.PodSet{
 				{
@@ -1143,13 +1441,14 @@ func TestEntryAssignFlavors(t *testing.T) {
 				},
 			},
 		},
-		"multiple flavors, skip missing ResourceFlavor": {
+		"multiple flavors, fits the cheapest flavor": {
 			wlPods: []kueue.PodSet{
 				{
 					Count: 1,
 					Name:  "main",
 					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
-						corev1.ResourceCPU: "3",
+						corev1.ResourceCPU:    "3",
+						corev1.ResourceMemory: "10Mi",
 					}),
 				},
 			},
@@ -1157,8 +1456,18 @@ func TestEntryAssignFlavors(t *testing.T) {
 				RequestableResources: map[corev1.ResourceName]*cache.Resource{
 					corev1.ResourceCPU: {
 						Flavors: []cache.FlavorLimits{
-							{Name: "non-existent", Min: 4000},
-							{Name: "two", Min: 4000},
+							{Name: "default", Min: 2000, Cost: 1},
+							{Name: "one", Min: 4000, Cost: 10},
+							{Name: "two", Min: 4000, Cost: 5},
+							{Name: "b_one", Min: 4000, Cost: 1},
+						},
+					},
+					corev1.ResourceMemory: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "default", Min: utiltesting.Mi},
+							{Name: "one", Min: utiltesting.Gi},
+							{Name: "two", Min: utiltesting.Gi, Cost: 1},
+							{Name: "b_one", Min: utiltesting.Gi, Cost: 5},
 						},
 					},
 				},
@@ -1166,20 +1475,48 @@ func TestEntryAssignFlavors(t *testing.T) {
 			wantFits: true,
 			wantFlavors: map[string]map[corev1.ResourceName]string{
 				"main": {
-					corev1.ResourceCPU: "two",
+					corev1.ResourceCPU:    "two",
+					corev1.ResourceMemory: "two",
 				},
 			},
 		},
-		"multiple flavors, fits a node selector": {
+		"multiple flavors, skip missing ResourceFlavor": {
 			wlPods: []kueue.PodSet{
 				{
 					Count: 1,
 					Name:  "main",
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{
-							{
-								Resources: corev1.ResourceRequirements{
-									Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "non-existent", Min: 4000},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"multiple flavors, fits a node selector": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
 								},
 							},
 						},
@@ -1621,6 +1958,374 @@ func TestEntryAssignFlavors(t *testing.T) {
 			},
 			wantMsg: "flavor nonexistent-flavor not found",
 		},
+		"multiple podSets, independent flavors": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "driver",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+				{
+					Count: 3,
+					Name:  "workers",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 1000},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"driver": {
+					corev1.ResourceCPU: "one",
+				},
+				"workers": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"multiple podSets, same flavor required, fits in a later flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "driver",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+				{
+					Count: 3,
+					Name:  "workers",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			podSetFlavorPolicy: kueue.PodSetFlavorSame,
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 1000},
+							{Name: "two", Min: 4000},
+						},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"driver": {
+					corev1.ResourceCPU: "two",
+				},
+				"workers": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"multiple podSets, same flavor required, doesn't fit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "driver",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+				{
+					Count: 3,
+					Name:  "workers",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			podSetFlavorPolicy: kueue.PodSetFlavorSame,
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 1000},
+							{Name: "two", Min: 3000},
+						},
+					},
+				},
+			},
+			wantMsg: "flavor one, assigned to other podSets, can't be used",
+		},
+		"minimize cost, fits podSets that don't fit one at a time": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "small",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+				{
+					Count: 1,
+					Name:  "big",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "3",
+						corev1.ResourceMemory: "1Gi",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				FlavorAssignmentPolicy: kueue.FlavorAssignmentMinimizeCost,
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						CodependentResources: sets.NewString("cpu", "memory"),
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 3000, Cost: 1},
+							{Name: "two", Min: 3000, Cost: 2},
+						},
+					},
+					corev1.ResourceMemory: {
+						CodependentResources: sets.NewString("cpu", "memory"),
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: utiltesting.Gi, Cost: 1},
+							{Name: "two", Min: 0, Cost: 1},
+						},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"small": {
+					corev1.ResourceCPU: "two",
+				},
+				"big": {
+					corev1.ResourceCPU:    "one",
+					corev1.ResourceMemory: "one",
+				},
+			},
+			wantCost: pointer.Int64(4),
+		},
+		"one podSet at a time, doesn't fit podSets that fit minimizing cost": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "small",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+				{
+					Count: 1,
+					Name:  "big",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "3",
+						corev1.ResourceMemory: "1Gi",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						CodependentResources: sets.NewString("cpu", "memory"),
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 3000, Cost: 1},
+							{Name: "two", Min: 3000, Cost: 2},
+						},
+					},
+					corev1.ResourceMemory: {
+						CodependentResources: sets.NewString("cpu", "memory"),
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: utiltesting.Gi, Cost: 1},
+							{Name: "two", Min: 0, Cost: 1},
+						},
+					},
+				},
+			},
+			wantMsg: "insufficient quota for cpu flavor one, 1 more needed",
+		},
+		"minimize cost, borrows in a cheaper flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				FlavorAssignmentPolicy: kueue.FlavorAssignmentMinimizeCost,
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 4000, Cost: 5},
+							{Name: "two", Min: 0, Cost: 1},
+						},
+					},
+				},
+				Cohort: &cache.Cohort{
+					RequestableResources: cache.ResourceQuantities{
+						corev1.ResourceCPU: {"one": 4000, "two": 10_000},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+			wantBorrows: cache.ResourceQuantities{
+				corev1.ResourceCPU: {"two": 3000},
+			},
+			wantCost: pointer.Int64(1),
+		},
+		"minimize cost, doesn't fit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				FlavorAssignmentPolicy: kueue.FlavorAssignmentMinimizeCost,
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 2000, Cost: 1},
+						},
+					},
+				},
+			},
+			wantMsg: "insufficient quota for cpu flavor one, 1 more needed",
+		},
+		"multiple flavors, skips flavor after reaching the eviction limit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "1",
+						corev1.ResourceMemory: "1Mi",
+					}),
+				},
+			},
+			flavorEvictions: []kueue.FlavorEvictions{
+				{Name: "one", Count: 2},
+				{Name: "two", Count: 5},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 4000, EvictionLimit: 2},
+							{Name: "two", Min: 4000},
+						},
+					},
+					corev1.ResourceMemory: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: utiltesting.Gi},
+							{Name: "two", Min: utiltesting.Gi},
+						},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU:    "two",
+					corev1.ResourceMemory: "two",
+				},
+			},
+		},
+		"multiple flavors, doesn't fit after reaching the eviction limit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			flavorEvictions: []kueue.FlavorEvictions{
+				{Name: "one", Count: 3},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {
+						Flavors: []cache.FlavorLimits{
+							{Name: "one", Min: 4000, EvictionLimit: 3},
+						},
+					},
+				},
+			},
+			wantMsg: "workload was evicted 3 times while using flavor one",
+		},
+		"node capacity check, first flavor's nodes too small": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 2,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU: {Flavors: []cache.FlavorLimits{
+						{Name: "one", Min: 10_000},
+						{Name: "two", Min: 10_000},
+					}},
+				},
+			},
+			nodeCapacities: nodeCapacities{
+				"one": {{corev1.ResourceCPU: 2_000}, {corev1.ResourceCPU: 2_000}},
+				"two": {{corev1.ResourceCPU: 4_000}},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"node capacity check, no node big enough": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "3",
+						corev1.ResourceMemory: "1Gi",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName]*cache.Resource{
+					corev1.ResourceCPU:    {Flavors: []cache.FlavorLimits{{Name: "default", Min: 10_000}}},
+					corev1.ResourceMemory: {Flavors: []cache.FlavorLimits{{Name: "default", Min: 10 * utiltesting.Gi}}},
+				},
+			},
+			nodeCapacities: nodeCapacities{
+				"default": {
+					{corev1.ResourceCPU: 4_000, corev1.ResourceMemory: 512 * utiltesting.Mi},
+					{corev1.ResourceCPU: 2_000, corev1.ResourceMemory: 2 * utiltesting.Gi},
+				},
+			},
+			wantMsg: "no node in flavor default has enough resources for a single pod",
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -1630,13 +2335,18 @@ func TestEntryAssignFlavors(t *testing.T) {
 			tc.clusterQueue.UpdateCodependentResources()
 			e := entry{
 				Info: *workload.NewInfo(&kueue.Workload{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
 					Spec: kueue.WorkloadSpec{
-						PodSets: tc.wlPods,
+						PodSets:            tc.wlPods,
+						PodSetFlavorPolicy: tc.podSetFlavorPolicy,
+					},
+					Status: kueue.WorkloadStatus{
+						FlavorEvictions: tc.flavorEvictions,
 					},
 				}),
 			}
 			tc.clusterQueue.UpdateWithFlavors(resourceFlavors)
-			status := e.assignFlavors(log, resourceFlavors, &tc.clusterQueue)
+			status := e.assignFlavors(log, resourceFlavors, &tc.clusterQueue, tc.nsLabels, tc.nodeCapacities)
 			if status.IsSuccess() != tc.wantFits {
 				t.Errorf("e.assignFlavors(_)=%t, want %t", status.IsSuccess(), tc.wantFits)
 			}
@@ -1658,6 +2368,9 @@ func TestEntryAssignFlavors(t *testing.T) {
 			if diff := cmp.Diff(tc.wantBorrows, e.borrows); diff != "" {
 				t.Errorf("Calculated unexpected borrowing (-want,+got):\n%s", diff)
 			}
+			if diff := cmp.Diff(tc.wantCost, e.cost); diff != "" {
+				t.Errorf("Calculated unexpected cost (-want,+got):\n%s", diff)
+			}
 		})
 	}
 }
@@ -1704,7 +2417,7 @@ func TestEntryOrdering(t *testing.T) {
 			},
 		},
 	}
-	sort.Sort(entryOrdering(input))
+	sort.Sort(entryOrdering{entries: input})
 	order := make([]string, len(input))
 	for i, e := range input {
 		order[i] = e.Obj.Name
@@ -1718,9 +2431,9 @@ func TestEntryOrdering(t *testing.T) {
 var ignoreConditionTimestamps = cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")
 
 func TestRequeueAndUpdate(t *testing.T) {
-	cq := utiltesting.MakeClusterQueue("cq").Obj()
-	q1 := utiltesting.MakeLocalQueue("q1", "ns1").ClusterQueue(cq.Name).Obj()
-	w1 := utiltesting.MakeWorkload("w1", "ns1").Queue(q1.Name).Obj()
+	cq := builder.MakeClusterQueue("cq").Obj()
+	q1 := builder.MakeLocalQueue("q1", "ns1").ClusterQueue(cq.Name).Obj()
+	w1 := builder.MakeWorkload("w1", "ns1").Queue(q1.Name).Obj()
 
 	cases := []struct {
 		name          string
@@ -1833,3 +2546,179 @@ func TestRequeueAndUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestStartFinishesInFlightAdmissions(t *testing.T) {
+	log := testr.NewWithOptions(t, testr.Options{
+		Verbosity: 2,
+	})
+	ctx := ctrl.LoggerInto(context.Background(), log)
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cq := builder.MakeClusterQueue("cq").
+		Resource(builder.MakeResource(corev1.ResourceCPU).
+			Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+		Obj()
+	q := builder.MakeLocalQueue("q", "ns").ClusterQueue(cq.Name).Obj()
+	wl := builder.MakeWorkload("wl", "ns").Queue(q.Name).Request(corev1.ResourceCPU, "1").Obj()
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(wl, q, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}).
+		Build()
+	broadcaster := record.NewBroadcaster()
+	recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: constants.AdmissionName})
+	cqCache := cache.New(cl)
+	qManager := queue.NewManager(cl, cqCache)
+	cqCache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+	if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Inserting clusterQueue %s in cache: %v", cq.Name, err)
+	}
+	if err := qManager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Inserting clusterQueue %s in manager: %v", cq.Name, err)
+	}
+	if err := qManager.AddLocalQueue(ctx, q); err != nil {
+		t.Fatalf("Inserting queue %s/%s in manager: %v", q.Namespace, q.Name, err)
+	}
+
+	scheduler := New(qManager, cqCache, cl, recorder)
+	admitting := make(chan struct{})
+	release := make(chan struct{})
+	var admissionErr error
+	scheduler.applyAdmission = func(ctx context.Context, w *kueue.Workload) error {
+		close(admitting)
+		<-release
+		admissionErr = ctx.Err()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		_ = scheduler.Start(ctx)
+		close(stopped)
+	}()
+
+	<-admitting
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("The scheduler stopped before the in-flight admission finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(queueingTimeout):
+		t.Fatal("The scheduler didn't stop after the in-flight admission finished")
+	}
+	if admissionErr != nil {
+		t.Errorf("The admission was applied with a canceled context: %v", admissionErr)
+	}
+}
+
+func TestAdmissionRetries(t *testing.T) {
+	gr := kueue.GroupVersion.WithResource("workloads").GroupResource()
+	cases := map[string]struct {
+		errs         []error
+		wantAttempts int
+		wantAdmitted bool
+	}{
+		"succeeds": {
+			wantAttempts: 1,
+			wantAdmitted: true,
+		},
+		"succeeds after transient errors": {
+			errs: []error{
+				errors.NewTooManyRequests("slow down", 1),
+				errors.NewServerTimeout(gr, "patch", 1),
+			},
+			wantAttempts: 3,
+			wantAdmitted: true,
+		},
+		"conflict isn't retried": {
+			errs: []error{
+				errors.NewConflict(gr, "wl", nil),
+			},
+			wantAttempts: 1,
+		},
+		"transient errors until giving up": {
+			errs: []error{
+				errors.NewServiceUnavailable("unavailable"),
+				errors.NewServiceUnavailable("unavailable"),
+				errors.NewServiceUnavailable("unavailable"),
+				errors.NewServiceUnavailable("unavailable"),
+				errors.NewServiceUnavailable("unavailable"),
+			},
+			wantAttempts: admissionBackoff.Steps,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			cq := builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor("default", "10").Obj()).Obj()).
+				Obj()
+			q := builder.MakeLocalQueue("q", "ns").ClusterQueue(cq.Name).Obj()
+			wl := builder.MakeWorkload("wl", "ns").Queue(q.Name).Request(corev1.ResourceCPU, "1").Obj()
+			cl := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(wl, q, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}).
+				Build()
+			broadcaster := record.NewBroadcaster()
+			recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: constants.AdmissionName})
+			cqCache := cache.New(cl)
+			qManager := queue.NewManager(cl, cqCache)
+			cqCache.AddOrUpdateResourceFlavor(builder.MakeResourceFlavor("default").Obj())
+			if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Inserting clusterQueue %s in cache: %v", cq.Name, err)
+			}
+			if err := qManager.AddClusterQueue(ctx, cq); err != nil {
+				t.Fatalf("Inserting clusterQueue %s in manager: %v", cq.Name, err)
+			}
+			if err := qManager.AddLocalQueue(ctx, q); err != nil {
+				t.Fatalf("Inserting queue %s/%s in manager: %v", q.Namespace, q.Name, err)
+			}
+
+			scheduler := New(qManager, cqCache, cl, recorder)
+			attempts := 0
+			scheduler.applyAdmission = func(ctx context.Context, w *kueue.Workload) error {
+				attempts++
+				if attempts <= len(tc.errs) {
+					return tc.errs[attempts-1]
+				}
+				return nil
+			}
+			wg := sync.WaitGroup{}
+			scheduler.setAdmissionRoutineWrapper(routine.NewWrapper(
+				func() { wg.Add(1) },
+				func() { wg.Done() },
+			))
+			defer func(b wait.Backoff) { admissionBackoff = b }(admissionBackoff)
+			admissionBackoff.Duration = time.Millisecond
+
+			ctx, cancel := context.WithTimeout(ctx, queueingTimeout)
+			go qManager.CleanUpOnContext(ctx)
+			defer cancel()
+			scheduler.schedule(ctx)
+			wg.Wait()
+
+			if attempts != tc.wantAttempts {
+				t.Errorf("Applied the admission %d times, want %d", attempts, tc.wantAttempts)
+			}
+			_, admitted := cqCache.Snapshot().ClusterQueues[cq.Name].Workloads[workload.Key(wl)]
+			if admitted != tc.wantAdmitted {
+				t.Errorf("Workload admitted in the cache: %t, want %t", admitted, tc.wantAdmitted)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// starvationWatchdog detects the workloads that are starving: they have been
+// pending for longer than a threshold while other workloads were admitted in
+// their cohort. It's only used from the scheduling loop.
+type starvationWatchdog struct {
+	threshold time.Duration
+	// escalate indicates that the starving workloads are evaluated before
+	// the other workloads of the cycle.
+	escalate bool
+
+	// lastAdmission is the time of the last admission in each cohort, or in
+	// each ClusterQueue without a cohort.
+	lastAdmission map[string]time.Time
+	// starving holds the workloads detected as starving, with the last time
+	// they were evaluated while starving, so that each of them is reported
+	// once while it keeps starving.
+	starving map[types.UID]time.Time
+}
+
+func newStarvationWatchdog(threshold time.Duration, escalate bool) *starvationWatchdog {
+	return &starvationWatchdog{
+		threshold:     threshold,
+		escalate:      escalate,
+		lastAdmission: make(map[string]time.Time),
+		starving:      make(map[types.UID]time.Time),
+	}
+}
+
+// cohortKey returns the name of the cohort of the ClusterQueue, or the name
+// of the ClusterQueue if it doesn't belong to a cohort.
+func cohortKey(snap *cache.Snapshot, cqName string) string {
+	if cq := snap.ClusterQueues[cqName]; cq != nil && cq.Cohort != nil {
+		return cq.Cohort.Name
+	}
+	return cqName
+}
+
+// isStarving returns whether the workload has been pending for longer than
+// the threshold and other workloads were admitted in the cohort after it was
+// queued.
+func (w *starvationWatchdog) isStarving(wl *workload.Info, ordering workload.Ordering, cohort string, now time.Time) bool {
+	queued := ordering.QueueOrderTimestamp(wl.Obj).Time
+	if now.Sub(queued) < w.threshold {
+		return false
+	}
+	last, ok := w.lastAdmission[cohort]
+	return ok && last.After(queued)
+}
+
+// admitted records an admission in the cohort.
+func (w *starvationWatchdog) admitted(wl *workload.Info, cohort string, now time.Time) {
+	w.lastAdmission[cohort] = now
+	delete(w.starving, wl.Obj.UID)
+}
+
+// shouldReport records that the workload is starving and returns whether it
+// wasn't already reported. The workloads that weren't evaluated while
+// starving for the threshold are forgotten, and reported again if they are
+// found starving later.
+func (w *starvationWatchdog) shouldReport(wl *workload.Info, now time.Time) bool {
+	for uid, seen := range w.starving {
+		if now.Sub(seen) >= w.threshold {
+			delete(w.starving, uid)
+		}
+	}
+	_, reported := w.starving[wl.Obj.UID]
+	w.starving[wl.Obj.UID] = now
+	return !reported
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func starvationTestInfo(name string, created time.Time) *workload.Info {
+	return &workload.Info{
+		Obj: &kueue.Workload{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(created),
+		}},
+	}
+}
+
+func TestStarvationWatchdog(t *testing.T) {
+	now := time.Now()
+	w := newStarvationWatchdog(time.Hour, false)
+	old := starvationTestInfo("old", now.Add(-2*time.Hour))
+	recent := starvationTestInfo("recent", now.Add(-time.Minute))
+	other := starvationTestInfo("other", now.Add(-3*time.Hour))
+
+	if w.isStarving(old, workload.Ordering{}, "cohort", now) {
+		t.Errorf("Workload is starving without admissions in the cohort")
+	}
+	w.admitted(other, "cohort", now.Add(-90*time.Minute))
+	if !w.isStarving(old, workload.Ordering{}, "cohort", now) {
+		t.Errorf("Workload isn't starving after an admission in the cohort")
+	}
+	if w.isStarving(old, workload.Ordering{}, "other-cohort", now) {
+		t.Errorf("Workload is starving after an admission in another cohort")
+	}
+	w.admitted(other, "cohort", now)
+	if w.isStarving(recent, workload.Ordering{}, "cohort", now) {
+		t.Errorf("Workload is starving before the threshold")
+	}
+
+	if !w.shouldReport(old, now) {
+		t.Errorf("Starving workload isn't reported the first time")
+	}
+	if w.shouldReport(old, now.Add(time.Minute)) {
+		t.Errorf("Starving workload is reported again")
+	}
+	if !w.shouldReport(old, now.Add(3*time.Hour)) {
+		t.Errorf("Starving workload isn't reported again after being forgotten")
+	}
+	w.admitted(old, "cohort", now.Add(3*time.Hour))
+	if !w.shouldReport(old, now.Add(3*time.Hour)) {
+		t.Errorf("Starving workload isn't reported after being admitted")
+	}
+}
+
+func TestEntryOrderingEscalateStarving(t *testing.T) {
+	now := time.Now()
+	input := []entry{
+		{Info: *starvationTestInfo("alpha", now)},
+		{Info: *starvationTestInfo("beta", now.Add(time.Second)), starving: true},
+		{Info: *starvationTestInfo("gamma", now.Add(2*time.Second))},
+	}
+	sort.Sort(entryOrdering{entries: input, escalateStarving: true})
+	order := make([]string, len(input))
+	for i, e := range input {
+		order[i] = e.Obj.Name
+	}
+	wantOrder := []string{"beta", "alpha", "gamma"}
+	if diff := cmp.Diff(wantOrder, order); diff != "" {
+		t.Errorf("Unexpected order (-want,+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admissioncheck provides helpers for controllers that evaluate
+// admission checks.
+//
+// An admission check controller owns the AdmissionChecks whose
+// spec.controllerName matches its name. For each Workload that reserved quota
+// in a ClusterQueue requiring one of those checks, the controller evaluates
+// the check, optionally using the parameters referenced by the
+// AdmissionCheck, and sets the state of the check in the Workload status.
+package admissioncheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/util/api"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+var (
+	ErrNilParametersRef = errors.New("missing parameters reference")
+	ErrBadParametersRef = errors.New("unexpected parameters reference")
+)
+
+// ChecksForController returns the AdmissionChecks listed in the status of the
+// workload that are managed by the controller with the given name.
+// Checks whose AdmissionCheck object doesn't exist are ignored.
+func ChecksForController(ctx context.Context, c client.Client, wl *kueue.Workload, controllerName string) ([]kueue.AdmissionCheck, error) {
+	var checks []kueue.AdmissionCheck
+	for _, state := range wl.Status.AdmissionChecks {
+		var ac kueue.AdmissionCheck
+		if err := c.Get(ctx, types.NamespacedName{Name: state.Name}, &ac); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ac.Spec.ControllerName == controllerName {
+			checks = append(checks, ac)
+		}
+	}
+	return checks, nil
+}
+
+// GetParameters fetches into obj the cluster-scoped object referenced by the
+// parameters of the AdmissionCheck. The group and kind of obj, obtained from
+// the scheme of the client or from obj itself if it's unstructured, must
+// match the reference.
+func GetParameters(ctx context.Context, c client.Client, check *kueue.AdmissionCheck, obj client.Object) error {
+	ref := check.Spec.Parameters
+	if ref == nil {
+		return ErrNilParametersRef
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if ref.APIGroup != gvk.Group || ref.Kind != gvk.Kind {
+		return fmt.Errorf("%w: got %s/%s, want %s/%s", ErrBadParametersRef, ref.APIGroup, ref.Kind, gvk.Group, gvk.Kind)
+	}
+	return c.Get(ctx, types.NamespacedName{Name: ref.Name}, obj)
+}
+
+// SetCheckState sets the state and message of the admission check with the
+// given name in the status of the workload.
+// Returns whether the status changed. The check must be listed in the status.
+func SetCheckState(wl *kueue.Workload, name string, state kueue.CheckState, message string) bool {
+	current := workload.FindAdmissionCheck(wl.Status.AdmissionChecks, name)
+	if current == nil {
+		return false
+	}
+	if current.State == state && current.Message == api.TruncateConditionMessage(message) {
+		return false
+	}
+	workload.SetAdmissionCheckState(&wl.Status.AdmissionChecks, kueue.AdmissionCheckState{
+		Name:               name,
+		State:              state,
+		LastTransitionTime: metav1.Now(),
+		Message:            message,
+	})
+	return true
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissioncheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestChecksForController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kueue.AdmissionCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Spec:       kueue.AdmissionCheckSpec{ControllerName: "example.com/mine"},
+		},
+		&kueue.AdmissionCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Spec:       kueue.AdmissionCheckSpec{ControllerName: "example.com/other"},
+		},
+	).Build()
+	wl := &kueue.Workload{
+		Status: kueue.WorkloadStatus{
+			AdmissionChecks: []kueue.AdmissionCheckState{
+				{Name: "a", State: kueue.CheckStatePending},
+				{Name: "b", State: kueue.CheckStatePending},
+				{Name: "missing", State: kueue.CheckStatePending},
+			},
+		},
+	}
+	checks, err := ChecksForController(context.Background(), cl, wl, "example.com/mine")
+	if err != nil {
+		t.Fatalf("ChecksForController failed: %v", err)
+	}
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	if diff := cmp.Diff([]string{"a"}, names); diff != "" {
+		t.Errorf("Unexpected checks (-want,+got):\n%s", diff)
+	}
+}
+
+func TestGetParameters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	flavor := &kueue.ResourceFlavor{ObjectMeta: metav1.ObjectMeta{Name: "params"}}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(flavor).Build()
+	cases := map[string]struct {
+		ref     *kueue.AdmissionCheckParametersReference
+		wantErr error
+	}{
+		"valid": {
+			ref: &kueue.AdmissionCheckParametersReference{
+				APIGroup: kueue.GroupVersion.Group,
+				Kind:     "ResourceFlavor",
+				Name:     "params",
+			},
+		},
+		"missing reference": {
+			wantErr: ErrNilParametersRef,
+		},
+		"wrong kind": {
+			ref: &kueue.AdmissionCheckParametersReference{
+				APIGroup: kueue.GroupVersion.Group,
+				Kind:     "ClusterQueue",
+				Name:     "params",
+			},
+			wantErr: ErrBadParametersRef,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			check := &kueue.AdmissionCheck{Spec: kueue.AdmissionCheckSpec{Parameters: tc.ref}}
+			var got kueue.ResourceFlavor
+			err := GetParameters(context.Background(), cl, check, &got)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("GetParameters returned error %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && got.Name != flavor.Name {
+				t.Errorf("GetParameters got object %q, want %q", got.Name, flavor.Name)
+			}
+		})
+	}
+}
+
+func TestSetCheckState(t *testing.T) {
+	wl := &kueue.Workload{
+		Status: kueue.WorkloadStatus{
+			AdmissionChecks: []kueue.AdmissionCheckState{
+				{Name: "a", State: kueue.CheckStatePending},
+			},
+		},
+	}
+	if !SetCheckState(wl, "a", kueue.CheckStateReady, "done") {
+		t.Error("SetCheckState returned false for a new state, want true")
+	}
+	if SetCheckState(wl, "a", kueue.CheckStateReady, "done") {
+		t.Error("SetCheckState returned true for the same state, want false")
+	}
+	if SetCheckState(wl, "missing", kueue.CheckStateReady, "") {
+		t.Error("SetCheckState returned true for a check not in the status, want false")
+	}
+	want := []kueue.AdmissionCheckState{{Name: "a", State: kueue.CheckStateReady, Message: "done"}}
+	if diff := cmp.Diff(want, wl.Status.AdmissionChecks,
+		cmpopts.IgnoreFields(kueue.AdmissionCheckState{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("Unexpected checks (-want,+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package example contains a sample admission check controller built on top
+// of the admissioncheck package. It isn't run by Kueue.
+//
+// The controller manages the AdmissionChecks with the controllerName
+// kueue.x-k8s.io/example-max-pods. The parameters of each check are a
+// MaxPodsParameters object with the field spec.maxPods. A workload passes the
+// check if it has at most spec.maxPods pods; otherwise, the check rejects it.
+package example
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/util/admissioncheck"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+const ControllerName = "kueue.x-k8s.io/example-max-pods"
+
+// ParametersGVK is the kind of the objects holding the parameters of the
+// checks. The CRD for this kind has to be installed separately.
+var ParametersGVK = schema.GroupVersionKind{
+	Group:   "example.kueue.x-k8s.io",
+	Version: "v1alpha1",
+	Kind:    "MaxPodsParameters",
+}
+
+// Reconciler evaluates the Pending admission checks for ControllerName.
+type Reconciler struct {
+	client client.Client
+}
+
+func NewReconciler(client client.Client) *Reconciler {
+	return &Reconciler{client: client}
+}
+
+// Reconcile evaluates the checks of a workload. The controller needs
+// permissions to watch workloads, admissionchecks and maxpodsparameters, and
+// to update workloads/status.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var wl kueue.Workload
+	if err := r.client.Get(ctx, req.NamespacedName, &wl); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log := ctrl.LoggerFrom(ctx).WithValues("workload", klog.KObj(&wl))
+	if wl.Spec.Admission == nil || workload.InCondition(&wl, kueue.WorkloadFinished) {
+		return ctrl.Result{}, nil
+	}
+
+	checks, err := admissioncheck.ChecksForController(ctx, r.client, &wl, ControllerName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	changed := false
+	for i := range checks {
+		check := &checks[i]
+		if state := workload.FindAdmissionCheck(wl.Status.AdmissionChecks, check.Name); state.State != kueue.CheckStatePending {
+			continue
+		}
+		maxPods, err := r.maxPods(ctx, check)
+		if err != nil {
+			log.Error(err, "Getting the parameters", "admissionCheck", check.Name)
+			if errors.Is(err, admissioncheck.ErrNilParametersRef) || errors.Is(err, admissioncheck.ErrBadParametersRef) {
+				// The check is misconfigured; wait for it to be fixed.
+				continue
+			}
+			return ctrl.Result{}, err
+		}
+		pods := totalPods(&wl)
+		if pods <= maxPods {
+			changed = admissioncheck.SetCheckState(&wl, check.Name, kueue.CheckStateReady, "") || changed
+		} else {
+			msg := fmt.Sprintf("The workload has %d pods, more than the maximum of %d", pods, maxPods)
+			changed = admissioncheck.SetCheckState(&wl, check.Name, kueue.CheckStateRejected, msg) || changed
+		}
+	}
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, client.IgnoreNotFound(r.client.Status().Update(ctx, &wl))
+}
+
+func (r *Reconciler) maxPods(ctx context.Context, check *kueue.AdmissionCheck) (int64, error) {
+	params := &unstructured.Unstructured{}
+	params.SetGroupVersionKind(ParametersGVK)
+	if err := admissioncheck.GetParameters(ctx, r.client, check, params); err != nil {
+		return 0, err
+	}
+	maxPods, found, err := unstructured.NestedInt64(params.Object, "spec", "maxPods")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("%w: %s doesn't set spec.maxPods", admissioncheck.ErrBadParametersRef, params.GetName())
+	}
+	return maxPods, nil
+}
+
+func totalPods(wl *kueue.Workload) int64 {
+	var pods int64
+	for _, ps := range wl.Spec.PodSets {
+		pods += int64(ps.Count)
+	}
+	return pods
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("example-max-pods-admission-check").
+		For(&kueue.Workload{}).
+		Complete(r)
+}
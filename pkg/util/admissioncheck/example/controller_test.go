@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package example
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+func maxPodsParameters(name string, maxPods int64) *unstructured.Unstructured {
+	params := &unstructured.Unstructured{}
+	params.SetGroupVersionKind(ParametersGVK)
+	params.SetName(name)
+	_ = unstructured.SetNestedField(params.Object, maxPods, "spec", "maxPods")
+	return params
+}
+
+func TestReconcile(t *testing.T) {
+	checks := []client.Object{
+		&kueue.AdmissionCheck{
+			ObjectMeta: ctrl.ObjectMeta{Name: "max-pods"},
+			Spec: kueue.AdmissionCheckSpec{
+				ControllerName: ControllerName,
+				Parameters: &kueue.AdmissionCheckParametersReference{
+					APIGroup: ParametersGVK.Group,
+					Kind:     ParametersGVK.Kind,
+					Name:     "small",
+				},
+			},
+		},
+		&kueue.AdmissionCheck{
+			ObjectMeta: ctrl.ObjectMeta{Name: "other"},
+			Spec: kueue.AdmissionCheckSpec{
+				ControllerName: "example.com/other",
+			},
+		},
+		maxPodsParameters("small", 3),
+	}
+	cases := map[string]struct {
+		pods       int32
+		checks     []kueue.AdmissionCheckState
+		wantChecks []kueue.AdmissionCheckState
+	}{
+		"fits": {
+			pods: 3,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "max-pods", State: kueue.CheckStatePending},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+			wantChecks: []kueue.AdmissionCheckState{
+				{Name: "max-pods", State: kueue.CheckStateReady},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+		},
+		"too many pods": {
+			pods: 4,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "max-pods", State: kueue.CheckStatePending},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+			wantChecks: []kueue.AdmissionCheckState{
+				{
+					Name:    "max-pods",
+					State:   kueue.CheckStateRejected,
+					Message: "The workload has 4 pods, more than the maximum of 3",
+				},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+		},
+		"not pending": {
+			pods: 4,
+			checks: []kueue.AdmissionCheckState{
+				{Name: "max-pods", State: kueue.CheckStateRetry},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+			wantChecks: []kueue.AdmissionCheckState{
+				{Name: "max-pods", State: kueue.CheckStateRetry},
+				{Name: "other", State: kueue.CheckStatePending},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			wl := builder.MakeWorkload("wl", "ns").Admit(builder.MakeAdmission("cq").Obj()).Obj()
+			wl.Spec.PodSets[0].Count = tc.pods
+			wl.Status.AdmissionChecks = tc.checks
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(checks, wl)...).Build()
+			r := NewReconciler(cl)
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(wl)}); err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			var got kueue.Workload
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(wl), &got); err != nil {
+				t.Fatalf("Getting the workload: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantChecks, got.Status.AdmissionChecks,
+				cmpopts.IgnoreFields(kueue.AdmissionCheckState{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Unexpected admission checks (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
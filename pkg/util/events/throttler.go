@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Throttler records events, dropping the ones that repeat the last event
+// recorded for the same object and reason within a period. Once the period
+// expires, the next event is recorded with the number of attempts that were
+// dropped.
+type Throttler struct {
+	recorder record.EventRecorder
+	period   time.Duration
+	clock    clock.Clock
+
+	sync.Mutex
+	entries   map[entryKey]*entry
+	lastPrune time.Time
+}
+
+type entryKey struct {
+	uid    types.UID
+	reason string
+}
+
+type entry struct {
+	message  string
+	recorded time.Time
+	dropped  int
+}
+
+// NewThrottler returns a Throttler that records at most one event per object
+// and reason, with the same message, in the given period, as measured by the
+// clock.
+func NewThrottler(recorder record.EventRecorder, period time.Duration, clock clock.Clock) *Throttler {
+	return &Throttler{
+		recorder:  recorder,
+		period:    period,
+		clock:     clock,
+		entries:   make(map[entryKey]*entry),
+		lastPrune: clock.Now(),
+	}
+}
+
+// Eventf records an event like record.EventRecorder.Eventf, unless it repeats
+// the last event for the object and reason.
+func (t *Throttler) Eventf(obj client.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	now := t.clock.Now()
+	key := entryKey{uid: obj.GetUID(), reason: reason}
+
+	t.Lock()
+	defer t.Unlock()
+	t.prune(now)
+	text := message
+	if e, ok := t.entries[key]; ok && e.message == message {
+		if now.Sub(e.recorded) < t.period {
+			e.dropped++
+			return
+		}
+		if e.dropped > 0 {
+			text = fmt.Sprintf("%s (%d more attempts since %s)", message, e.dropped, e.recorded.UTC().Format(time.RFC3339))
+		}
+	}
+	t.entries[key] = &entry{message: message, recorded: now}
+	t.recorder.Event(obj, eventtype, reason, text)
+}
+
+// prune removes the entries that expired long ago, at most once per period.
+// The attempts dropped for those entries are not reported.
+func (t *Throttler) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < t.period {
+		return
+	}
+	for k, e := range t.entries {
+		if now.Sub(e.recorded) >= 2*t.period {
+			delete(t.entries, k)
+		}
+	}
+	t.lastPrune = now
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestThrottler(t *testing.T) {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	obj := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}}
+
+	type step struct {
+		after   time.Duration
+		obj     *corev1.Pod
+		reason  string
+		message string
+	}
+	cases := map[string]struct {
+		steps      []step
+		wantEvents []string
+	}{
+		"repeated events are dropped": {
+			steps: []step{
+				{obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Second, obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Second, obj: obj, reason: "Pending", message: "no quota"},
+			},
+			wantEvents: []string{"Normal Pending no quota"},
+		},
+		"summary after the period": {
+			steps: []step{
+				{obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Second, obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Second, obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Minute, obj: obj, reason: "Pending", message: "no quota"},
+				{after: time.Minute, obj: obj, reason: "Pending", message: "no quota"},
+			},
+			wantEvents: []string{
+				"Normal Pending no quota",
+				"Normal Pending no quota (2 more attempts since 2022-10-01T00:00:00Z)",
+				"Normal Pending no quota",
+			},
+		},
+		"different messages, reasons and objects": {
+			steps: []step{
+				{obj: obj, reason: "Pending", message: "no quota"},
+				{obj: obj, reason: "Pending", message: "no flavor"},
+				{obj: obj, reason: "Other", message: "no flavor"},
+				{obj: other, reason: "Pending", message: "no quota"},
+			},
+			wantEvents: []string{
+				"Normal Pending no quota",
+				"Normal Pending no flavor",
+				"Normal Other no flavor",
+				"Normal Pending no quota",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(len(tc.steps))
+			clock := testingclock.NewFakeClock(start)
+			throttler := NewThrottler(recorder, time.Minute, clock)
+			for _, s := range tc.steps {
+				clock.Step(s.after)
+				throttler.Eventf(s.obj, corev1.EventTypeNormal, s.reason, s.message)
+			}
+			close(recorder.Events)
+			var gotEvents []string
+			for e := range recorder.Events {
+				gotEvents = append(gotEvents, e)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("Unexpected events (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
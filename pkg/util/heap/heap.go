@@ -32,6 +32,9 @@ type keyFunc func(obj interface{}) string
 type heapItem struct {
 	obj   interface{}
 	index int
+	// deleted indicates that the item was deleted, but it's still in the
+	// heap until it's popped or the heap is compacted.
+	deleted bool
 }
 
 type itemKeyValue struct {
@@ -45,7 +48,9 @@ type data struct {
 	// items is a map from key of the objects to the objects and their index
 	items map[string]*heapItem
 	// keys keeps the keys of the objects ordered according to the heap invariant.
-	keys     []string
+	keys []string
+	// deleted is the number of items in keys that were deleted.
+	deleted  int
 	keyFunc  keyFunc
 	lessFunc lessFunc
 }
@@ -69,7 +74,7 @@ func (h *data) Less(i, j int) bool {
 	return h.lessFunc(a.obj, b.obj)
 }
 
-// Len returns the number of items in the Heap.
+// Len returns the number of items in the Heap, including the deleted ones.
 func (h *data) Len() int {
 	return len(h.keys)
 }
@@ -102,11 +107,14 @@ func (h *data) Pop() interface{} {
 		return nil
 	}
 	delete(h.items, key)
-	return item.obj
+	return item
 }
 
 // Heap is a producer/consumer queue that implements a heap data structure.
 // It can be used to implement priority queues and similar data structures.
+// The items are deleted lazily: they are only marked as deleted, and removed
+// when they reach the head of the heap or when more than half of the items
+// are deleted, so that deleting many items doesn't fix the heap each time.
 type Heap struct {
 	data data
 }
@@ -115,9 +123,10 @@ type Heap struct {
 // The item will be updated if it already exists.
 func (h *Heap) PushOrUpdate(obj interface{}) {
 	key := h.data.keyFunc(obj)
-	if _, exists := h.data.items[key]; exists {
-		h.data.items[key].obj = obj
-		heap.Fix(&h.data, h.data.items[key].index)
+	if item, exists := h.data.items[key]; exists {
+		h.revive(item)
+		item.obj = obj
+		heap.Fix(&h.data, item.index)
 	} else {
 		heap.Push(&h.data, &itemKeyValue{key, obj})
 	}
@@ -127,26 +136,68 @@ func (h *Heap) PushOrUpdate(obj interface{}) {
 // the key is present in the map, no changes is made to the item.
 func (h *Heap) PushIfNotPresent(obj interface{}) (added bool) {
 	key := h.data.keyFunc(obj)
-	if _, exists := h.data.items[key]; exists {
-		return false
+	if item, exists := h.data.items[key]; exists {
+		if !item.deleted {
+			return false
+		}
+		h.revive(item)
+		item.obj = obj
+		heap.Fix(&h.data, item.index)
+		return true
 	}
 
 	heap.Push(&h.data, &itemKeyValue{key, obj})
 	return true
 }
 
+// revive clears the deleted mark of the item.
+func (h *Heap) revive(item *heapItem) {
+	if item.deleted {
+		item.deleted = false
+		h.data.deleted--
+	}
+}
+
 // Delete removes an item.
 func (h *Heap) Delete(key string) {
 	item, exists := h.data.items[key]
-	if !exists {
+	if !exists || item.deleted {
 		return
 	}
-	heap.Remove(&h.data, item.index)
+	item.deleted = true
+	h.data.deleted++
+	if h.data.deleted > len(h.data.keys)/2 {
+		h.compact()
+	}
+}
+
+// compact removes the deleted items and restores the heap invariant.
+func (h *Heap) compact() {
+	keys := h.data.keys[:0]
+	for _, key := range h.data.keys {
+		item := h.data.items[key]
+		if item.deleted {
+			delete(h.data.items, key)
+			continue
+		}
+		item.index = len(keys)
+		keys = append(keys, key)
+	}
+	h.data.keys = keys
+	h.data.deleted = 0
+	heap.Init(&h.data)
 }
 
 // Pop returns the head of the heap and removes it.
 func (h *Heap) Pop() interface{} {
-	return heap.Pop(&h.data)
+	for h.data.Len() > 0 {
+		item := heap.Pop(&h.data).(*heapItem)
+		if !item.deleted {
+			return item.obj
+		}
+		h.data.deleted--
+	}
+	return nil
 }
 
 // Get returns the requested item, exists, error.
@@ -158,7 +209,7 @@ func (h *Heap) Get(obj interface{}) (item interface{}) {
 // GetByKey returns the requested item, or sets exists=false.
 func (h *Heap) GetByKey(key string) interface{} {
 	item, exists := h.data.items[key]
-	if !exists {
+	if !exists || item.deleted {
 		return nil
 	}
 	return item.obj
@@ -166,14 +217,16 @@ func (h *Heap) GetByKey(key string) interface{} {
 
 // Len returns the number of items in the heap.
 func (h *Heap) Len() int {
-	return h.data.Len()
+	return h.data.Len() - h.data.deleted
 }
 
 // List returns a list of all the items.
 func (h *Heap) List() []interface{} {
 	list := make([]interface{}, 0, h.Len())
 	for _, item := range h.data.items {
-		list = append(list, item.obj)
+		if !item.deleted {
+			list = append(list, item.obj)
+		}
 	}
 	return list
 }
@@ -169,9 +169,9 @@ func TestHeap_Delete(t *testing.T) {
 	}
 	h.PushOrUpdate(mkHeapObj("zab", 30))
 	h.PushOrUpdate(mkHeapObj("faz", 30))
-	len := h.data.Len()
+	len := h.Len()
 	// Delete non-existing item.
-	if h.Delete("non-existent"); len != h.data.Len() {
+	if h.Delete("non-existent"); len != h.Len() {
 		t.Fatalf("Didn't expect any item removal")
 	}
 	// Delete tail.
@@ -186,7 +186,47 @@ func TestHeap_Delete(t *testing.T) {
 	if e, a := 30, item.(testHeapObject).val; a != e {
 		t.Fatalf("expected %d, got %d", e, a)
 	}
-	if h.data.Len() != 0 {
+	if h.Len() != 0 {
+		t.Fatalf("expected an empty heap.")
+	}
+}
+
+// TestHeap_DeleteLazy tests that the deleted items are neither popped nor
+// listed, and that they can be pushed again.
+func TestHeap_DeleteLazy(t *testing.T) {
+	h := New(testHeapObjectKeyFunc, compareInts)
+	const amount = 10
+	for i := 0; i < amount; i++ {
+		h.PushOrUpdate(mkHeapObj(string([]rune{'a', rune(i)}), i))
+	}
+	// Delete the items with an even value, less than half of the items stay
+	// in the heap as deleted.
+	for i := 0; i < amount; i += 2 {
+		h.Delete(string([]rune{'a', rune(i)}))
+	}
+	if h.data.deleted == 0 {
+		t.Fatalf("expected deleted items in the heap.")
+	}
+	if e, a := amount/2, h.Len(); a != e {
+		t.Fatalf("expected %d items, got %d", e, a)
+	}
+	if e, a := amount/2, len(h.List()); a != e {
+		t.Fatalf("expected %d listed items, got %d", e, a)
+	}
+	if h.GetByKey(string([]rune{'a', 0})) != nil {
+		t.Fatalf("expected a deleted item not to be found.")
+	}
+	if !h.PushIfNotPresent(mkHeapObj(string([]rune{'a', 0}), 0)) {
+		t.Fatalf("expected a deleted item to be pushed again.")
+	}
+	want := []int{0, 1, 3, 5, 7, 9}
+	for _, e := range want {
+		item := h.Pop()
+		if a := item.(testHeapObject).val; a != e {
+			t.Fatalf("expected %d, got %d", e, a)
+		}
+	}
+	if h.Pop() != nil || h.Len() != 0 || h.data.Len() != 0 {
 		t.Fatalf("expected an empty heap.")
 	}
 }
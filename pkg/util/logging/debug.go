@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+// ForObject returns the logger to use for the object. If the object has the
+// debug annotation set to "true", the logger logs the messages of any
+// verbosity, tagged with debug=true, so that a single object can be debugged
+// without raising the verbosity of the whole manager.
+func ForObject(log logr.Logger, obj metav1.Object) logr.Logger {
+	if obj.GetAnnotations()[constants.DebugAnnotation] != "true" {
+		return log
+	}
+	return logr.New(verboseSink{log.GetSink()}).WithValues("debug", true)
+}
+
+// verboseSink logs the messages of any verbosity as if they had verbosity 0.
+type verboseSink struct {
+	logr.LogSink
+}
+
+// Init is a no-op, as the wrapped sink is already initialized.
+func (s verboseSink) Init(logr.RuntimeInfo) {}
+
+func (s verboseSink) Enabled(int) bool {
+	return s.LogSink.Enabled(0)
+}
+
+func (s verboseSink) Info(_ int, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Info(0, msg, keysAndValues...)
+}
+
+func (s verboseSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return verboseSink{s.LogSink.WithValues(keysAndValues...)}
+}
+
+func (s verboseSink) WithName(name string) logr.LogSink {
+	return verboseSink{s.LogSink.WithName(name)}
+}
+
+func (s verboseSink) WithCallDepth(depth int) logr.LogSink {
+	if sink, ok := s.LogSink.(logr.CallDepthLogSink); ok {
+		return verboseSink{sink.WithCallDepth(depth)}
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+func TestForObject(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		wantLines   []string
+	}{
+		"without annotation": {
+			wantLines: []string{
+				`"level"=0 "msg"="info" "workload"="wl"`,
+			},
+		},
+		"debug annotation": {
+			annotations: map[string]string{constants.DebugAnnotation: "true"},
+			wantLines: []string{
+				`"level"=0 "msg"="info" "workload"="wl" "debug"=true`,
+				`"level"=0 "msg"="details" "workload"="wl" "debug"=true "step"=1`,
+			},
+		},
+		"debug annotation not true": {
+			annotations: map[string]string{constants.DebugAnnotation: "false"},
+			wantLines: []string{
+				`"level"=0 "msg"="info" "workload"="wl"`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var lines []string
+			log := funcr.New(func(prefix, args string) {
+				lines = append(lines, args)
+			}, funcr.Options{}).WithValues("workload", "wl")
+			obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+
+			log = ForObject(log, obj)
+			log.Info("info")
+			log.V(4).Info("details", "step", 1)
+			if diff := cmp.Diff(tc.wantLines, lines); diff != "" {
+				t.Errorf("Unexpected log lines (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -27,9 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
-	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 )
 
 func TestPriority(t *testing.T) {
@@ -38,7 +38,7 @@ func TestPriority(t *testing.T) {
 		want     int32
 	}{
 		"priority is specified": {
-			workload: utiltesting.MakeWorkload("name", "ns").Priority(pointer.Int32(100)).Obj(),
+			workload: builder.MakeWorkload("name", "ns").Priority(pointer.Int32(100)).Obj(),
 			want:     100,
 		},
 		"priority is empty": {
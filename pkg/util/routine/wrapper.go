@@ -51,3 +51,28 @@ func NewWrapper(before, after func()) Wrapper {
 		after:  after,
 	}
 }
+
+var _ Wrapper = &boundedWrapper{}
+
+// boundedWrapper implements the Wrapper interface, running at most
+// cap(slots) functions at the same time.
+type boundedWrapper struct {
+	slots chan struct{}
+}
+
+func (b *boundedWrapper) Run(f func()) {
+	go func() {
+		b.slots <- struct{}{}
+		defer func() { <-b.slots }()
+		f()
+	}()
+}
+
+// NewBoundedWrapper returns a Wrapper that runs at most workers functions at
+// the same time. Run doesn't block: the functions in excess wait in their
+// goroutines until a running function ends.
+func NewBoundedWrapper(workers int) Wrapper {
+	return &boundedWrapper{
+		slots: make(chan struct{}, workers),
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"hash/fnv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// Shard is the portion of the cohorts that an instance of the manager
+// schedules. The cohorts are assigned to the shards by the hash of their name;
+// a ClusterQueue that doesn't belong to a cohort is assigned by the hash of its
+// own name. The zero value owns all the cohorts.
+type Shard struct {
+	// Index is the index of the shard, from 0 to Count-1.
+	Index int32
+	// Count is the number of shards.
+	Count int32
+}
+
+// Enabled returns whether the cohorts are split among several shards.
+func (s Shard) Enabled() bool {
+	return s.Count > 1
+}
+
+// Owns returns whether the ClusterQueue belongs to the shard. A nil
+// ClusterQueue, for objects that reference a ClusterQueue that doesn't exist,
+// belongs to the first shard.
+func (s Shard) Owns(cq *kueue.ClusterQueue) bool {
+	if !s.Enabled() {
+		return true
+	}
+	if cq == nil {
+		return s.Index == 0
+	}
+	key := cq.Spec.Cohort
+	if key == "" {
+		key = cq.Name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int32(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// OwnsClusterQueue returns whether the ClusterQueue with the given name
+// belongs to the shard.
+func (s Shard) OwnsClusterQueue(ctx context.Context, c client.Reader, name string) (bool, error) {
+	if !s.Enabled() {
+		return true, nil
+	}
+	var cq kueue.ClusterQueue
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, &cq); err != nil {
+		if apierrors.IsNotFound(err) {
+			return s.Owns(nil), nil
+		}
+		return false, err
+	}
+	return s.Owns(&cq), nil
+}
+
+// OwnsLocalQueue returns whether the ClusterQueue of the LocalQueue with the
+// given namespace and name belongs to the shard.
+func (s Shard) OwnsLocalQueue(ctx context.Context, c client.Reader, namespace, name string) (bool, error) {
+	if !s.Enabled() {
+		return true, nil
+	}
+	var q kueue.LocalQueue
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &q); err != nil {
+		if apierrors.IsNotFound(err) {
+			return s.Owns(nil), nil
+		}
+		return false, err
+	}
+	return s.OwnsClusterQueue(ctx, c, string(q.Spec.ClusterQueue))
+}
+
+// OwnsWorkload returns whether the ClusterQueue where the workload is
+// admitted, or the ClusterQueue of its LocalQueue, belongs to the shard.
+func (s Shard) OwnsWorkload(ctx context.Context, c client.Reader, wl *kueue.Workload) (bool, error) {
+	if !s.Enabled() {
+		return true, nil
+	}
+	if wl.Spec.Admission != nil {
+		return s.OwnsClusterQueue(ctx, c, string(wl.Spec.Admission.ClusterQueue))
+	}
+	return s.OwnsLocalQueue(ctx, c, wl.Namespace, wl.Spec.QueueName)
+}
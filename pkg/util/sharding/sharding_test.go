@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+const shardCount = 4
+
+// owners returns the indexes of the shards that own the ClusterQueue.
+func owners(cq *kueue.ClusterQueue) []int32 {
+	var got []int32
+	for i := int32(0); i < shardCount; i++ {
+		if (Shard{Index: i, Count: shardCount}).Owns(cq) {
+			got = append(got, i)
+		}
+	}
+	return got
+}
+
+func TestOwns(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		cq := builder.MakeClusterQueue(fmt.Sprintf("cq-%d", i)).Obj()
+		if !(Shard{}).Owns(cq) {
+			t.Errorf("The zero shard doesn't own clusterQueue %s", cq.Name)
+		}
+		if got := owners(cq); len(got) != 1 {
+			t.Errorf("ClusterQueue %s is owned by shards %v, want exactly one", cq.Name, got)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		cohort := fmt.Sprintf("cohort-%d", i)
+		want := owners(builder.MakeClusterQueue("a").Cohort(cohort).Obj())
+		got := owners(builder.MakeClusterQueue("b").Cohort(cohort).Obj())
+		if len(want) != 1 || len(got) != 1 || want[0] != got[0] {
+			t.Errorf("ClusterQueues of cohort %s are owned by shards %v and %v, want the same shard", cohort, want, got)
+		}
+	}
+	if got := owners(nil); len(got) != 1 || got[0] != 0 {
+		t.Errorf("A missing clusterQueue is owned by shards %v, want [0]", got)
+	}
+}
+
+func TestOwnsWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cq := builder.MakeClusterQueue("cq").Cohort("cohort").Obj()
+	owner := Shard{Index: owners(cq)[0], Count: shardCount}
+	other := Shard{Index: (owner.Index + 1) % shardCount, Count: shardCount}
+	kClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cq,
+		builder.MakeLocalQueue("queue", "ns").ClusterQueue("cq").Obj(),
+	).Build()
+
+	tests := map[string]*kueue.Workload{
+		"pending":  builder.MakeWorkload("a", "ns").Queue("queue").Obj(),
+		"admitted": builder.MakeWorkload("b", "ns").Admit(builder.MakeAdmission("cq").Obj()).Obj(),
+	}
+	for name, wl := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			owned, err := owner.OwnsWorkload(ctx, kClient, wl)
+			if err != nil {
+				t.Fatalf("Failed getting the shard of the workload: %v", err)
+			}
+			if !owned {
+				t.Errorf("Shard %d doesn't own the workload", owner.Index)
+			}
+			owned, err = other.OwnsWorkload(ctx, kClient, wl)
+			if err != nil {
+				t.Fatalf("Failed getting the shard of the workload: %v", err)
+			}
+			if owned {
+				t.Errorf("Shard %d owns the workload", other.Index)
+			}
+		})
+	}
+}
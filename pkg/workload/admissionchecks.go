@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/util/api"
+)
+
+// FindAdmissionCheck returns the state of the admission check with the given
+// name, or nil if the check is not present.
+func FindAdmissionCheck(checks []kueue.AdmissionCheckState, name string) *kueue.AdmissionCheckState {
+	for i := range checks {
+		if checks[i].Name == name {
+			return &checks[i]
+		}
+	}
+	return nil
+}
+
+// SetAdmissionCheckState adds or updates the state of an admission check.
+// The lastTransitionTime is only updated when the state changes; it's set to
+// the current time if newCheck doesn't provide one.
+func SetAdmissionCheckState(checks *[]kueue.AdmissionCheckState, newCheck kueue.AdmissionCheckState) {
+	if checks == nil {
+		return
+	}
+	newCheck.Message = api.TruncateConditionMessage(newCheck.Message)
+	existing := FindAdmissionCheck(*checks, newCheck.Name)
+	if existing == nil {
+		if newCheck.LastTransitionTime.IsZero() {
+			newCheck.LastTransitionTime = metav1.Now()
+		}
+		*checks = append(*checks, newCheck)
+		return
+	}
+	if existing.State != newCheck.State {
+		existing.State = newCheck.State
+		existing.LastTransitionTime = newCheck.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		}
+	}
+	existing.Message = newCheck.Message
+}
+
+// SyncAdmissionChecks makes the admission checks in the status of the
+// workload match the given names: missing checks are added as Pending and
+// checks that are no longer required are removed.
+// Returns whether the status changed.
+func SyncAdmissionChecks(wl *kueue.Workload, names []string) bool {
+	required := sets.NewString(names...)
+	changed := false
+	checks := make([]kueue.AdmissionCheckState, 0, len(names))
+	for _, c := range wl.Status.AdmissionChecks {
+		if required.Has(c.Name) {
+			checks = append(checks, c)
+		} else {
+			changed = true
+		}
+	}
+	for _, name := range names {
+		if FindAdmissionCheck(checks, name) == nil {
+			SetAdmissionCheckState(&checks, kueue.AdmissionCheckState{
+				Name:  name,
+				State: kueue.CheckStatePending,
+			})
+			changed = true
+		}
+	}
+	if len(checks) == 0 {
+		checks = nil
+	}
+	wl.Status.AdmissionChecks = checks
+	return changed
+}
+
+// AdmissionChecksReady returns whether all the given admission checks are
+// Ready in the status of the workload.
+func AdmissionChecksReady(wl *kueue.Workload, names []string) bool {
+	for _, name := range names {
+		c := FindAdmissionCheck(wl.Status.AdmissionChecks, name)
+		if c == nil || c.State != kueue.CheckStateReady {
+			return false
+		}
+	}
+	return true
+}
+
+// FirstAdmissionCheckInState returns the first admission check of the
+// workload in the given state, or nil if there is none.
+func FirstAdmissionCheckInState(wl *kueue.Workload, state kueue.CheckState) *kueue.AdmissionCheckState {
+	for i := range wl.Status.AdmissionChecks {
+		if wl.Status.AdmissionChecks[i].State == state {
+			return &wl.Status.AdmissionChecks[i]
+		}
+	}
+	return nil
+}
+
+// IsActive returns whether the workload can be admitted into a queue.
+func IsActive(wl *kueue.Workload) bool {
+	return wl.Spec.Active == nil || *wl.Spec.Active
+}
+
+// RequeueAfter returns how long the workload has to wait until it can be
+// queued again, or zero if it can be queued already.
+func RequeueAfter(wl *kueue.Workload, now time.Time) time.Duration {
+	if wl.Status.RequeueState == nil || wl.Status.RequeueState.RequeueAt == nil {
+		return 0
+	}
+	if d := wl.Status.RequeueState.RequeueAt.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestSetAdmissionCheckState(t *testing.T) {
+	before := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	cases := map[string]struct {
+		checks    []kueue.AdmissionCheckState
+		newCheck  kueue.AdmissionCheckState
+		wantCheck []kueue.AdmissionCheckState
+	}{
+		"add check": {
+			newCheck: kueue.AdmissionCheckState{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: now,
+			},
+			wantCheck: []kueue.AdmissionCheckState{{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: now,
+			}},
+		},
+		"state changes": {
+			checks: []kueue.AdmissionCheckState{{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: before,
+			}},
+			newCheck: kueue.AdmissionCheckState{
+				Name:               "check",
+				State:              kueue.CheckStateReady,
+				LastTransitionTime: now,
+				Message:            "provisioned",
+			},
+			wantCheck: []kueue.AdmissionCheckState{{
+				Name:               "check",
+				State:              kueue.CheckStateReady,
+				LastTransitionTime: now,
+				Message:            "provisioned",
+			}},
+		},
+		"same state keeps transition time": {
+			checks: []kueue.AdmissionCheckState{{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: before,
+			}},
+			newCheck: kueue.AdmissionCheckState{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: now,
+				Message:            "waiting for capacity",
+			},
+			wantCheck: []kueue.AdmissionCheckState{{
+				Name:               "check",
+				State:              kueue.CheckStatePending,
+				LastTransitionTime: before,
+				Message:            "waiting for capacity",
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			checks := tc.checks
+			SetAdmissionCheckState(&checks, tc.newCheck)
+			if diff := cmp.Diff(tc.wantCheck, checks); diff != "" {
+				t.Errorf("Unexpected checks (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncAdmissionChecks(t *testing.T) {
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	cases := map[string]struct {
+		checks      []kueue.AdmissionCheckState
+		names       []string
+		wantChecks  []kueue.AdmissionCheckState
+		wantChanged bool
+	}{
+		"no checks": {},
+		"add missing checks as pending": {
+			checks: []kueue.AdmissionCheckState{{
+				Name:               "a",
+				State:              kueue.CheckStateReady,
+				LastTransitionTime: now,
+			}},
+			names: []string{"a", "b"},
+			wantChecks: []kueue.AdmissionCheckState{
+				{
+					Name:  "a",
+					State: kueue.CheckStateReady,
+				},
+				{
+					Name:  "b",
+					State: kueue.CheckStatePending,
+				},
+			},
+			wantChanged: true,
+		},
+		"remove checks no longer required": {
+			checks: []kueue.AdmissionCheckState{
+				{
+					Name:  "a",
+					State: kueue.CheckStateReady,
+				},
+				{
+					Name:  "b",
+					State: kueue.CheckStatePending,
+				},
+			},
+			names: []string{"a"},
+			wantChecks: []kueue.AdmissionCheckState{{
+				Name:  "a",
+				State: kueue.CheckStateReady,
+			}},
+			wantChanged: true,
+		},
+		"remove all checks": {
+			checks: []kueue.AdmissionCheckState{{
+				Name:  "a",
+				State: kueue.CheckStateReady,
+			}},
+			wantChanged: true,
+		},
+		"unchanged": {
+			checks: []kueue.AdmissionCheckState{{
+				Name:  "a",
+				State: kueue.CheckStateRetry,
+			}},
+			names: []string{"a"},
+			wantChecks: []kueue.AdmissionCheckState{{
+				Name:  "a",
+				State: kueue.CheckStateRetry,
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{
+				Status: kueue.WorkloadStatus{AdmissionChecks: tc.checks},
+			}
+			changed := SyncAdmissionChecks(wl, tc.names)
+			if changed != tc.wantChanged {
+				t.Errorf("SyncAdmissionChecks returned %t, want %t", changed, tc.wantChanged)
+			}
+			if diff := cmp.Diff(tc.wantChecks, wl.Status.AdmissionChecks,
+				cmpopts.IgnoreFields(kueue.AdmissionCheckState{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Unexpected checks (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAdmissionChecksReady(t *testing.T) {
+	cases := map[string]struct {
+		checks []kueue.AdmissionCheckState
+		names  []string
+		want   bool
+	}{
+		"no checks required": {
+			want: true,
+		},
+		"all ready": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "a", State: kueue.CheckStateReady},
+				{Name: "b", State: kueue.CheckStateReady},
+			},
+			names: []string{"a", "b"},
+			want:  true,
+		},
+		"one pending": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "a", State: kueue.CheckStateReady},
+				{Name: "b", State: kueue.CheckStatePending},
+			},
+			names: []string{"a", "b"},
+		},
+		"one missing": {
+			checks: []kueue.AdmissionCheckState{
+				{Name: "a", State: kueue.CheckStateReady},
+			},
+			names: []string{"a", "b"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{
+				Status: kueue.WorkloadStatus{AdmissionChecks: tc.checks},
+			}
+			if got := AdmissionChecksReady(wl, tc.names); got != tc.want {
+				t.Errorf("AdmissionChecksReady returned %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequeueAfter(t *testing.T) {
+	now := time.Now()
+	requeueAt := func(t time.Time) *kueue.RequeueState {
+		mt := metav1.NewTime(t)
+		return &kueue.RequeueState{Count: 1, RequeueAt: &mt}
+	}
+	cases := map[string]struct {
+		requeueState *kueue.RequeueState
+		want         time.Duration
+	}{
+		"never requeued": {},
+		"backoff expired": {
+			requeueState: &kueue.RequeueState{Count: 2},
+		},
+		"requeueAt in the past": {
+			requeueState: requeueAt(now.Add(-time.Minute)),
+		},
+		"requeueAt in the future": {
+			requeueState: requeueAt(now.Add(time.Minute)),
+			want:         time.Minute,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{
+				Status: kueue.WorkloadStatus{RequeueState: tc.requeueState},
+			}
+			if got := RequeueAfter(wl, now); got != tc.want {
+				t.Errorf("RequeueAfter returned %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"time"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// RecordDeadline sets the deadline of the workload, from its
+// activeDeadlineSeconds counted from the given admission time, unless the
+// workload has no activeDeadlineSeconds or the deadline is already set.
+// Returns whether the status changed.
+func RecordDeadline(wl *kueue.Workload, admittedAt time.Time) bool {
+	if wl.Spec.ActiveDeadlineSeconds == nil || wl.Status.Deadline != nil {
+		return false
+	}
+	wl.Status.Deadline = timePtr(admittedAt.Add(time.Duration(*wl.Spec.ActiveDeadlineSeconds) * time.Second))
+	return true
+}
+
+// DeadlineExceeded returns whether the deadline of the workload passed.
+func DeadlineExceeded(wl *kueue.Workload, now time.Time) bool {
+	return wl.Status.Deadline != nil && !now.Before(wl.Status.Deadline.Time)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/utils/pointer"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestDeadline(t *testing.T) {
+	admittedAt := time.Now().Truncate(time.Second)
+	wl := &kueue.Workload{}
+	if RecordDeadline(wl, admittedAt) {
+		t.Errorf("RecordDeadline set a deadline for a workload without activeDeadlineSeconds")
+	}
+	if DeadlineExceeded(wl, admittedAt.Add(time.Hour)) {
+		t.Errorf("DeadlineExceeded returned true for a workload without deadline")
+	}
+
+	wl.Spec.ActiveDeadlineSeconds = pointer.Int64(60)
+	if !RecordDeadline(wl, admittedAt) {
+		t.Fatalf("RecordDeadline didn't set the deadline")
+	}
+	if want := admittedAt.Add(time.Minute); !wl.Status.Deadline.Time.Equal(want) {
+		t.Errorf("Got deadline %v, want %v", wl.Status.Deadline.Time, want)
+	}
+	if RecordDeadline(wl, admittedAt.Add(time.Hour)) {
+		t.Errorf("RecordDeadline changed the deadline after a new admission")
+	}
+	if DeadlineExceeded(wl, admittedAt.Add(59*time.Second)) {
+		t.Errorf("DeadlineExceeded returned true before the deadline")
+	}
+	if !DeadlineExceeded(wl, admittedAt.Add(time.Minute)) {
+		t.Errorf("DeadlineExceeded returned false at the deadline")
+	}
+}
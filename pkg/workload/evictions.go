@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// RecordFlavorEvictions counts an eviction of the workload for each of the
+// flavors assigned in its admission.
+func RecordFlavorEvictions(wl *kueue.Workload) {
+	if wl.Spec.Admission == nil {
+		return
+	}
+	flavors := sets.NewString()
+	for _, ps := range wl.Spec.Admission.PodSetFlavors {
+		for _, f := range ps.Flavors {
+			flavors.Insert(f)
+		}
+	}
+	for _, name := range flavors.List() {
+		found := false
+		for i := range wl.Status.FlavorEvictions {
+			if e := &wl.Status.FlavorEvictions[i]; string(e.Name) == name {
+				e.Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			wl.Status.FlavorEvictions = append(wl.Status.FlavorEvictions, kueue.FlavorEvictions{
+				Name:  kueue.ResourceFlavorReference(name),
+				Count: 1,
+			})
+		}
+	}
+}
+
+// FlavorEvictions returns the number of times the workload was evicted while
+// using each flavor.
+func FlavorEvictions(wl *kueue.Workload) map[string]int32 {
+	if len(wl.Status.FlavorEvictions) == 0 {
+		return nil
+	}
+	evictions := make(map[string]int32, len(wl.Status.FlavorEvictions))
+	for _, e := range wl.Status.FlavorEvictions {
+		evictions[string(e.Name)] = e.Count
+	}
+	return evictions
+}
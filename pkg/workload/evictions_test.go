@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestRecordFlavorEvictions(t *testing.T) {
+	cases := map[string]struct {
+		admission *kueue.Admission
+		evictions []kueue.FlavorEvictions
+		want      []kueue.FlavorEvictions
+	}{
+		"not admitted": {
+			evictions: []kueue.FlavorEvictions{{Name: "spot", Count: 1}},
+			want:      []kueue.FlavorEvictions{{Name: "spot", Count: 1}},
+		},
+		"first eviction": {
+			admission: &kueue.Admission{
+				PodSetFlavors: []kueue.PodSetFlavors{
+					{
+						Name: "driver",
+						Flavors: map[corev1.ResourceName]string{
+							corev1.ResourceCPU:    "on-demand",
+							corev1.ResourceMemory: "on-demand",
+						},
+					},
+					{
+						Name: "workers",
+						Flavors: map[corev1.ResourceName]string{
+							corev1.ResourceCPU: "spot",
+							"example.com/gpu":  "a100",
+						},
+					},
+				},
+			},
+			want: []kueue.FlavorEvictions{
+				{Name: "a100", Count: 1},
+				{Name: "on-demand", Count: 1},
+				{Name: "spot", Count: 1},
+			},
+		},
+		"repeated eviction": {
+			admission: &kueue.Admission{
+				PodSetFlavors: []kueue.PodSetFlavors{
+					{
+						Name:    "main",
+						Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: "spot"},
+					},
+					{
+						Name:    "workers",
+						Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: "on-demand"},
+					},
+				},
+			},
+			evictions: []kueue.FlavorEvictions{{Name: "spot", Count: 2}},
+			want: []kueue.FlavorEvictions{
+				{Name: "spot", Count: 3},
+				{Name: "on-demand", Count: 1},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{
+				Spec:   kueue.WorkloadSpec{Admission: tc.admission},
+				Status: kueue.WorkloadStatus{FlavorEvictions: tc.evictions},
+			}
+			RecordFlavorEvictions(wl)
+			if diff := cmp.Diff(tc.want, wl.Status.FlavorEvictions); diff != "" {
+				t.Errorf("Unexpected flavor evictions (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
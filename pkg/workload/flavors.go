@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// AssignedFlavors summarizes the flavors assigned in the admission for each
+// resource, sorted by resource, such as "cpu=on-demand,memory=on-demand".
+// When a resource is assigned different flavors in different podSets, its
+// flavors are separated by "|".
+func AssignedFlavors(admission *kueue.Admission) string {
+	if admission == nil {
+		return ""
+	}
+	flavors := make(map[corev1.ResourceName]sets.String)
+	for _, ps := range admission.PodSetFlavors {
+		for res, f := range ps.Flavors {
+			if flavors[res] == nil {
+				flavors[res] = sets.NewString()
+			}
+			flavors[res].Insert(f)
+		}
+	}
+	resources := make([]string, 0, len(flavors))
+	for res := range flavors {
+		resources = append(resources, string(res))
+	}
+	sort.Strings(resources)
+	entries := make([]string, len(resources))
+	for i, res := range resources {
+		entries[i] = res + "=" + strings.Join(flavors[corev1.ResourceName(res)].List(), "|")
+	}
+	return strings.Join(entries, ",")
+}
+
+// RecordAssignedFlavors sets the summary of the flavors assigned in the
+// admission of the workload into its status. It returns whether the status
+// changed.
+func RecordAssignedFlavors(wl *kueue.Workload) bool {
+	summary := AssignedFlavors(wl.Spec.Admission)
+	if wl.Status.AssignedFlavors == summary {
+		return false
+	}
+	wl.Status.AssignedFlavors = summary
+	return true
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestRecordAssignedFlavors(t *testing.T) {
+	cases := map[string]struct {
+		admission   *kueue.Admission
+		assigned    string
+		want        string
+		wantChanged bool
+	}{
+		"not admitted": {},
+		"evicted": {
+			assigned:    "cpu=spot",
+			wantChanged: true,
+		},
+		"podSets with different flavors": {
+			admission: &kueue.Admission{
+				PodSetFlavors: []kueue.PodSetFlavors{
+					{
+						Name: "driver",
+						Flavors: map[corev1.ResourceName]string{
+							corev1.ResourceMemory: "on-demand",
+							corev1.ResourceCPU:    "on-demand",
+						},
+					},
+					{
+						Name: "workers",
+						Flavors: map[corev1.ResourceName]string{
+							corev1.ResourceCPU: "spot",
+							"example.com/gpu":  "a100",
+						},
+					},
+				},
+			},
+			want:        "cpu=on-demand|spot,example.com/gpu=a100,memory=on-demand",
+			wantChanged: true,
+		},
+		"unchanged": {
+			admission: &kueue.Admission{
+				PodSetFlavors: []kueue.PodSetFlavors{
+					{
+						Name:    "main",
+						Flavors: map[corev1.ResourceName]string{corev1.ResourceCPU: "spot"},
+					},
+				},
+			},
+			assigned: "cpu=spot",
+			want:     "cpu=spot",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{
+				Spec:   kueue.WorkloadSpec{Admission: tc.admission},
+				Status: kueue.WorkloadStatus{AssignedFlavors: tc.assigned},
+			}
+			changed := RecordAssignedFlavors(wl)
+			if changed != tc.wantChanged {
+				t.Errorf("RecordAssignedFlavors(_)=%t, want %t", changed, tc.wantChanged)
+			}
+			if wl.Status.AssignedFlavors != tc.want {
+				t.Errorf("Got assignedFlavors %q, want %q", wl.Status.AssignedFlavors, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+// Ordering determines the timestamps used to order workloads in the queues.
+type Ordering struct {
+	// RequeueByEvictionTime indicates that evicted workloads are ordered by
+	// the time of their last eviction, instead of their creation time.
+	RequeueByEvictionTime bool
+}
+
+// QueueOrderTimestamp returns the timestamp used to order the workload in
+// the queues. The workloads evicted because of a node failure get the zero
+// timestamp, so that they go ahead of the workloads with the same priority.
+func (o Ordering) QueueOrderTimestamp(w *kueue.Workload) *metav1.Time {
+	if c := apimeta.FindStatusCondition(w.Status.Conditions, kueue.WorkloadEvicted); c != nil && c.Status == metav1.ConditionTrue {
+		if c.Reason == constants.NodeFailureEvictionReason {
+			return &metav1.Time{}
+		}
+		if o.RequeueByEvictionTime {
+			return &c.LastTransitionTime
+		}
+	}
+	return &w.CreationTimestamp
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/constants"
+)
+
+func TestQueueOrderTimestamp(t *testing.T) {
+	created := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	evicted := metav1.NewTime(time.Now().Truncate(time.Second))
+	evictedCondition := func(status metav1.ConditionStatus) *kueue.Workload {
+		return &kueue.Workload{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+			Status: kueue.WorkloadStatus{
+				Conditions: []metav1.Condition{{
+					Type:               kueue.WorkloadEvicted,
+					Status:             status,
+					LastTransitionTime: evicted,
+				}},
+			},
+		}
+	}
+	cases := map[string]struct {
+		wl       *kueue.Workload
+		ordering Ordering
+		want     metav1.Time
+	}{
+		"never evicted": {
+			wl:       &kueue.Workload{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}},
+			ordering: Ordering{RequeueByEvictionTime: true},
+			want:     created,
+		},
+		"evicted, by eviction time": {
+			wl:       evictedCondition(metav1.ConditionTrue),
+			ordering: Ordering{RequeueByEvictionTime: true},
+			want:     evicted,
+		},
+		"evicted, by creation time": {
+			wl:   evictedCondition(metav1.ConditionTrue),
+			want: created,
+		},
+		"evicted because of a node failure": {
+			wl: func() *kueue.Workload {
+				wl := evictedCondition(metav1.ConditionTrue)
+				wl.Status.Conditions[0].Reason = constants.NodeFailureEvictionReason
+				return wl
+			}(),
+			ordering: Ordering{RequeueByEvictionTime: true},
+			want:     metav1.Time{},
+		},
+		"reserved quota again after eviction": {
+			wl:       evictedCondition(metav1.ConditionFalse),
+			ordering: Ordering{RequeueByEvictionTime: true},
+			want:     created,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.ordering.QueueOrderTimestamp(tc.wl)
+			if !got.Equal(&tc.want) {
+				t.Errorf("QueueOrderTimestamp returned %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// RequestsCache memoizes the total requests of the workloads computed with
+// given options, for the resourceVersion of each workload, so that they are
+// not computed again each time a workload is evaluated. The requests returned
+// are shared and must not be modified. It's not safe for concurrent use.
+type RequestsCache struct {
+	entries map[types.UID]*requestsCacheEntry
+}
+
+type requestsCacheEntry struct {
+	resourceVersion string
+	requests        map[InfoOptions][]PodSetResources
+	// used indicates that the entry was used since the last sweep.
+	used bool
+}
+
+func NewRequestsCache() *RequestsCache {
+	return &RequestsCache{
+		entries: make(map[types.UID]*requestsCacheEntry),
+	}
+}
+
+// TotalRequests returns the total requests of the podSets of the workload,
+// computed with the options.
+func (c *RequestsCache) TotalRequests(w *kueue.Workload, opts ...InfoOption) []PodSetResources {
+	var options InfoOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	entry := c.entries[w.UID]
+	if entry == nil || entry.resourceVersion != w.ResourceVersion {
+		entry = &requestsCacheEntry{
+			resourceVersion: w.ResourceVersion,
+			requests:        make(map[InfoOptions][]PodSetResources, 1),
+		}
+		c.entries[w.UID] = entry
+	}
+	entry.used = true
+	requests, ok := entry.requests[options]
+	if !ok {
+		requests = totalRequests(w, &options)
+		entry.requests[options] = requests
+	}
+	return requests
+}
+
+// Sweep drops the entries of the workloads that weren't used since the
+// previous sweep.
+func (c *RequestsCache) Sweep() {
+	for uid, entry := range c.entries {
+		if !entry.used {
+			delete(c.entries, uid)
+			continue
+		}
+		entry.used = false
+	}
+}
+
+// Len returns the number of workloads in the cache.
+func (c *RequestsCache) Len() int {
+	return len(c.entries)
+}
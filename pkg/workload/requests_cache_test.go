@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+)
+
+func TestRequestsCache(t *testing.T) {
+	wl := builder.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "1").Obj()
+	wl.UID = "uid"
+	wl.ResourceVersion = "1"
+	wl.Spec.PodSets[0].Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+	cache := NewRequestsCache()
+
+	got := cache.TotalRequests(wl)
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 1100}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected requests (-want,+got):\n%s", diff)
+	}
+	got = cache.TotalRequests(wl, WithoutPodOverhead())
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 1000}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected requests without overhead (-want,+got):\n%s", diff)
+	}
+
+	// The requests are memoized for the resourceVersion.
+	wl.Spec.PodSets[0].Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("2")
+	got = cache.TotalRequests(wl, WithoutPodOverhead())
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 1000}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected memoized requests (-want,+got):\n%s", diff)
+	}
+	wl.ResourceVersion = "2"
+	got = cache.TotalRequests(wl, WithoutPodOverhead())
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 2000}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected requests for the new resourceVersion (-want,+got):\n%s", diff)
+	}
+
+	// The entries are kept for one sweep after they are last used.
+	cache.Sweep()
+	if cache.Len() != 1 {
+		t.Errorf("Got %d entries after the first sweep, want 1", cache.Len())
+	}
+	cache.Sweep()
+	if cache.Len() != 0 {
+		t.Errorf("Got %d entries after the second sweep, want 0", cache.Len())
+	}
+}
+
+// doublingTransformer isn't comparable, so it can't be part of the key of the
+// cached requests.
+type doublingTransformer struct {
+	resources []corev1.ResourceName
+}
+
+func (t doublingTransformer) TransformRequests(_ *kueue.Workload, _ *kueue.PodSet, requests Requests) Requests {
+	res := make(Requests, len(requests))
+	for name, v := range requests {
+		res[name] = v
+	}
+	for _, name := range t.resources {
+		res[name] *= 2
+	}
+	return res
+}
+
+func TestRequestsCacheTransformer(t *testing.T) {
+	RegisterRequestsTransformer("doubling", doublingTransformer{resources: []corev1.ResourceName{corev1.ResourceCPU}})
+	t.Cleanup(func() {
+		delete(requestsTransformers, "doubling")
+	})
+	wl := builder.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "1").Obj()
+	wl.UID = "uid"
+	wl.ResourceVersion = "1"
+	cache := NewRequestsCache()
+
+	got := cache.TotalRequests(wl, WithRequestsTransformer("doubling"))
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 2000}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected transformed requests (-want,+got):\n%s", diff)
+	}
+	got = cache.TotalRequests(wl)
+	if diff := cmp.Diff(Requests{corev1.ResourceCPU: 1000}, got[0].Requests); diff != "" {
+		t.Errorf("Unexpected requests (-want,+got):\n%s", diff)
+	}
+}
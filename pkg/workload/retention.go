@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// Retention is how long the finished workloads are kept before they are
+// deleted. Zero keeps them until their owner is deleted.
+type Retention struct {
+	AfterSucceeded time.Duration
+	AfterFailed    time.Duration
+}
+
+// IsFailed returns whether the workload finished without succeeding.
+func IsFailed(wl *kueue.Workload) bool {
+	return apimeta.IsStatusConditionTrue(wl.Status.Conditions, kueue.WorkloadFailed)
+}
+
+// DeleteAfter returns how long until the finished workload has to be
+// deleted, which is zero if its retention already expired. It returns false
+// if the workload isn't finished or is kept until its owner is deleted.
+func (r Retention) DeleteAfter(wl *kueue.Workload, now time.Time) (time.Duration, bool) {
+	c := apimeta.FindStatusCondition(wl.Status.Conditions, kueue.WorkloadFinished)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		return 0, false
+	}
+	retention := r.AfterSucceeded
+	if IsFailed(wl) {
+		retention = r.AfterFailed
+	}
+	if retention == 0 {
+		return 0, false
+	}
+	if d := c.LastTransitionTime.Add(retention).Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestRetentionDeleteAfter(t *testing.T) {
+	now := time.Now()
+	finishedAt := metav1.NewTime(now.Add(-time.Hour))
+	finished := metav1.Condition{
+		Type:               kueue.WorkloadFinished,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: finishedAt,
+	}
+	failed := metav1.Condition{
+		Type:               kueue.WorkloadFailed,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: finishedAt,
+	}
+	retention := Retention{AfterSucceeded: 2 * time.Hour, AfterFailed: 30 * time.Minute}
+	cases := map[string]struct {
+		retention Retention
+		conds     []metav1.Condition
+		want      time.Duration
+		wantOk    bool
+	}{
+		"not finished": {
+			retention: retention,
+		},
+		"succeeded": {
+			retention: retention,
+			conds:     []metav1.Condition{finished},
+			want:      time.Hour,
+			wantOk:    true,
+		},
+		"failed, retention expired": {
+			retention: retention,
+			conds:     []metav1.Condition{finished, failed},
+			wantOk:    true,
+		},
+		"failed, kept": {
+			retention: Retention{AfterSucceeded: 2 * time.Hour},
+			conds:     []metav1.Condition{finished, failed},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := &kueue.Workload{Status: kueue.WorkloadStatus{Conditions: tc.conds}}
+			got, ok := tc.retention.DeleteAfter(wl, now)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("DeleteAfter(_)=(%v, %t), want (%v, %t)", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
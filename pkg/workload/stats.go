@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+// RecordQuotaReservation records in the scheduling stats of the workload that
+// it reserved quota at the given time, unless it was already recorded since
+// the workload was queued. Returns whether the stats changed.
+func RecordQuotaReservation(wl *kueue.Workload, now time.Time) bool {
+	stats := schedulingStats(wl)
+	changed := false
+	if stats.QueuedAt == nil {
+		stats.QueuedAt = wl.CreationTimestamp.DeepCopy()
+		changed = true
+	}
+	if stats.QuotaReservedAt == nil {
+		stats.QuotaReservedAt = timePtr(now)
+		changed = true
+	}
+	return changed
+}
+
+// RecordAdmission records in the scheduling stats of the workload that it was
+// admitted at the given time, unless it was already recorded since the
+// workload was queued. Returns whether the stats changed.
+func RecordAdmission(wl *kueue.Workload, now time.Time) bool {
+	changed := RecordQuotaReservation(wl, now)
+	if stats := wl.Status.SchedulingStats; stats.AdmittedAt == nil {
+		stats.AdmittedAt = timePtr(now)
+		changed = true
+	}
+	return changed
+}
+
+// RecordEviction records in the scheduling stats of the workload that it was
+// evicted for the reason, and queued again, at the given time. Returns whether
+// it's the first eviction of the workload for the reason.
+func RecordEviction(wl *kueue.Workload, reason string, now time.Time) bool {
+	stats := schedulingStats(wl)
+	stats.EvictionCount++
+	stats.QueuedAt = timePtr(now)
+	stats.QuotaReservedAt = nil
+	stats.AdmittedAt = nil
+	for i := range stats.Evictions {
+		if e := &stats.Evictions[i]; e.Reason == reason {
+			e.Count++
+			return false
+		}
+	}
+	stats.Evictions = append(stats.Evictions, kueue.EvictionsByReason{Reason: reason, Count: 1})
+	return true
+}
+
+func schedulingStats(wl *kueue.Workload) *kueue.SchedulingStats {
+	if wl.Status.SchedulingStats == nil {
+		wl.Status.SchedulingStats = &kueue.SchedulingStats{}
+	}
+	return wl.Status.SchedulingStats
+}
+
+func timePtr(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+func TestSchedulingStats(t *testing.T) {
+	created := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	reserved := created.Add(time.Minute)
+	admitted := created.Add(2 * time.Minute)
+	evicted := created.Add(3 * time.Minute)
+	wl := &kueue.Workload{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+
+	if !RecordQuotaReservation(wl, reserved) {
+		t.Errorf("RecordQuotaReservation didn't change the stats")
+	}
+	if RecordQuotaReservation(wl, admitted) {
+		t.Errorf("RecordQuotaReservation changed the stats again")
+	}
+	if !RecordAdmission(wl, admitted) {
+		t.Errorf("RecordAdmission didn't change the stats")
+	}
+	if RecordAdmission(wl, evicted) {
+		t.Errorf("RecordAdmission changed the stats again")
+	}
+	want := &kueue.SchedulingStats{
+		QueuedAt:        &created,
+		QuotaReservedAt: timePtr(reserved),
+		AdmittedAt:      timePtr(admitted),
+	}
+	if diff := cmp.Diff(want, wl.Status.SchedulingStats); diff != "" {
+		t.Errorf("Unexpected stats after the admission (-want,+got):\n%s", diff)
+	}
+
+	if !RecordEviction(wl, "Preempted", evicted) {
+		t.Errorf("RecordEviction wasn't the first eviction for the reason")
+	}
+	want = &kueue.SchedulingStats{
+		QueuedAt:      timePtr(evicted),
+		EvictionCount: 1,
+		Evictions:     []kueue.EvictionsByReason{{Reason: "Preempted", Count: 1}},
+	}
+	if diff := cmp.Diff(want, wl.Status.SchedulingStats); diff != "" {
+		t.Errorf("Unexpected stats after the eviction (-want,+got):\n%s", diff)
+	}
+}
+
+func TestRecordEvictionReasons(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	wl := &kueue.Workload{}
+	for _, tc := range []struct {
+		reason    string
+		wantFirst bool
+	}{
+		{reason: "AdmissionCheckRetry", wantFirst: true},
+		{reason: "AdmissionCheckRetry"},
+		{reason: "Inactive", wantFirst: true},
+		{reason: "AdmissionCheckRetry"},
+	} {
+		if got := RecordEviction(wl, tc.reason, now); got != tc.wantFirst {
+			t.Errorf("RecordEviction(%q) = %t, want %t", tc.reason, got, tc.wantFirst)
+		}
+	}
+	want := []kueue.EvictionsByReason{
+		{Reason: "AdmissionCheckRetry", Count: 3},
+		{Reason: "Inactive", Count: 1},
+	}
+	if diff := cmp.Diff(want, wl.Status.SchedulingStats.Evictions); diff != "" {
+		t.Errorf("Unexpected evictions (-want,+got):\n%s", diff)
+	}
+
+	// The evictions are read back from the persisted status, for example,
+	// after a restart.
+	restored := wl.DeepCopy()
+	if RecordEviction(restored, "Inactive", now) {
+		t.Errorf("RecordEviction counted again an eviction reason from the persisted status")
+	}
+}
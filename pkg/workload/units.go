@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceUnit is the unit in which the quantities of a resource are stored
+// as integers, in Requests and in the usage tracked by the cache, and the
+// format of the quantities built back from those integers.
+type ResourceUnit struct {
+	// Scale of the integers, relative to the base unit of the resource:
+	// resource.Milli for milli-units, or 0 for units.
+	Scale resource.Scale
+	// Format of the quantities built from the integers, for example,
+	// resource.BinarySI to report 1Gi instead of 1073741824.
+	Format resource.Format
+}
+
+var (
+	milliUnits   = ResourceUnit{Scale: resource.Milli, Format: resource.DecimalSI}
+	decimalUnits = ResourceUnit{Format: resource.DecimalSI}
+	binaryUnits  = ResourceUnit{Format: resource.BinarySI}
+)
+
+// resourceUnits holds the units that override the defaults. It's only
+// modified by SetResourceUnits, at startup.
+var resourceUnits map[corev1.ResourceName]ResourceUnit
+
+// SetResourceUnits overrides the units of the given resources. It must be
+// called before any quantity is converted, as the integers stored with
+// different units can't be compared.
+func SetResourceUnits(units map[corev1.ResourceName]ResourceUnit) {
+	resourceUnits = units
+}
+
+// UnitOf returns the unit of the resource. Unless overridden, it's milli-units
+// for CPU and units for everything else, with quantities in binary format for
+// memory, ephemeral storage and huge pages.
+func UnitOf(name corev1.ResourceName) ResourceUnit {
+	if u, ok := resourceUnits[name]; ok {
+		return u
+	}
+	switch name {
+	case corev1.ResourceCPU:
+		return milliUnits
+	case corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+		return binaryUnits
+	default:
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			return binaryUnits
+		}
+		return decimalUnits
+	}
+}
+
+// Value returns the quantity as an integer in the unit, rounded up.
+func (u ResourceUnit) Value(q resource.Quantity) int64 {
+	return q.ScaledValue(u.Scale)
+}
+
+// Quantity returns the quantity of an integer in the unit.
+func (u ResourceUnit) Quantity(v int64) resource.Quantity {
+	q := resource.NewScaledQuantity(v, u.Scale)
+	q.Format = u.Format
+	return *q
+}
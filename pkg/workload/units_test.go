@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceUnits(t *testing.T) {
+	cases := map[string]struct {
+		units     map[corev1.ResourceName]ResourceUnit
+		name      corev1.ResourceName
+		quantity  string
+		wantValue int64
+		wantQuant string
+	}{
+		"cpu": {
+			name:      corev1.ResourceCPU,
+			quantity:  "1500m",
+			wantValue: 1_500,
+			wantQuant: "1500m",
+		},
+		"memory": {
+			name:      corev1.ResourceMemory,
+			quantity:  "1Gi",
+			wantValue: 1 << 30,
+			wantQuant: "1Gi",
+		},
+		"hugepages": {
+			name:      corev1.ResourceHugePagesPrefix + "2Mi",
+			quantity:  "4Mi",
+			wantValue: 4 << 20,
+			wantQuant: "4Mi",
+		},
+		"extended resource rounded up": {
+			name:      "example.com/gpu",
+			quantity:  "0.5",
+			wantValue: 1,
+			wantQuant: "1",
+		},
+		"extended resource in milli-units": {
+			units: map[corev1.ResourceName]ResourceUnit{
+				"example.com/gpu": {Scale: resource.Milli, Format: resource.DecimalSI},
+			},
+			name:      "example.com/gpu",
+			quantity:  "0.5",
+			wantValue: 500,
+			wantQuant: "500m",
+		},
+		"memory in decimal format": {
+			units: map[corev1.ResourceName]ResourceUnit{
+				corev1.ResourceMemory: {Format: resource.DecimalSI},
+			},
+			name:      corev1.ResourceMemory,
+			quantity:  "1G",
+			wantValue: 1_000_000_000,
+			wantQuant: "1G",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			SetResourceUnits(tc.units)
+			defer SetResourceUnits(nil)
+			v := ResourceValue(tc.name, resource.MustParse(tc.quantity))
+			if v != tc.wantValue {
+				t.Errorf("ResourceValue(_)=%d, want %d", v, tc.wantValue)
+			}
+			q := ResourceQuantity(tc.name, v)
+			if q.String() != tc.wantQuant {
+				t.Errorf("ResourceQuantity(_)=%s, want %s", q.String(), tc.wantQuant)
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ package workload
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -38,6 +37,10 @@ type Info struct {
 	// Populated from the queue during admission or from the admission field if
 	// already admitted.
 	ClusterQueue string
+	// Round orders the workload with respect to the workloads of other
+	// LocalQueues and users, when the queues order the workloads fairly. Zero
+	// if they don't.
+	Round int
 }
 
 type PodSetResources struct {
@@ -46,10 +49,88 @@ type PodSetResources struct {
 	Flavors  map[corev1.ResourceName]string
 }
 
-func NewInfo(w *kueue.Workload) *Info {
+// InfoOptions control how the requests of a Workload are computed.
+type InfoOptions struct {
+	excludePodOverhead    bool
+	excludeInitContainers bool
+	// transformer is the name of a registered RequestsTransformer. The options
+	// only hold comparable values, as they are used to index the cached
+	// requests.
+	transformer string
+}
+
+// InfoOption configures the InfoOptions used by NewInfo.
+type InfoOption func(*InfoOptions)
+
+// WithoutPodOverhead excludes the pod overhead, set from the RuntimeClass,
+// from the requests of the podSets.
+func WithoutPodOverhead() InfoOption {
+	return func(o *InfoOptions) {
+		o.excludePodOverhead = true
+	}
+}
+
+// WithoutInitContainers excludes the requests of the init containers from
+// the requests of the podSets.
+func WithoutInitContainers() InfoOption {
+	return func(o *InfoOptions) {
+		o.excludeInitContainers = true
+	}
+}
+
+// RequestsTransformer translates the requests of a pod of a podSet before
+// they are checked against the quota. For example, it can translate the
+// requests of fractional or shared GPUs, like time-slicing or MIG, described
+// in the annotations of the workload, into quantities of the resources that
+// the ClusterQueues define quotas for.
+//
+// The implementations are registered with RegisterRequestsTransformer and
+// selected by name in the requestsTransformer field of the configuration.
+type RequestsTransformer interface {
+	// TransformRequests returns the requests of a pod of the podSet, given
+	// the requests computed from its spec. It must not modify the workload
+	// and it must return the same requests for the same workload.
+	TransformRequests(w *kueue.Workload, podSet *kueue.PodSet, requests Requests) Requests
+}
+
+// requestsTransformers holds the transformers by name. It's only modified by
+// RegisterRequestsTransformer, at startup.
+var requestsTransformers = make(map[string]RequestsTransformer)
+
+// RegisterRequestsTransformer registers the transformer with the name. It
+// must be called before the manager starts, typically from the init function
+// of the package that implements the transformer, which is linked into the
+// binary with a blank import. It panics if the name is already registered.
+func RegisterRequestsTransformer(name string, t RequestsTransformer) {
+	if _, ok := requestsTransformers[name]; ok {
+		panic(fmt.Sprintf("requests transformer %q already registered", name))
+	}
+	requestsTransformers[name] = t
+}
+
+// RequestsTransformerRegistered returns whether a transformer is registered
+// with the name.
+func RequestsTransformerRegistered(name string) bool {
+	_, ok := requestsTransformers[name]
+	return ok
+}
+
+// WithRequestsTransformer makes the transformer registered with the name
+// translate the requests of the pods of each podSet.
+func WithRequestsTransformer(name string) InfoOption {
+	return func(o *InfoOptions) {
+		o.transformer = name
+	}
+}
+
+func NewInfo(w *kueue.Workload, opts ...InfoOption) *Info {
+	var options InfoOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	info := &Info{
 		Obj:           w,
-		TotalRequests: totalRequests(&w.Spec),
+		TotalRequests: totalRequests(w, &options),
 	}
 	if w.Spec.Admission != nil {
 		info.ClusterQueue = string(w.Spec.Admission.ClusterQueue)
@@ -69,52 +150,122 @@ func QueueKey(w *kueue.Workload) string {
 	return fmt.Sprintf("%s/%s", w.Namespace, w.Spec.QueueName)
 }
 
-func totalRequests(spec *kueue.WorkloadSpec) []PodSetResources {
+// CheckLimits returns an error if the workload exceeds the limits on the
+// number of podSets or pods, which are enforced by the webhook. The scheduler
+// checks them too, for the workloads created while the webhook wasn't
+// installed, as the flavor assignment is combinatorial in the podSets.
+func CheckLimits(w *kueue.Workload) error {
+	if n := len(w.Spec.PodSets); n > kueue.MaxPodSets {
+		return fmt.Errorf("%d podSets exceed the maximum of %d", n, kueue.MaxPodSets)
+	}
+	var pods int64
+	for i := range w.Spec.PodSets {
+		pods += int64(w.Spec.PodSets[i].Count)
+	}
+	if pods > kueue.MaxPodsPerWorkload {
+		return fmt.Errorf("%d pods exceed the maximum of %d", pods, kueue.MaxPodsPerWorkload)
+	}
+	return nil
+}
+
+// PodsNeeded returns the number of pods of the podSet that still need
+// resources, excluding the reclaimable pods.
+func PodsNeeded(w *kueue.Workload, ps *kueue.PodSet) int32 {
+	for _, rp := range w.Status.ReclaimablePods {
+		if rp.Name == ps.Name {
+			if rp.Count >= ps.Count {
+				return 0
+			}
+			return ps.Count - rp.Count
+		}
+	}
+	return ps.Count
+}
+
+func totalRequests(w *kueue.Workload, opts *InfoOptions) []PodSetResources {
+	spec := &w.Spec
 	if len(spec.PodSets) == 0 {
 		return nil
 	}
 	res := make([]PodSetResources, 0, len(spec.PodSets))
-	var podSetFlavors map[string]map[corev1.ResourceName]string
+	var podSetFlavors map[string]*kueue.PodSetFlavors
 	if spec.Admission != nil {
-		podSetFlavors = make(map[string]map[corev1.ResourceName]string, len(spec.Admission.PodSetFlavors))
-		for _, ps := range spec.Admission.PodSetFlavors {
-			podSetFlavors[ps.Name] = ps.Flavors
+		podSetFlavors = make(map[string]*kueue.PodSetFlavors, len(spec.Admission.PodSetFlavors))
+		for i := range spec.Admission.PodSetFlavors {
+			ps := &spec.Admission.PodSetFlavors[i]
+			podSetFlavors[ps.Name] = ps
 		}
 	}
 
-	for _, ps := range spec.PodSets {
+	for i := range spec.PodSets {
+		ps := &spec.PodSets[i]
 		setRes := PodSetResources{
 			Name: ps.Name,
 		}
-		setRes.Requests = podRequests(&ps.Spec)
-		setRes.Requests.scale(int64(ps.Count))
-		flavors := podSetFlavors[ps.Name]
-		if len(flavors) > 0 {
-			setRes.Flavors = make(map[corev1.ResourceName]string, len(flavors))
-			for r, t := range flavors {
-				setRes.Flavors[r] = t
-			}
+		podReq := podRequests(&ps.Spec, opts)
+		if t := requestsTransformers[opts.transformer]; t != nil {
+			podReq = t.TransformRequests(w, ps, podReq)
+		}
+		needed := PodsNeeded(w, ps)
+		setRes.Requests = podReq.scaled(int64(needed))
+		admitted := podSetFlavors[ps.Name]
+		if admitted != nil {
+			setRes.Flavors = copyFlavors(admitted.Flavors)
 		}
 		res = append(res, setRes)
 	}
 	return res
 }
 
+func copyFlavors(flavors map[corev1.ResourceName]string) map[corev1.ResourceName]string {
+	if len(flavors) == 0 {
+		return nil
+	}
+	res := make(map[corev1.ResourceName]string, len(flavors))
+	for r, t := range flavors {
+		res[r] = t
+	}
+	return res
+}
+
 // The following resources calculations are inspired on
 // https://github.com/kubernetes/kubernetes/blob/master/pkg/scheduler/framework/types.go
 
 // Requests maps ResourceName to flavor to value; for CPU it is tracked in MilliCPU.
 type Requests map[corev1.ResourceName]int64
 
-func podRequests(spec *corev1.PodSpec) Requests {
+// PodRequests returns the requests of a pod with the spec, including the
+// requests of its init containers and its overhead.
+func PodRequests(spec *corev1.PodSpec) Requests {
+	return podRequests(spec, &InfoOptions{})
+}
+
+func podRequests(spec *corev1.PodSpec, opts *InfoOptions) Requests {
 	res := Requests{}
-	for _, c := range spec.Containers {
-		res.add(newRequests(c.Resources.Requests))
+	for i := range spec.Containers {
+		res.add(containerRequests(&spec.Containers[i]))
+	}
+	if !opts.excludeInitContainers {
+		for i := range spec.InitContainers {
+			res.setMax(containerRequests(&spec.InitContainers[i]))
+		}
 	}
-	for _, c := range spec.InitContainers {
-		res.setMax(newRequests(c.Resources.Requests))
+	if !opts.excludePodOverhead {
+		res.add(newRequests(spec.Overhead))
+	}
+	return res
+}
+
+// containerRequests returns the requests of the container. The resources with
+// a limit but no request, like huge pages and extended resources usually
+// are, request their limit, as the API server defaults them in the pods.
+func containerRequests(c *corev1.Container) Requests {
+	res := newRequests(c.Resources.Requests)
+	for name, quant := range c.Resources.Limits {
+		if _, ok := c.Resources.Requests[name]; !ok {
+			res[name] = ResourceValue(name, quant)
+		}
 	}
-	res.add(newRequests(spec.Overhead))
 	return res
 }
 
@@ -126,27 +277,16 @@ func newRequests(rl corev1.ResourceList) Requests {
 	return r
 }
 
-// ResourceValue returns the integer value for the resource name.
-// It's milli-units for CPU and absolute units for everything else.
+// ResourceValue returns the integer value for the resource name, in the unit
+// of the resource. See UnitOf.
 func ResourceValue(name corev1.ResourceName, q resource.Quantity) int64 {
-	if name == corev1.ResourceCPU {
-		return q.MilliValue()
-	}
-	return q.Value()
+	return UnitOf(name).Value(q)
 }
 
+// ResourceQuantity returns the quantity of an integer value for the resource
+// name, in the unit of the resource. See UnitOf.
 func ResourceQuantity(name corev1.ResourceName, v int64) resource.Quantity {
-	switch name {
-	case corev1.ResourceCPU:
-		return *resource.NewMilliQuantity(v, resource.DecimalSI)
-	case corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
-		return *resource.NewQuantity(v, resource.BinarySI)
-	default:
-		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
-			return *resource.NewQuantity(v, resource.BinarySI)
-		}
-		return *resource.NewQuantity(v, resource.DecimalSI)
-	}
+	return UnitOf(name).Quantity(v)
 }
 
 func (r Requests) add(o Requests) {
@@ -161,10 +301,12 @@ func (r Requests) setMax(o Requests) {
 	}
 }
 
-func (r Requests) scale(f int64) {
-	for name := range r {
-		r[name] *= f
+func (r Requests) scaled(f int64) Requests {
+	res := make(Requests, len(r))
+	for name, val := range r {
+		res[name] = val * f
 	}
+	return res
 }
 
 func max(v1, v2 int64) int64 {
@@ -242,3 +384,20 @@ func InCondition(w *kueue.Workload, condition string) bool {
 	i := FindConditionIndex(&w.Status, condition)
 	return i != -1 && w.Status.Conditions[i].Status == metav1.ConditionTrue
 }
+
+// SelectLabels returns the labels of the workload with the given keys, or nil
+// if the workload has none of them.
+func SelectLabels(wl *kueue.Workload, keys []string) map[string]string {
+	var selected map[string]string
+	for _, k := range keys {
+		v, ok := wl.Labels[k]
+		if !ok {
+			continue
+		}
+		if selected == nil {
+			selected = make(map[string]string, len(keys))
+		}
+		selected[k] = v
+	}
+	return selected
+}
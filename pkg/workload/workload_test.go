@@ -18,6 +18,8 @@ package workload
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -30,12 +32,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 )
 
 func TestPodRequests(t *testing.T) {
 	cases := map[string]struct {
 		spec         corev1.PodSpec
+		opts         InfoOptions
 		wantRequests Requests
 	}{
 		"core": {
@@ -91,6 +95,68 @@ func TestPodRequests(t *testing.T) {
 				"ex.com/ssd": 1,
 			},
 		},
+		"hugepages": {
+			spec: corev1.PodSpec{
+				Containers: containersForRequests(
+					map[corev1.ResourceName]string{
+						corev1.ResourceHugePagesPrefix + "2Mi": "4Mi",
+					},
+					map[corev1.ResourceName]string{
+						corev1.ResourceHugePagesPrefix + "2Mi": "2Mi",
+						corev1.ResourceHugePagesPrefix + "1Gi": "1Gi",
+					},
+				),
+				InitContainers: containersForRequests(
+					map[corev1.ResourceName]string{
+						corev1.ResourceHugePagesPrefix + "2Mi": "8Mi",
+					},
+				),
+			},
+			wantRequests: Requests{
+				corev1.ResourceHugePagesPrefix + "2Mi": 8 << 20,
+				corev1.ResourceHugePagesPrefix + "1Gi": 1 << 30,
+			},
+		},
+		"limits without requests": {
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("1"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:                     resource.MustParse("2"),
+								"ex.com/gpu":                           resource.MustParse("1"),
+								corev1.ResourceHugePagesPrefix + "2Mi": resource.MustParse("2Mi"),
+							},
+						},
+					},
+					{
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								"ex.com/gpu": resource.MustParse("2"),
+							},
+						},
+					},
+				},
+				InitContainers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								"ex.com/gpu":                           resource.MustParse("1"),
+								corev1.ResourceHugePagesPrefix + "2Mi": resource.MustParse("4Mi"),
+							},
+						},
+					},
+				},
+			},
+			wantRequests: Requests{
+				corev1.ResourceCPU:                     1000,
+				"ex.com/gpu":                           3,
+				corev1.ResourceHugePagesPrefix + "2Mi": 4 << 20,
+			},
+		},
 		"Pod Overhead defined": {
 			spec: corev1.PodSpec{
 				Containers: containersForRequests(
@@ -122,10 +188,51 @@ func TestPodRequests(t *testing.T) {
 				corev1.ResourceEphemeralStorage: 1024,
 			},
 		},
+		"Pod Overhead excluded": {
+			spec: corev1.PodSpec{
+				Containers: containersForRequests(
+					map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "10m",
+						corev1.ResourceMemory: "1Ki",
+					},
+				),
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("0.1"),
+					corev1.ResourceMemory: resource.MustParse("1Ki"),
+				},
+			},
+			opts: InfoOptions{excludePodOverhead: true},
+			wantRequests: Requests{
+				corev1.ResourceCPU:    10,
+				corev1.ResourceMemory: 1024,
+			},
+		},
+		"init containers excluded": {
+			spec: corev1.PodSpec{
+				Containers: containersForRequests(
+					map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "10m",
+						corev1.ResourceMemory: "1Ki",
+					},
+				),
+				InitContainers: containersForRequests(
+					map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "20m",
+						corev1.ResourceMemory: "4Ki",
+						"ex.com/ssd":          "1",
+					},
+				),
+			},
+			opts: InfoOptions{excludeInitContainers: true},
+			wantRequests: Requests{
+				corev1.ResourceCPU:    10,
+				corev1.ResourceMemory: 1024,
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			gotRequests := podRequests(&tc.spec)
+			gotRequests := podRequests(&tc.spec, &tc.opts)
 			if diff := cmp.Diff(tc.wantRequests, gotRequests); diff != "" {
 				t.Errorf("podRequests returned unexpected requests (-want,+got):\n%s", diff)
 			}
@@ -233,6 +340,35 @@ func TestNewInfo(t *testing.T) {
 				},
 			},
 		},
+		"with reclaimable pods": {
+			workload: kueue.Workload{
+				Spec: kueue.WorkloadSpec{
+					PodSets: []kueue.PodSet{
+						{
+							Name: "workers",
+							Spec: utiltesting.PodSpecForRequest(
+								map[corev1.ResourceName]string{
+									corev1.ResourceCPU: "1",
+								}),
+							Count: 5,
+						},
+					},
+				},
+				Status: kueue.WorkloadStatus{
+					ReclaimablePods: []kueue.ReclaimablePod{{Name: "workers", Count: 2}},
+				},
+			},
+			wantInfo: Info{
+				TotalRequests: []PodSetResources{
+					{
+						Name: "workers",
+						Requests: Requests{
+							corev1.ResourceCPU: 3000,
+						},
+					},
+				},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -244,6 +380,103 @@ func TestNewInfo(t *testing.T) {
 	}
 }
 
+// timeSlicingTransformer translates the requests of time-sliced GPUs into
+// whole GPUs, given the number of replicas of each GPU in an annotation.
+type timeSlicingTransformer struct{}
+
+func (*timeSlicingTransformer) TransformRequests(w *kueue.Workload, _ *kueue.PodSet, requests Requests) Requests {
+	shared, ok := requests["example.com/gpu.shared"]
+	if !ok {
+		return requests
+	}
+	replicas, err := strconv.ParseInt(w.Annotations["example.com/gpu-replicas"], 10, 64)
+	if err != nil || replicas < 1 {
+		return requests
+	}
+	res := make(Requests, len(requests))
+	for name, v := range requests {
+		res[name] = v
+	}
+	delete(res, "example.com/gpu.shared")
+	res["example.com/gpu"] += (shared + replicas - 1) / replicas
+	return res
+}
+
+func TestRequestsTransformer(t *testing.T) {
+	RegisterRequestsTransformer("time-slicing", &timeSlicingTransformer{})
+	t.Cleanup(func() {
+		delete(requestsTransformers, "time-slicing")
+	})
+	wl := builder.MakeWorkload("foo", "ns").
+		Annotation("example.com/gpu-replicas", "4").
+		PodSets([]kueue.PodSet{
+			{
+				Name: "main",
+				Spec: corev1.PodSpec{
+					Containers: containersForRequests(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:       "1",
+						"example.com/gpu.shared": "6",
+					}),
+				},
+				Count: 3,
+			},
+		}).
+		Obj()
+	want := []PodSetResources{
+		{
+			Name: "main",
+			Requests: Requests{
+				corev1.ResourceCPU: 3_000,
+				"example.com/gpu":  6,
+			},
+		},
+	}
+	info := NewInfo(wl, WithRequestsTransformer("time-slicing"))
+	if diff := cmp.Diff(want, info.TotalRequests); diff != "" {
+		t.Errorf("Unexpected requests (-want,+got):\n%s", diff)
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	cases := map[string]struct {
+		podSets []kueue.PodSet
+		wantErr bool
+	}{
+		"within the limits": {
+			podSets: []kueue.PodSet{
+				{Name: "driver", Count: 1},
+				{Name: "workers", Count: 99999},
+			},
+		},
+		"too many podSets": {
+			podSets: func() []kueue.PodSet {
+				ps := make([]kueue.PodSet, kueue.MaxPodSets+1)
+				for i := range ps {
+					ps[i] = kueue.PodSet{Name: fmt.Sprintf("ps%d", i), Count: 1}
+				}
+				return ps
+			}(),
+			wantErr: true,
+		},
+		"too many pods": {
+			podSets: []kueue.PodSet{
+				{Name: "driver", Count: 1},
+				{Name: "workers", Count: kueue.MaxPodsPerWorkload},
+			},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := kueue.Workload{Spec: kueue.WorkloadSpec{PodSets: tc.podSets}}
+			err := CheckLimits(&wl)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("CheckLimits(_) = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 var ignoreConditionTimestamps = cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")
 
 func TestUpdateWorkloadStatus(t *testing.T) {
@@ -326,7 +559,7 @@ func TestUpdateWorkloadStatus(t *testing.T) {
 			if err := kueue.AddToScheme(scheme); err != nil {
 				t.Fatalf("Failed to add kueue scheme: %v", err)
 			}
-			workload := utiltesting.MakeWorkload("foo", "bar").Obj()
+			workload := builder.MakeWorkload("foo", "bar").Obj()
 			workload.Status = tc.oldStatus
 			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workload).Build()
 			ctx := context.Background()
@@ -345,6 +578,32 @@ func TestUpdateWorkloadStatus(t *testing.T) {
 	}
 }
 
+func TestSelectLabels(t *testing.T) {
+	wl := builder.MakeWorkload("wl", "ns").Obj()
+	wl.Labels = map[string]string{"example.com/team": "ml", "user": "alice"}
+	cases := map[string]struct {
+		keys []string
+		want map[string]string
+	}{
+		"no keys": {},
+		"some keys": {
+			keys: []string{"example.com/team", "tier"},
+			want: map[string]string{"example.com/team": "ml"},
+		},
+		"none of the keys": {
+			keys: []string{"tier"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := SelectLabels(wl, tc.keys)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected labels (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func containersForRequests(requests ...map[corev1.ResourceName]string) []corev1.Container {
 	containers := make([]corev1.Container, len(requests))
 	for i, r := range requests {
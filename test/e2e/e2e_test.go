@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	"sigs.k8s.io/kueue/test/integration/framework"
+)
+
+// The worker of the kind cluster has this label, see hack/kind-cluster.yaml.
+const (
+	instanceTypeLabel = "instance-type"
+	onDemand          = "on-demand"
+)
+
+var _ = ginkgo.Describe("Kueue", func() {
+	var (
+		ns           *corev1.Namespace
+		onDemandRF   *kueue.ResourceFlavor
+		clusterQueue *kueue.ClusterQueue
+		localQueue   *kueue.LocalQueue
+	)
+
+	ginkgo.BeforeEach(func() {
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-"}}
+		gomega.Expect(k8sClient.Create(ctx, ns)).To(gomega.Succeed())
+
+		onDemandRF = builder.MakeResourceFlavor(onDemand).Label(instanceTypeLabel, onDemand).Obj()
+		// The webhooks might not be ready right after the deployment.
+		gomega.Eventually(func() error {
+			return k8sClient.Create(ctx, onDemandRF)
+		}, timeout, interval).Should(gomega.Succeed())
+
+		clusterQueue = builder.MakeClusterQueue("cluster-queue").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor(onDemand, "1").Obj()).Obj()).
+			Obj()
+		gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
+
+		localQueue = builder.MakeLocalQueue("main", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(framework.DeleteNamespace(ctx, k8sClient, ns)).To(gomega.Succeed())
+		framework.ExpectClusterQueueToBeDeleted(ctx, k8sClient, clusterQueue, true)
+		framework.ExpectResourceFlavorToBeDeleted(ctx, k8sClient, onDemandRF, true)
+	})
+
+	ginkgo.It("Should start the pods of a job only once it's admitted, on the nodes of its flavor", func() {
+		job := builder.MakeJob("job", ns.Name).
+			Queue(localQueue.Name).
+			Image(sleepImage, []string{"1ms"}).
+			Request(corev1.ResourceCPU, "500m").
+			Obj()
+		gomega.Expect(k8sClient.Create(ctx, job)).To(gomega.Succeed())
+
+		ginkgo.By("checking that the job stays suspended while its LocalQueue doesn't exist")
+		gomega.Consistently(func() []corev1.Pod {
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job)).To(gomega.Succeed())
+			gomega.Expect(job.Spec.Suspend).To(gomega.Equal(pointer.Bool(true)))
+			return jobPods(job)
+		}, framework.ConsistentDuration, interval).Should(gomega.BeEmpty())
+
+		ginkgo.By("creating the LocalQueue")
+		gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
+
+		ginkgo.By("checking that the job runs on the nodes of the flavor")
+		gomega.Eventually(func() []corev1.Pod {
+			return jobPods(job)
+		}, timeout, interval).ShouldNot(gomega.BeEmpty())
+		for _, pod := range jobPods(job) {
+			gomega.Expect(pod.Spec.NodeSelector).To(gomega.HaveKeyWithValue(instanceTypeLabel, onDemand))
+		}
+		expectJobToFinish(job)
+	})
+
+	ginkgo.It("Should not start more jobs than the quota allows", func() {
+		gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
+		first := builder.MakeJob("first", ns.Name).
+			Queue(localQueue.Name).
+			Image(sleepImage, []string{"10s"}).
+			Request(corev1.ResourceCPU, "1").
+			Obj()
+		gomega.Expect(k8sClient.Create(ctx, first)).To(gomega.Succeed())
+		gomega.Eventually(func() []corev1.Pod {
+			return jobPods(first)
+		}, timeout, interval).ShouldNot(gomega.BeEmpty())
+
+		ginkgo.By("checking that the second job waits for the quota")
+		second := builder.MakeJob("second", ns.Name).
+			Queue(localQueue.Name).
+			Image(sleepImage, []string{"1ms"}).
+			Request(corev1.ResourceCPU, "1").
+			Obj()
+		gomega.Expect(k8sClient.Create(ctx, second)).To(gomega.Succeed())
+		framework.ExpectWorkloadsToBePending(ctx, k8sClient, workloadFor(second))
+		gomega.Expect(jobPods(second)).To(gomega.BeEmpty())
+
+		ginkgo.By("checking that the second job starts once the first one finishes")
+		expectJobToFinish(first)
+		expectJobToFinish(second)
+	})
+})
+
+// jobPods returns the pods created for the job.
+func jobPods(job *batchv1.Job) []corev1.Pod {
+	var pods corev1.PodList
+	gomega.ExpectWithOffset(1, k8sClient.List(ctx, &pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name})).To(gomega.Succeed())
+	return pods.Items
+}
+
+// workloadFor returns the workload created for the job.
+func workloadFor(job *batchv1.Job) *kueue.Workload {
+	var wl kueue.Workload
+	key := client.ObjectKey{Namespace: job.Namespace, Name: job.Name}
+	gomega.EventuallyWithOffset(1, func() error {
+		return k8sClient.Get(ctx, key, &wl)
+	}, timeout, interval).Should(gomega.Succeed())
+	return &wl
+}
+
+func expectJobToFinish(job *batchv1.Job) {
+	gomega.EventuallyWithOffset(1, func() bool {
+		var updated batchv1.Job
+		gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(job), &updated)).To(gomega.Succeed())
+		for _, c := range updated.Status.Conditions {
+			if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+		return false
+	}, timeout, interval).Should(gomega.BeTrue())
+}
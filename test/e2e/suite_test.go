@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+)
+
+const (
+	// timeout is longer than in the integration tests, as the pods of the
+	// jobs have to be scheduled and their images pulled.
+	timeout  = 2 * time.Minute
+	interval = time.Second
+
+	// sleepImage is the image of the jobs, which exits after the duration
+	// given as argument.
+	sleepImage = "gcr.io/k8s-staging-perf-tests/sleep:v0.0.3"
+)
+
+var (
+	k8sClient client.Client
+	ctx       context.Context
+)
+
+func TestE2E(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+
+	ginkgo.RunSpecs(t,
+		"End To End Suite",
+	)
+}
+
+// The suite runs against the cluster of the current kubeconfig context, where
+// Kueue is already deployed. See hack/e2e-test.sh.
+var _ = ginkgo.BeforeSuite(func() {
+	ctx = context.Background()
+	cfg := ctrl.GetConfigOrDie()
+	gomega.Expect(kueue.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+
+	var err error
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+})
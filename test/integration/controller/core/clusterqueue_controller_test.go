@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
 	"sigs.k8s.io/kueue/pkg/util/testing"
@@ -77,15 +78,15 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 		)
 
 		ginkgo.BeforeEach(func() {
-			clusterQueue = testing.MakeClusterQueue("cluster-queue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).
-					Flavor(testing.MakeFlavor(flavorSpot, "5").Max("10").Obj()).Obj()).
-				Resource(testing.MakeResource(resourceGPU).
-					Flavor(testing.MakeFlavor(flavorModelA, "5").Max("10").Obj()).
-					Flavor(testing.MakeFlavor(flavorModelB, "5").Max("10").Obj()).Obj()).Obj()
+			clusterQueue = builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).
+					Flavor(builder.MakeFlavor(flavorSpot, "5").Max("10").Obj()).Obj()).
+				Resource(builder.MakeResource(resourceGPU).
+					Flavor(builder.MakeFlavor(flavorModelA, "5").Max("10").Obj()).
+					Flavor(builder.MakeFlavor(flavorModelB, "5").Max("10").Obj()).Obj()).Obj()
 			gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
-			localQueue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+			localQueue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
 		})
 
@@ -95,17 +96,17 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 
 		ginkgo.It("Should update status when workloads are assigned and finish", func() {
 			workloads := []*kueue.Workload{
-				testing.MakeWorkload("one", ns.Name).Queue(localQueue.Name).
+				builder.MakeWorkload("one", ns.Name).Queue(localQueue.Name).
 					Request(corev1.ResourceCPU, "2").Request(resourceGPU, "2").Obj(),
-				testing.MakeWorkload("two", ns.Name).Queue(localQueue.Name).
+				builder.MakeWorkload("two", ns.Name).Queue(localQueue.Name).
 					Request(corev1.ResourceCPU, "3").Request(resourceGPU, "3").Obj(),
-				testing.MakeWorkload("three", ns.Name).Queue(localQueue.Name).
+				builder.MakeWorkload("three", ns.Name).Queue(localQueue.Name).
 					Request(corev1.ResourceCPU, "1").Request(resourceGPU, "1").Obj(),
-				testing.MakeWorkload("four", ns.Name).Queue(localQueue.Name).
+				builder.MakeWorkload("four", ns.Name).Queue(localQueue.Name).
 					Request(corev1.ResourceCPU, "1").Request(resourceGPU, "1").Obj(),
-				testing.MakeWorkload("five", ns.Name).Queue("other").
+				builder.MakeWorkload("five", ns.Name).Queue("other").
 					Request(corev1.ResourceCPU, "1").Request(resourceGPU, "1").Obj(),
-				testing.MakeWorkload("six", ns.Name).Queue(localQueue.Name).
+				builder.MakeWorkload("six", ns.Name).Queue(localQueue.Name).
 					Request(corev1.ResourceCPU, "1").Request(resourceGPU, "1").Obj(),
 			}
 
@@ -127,15 +128,15 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 
 			ginkgo.By("Admitting workloads")
 			admissions := []*kueue.Admission{
-				testing.MakeAdmission(clusterQueue.Name).
+				builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Flavor(resourceGPU, flavorModelA).Obj(),
-				testing.MakeAdmission(clusterQueue.Name).
+				builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Flavor(resourceGPU, flavorModelA).Obj(),
-				testing.MakeAdmission(clusterQueue.Name).
+				builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Flavor(resourceGPU, flavorModelB).Obj(),
-				testing.MakeAdmission(clusterQueue.Name).
+				builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorSpot).Flavor(resourceGPU, flavorModelB).Obj(),
-				testing.MakeAdmission("other").
+				builder.MakeAdmission("other").
 					Flavor(corev1.ResourceCPU, flavorSpot).Flavor(resourceGPU, flavorModelB).Obj(),
 				nil,
 			}
@@ -198,8 +199,8 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 		)
 
 		ginkgo.BeforeEach(func() {
-			cq = testing.MakeClusterQueue("foo-cq").Obj()
-			lq = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			cq = builder.MakeClusterQueue("foo-cq").Obj()
+			lq = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, lq)).To(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, cq)).To(gomega.Succeed())
 		})
@@ -212,8 +213,8 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 			framework.ExpectClusterQueueStatusMetric(cq, metrics.CQStatusActive)
 
 			ginkgo.By("Admit workload")
-			admission := testing.MakeAdmission(cq.Name).Obj()
-			wl := testing.MakeWorkload("workload", ns.Name).Queue(lq.Name).Admit(admission).Obj()
+			admission := builder.MakeAdmission(cq.Name).Obj()
+			wl := builder.MakeWorkload("workload", ns.Name).Queue(lq.Name).Admit(admission).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
 
 			ginkgo.By("Delete clusterQueue")
@@ -24,7 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	"sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
@@ -47,12 +47,12 @@ var _ = ginkgo.Describe("Queue controller", func() {
 	})
 
 	ginkgo.BeforeEach(func() {
-		clusterQueue = testing.MakeClusterQueue("cluster-queue.queue-controller").
-			Resource(testing.MakeResource(resourceGPU).
-				Flavor(testing.MakeFlavor(flavorModelA, "5").Max("10").Obj()).
-				Flavor(testing.MakeFlavor(flavorModelB, "5").Max("10").Obj()).Obj()).Obj()
+		clusterQueue = builder.MakeClusterQueue("cluster-queue.queue-controller").
+			Resource(builder.MakeResource(resourceGPU).
+				Flavor(builder.MakeFlavor(flavorModelA, "5").Max("10").Obj()).
+				Flavor(builder.MakeFlavor(flavorModelB, "5").Max("10").Obj()).Obj()).Obj()
 		gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
-		queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+		queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
 		gomega.Expect(k8sClient.Create(ctx, queue)).To(gomega.Succeed())
 	})
 
@@ -63,13 +63,13 @@ var _ = ginkgo.Describe("Queue controller", func() {
 
 	ginkgo.It("Should update status when workloads are created", func() {
 		workloads := []*kueue.Workload{
-			testing.MakeWorkload("one", ns.Name).
+			builder.MakeWorkload("one", ns.Name).
 				Queue(queue.Name).
 				Request(corev1.ResourceCPU, "2").Obj(),
-			testing.MakeWorkload("two", ns.Name).
+			builder.MakeWorkload("two", ns.Name).
 				Queue(queue.Name).
 				Request(corev1.ResourceCPU, "3").Obj(),
-			testing.MakeWorkload("three", ns.Name).
+			builder.MakeWorkload("three", ns.Name).
 				Queue(queue.Name).
 				Request(corev1.ResourceCPU, "1").Obj(),
 		}
@@ -89,7 +89,7 @@ var _ = ginkgo.Describe("Queue controller", func() {
 			gomega.Eventually(func() error {
 				var newWL kueue.Workload
 				gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(w), &newWL)).To(gomega.Succeed())
-				newWL.Spec.Admission = testing.MakeAdmission(clusterQueue.Name).
+				newWL.Spec.Admission = builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()
 				return k8sClient.Update(ctx, &newWL)
 			}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
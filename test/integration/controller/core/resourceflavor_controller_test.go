@@ -24,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
@@ -52,10 +53,10 @@ var _ = ginkgo.Describe("ResourceFlavor controller", func() {
 		var flavor *kueue.Flavor
 
 		ginkgo.BeforeEach(func() {
-			resourceFlavor = utiltesting.MakeResourceFlavor("cq-refer-resourceflavor").Obj()
-			flavor = utiltesting.MakeFlavor(resourceFlavor.Name, "5").Obj()
-			clusterQueue = utiltesting.MakeClusterQueue("foo").
-				Resource(utiltesting.MakeResource("cpu").Flavor(flavor).Obj()).
+			resourceFlavor = builder.MakeResourceFlavor("cq-refer-resourceflavor").Obj()
+			flavor = builder.MakeFlavor(resourceFlavor.Name, "5").Obj()
+			clusterQueue = builder.MakeClusterQueue("foo").
+				Resource(builder.MakeResource("cpu").Flavor(flavor).Obj()).
 				Obj()
 
 			gomega.Expect(k8sClient.Create(ctx, resourceFlavor)).To(gomega.Succeed())
@@ -28,8 +28,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
-	"sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
@@ -72,7 +72,7 @@ var _ = ginkgo.Describe("Workload controller", func() {
 			gomega.Expect(framework.DeleteNamespace(ctx, k8sClient, ns)).To(gomega.Succeed())
 		})
 		ginkgo.It("Should update status when workloads are created", func() {
-			wl = testing.MakeWorkload("one", ns.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl = builder.MakeWorkload("one", ns.Name).Request(corev1.ResourceCPU, "1").Obj()
 			message = fmt.Sprintf("Queue %s doesn't exist", "")
 			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
 			gomega.Eventually(func() int {
@@ -88,7 +88,7 @@ var _ = ginkgo.Describe("Workload controller", func() {
 			gomega.Expect(framework.DeleteNamespace(ctx, k8sClient, ns)).To(gomega.Succeed())
 		})
 		ginkgo.It("Should update status when workloads are created", func() {
-			wl = testing.MakeWorkload("two", ns.Name).Queue("non-created-queue").Request(corev1.ResourceCPU, "1").Obj()
+			wl = builder.MakeWorkload("two", ns.Name).Queue("non-created-queue").Request(corev1.ResourceCPU, "1").Obj()
 			message = fmt.Sprintf("Queue %s doesn't exist", "non-created-queue")
 			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
 			gomega.Eventually(func() int {
@@ -101,14 +101,14 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 	ginkgo.When("the clusterqueue doesn't exist", func() {
 		ginkgo.BeforeEach(func() {
-			localQueue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue("fooclusterqueue").Obj()
+			localQueue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue("fooclusterqueue").Obj()
 			gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
 		})
 		ginkgo.AfterEach(func() {
 			gomega.Expect(framework.DeleteNamespace(ctx, k8sClient, ns)).To(gomega.Succeed())
 		})
 		ginkgo.It("Should update status when workloads are created", func() {
-			wl = testing.MakeWorkload("three", ns.Name).Queue(localQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl = builder.MakeWorkload("three", ns.Name).Queue(localQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			message = fmt.Sprintf("ClusterQueue %s doesn't exist", "fooclusterqueue")
 			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
 			gomega.Eventually(func() []metav1.Condition {
@@ -123,14 +123,14 @@ var _ = ginkgo.Describe("Workload controller", func() {
 		var flavor *kueue.ResourceFlavor
 
 		ginkgo.BeforeEach(func() {
-			flavor = testing.MakeResourceFlavor(flavorOnDemand).Obj()
+			flavor = builder.MakeResourceFlavor(flavorOnDemand).Obj()
 			gomega.Expect(k8sClient.Create(ctx, flavor)).Should(gomega.Succeed())
-			clusterQueue = testing.MakeClusterQueue("cluster-queue").
-				Resource(testing.MakeResource(resourceGPU).
-					Flavor(testing.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
+			clusterQueue = builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource(resourceGPU).
+					Flavor(builder.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
-			localQueue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+			localQueue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
 		})
 		ginkgo.AfterEach(func() {
@@ -141,12 +141,12 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 		ginkgo.It("Should update the workload's condition", func() {
 			ginkgo.By("Create workload")
-			wl = testing.MakeWorkload("one", ns.Name).Queue(localQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl = builder.MakeWorkload("one", ns.Name).Queue(localQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
 
 			ginkgo.By("Admit workload")
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), &updatedQueueWorkload)).To(gomega.Succeed())
-			updatedQueueWorkload.Spec.Admission = testing.MakeAdmission(clusterQueue.Name).
+			updatedQueueWorkload.Spec.Admission = builder.MakeAdmission(clusterQueue.Name).
 				Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()
 			gomega.Expect(k8sClient.Update(ctx, &updatedQueueWorkload)).To(gomega.Succeed())
 			gomega.Eventually(func() bool {
@@ -158,14 +158,14 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 	ginkgo.When("Workload with RuntimeClass defined", func() {
 		ginkgo.BeforeEach(func() {
-			runtimeClass = testing.MakeRuntimeClass("kata", "bar-handler").PodOverhead(resources).Obj()
+			runtimeClass = builder.MakeRuntimeClass("kata", "bar-handler").PodOverhead(resources).Obj()
 			gomega.Expect(k8sClient.Create(ctx, runtimeClass)).To(gomega.Succeed())
-			clusterQueue = testing.MakeClusterQueue("clusterqueue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
+			clusterQueue = builder.MakeClusterQueue("clusterqueue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
-			localQueue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+			localQueue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
 		})
 		ginkgo.AfterEach(func() {
@@ -176,10 +176,10 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 		ginkgo.It("Should accumulate RuntimeClass's overhead", func() {
 			ginkgo.By("Create workload")
-			wl = testing.MakeWorkload("one", ns.Name).
+			wl = builder.MakeWorkload("one", ns.Name).
 				Queue(localQueue.Name).
 				Request(corev1.ResourceCPU, "1").
-				Admit(testing.MakeAdmission(clusterQueue.Name).
+				Admit(builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()).
 				RuntimeClass("kata").
 				Obj()
@@ -206,12 +206,12 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 	ginkgo.When("Workload with non-existent RuntimeClass defined", func() {
 		ginkgo.BeforeEach(func() {
-			clusterQueue = testing.MakeClusterQueue("clusterqueue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
+			clusterQueue = builder.MakeClusterQueue("clusterqueue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(flavorOnDemand, "5").Max("10").Obj()).Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, clusterQueue)).To(gomega.Succeed())
-			localQueue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
+			localQueue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(clusterQueue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, localQueue)).To(gomega.Succeed())
 		})
 		ginkgo.AfterEach(func() {
@@ -221,10 +221,10 @@ var _ = ginkgo.Describe("Workload controller", func() {
 
 		ginkgo.It("Should not accumulate RuntimeClass's overhead", func() {
 			ginkgo.By("Create workload")
-			wl = testing.MakeWorkload("one", ns.Name).
+			wl = builder.MakeWorkload("one", ns.Name).
 				Queue(localQueue.Name).
 				Request(corev1.ResourceCPU, "1").
-				Admit(testing.MakeAdmission(clusterQueue.Name).
+				Admit(builder.MakeAdmission(clusterQueue.Name).
 					Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()).
 				RuntimeClass("kata").
 				Obj()
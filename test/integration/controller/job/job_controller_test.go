@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/controller/workload/job"
 	workloadjob "sigs.k8s.io/kueue/pkg/controller/workload/job"
@@ -67,6 +68,7 @@ var _ = ginkgo.Describe("Job controller", func() {
 		fwk = &framework.Framework{
 			ManagerSetup: managerSetup(job.WithManageJobsWithoutQueueName(true)),
 			CRDPath:      crdPath,
+			SkipWebhooks: true,
 		}
 		ctx, cfg, k8sClient = fwk.Setup()
 	})
@@ -75,10 +77,10 @@ var _ = ginkgo.Describe("Job controller", func() {
 	})
 	ginkgo.It("Should reconcile workload and job for all jobs", func() {
 		ginkgo.By("checking the job gets suspended when created unsuspended")
-		priorityClass := testing.MakePriorityClass(priorityClassName).
+		priorityClass := builder.MakePriorityClass(priorityClassName).
 			PriorityValue(int32(priorityValue)).Obj()
 		gomega.Expect(k8sClient.Create(ctx, priorityClass)).Should(gomega.Succeed())
-		job := testing.MakeJob(jobName, jobNamespace).PriorityClass(priorityClassName).Obj()
+		job := builder.MakeJob(jobName, jobNamespace).PriorityClass(priorityClassName).Obj()
 		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
 		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
 		createdJob := &batchv1.Job{}
@@ -134,14 +136,14 @@ var _ = ginkgo.Describe("Job controller", func() {
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 
 		ginkgo.By("checking the job is unsuspended when workload is assigned")
-		onDemandFlavor := testing.MakeResourceFlavor("on-demand").Label(labelKey, "on-demand").Obj()
+		onDemandFlavor := builder.MakeResourceFlavor("on-demand").Label(labelKey, "on-demand").Obj()
 		gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
-		spotFlavor := testing.MakeResourceFlavor("spot").Label(labelKey, "spot").Obj()
+		spotFlavor := builder.MakeResourceFlavor("spot").Label(labelKey, "spot").Obj()
 		gomega.Expect(k8sClient.Create(ctx, spotFlavor)).Should(gomega.Succeed())
-		clusterQueue := testing.MakeClusterQueue("cluster-queue").
-			Resource(testing.MakeResource(corev1.ResourceCPU).
-				Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
-				Flavor(testing.MakeFlavor(spotFlavor.Name, "5").Obj()).
+		clusterQueue := builder.MakeClusterQueue("cluster-queue").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Flavor(builder.MakeFlavor(spotFlavor.Name, "5").Obj()).
 				Obj()).Obj()
 		gomega.Expect(k8sClient.Create(ctx, clusterQueue)).Should(gomega.Succeed())
 		createdWorkload.Spec.Admission = &kueue.Admission{
@@ -248,6 +250,7 @@ var _ = ginkgo.Describe("Job controller for workloads with no queue set", func()
 		fwk = &framework.Framework{
 			ManagerSetup: managerSetup(),
 			CRDPath:      crdPath,
+			SkipWebhooks: true,
 		}
 		ctx, cfg, k8sClient = fwk.Setup()
 	})
@@ -256,7 +259,7 @@ var _ = ginkgo.Describe("Job controller for workloads with no queue set", func()
 	})
 	ginkgo.It("Should reconcile jobs only when queue is set", func() {
 		ginkgo.By("checking the workload is not created when queue name is not set")
-		job := testing.MakeJob(jobName, jobNamespace).Obj()
+		job := builder.MakeJob(jobName, jobNamespace).Obj()
 		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
 		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
 		createdJob := &batchv1.Job{}
@@ -297,6 +300,7 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 		fwk = &framework.Framework{
 			ManagerSetup: managerAndSchedulerSetup(),
 			CRDPath:      crdPath,
+			SkipWebhooks: true,
 		}
 		ctx, cfg, k8sClient = fwk.Setup()
 
@@ -307,10 +311,10 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 		}
 		gomega.Expect(k8sClient.Create(ctx, ns)).To(gomega.Succeed())
 
-		onDemandFlavor = testing.MakeResourceFlavor("on-demand").Label(instanceKey, "on-demand").Obj()
+		onDemandFlavor = builder.MakeResourceFlavor("on-demand").Label(instanceKey, "on-demand").Obj()
 		gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 
-		spotTaintedFlavor = testing.MakeResourceFlavor("spot-tainted").
+		spotTaintedFlavor = builder.MakeResourceFlavor("spot-tainted").
 			Label(instanceKey, "spot-tainted").
 			Taint(corev1.Taint{
 				Key:    instanceKey,
@@ -319,30 +323,30 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 			}).Obj()
 		gomega.Expect(k8sClient.Create(ctx, spotTaintedFlavor)).Should(gomega.Succeed())
 
-		spotUntaintedFlavor = testing.MakeResourceFlavor("spot-untainted").Label(instanceKey, "spot-untainted").Obj()
+		spotUntaintedFlavor = builder.MakeResourceFlavor("spot-untainted").Label(instanceKey, "spot-untainted").Obj()
 		gomega.Expect(k8sClient.Create(ctx, spotUntaintedFlavor)).Should(gomega.Succeed())
 
-		prodClusterQ = testing.MakeClusterQueue("prod-cq").
+		prodClusterQ = builder.MakeClusterQueue("prod-cq").
 			Cohort("prod").
-			Resource(testing.MakeResource(corev1.ResourceCPU).
-				Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
-				Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
+				Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 				Obj()).
 			Obj()
 		gomega.Expect(k8sClient.Create(ctx, prodClusterQ)).Should(gomega.Succeed())
 
-		devClusterQ = testing.MakeClusterQueue("dev-clusterqueue").
-			Resource(testing.MakeResource(corev1.ResourceCPU).
-				Flavor(testing.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
-				Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+		devClusterQ = builder.MakeClusterQueue("dev-clusterqueue").
+			Resource(builder.MakeResource(corev1.ResourceCPU).
+				Flavor(builder.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
+				Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 				Obj()).
 			Obj()
 		gomega.Expect(k8sClient.Create(ctx, devClusterQ)).Should(gomega.Succeed())
 
-		prodLocalQ = testing.MakeLocalQueue("prod-queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
+		prodLocalQ = builder.MakeLocalQueue("prod-queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
 		gomega.Expect(k8sClient.Create(ctx, prodLocalQ)).Should(gomega.Succeed())
 
-		devLocalQ = testing.MakeLocalQueue("dev-queue", ns.Name).ClusterQueue(devClusterQ.Name).Obj()
+		devLocalQ = builder.MakeLocalQueue("dev-queue", ns.Name).ClusterQueue(devClusterQ.Name).Obj()
 		gomega.Expect(k8sClient.Create(ctx, devLocalQ)).Should(gomega.Succeed())
 	})
 
@@ -359,7 +363,7 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 
 	ginkgo.It("Should schedule jobs as they fit in their ClusterQueue", func() {
 		ginkgo.By("checking the first prod job starts")
-		prodJob1 := testing.MakeJob("prod-job1", ns.Name).Queue(prodLocalQ.Name).Request(corev1.ResourceCPU, "2").Obj()
+		prodJob1 := builder.MakeJob("prod-job1", ns.Name).Queue(prodLocalQ.Name).Request(corev1.ResourceCPU, "2").Obj()
 		gomega.Expect(k8sClient.Create(ctx, prodJob1)).Should(gomega.Succeed())
 		lookupKey1 := types.NamespacedName{Name: prodJob1.Name, Namespace: prodJob1.Namespace}
 		createdProdJob1 := &batchv1.Job{}
@@ -372,7 +376,7 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 		framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 
 		ginkgo.By("checking a second no-fit prod job does not start")
-		prodJob2 := testing.MakeJob("prod-job2", ns.Name).Queue(prodLocalQ.Name).Request(corev1.ResourceCPU, "5").Obj()
+		prodJob2 := builder.MakeJob("prod-job2", ns.Name).Queue(prodLocalQ.Name).Request(corev1.ResourceCPU, "5").Obj()
 		gomega.Expect(k8sClient.Create(ctx, prodJob2)).Should(gomega.Succeed())
 		lookupKey2 := types.NamespacedName{Name: prodJob2.Name, Namespace: prodJob2.Namespace}
 		createdProdJob2 := &batchv1.Job{}
@@ -384,7 +388,7 @@ var _ = ginkgo.Describe("Job controller interacting with scheduler", func() {
 		framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 
 		ginkgo.By("checking a dev job starts")
-		devJob := testing.MakeJob("dev-job", ns.Name).Queue(devLocalQ.Name).Request(corev1.ResourceCPU, "5").Obj()
+		devJob := builder.MakeJob("dev-job", ns.Name).Queue(devLocalQ.Name).Request(corev1.ResourceCPU, "5").Obj()
 		gomega.Expect(k8sClient.Create(ctx, devJob)).Should(gomega.Succeed())
 		createdDevJob := &batchv1.Job{}
 		gomega.Eventually(func() *bool {
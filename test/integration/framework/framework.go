@@ -14,6 +14,10 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package framework provides a harness to run integration tests against an
+// envtest API server with the Kueue CRDs installed, along with helpers to
+// clean up and assert on the state of Kueue objects. It can be imported by
+// out-of-tree job integrations to reuse the same harness.
 package framework
 
 import (
@@ -21,6 +25,8 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
@@ -47,16 +53,53 @@ import (
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
+// ManagerSetup registers the controllers and webhooks under test in the
+// manager, before it's started.
 type ManagerSetup func(manager.Manager, context.Context)
 
+// Framework starts an envtest API server and a manager configured with
+// ManagerSetup.
 type Framework struct {
-	CRDPath      string
-	WebhookPath  string
+	// CRDPath is the directory containing the Kueue CRDs. Defaults to
+	// KueueCRDPath().
+	CRDPath string
+	// DepCRDPaths are additional directories of CRDs to install, such as the
+	// CRDs of the jobs of an out-of-tree integration.
+	DepCRDPaths []string
+	// WebhookPath is the directory containing the webhook configurations,
+	// such as KueueWebhookPath(). The webhooks aren't installed if it's empty.
+	WebhookPath string
+	// SkipWebhooks indicates that the manager doesn't serve webhooks, for the
+	// suites that only need the controllers. The suite doesn't wait for the
+	// webhook server to be ready then.
+	SkipWebhooks bool
 	ManagerSetup ManagerSetup
 	testEnv      *envtest.Environment
 	cancel       context.CancelFunc
 }
 
+// KueueCRDPath returns the directory of the Kueue CRDs in the source tree of
+// this module.
+func KueueCRDPath() string {
+	return filepath.Join(moduleRoot(), "config", "components", "crd", "bases")
+}
+
+// KueueWebhookPath returns the directory of the Kueue webhook configurations
+// in the source tree of this module.
+func KueueWebhookPath() string {
+	return filepath.Join(moduleRoot(), "config", "components", "webhook")
+}
+
+// moduleRoot returns the root of the source tree of this module, which is
+// also available when the module is a dependency.
+func moduleRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..")
+}
+
+// Setup starts the test environment and the manager. It returns the context
+// of the manager, which is cancelled by Teardown, the configuration to access
+// the API server and a client.
 func (f *Framework) Setup() (context.Context, *rest.Config, client.Client) {
 	opts := func(o *zap.Options) {
 		o.TimeEncoder = zapcore.RFC3339NanoTimeEncoder
@@ -70,8 +113,12 @@ func (f *Framework) Setup() (context.Context, *rest.Config, client.Client) {
 	)
 
 	ginkgo.By("bootstrapping test environment")
+	crdPath := f.CRDPath
+	if crdPath == "" {
+		crdPath = KueueCRDPath()
+	}
 	f.testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{f.CRDPath},
+		CRDDirectoryPaths:     append([]string{crdPath}, f.DepCRDPaths...),
 		ErrorIfCRDPathMissing: true,
 	}
 	webhookEnabled := len(f.WebhookPath) > 0
@@ -96,9 +143,11 @@ func (f *Framework) Setup() (context.Context, *rest.Config, client.Client) {
 	mgrOpts := manager.Options{
 		Scheme:             scheme.Scheme,
 		MetricsBindAddress: "0", // disable metrics to avoid conflicts between packages.
-		Host:               webhookInstallOptions.LocalServingHost,
-		Port:               webhookInstallOptions.LocalServingPort,
-		CertDir:            webhookInstallOptions.LocalServingCertDir,
+	}
+	if !f.SkipWebhooks {
+		mgrOpts.Host = webhookInstallOptions.LocalServingHost
+		mgrOpts.Port = webhookInstallOptions.LocalServingPort
+		mgrOpts.CertDir = webhookInstallOptions.LocalServingCertDir
 	}
 	mgr, err := ctrl.NewManager(cfg, mgrOpts)
 	gomega.ExpectWithOffset(1, err).NotTo(gomega.HaveOccurred(), "failed to create manager")
@@ -113,7 +162,7 @@ func (f *Framework) Setup() (context.Context, *rest.Config, client.Client) {
 		gomega.ExpectWithOffset(1, err).NotTo(gomega.HaveOccurred(), "failed to run manager")
 	}()
 
-	if webhookEnabled {
+	if webhookEnabled && !f.SkipWebhooks {
 		// wait for the webhook server to get ready
 		dialer := &net.Dialer{Timeout: time.Second}
 		addrPort := fmt.Sprintf("%s:%d", webhookInstallOptions.LocalServingHost, webhookInstallOptions.LocalServingPort)
@@ -130,6 +179,7 @@ func (f *Framework) Setup() (context.Context, *rest.Config, client.Client) {
 	return ctx, cfg, k8sClient
 }
 
+// Teardown stops the manager and the test environment.
 func (f *Framework) Teardown() {
 	ginkgo.By("tearing down the test environment")
 	f.cancel()
@@ -25,9 +25,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
-	"sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
@@ -54,9 +54,9 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		}
 		gomega.Expect(k8sClient.Create(ctx, ns)).To(gomega.Succeed())
 
-		onDemandFlavor = testing.MakeResourceFlavor("on-demand").Label(instanceKey, "on-demand").Obj()
+		onDemandFlavor = builder.MakeResourceFlavor("on-demand").Label(instanceKey, "on-demand").Obj()
 
-		spotTaintedFlavor = testing.MakeResourceFlavor("spot-tainted").
+		spotTaintedFlavor = builder.MakeResourceFlavor("spot-tainted").
 			Label(instanceKey, "spot-tainted").
 			Taint(corev1.Taint{
 				Key:    instanceKey,
@@ -71,7 +71,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			Effect:   corev1.TaintEffectNoSchedule,
 		}
 
-		spotUntaintedFlavor = testing.MakeResourceFlavor("spot-untainted").Label(instanceKey, "spot-untainted").Obj()
+		spotUntaintedFlavor = builder.MakeResourceFlavor("spot-untainted").Label(instanceKey, "spot-untainted").Obj()
 	})
 
 	ginkgo.When("Scheduling workloads on clusterQueues", func() {
@@ -87,26 +87,26 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Create(ctx, spotTaintedFlavor)).To(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, spotUntaintedFlavor)).To(gomega.Succeed())
 
-			prodClusterQ = testing.MakeClusterQueue("prod-cq").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			prodClusterQ = builder.MakeClusterQueue("prod-cq").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodClusterQ)).Should(gomega.Succeed())
 
-			devClusterQ = testing.MakeClusterQueue("dev-clusterqueue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			devClusterQ = builder.MakeClusterQueue("dev-clusterqueue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, devClusterQ)).Should(gomega.Succeed())
 
-			prodQueue = testing.MakeLocalQueue("prod-queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
+			prodQueue = builder.MakeLocalQueue("prod-queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodQueue)).Should(gomega.Succeed())
 
-			devQueue = testing.MakeLocalQueue("dev-queue", ns.Name).ClusterQueue(devClusterQ.Name).Obj()
+			devQueue = builder.MakeLocalQueue("dev-queue", ns.Name).ClusterQueue(devClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, devQueue)).Should(gomega.Succeed())
 		})
 
@@ -121,24 +121,24 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should admit workloads as they fit in their ClusterQueue", func() {
 			ginkgo.By("checking the first prod workload gets admitted")
-			prodWl1 := testing.MakeWorkload("prod-wl1", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2").Obj()
+			prodWl1 := builder.MakeWorkload("prod-wl1", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2").Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodWl1)).Should(gomega.Succeed())
-			onDemandFlavorAdmission := testing.MakeAdmission(prodClusterQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			onDemandFlavorAdmission := builder.MakeAdmission(prodClusterQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, prodWl1, onDemandFlavorAdmission)
 			framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(prodClusterQ, 1)
 
 			ginkgo.By("checking a second no-fit workload does not get admitted")
-			prodWl2 := testing.MakeWorkload("prod-wl2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
+			prodWl2 := builder.MakeWorkload("prod-wl2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodWl2)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, prodWl2)
 			framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 1)
 
 			ginkgo.By("checking a dev workload gets admitted")
-			devWl := testing.MakeWorkload("dev-wl", ns.Name).Queue(devQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
+			devWl := builder.MakeWorkload("dev-wl", ns.Name).Queue(devQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
 			gomega.Expect(k8sClient.Create(ctx, devWl)).Should(gomega.Succeed())
-			spotUntaintedFlavorAdmission := testing.MakeAdmission(devClusterQ.Name).Flavor(corev1.ResourceCPU, spotUntaintedFlavor.Name).Obj()
+			spotUntaintedFlavorAdmission := builder.MakeAdmission(devClusterQ.Name).Flavor(corev1.ResourceCPU, spotUntaintedFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, devWl, spotUntaintedFlavorAdmission)
 			framework.ExpectPendingWorkloadsMetric(devClusterQ, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(devClusterQ, 1)
@@ -153,13 +153,13 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should admit workloads according to their priorities", func() {
-			queue := testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
+			queue := builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(prodClusterQ.Name).Obj()
 
 			lowPriorityVal, highPriorityVal := int32(10), int32(100)
 
-			wlLowPriority := testing.MakeWorkload("wl-low-priority", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Priority(&lowPriorityVal).Obj()
+			wlLowPriority := builder.MakeWorkload("wl-low-priority", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Priority(&lowPriorityVal).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wlLowPriority)).Should(gomega.Succeed())
-			wlHighPriority := testing.MakeWorkload("wl-high-priority", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Priority(&highPriorityVal).Obj()
+			wlHighPriority := builder.MakeWorkload("wl-high-priority", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Priority(&highPriorityVal).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wlHighPriority)).Should(gomega.Succeed())
 
 			framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 0)
@@ -179,7 +179,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should admit two small workloads after a big one finishes", func() {
-			bigWl := testing.MakeWorkload("big-wl", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
+			bigWl := builder.MakeWorkload("big-wl", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
 			ginkgo.By("Creating big workload")
 			gomega.Expect(k8sClient.Create(ctx, bigWl)).Should(gomega.Succeed())
 
@@ -188,8 +188,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(prodClusterQ, 1)
 
-			smallWl1 := testing.MakeWorkload("small-wl-1", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
-			smallWl2 := testing.MakeWorkload("small-wl-2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
+			smallWl1 := builder.MakeWorkload("small-wl-1", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
+			smallWl2 := builder.MakeWorkload("small-wl-2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
 			ginkgo.By("Creating two small workloads")
 			gomega.Expect(k8sClient.Create(ctx, smallWl1)).Should(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, smallWl2)).Should(gomega.Succeed())
@@ -218,15 +218,15 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, spotTaintedFlavor)).Should(gomega.Succeed())
 
-			cq = testing.MakeClusterQueue("cluster-queue").
+			cq = builder.MakeClusterQueue("cluster-queue").
 				Cohort("prod").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 		})
 
@@ -239,16 +239,16 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should re-enqueue by the delete event of workload belonging to the same ClusterQueue", func() {
 			ginkgo.By("First big workload starts")
-			wl1 := testing.MakeWorkload("on-demand-wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "4").Obj()
+			wl1 := builder.MakeWorkload("on-demand-wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "4").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			expectAdmission := testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl1, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 1)
 
 			ginkgo.By("Second big workload is pending")
-			wl2 := testing.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "4").Obj()
+			wl2 := builder.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "4").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl2)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
@@ -256,7 +256,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 1)
 
 			ginkgo.By("Third small workload starts")
-			wl3 := testing.MakeWorkload("on-demand-wl3", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl3 := builder.MakeWorkload("on-demand-wl3", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl3)).Should(gomega.Succeed())
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl3, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
@@ -272,10 +272,10 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should re-enqueue by the delete event of workload belonging to the same Cohort", func() {
-			fooCQ := testing.MakeClusterQueue("foo-clusterqueue").
+			fooCQ := builder.MakeClusterQueue("foo-clusterqueue").
 				Cohort(cq.Spec.Cohort).
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, fooCQ)).Should(gomega.Succeed())
@@ -283,20 +283,20 @@ var _ = ginkgo.Describe("Scheduler", func() {
 				gomega.Expect(framework.DeleteClusterQueue(ctx, k8sClient, fooCQ)).Should(gomega.Succeed())
 			}()
 
-			fooQ := testing.MakeLocalQueue("foo-queue", ns.Name).ClusterQueue(fooCQ.Name).Obj()
+			fooQ := builder.MakeLocalQueue("foo-queue", ns.Name).ClusterQueue(fooCQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, fooQ)).Should(gomega.Succeed())
 
 			ginkgo.By("First big workload starts")
-			wl1 := testing.MakeWorkload("on-demand-wl1", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "8").Obj()
+			wl1 := builder.MakeWorkload("on-demand-wl1", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "8").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			expectAdmission := testing.MakeAdmission(fooCQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(fooCQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl1, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(fooCQ, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(fooCQ, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(fooCQ, 1)
 
 			ginkgo.By("Second big workload is pending")
-			wl2 := testing.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "8").Obj()
+			wl2 := builder.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "8").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl2)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
@@ -304,9 +304,9 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 0)
 
 			ginkgo.By("Third small workload starts")
-			wl3 := testing.MakeWorkload("on-demand-wl3", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "2").Obj()
+			wl3 := builder.MakeWorkload("on-demand-wl3", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "2").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl3)).Should(gomega.Succeed())
-			expectAdmission = testing.MakeAdmission(fooCQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission = builder.MakeAdmission(fooCQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl3, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(fooCQ, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(fooCQ, 2)
@@ -314,7 +314,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 			ginkgo.By("Second big workload starts after the first one is deleted")
 			gomega.Expect(k8sClient.Delete(ctx, wl1, client.PropagationPolicy(metav1.DeletePropagationBackground))).Should(gomega.Succeed())
-			expectAdmission = testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission = builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl2, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 1)
@@ -331,13 +331,13 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		ginkgo.BeforeEach(func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 
-			cq = testing.MakeClusterQueue("cluster-queue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			cq = builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 		})
 
@@ -347,7 +347,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectResourceFlavorToBeDeleted(ctx, k8sClient, onDemandFlavor, true)
 		})
 		ginkgo.It("Should re-enqueue by the update event of ClusterQueue", func() {
-			wl := testing.MakeWorkload("on-demand-wl", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "6").Obj()
+			wl := builder.MakeWorkload("on-demand-wl", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "6").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
@@ -358,11 +358,11 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			updatedCq := &kueue.ClusterQueue{}
 			gomega.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: cq.Name}, updatedCq)).Should(gomega.Succeed())
 
-			updatedResource := testing.MakeResource(corev1.ResourceCPU).Flavor(testing.MakeFlavor(onDemandFlavor.Name, "6").Max("6").Obj()).Obj()
+			updatedResource := builder.MakeResource(corev1.ResourceCPU).Flavor(builder.MakeFlavor(onDemandFlavor.Name, "6").Max("6").Obj()).Obj()
 			updatedCq.Spec.Resources = []kueue.Resource{*updatedResource}
 			gomega.Expect(k8sClient.Update(ctx, updatedCq)).Should(gomega.Succeed())
 
-			expectAdmission := testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 1)
@@ -381,7 +381,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		ginkgo.BeforeEach(func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 
-			cq = testing.MakeClusterQueue("cluster-queue-with-selector").
+			cq = builder.MakeClusterQueue("cluster-queue-with-selector").
 				NamespaceSelector(&metav1.LabelSelector{
 					MatchExpressions: []metav1.LabelSelectorRequirement{
 						{
@@ -391,11 +391,11 @@ var _ = ginkgo.Describe("Scheduler", func() {
 						},
 					},
 				}).
-				Resource(testing.MakeResource(corev1.ResourceCPU).Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 
 			nsFoo = &corev1.Namespace{
@@ -404,7 +404,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 				},
 			}
 			gomega.Expect(k8sClient.Create(ctx, nsFoo)).To(gomega.Succeed())
-			queueFoo = testing.MakeLocalQueue("foo", nsFoo.Name).ClusterQueue(cq.Name).Obj()
+			queueFoo = builder.MakeLocalQueue("foo", nsFoo.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queueFoo)).Should(gomega.Succeed())
 		})
 
@@ -416,9 +416,9 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should admit workloads from the selected namespaces", func() {
 			ginkgo.By("checking the workloads don't get admitted at first")
-			wl1 := testing.MakeWorkload("wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl1 := builder.MakeWorkload("wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			wl2 := testing.MakeWorkload("wl2", nsFoo.Name).Queue(queueFoo.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl2 := builder.MakeWorkload("wl2", nsFoo.Name).Queue(queueFoo.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl1, wl2)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 2)
@@ -442,14 +442,14 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		)
 
 		ginkgo.BeforeEach(func() {
-			fooCQ = testing.MakeClusterQueue("foo-cq").
+			fooCQ = builder.MakeClusterQueue("foo-cq").
 				QueueingStrategy(kueue.BestEffortFIFO).
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor("foo-flavor", "15").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor("foo-flavor", "15").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, fooCQ)).Should(gomega.Succeed())
-			fooQ = testing.MakeLocalQueue("foo-queue", ns.Name).ClusterQueue(fooCQ.Name).Obj()
+			fooQ = builder.MakeLocalQueue("foo-queue", ns.Name).ClusterQueue(fooCQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, fooQ)).Should(gomega.Succeed())
 		})
 
@@ -461,7 +461,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		ginkgo.It("Should be inactive until the flavor is created", func() {
 			ginkgo.By("Creating one workload")
 			framework.ExpectClusterQueueStatusMetric(fooCQ, metrics.CQStatusPending)
-			wl := testing.MakeWorkload("workload", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl := builder.MakeWorkload("workload", ns.Name).Queue(fooQ.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBeFrozen(ctx, k8sClient, fooCQ.Name, wl)
 			framework.ExpectPendingWorkloadsMetric(fooCQ, 0, 1)
@@ -469,7 +469,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedWorkloadsTotalMetric(fooCQ, 0)
 
 			ginkgo.By("Creating foo flavor")
-			fooFlavor := testing.MakeResourceFlavor("foo-flavor").Obj()
+			fooFlavor := builder.MakeResourceFlavor("foo-flavor").Obj()
 			gomega.Expect(k8sClient.Create(ctx, fooFlavor)).Should(gomega.Succeed())
 			defer func() {
 				gomega.Expect(framework.DeleteResourceFlavor(ctx, k8sClient, fooFlavor)).To(gomega.Succeed())
@@ -492,16 +492,16 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, spotTaintedFlavor)).Should(gomega.Succeed())
 
-			cq = testing.MakeClusterQueue("cluster-queue").
+			cq = builder.MakeClusterQueue("cluster-queue").
 				QueueingStrategy(kueue.BestEffortFIFO).
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 		})
 
@@ -514,17 +514,17 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should schedule workloads on tolerated flavors", func() {
 			ginkgo.By("checking a workload without toleration starts on the non-tainted flavor")
-			wl1 := testing.MakeWorkload("on-demand-wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Obj()
+			wl1 := builder.MakeWorkload("on-demand-wl1", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
 
-			expectAdmission := testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl1, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 1)
 
 			ginkgo.By("checking a second workload without toleration doesn't start")
-			wl2 := testing.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Obj()
+			wl2 := builder.MakeWorkload("on-demand-wl2", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "5").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl2)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
@@ -532,10 +532,10 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 1)
 
 			ginkgo.By("checking a third workload with toleration starts")
-			wl3 := testing.MakeWorkload("on-demand-wl3", ns.Name).Queue(queue.Name).Toleration(spotToleration).Request(corev1.ResourceCPU, "5").Obj()
+			wl3 := builder.MakeWorkload("on-demand-wl3", ns.Name).Queue(queue.Name).Toleration(spotToleration).Request(corev1.ResourceCPU, "5").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl3)).Should(gomega.Succeed())
 
-			expectAdmission = testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, spotTaintedFlavor.Name).Obj()
+			expectAdmission = builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, spotTaintedFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl3, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 1)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 2)
@@ -553,15 +553,15 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, spotUntaintedFlavor)).Should(gomega.Succeed())
 
-			cq = testing.MakeClusterQueue("cluster-queue").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			cq = builder.MakeClusterQueue("cluster-queue").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotUntaintedFlavor.Name, "5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 		})
 
@@ -574,21 +574,21 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should admit workloads with affinity to specific flavor", func() {
 			ginkgo.By("checking a workload without affinity gets admitted on the first flavor")
-			wl1 := testing.MakeWorkload("no-affinity-workload", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			wl1 := builder.MakeWorkload("no-affinity-workload", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			expectAdmission := testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, spotUntaintedFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, spotUntaintedFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl1, expectAdmission)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 1)
 			framework.ExpectAdmittedWorkloadsTotalMetric(cq, 1)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 
 			ginkgo.By("checking a second workload with affinity to on-demand gets admitted")
-			wl2 := testing.MakeWorkload("affinity-wl", ns.Name).Queue(queue.Name).
+			wl2 := builder.MakeWorkload("affinity-wl", ns.Name).Queue(queue.Name).
 				NodeSelector(map[string]string{instanceKey: onDemandFlavor.Name, "foo": "bar"}).
 				Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			gomega.Expect(len(wl2.Spec.PodSets[0].Spec.NodeSelector)).Should(gomega.Equal(2))
-			expectAdmission = testing.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission = builder.MakeAdmission(cq.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl2, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(cq, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(cq, 2)
@@ -604,13 +604,13 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		)
 
 		ginkgo.BeforeEach(func() {
-			cq = testing.MakeClusterQueue("cq").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+			cq = builder.MakeClusterQueue("cq").
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
-			q = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
-			w = testing.MakeWorkload("workload", ns.Name).Queue(q.Name).Request(corev1.ResourceCPU, "2").Obj()
+			q = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			w = builder.MakeWorkload("workload", ns.Name).Queue(q.Name).Request(corev1.ResourceCPU, "2").Obj()
 		})
 
 		ginkgo.AfterEach(func() {
@@ -672,20 +672,20 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should admit workloads using borrowed ClusterQueue", func() {
-			prodBEClusterQ = testing.MakeClusterQueue("prod-be-cq").
+			prodBEClusterQ = builder.MakeClusterQueue("prod-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Max("5").Obj()).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodBEClusterQ)).Should(gomega.Succeed())
 
-			queue := testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
+			queue := builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 
 			ginkgo.By("checking a no-fit workload does not get admitted")
-			wl := testing.MakeWorkload("wl", ns.Name).Queue(queue.Name).
+			wl := builder.MakeWorkload("wl", ns.Name).Queue(queue.Name).
 				Request(corev1.ResourceCPU, "10").Toleration(spotToleration).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl)).Should(gomega.Succeed())
 			framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl)
@@ -694,11 +694,11 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectAdmittedWorkloadsTotalMetric(prodBEClusterQ, 0)
 
 			ginkgo.By("checking the workload gets admitted when a fallback ClusterQueue gets added")
-			fallbackClusterQueue := testing.MakeClusterQueue("fallback-cq").
+			fallbackClusterQueue := builder.MakeClusterQueue("fallback-cq").
 				Cohort(prodBEClusterQ.Spec.Cohort).
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(spotTaintedFlavor.Name, "5").Obj()). // cluster-queue can't borrow this flavor due to its max quota.
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(spotTaintedFlavor.Name, "5").Obj()). // cluster-queue can't borrow this flavor due to its max quota.
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, fallbackClusterQueue)).Should(gomega.Succeed())
@@ -706,7 +706,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 				gomega.Expect(framework.DeleteClusterQueue(ctx, k8sClient, fallbackClusterQueue)).ToNot(gomega.HaveOccurred())
 			}()
 
-			expectAdmission := testing.MakeAdmission(prodBEClusterQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
+			expectAdmission := builder.MakeAdmission(prodBEClusterQ.Name).Flavor(corev1.ResourceCPU, onDemandFlavor.Name).Obj()
 			framework.ExpectWorkloadToBeAdmittedAs(ctx, k8sClient, wl, expectAdmission)
 			framework.ExpectPendingWorkloadsMetric(prodBEClusterQ, 0, 0)
 			framework.ExpectAdmittedActiveWorkloadsMetric(prodBEClusterQ, 1)
@@ -714,29 +714,29 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should schedule workloads borrowing quota from ClusterQueues in the same Cohort", func() {
-			prodBEClusterQ = testing.MakeClusterQueue("prod-be-cq").
+			prodBEClusterQ = builder.MakeClusterQueue("prod-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Max("15").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Max("15").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodBEClusterQ)).Should(gomega.Succeed())
 
-			devBEClusterQ = testing.MakeClusterQueue("dev-be-cq").
+			devBEClusterQ = builder.MakeClusterQueue("dev-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Max("15").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Max("15").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, devBEClusterQ)).Should(gomega.Succeed())
 
-			prodBEQueue := testing.MakeLocalQueue("prod-be-queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
+			prodBEQueue := builder.MakeLocalQueue("prod-be-queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodBEQueue)).Should(gomega.Succeed())
 
-			devBEQueue := testing.MakeLocalQueue("dev-be-queue", ns.Name).ClusterQueue(devBEClusterQ.Name).Obj()
+			devBEQueue := builder.MakeLocalQueue("dev-be-queue", ns.Name).ClusterQueue(devBEClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, devBEQueue)).Should(gomega.Succeed())
-			wl1 := testing.MakeWorkload("wl-1", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "11").Obj()
-			wl2 := testing.MakeWorkload("wl-2", ns.Name).Queue(devBEQueue.Name).Request(corev1.ResourceCPU, "11").Obj()
+			wl1 := builder.MakeWorkload("wl-1", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "11").Obj()
+			wl2 := builder.MakeWorkload("wl-2", ns.Name).Queue(devBEQueue.Name).Request(corev1.ResourceCPU, "11").Obj()
 
 			ginkgo.By("Creating two workloads")
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
@@ -751,10 +751,10 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 			// Delay cluster queue creation to make sure workloads are in the same
 			// scheduling cycle.
-			testBEClusterQ := testing.MakeClusterQueue("test-be-cq").
+			testBEClusterQ := builder.MakeClusterQueue("test-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name,
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name,
 						"15").Max("15").Obj()).
 					Obj()).
 				Obj()
@@ -774,30 +774,30 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should start workloads that are under min quota before borrowing", func() {
-			prodBEClusterQ = testing.MakeClusterQueue("prod-be-cq").
+			prodBEClusterQ = builder.MakeClusterQueue("prod-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "1").Max("2").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "1").Max("2").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodBEClusterQ)).To(gomega.Succeed())
 
-			devBEClusterQ = testing.MakeClusterQueue("dev-be-cq").
+			devBEClusterQ = builder.MakeClusterQueue("dev-be-cq").
 				Cohort("be").
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "1").Max("2").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "1").Max("2").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, devBEClusterQ)).To(gomega.Succeed())
 
-			prodBEQueue := testing.MakeLocalQueue("prod-be-queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
+			prodBEQueue := builder.MakeLocalQueue("prod-be-queue", ns.Name).ClusterQueue(prodBEClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, prodBEQueue)).To(gomega.Succeed())
 
-			devBEQueue := testing.MakeLocalQueue("dev-be-queue", ns.Name).ClusterQueue(devBEClusterQ.Name).Obj()
+			devBEQueue := builder.MakeLocalQueue("dev-be-queue", ns.Name).ClusterQueue(devBEClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, devBEQueue)).To(gomega.Succeed())
 
-			pWl1 := testing.MakeWorkload("p-wl-1", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
-			pWl2 := testing.MakeWorkload("p-wl-2", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			pWl1 := builder.MakeWorkload("p-wl-1", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			pWl2 := builder.MakeWorkload("p-wl-2", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
 
 			ginkgo.By("Creating two workloads for first ClusterQueue")
 			gomega.Expect(k8sClient.Create(ctx, pWl1)).To(gomega.Succeed())
@@ -805,8 +805,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			framework.ExpectWorkloadsToBeAdmitted(ctx, k8sClient, prodBEClusterQ.Name, pWl1, pWl2)
 
 			ginkgo.By("Creating a workload for each ClusterQueue")
-			pWl3 := testing.MakeWorkload("p-wl-3", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
-			dWl1 := testing.MakeWorkload("d-wl-1", ns.Name).Queue(devBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			pWl3 := builder.MakeWorkload("p-wl-3", ns.Name).Queue(prodBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
+			dWl1 := builder.MakeWorkload("d-wl-1", ns.Name).Queue(devBEQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
 			gomega.Expect(k8sClient.Create(ctx, pWl3)).To(gomega.Succeed())
 			gomega.Expect(k8sClient.Create(ctx, dWl1)).To(gomega.Succeed())
 
@@ -829,7 +829,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.BeforeEach(func() {
 			gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
-			strictFIFOClusterQ = testing.MakeClusterQueue("strict-fifo-cq").
+			strictFIFOClusterQ = builder.MakeClusterQueue("strict-fifo-cq").
 				QueueingStrategy(kueue.StrictFIFO).
 				NamespaceSelector(&metav1.LabelSelector{
 					MatchExpressions: []metav1.LabelSelectorRequirement{
@@ -840,8 +840,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 						},
 					},
 				}).
-				Resource(testing.MakeResource(corev1.ResourceCPU).
-					Flavor(testing.MakeFlavor(onDemandFlavor.Name, "5").Max("5").Obj()).
+				Resource(builder.MakeResource(corev1.ResourceCPU).
+					Flavor(builder.MakeFlavor(onDemandFlavor.Name, "5").Max("5").Obj()).
 					Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, strictFIFOClusterQ)).Should(gomega.Succeed())
@@ -862,17 +862,17 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Should schedule workloads by their priority strictly", func() {
-			strictFIFOQueue := testing.MakeLocalQueue("strict-fifo-q", matchingNS.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
+			strictFIFOQueue := builder.MakeLocalQueue("strict-fifo-q", matchingNS.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
 
 			ginkgo.By("Creating workloads")
-			wl1 := testing.MakeWorkload("wl1", matchingNS.Name).Queue(strictFIFOQueue.
+			wl1 := builder.MakeWorkload("wl1", matchingNS.Name).Queue(strictFIFOQueue.
 				Name).Request(corev1.ResourceCPU, "2").Priority(pointer.Int32(100)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			wl2 := testing.MakeWorkload("wl2", matchingNS.Name).Queue(strictFIFOQueue.
+			wl2 := builder.MakeWorkload("wl2", matchingNS.Name).Queue(strictFIFOQueue.
 				Name).Request(corev1.ResourceCPU, "5").Priority(pointer.Int32(10)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			// wl3 can't be scheduled before wl2 even though there is enough quota.
-			wl3 := testing.MakeWorkload("wl3", matchingNS.Name).Queue(strictFIFOQueue.
+			wl3 := builder.MakeWorkload("wl3", matchingNS.Name).Queue(strictFIFOQueue.
 				Name).Request(corev1.ResourceCPU, "1").Priority(pointer.Int32(1)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl3)).Should(gomega.Succeed())
 
@@ -892,21 +892,21 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		ginkgo.It("Workloads not matching namespaceSelector should not block others", func() {
-			notMatchingQueue := testing.MakeLocalQueue("not-matching-queue", ns.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
+			notMatchingQueue := builder.MakeLocalQueue("not-matching-queue", ns.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, notMatchingQueue)).Should(gomega.Succeed())
 
-			matchingQueue := testing.MakeLocalQueue("matching-queue", matchingNS.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
+			matchingQueue := builder.MakeLocalQueue("matching-queue", matchingNS.Name).ClusterQueue(strictFIFOClusterQ.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, matchingQueue)).Should(gomega.Succeed())
 
 			ginkgo.By("Creating workloads")
-			wl1 := testing.MakeWorkload("wl1", matchingNS.Name).Queue(matchingQueue.
+			wl1 := builder.MakeWorkload("wl1", matchingNS.Name).Queue(matchingQueue.
 				Name).Request(corev1.ResourceCPU, "2").Priority(pointer.Int32(100)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
-			wl2 := testing.MakeWorkload("wl2", ns.Name).Queue(notMatchingQueue.
+			wl2 := builder.MakeWorkload("wl2", ns.Name).Queue(notMatchingQueue.
 				Name).Request(corev1.ResourceCPU, "5").Priority(pointer.Int32(10)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			// wl2 can't block wl3 from getting scheduled.
-			wl3 := testing.MakeWorkload("wl3", matchingNS.Name).Queue(matchingQueue.
+			wl3 := builder.MakeWorkload("wl3", matchingNS.Name).Queue(matchingQueue.
 				Name).Request(corev1.ResourceCPU, "1").Priority(pointer.Int32(1)).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl3)).Should(gomega.Succeed())
 
@@ -929,13 +929,13 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		ginkgo.It("Should not admit new created workloads", func() {
 			ginkgo.By("Create clusterQueue")
-			cq = testing.MakeClusterQueue("cluster-queue").Obj()
+			cq = builder.MakeClusterQueue("cluster-queue").Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
-			queue = testing.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
+			queue = builder.MakeLocalQueue("queue", ns.Name).ClusterQueue(cq.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, queue)).Should(gomega.Succeed())
 
 			ginkgo.By("New created workloads should be admitted")
-			wl1 := testing.MakeWorkload("workload1", ns.Name).Queue(queue.Name).Obj()
+			wl1 := builder.MakeWorkload("workload1", ns.Name).Queue(queue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
 			defer func() {
 				gomega.Expect(framework.DeleteWorkload(ctx, k8sClient, wl1)).To(gomega.Succeed())
@@ -953,7 +953,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal([]string{kueue.ResourceInUseFinalizerName}))
 
 			ginkgo.By("New created workloads should be frozen")
-			wl2 := testing.MakeWorkload("workload2", ns.Name).Queue(queue.Name).Obj()
+			wl2 := builder.MakeWorkload("workload2", ns.Name).Queue(queue.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 			defer func() {
 				gomega.Expect(framework.DeleteWorkload(ctx, k8sClient, wl2)).To(gomega.Succeed())
@@ -23,7 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	"sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
@@ -46,7 +46,7 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 	ginkgo.When("Creating a ClusterQueue", func() {
 		ginkgo.It("Should have a finalizer", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Obj()
+			cq := builder.MakeClusterQueue("cluster-queue").Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 			defer func() {
 				var deleteCQ kueue.ClusterQueue
@@ -63,8 +63,8 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have qualified resource names when creating", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Resource(
-				testing.MakeResource("@cpu").Obj(),
+			cq := builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("@cpu").Obj(),
 			).Obj()
 			err := k8sClient.Create(ctx, cq)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
@@ -73,7 +73,7 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have qualified resource names when updating", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Obj()
+			cq := builder.MakeClusterQueue("cluster-queue").Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 
 			defer func() {
@@ -87,7 +87,7 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 			var updateCQ kueue.ClusterQueue
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cq), &updateCQ)).Should(gomega.Succeed())
 			updateCQ.Spec.Resources = []kueue.Resource{
-				*testing.MakeResource("@cpu").Obj(),
+				*builder.MakeResource("@cpu").Obj(),
 			}
 
 			err := k8sClient.Update(ctx, &updateCQ)
@@ -97,8 +97,8 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have qualified flavor names when creating", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Resource(
-				testing.MakeResource("cpu").Flavor(testing.MakeFlavor("invalid_name", "5").Obj()).Obj(),
+			cq := builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("invalid_name", "5").Obj()).Obj(),
 			).Obj()
 			err := k8sClient.Create(ctx, cq)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
@@ -107,8 +107,8 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have qualified flavor names when creating", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Resource(
-				testing.MakeResource("cpu").Flavor(testing.MakeFlavor("x86", "5").Obj()).Obj(),
+			cq := builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "5").Obj()).Obj(),
 			).Obj()
 			gomega.Expect(k8sClient.Create(ctx, cq)).Should(gomega.Succeed())
 			defer func() {
@@ -130,8 +130,8 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have non-negative quota value when creating", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Resource(
-				testing.MakeResource("cpu").Flavor(testing.MakeFlavor("x86", "-1").Obj()).Obj(),
+			cq := builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "-1").Obj()).Obj(),
 			).Obj()
 			err := k8sClient.Create(ctx, cq)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
@@ -140,8 +140,8 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should have quota whose max value is greater than min", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").Resource(
-				testing.MakeResource("cpu").Flavor(testing.MakeFlavor("x86", "2").Max("1").Obj()).Obj(),
+			cq := builder.MakeClusterQueue("cluster-queue").Resource(
+				builder.MakeResource("cpu").Flavor(builder.MakeFlavor("x86", "2").Max("1").Obj()).Obj(),
 			).Obj()
 			err := k8sClient.Create(ctx, cq)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
@@ -150,7 +150,7 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should forbid clusterQueue creation with unsupported scoringStrategy", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").QueueingStrategy("unknown").Obj()
+			cq := builder.MakeClusterQueue("cluster-queue").QueueingStrategy("unknown").Obj()
 			err := k8sClient.Create(ctx, cq)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
 			gomega.Expect(errors.IsInvalid(err)).Should(gomega.BeTrue(), "error: %v", err)
@@ -158,7 +158,7 @@ var _ = ginkgo.Describe("ClusterQueue Webhook", func() {
 
 		ginkgo.It("Should forbid clusterQueue creation with unqualified labelSelector", func() {
 			ginkgo.By("Creating a new clusterQueue")
-			cq := testing.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
+			cq := builder.MakeClusterQueue("cluster-queue").NamespaceSelector(&metav1.LabelSelector{
 				MatchLabels: map[string]string{"nospecialchars^=@": "bar"},
 			}).Obj()
 			err := k8sClient.Create(ctx, cq)
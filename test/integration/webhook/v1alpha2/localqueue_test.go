@@ -20,7 +20,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	"sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
+	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
 const queueName = "queue-test"
@@ -29,7 +30,7 @@ var _ = ginkgo.Describe("Queue validating webhook", func() {
 	ginkgo.When("Updating a Queue", func() {
 		ginkgo.It("Should allow the change of status", func() {
 			ginkgo.By("Creating a new Queue")
-			obj := testing.MakeLocalQueue(queueName, ns.Name).ClusterQueue("foo").Obj()
+			obj := builder.MakeLocalQueue(queueName, ns.Name).ClusterQueue("foo").Obj()
 			gomega.Expect(k8sClient.Create(ctx, obj)).Should(gomega.Succeed())
 
 			ginkgo.By("Updating the Queue status")
@@ -40,16 +41,32 @@ var _ = ginkgo.Describe("Queue validating webhook", func() {
 			gomega.Expect(after.Status.PendingWorkloads).Should(gomega.Equal(int32(3)))
 		})
 
-		ginkgo.It("Should reject the change of spec.clusterQueue", func() {
+		ginkgo.It("Should reject the change of spec.clusterQueue while the Queue has workloads", func() {
 			ginkgo.By("Creating a new Queue")
-			obj := testing.MakeLocalQueue(queueName, ns.Name).ClusterQueue("foo").Obj()
+			obj := builder.MakeLocalQueue(queueName, ns.Name).ClusterQueue("foo").Obj()
+			gomega.Expect(k8sClient.Create(ctx, obj)).Should(gomega.Succeed())
+
+			ginkgo.By("Creating a Workload in the Queue")
+			wl := builder.MakeWorkload("wl", ns.Name).Queue(queueName).Obj()
+			gomega.Expect(k8sClient.Create(ctx, wl)).Should(gomega.Succeed())
+
+			ginkgo.By("Updating the Queue")
+			gomega.Eventually(func() bool {
+				var q kueue.LocalQueue
+				gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), &q)).Should(gomega.Succeed())
+				q.Spec.ClusterQueue = "bar"
+				return errors.IsForbidden(k8sClient.Update(ctx, &q))
+			}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+		})
+
+		ginkgo.It("Should allow the change of spec.clusterQueue while the Queue has no workloads", func() {
+			ginkgo.By("Creating a new Queue")
+			obj := builder.MakeLocalQueue(queueName, ns.Name).ClusterQueue("foo").Obj()
 			gomega.Expect(k8sClient.Create(ctx, obj)).Should(gomega.Succeed())
 
 			ginkgo.By("Updating the Queue")
 			obj.Spec.ClusterQueue = "bar"
-			err := k8sClient.Update(ctx, obj)
-			gomega.Expect(err).Should(gomega.HaveOccurred())
-			gomega.Expect(errors.IsForbidden(err)).Should(gomega.BeTrue(), "error: %v", err)
+			gomega.Expect(k8sClient.Update(ctx, obj)).Should(gomega.Succeed())
 		})
 	})
 })
@@ -23,7 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	"sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
@@ -46,7 +46,7 @@ var _ = ginkgo.Describe("ResourceFlavor Webhook", func() {
 	ginkgo.When("Creating a ResourceFlavor", func() {
 		ginkgo.It("Should have a finalizer", func() {
 			ginkgo.By("Creating a new resourceFlavor")
-			resourceFlavor := testing.MakeResourceFlavor("resource-flavor").Obj()
+			resourceFlavor := builder.MakeResourceFlavor("resource-flavor").Obj()
 			gomega.Expect(k8sClient.Create(ctx, resourceFlavor)).Should(gomega.Succeed())
 			defer func() {
 				var rf kueue.ResourceFlavor
@@ -65,7 +65,7 @@ var _ = ginkgo.Describe("ResourceFlavor Webhook", func() {
 	ginkgo.When("Creating a ResourceFlavor with invalid taints", func() {
 		ginkgo.It("Should fail to create", func() {
 			ginkgo.By("Creating a new resourceFlavor")
-			resourceFlavor := testing.MakeResourceFlavor("resource-flavor").Taint(corev1.Taint{
+			resourceFlavor := builder.MakeResourceFlavor("resource-flavor").Taint(corev1.Taint{
 				Key:    "@foo",
 				Value:  "bar",
 				Effect: corev1.TaintEffectNoSchedule,
@@ -79,7 +79,7 @@ var _ = ginkgo.Describe("ResourceFlavor Webhook", func() {
 	ginkgo.When("Updating a ResourceFlavor with invalid taints", func() {
 		ginkgo.It("Should fail to update", func() {
 			ginkgo.By("Creating a new resourceFlavor")
-			resourceFlavor := testing.MakeResourceFlavor("resource-flavor").Obj()
+			resourceFlavor := builder.MakeResourceFlavor("resource-flavor").Obj()
 			gomega.Expect(k8sClient.Create(ctx, resourceFlavor)).Should(gomega.Succeed())
 			defer func() {
 				var rf kueue.ResourceFlavor
@@ -22,7 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha2"
-	"sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/builder"
 	"sigs.k8s.io/kueue/test/integration/framework"
 )
 
@@ -77,7 +77,7 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 	ginkgo.Context("When creating a Workload", func() {
 		ginkgo.It("Should validate Workload", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).
+			workload := builder.MakeWorkload(workloadName, ns.Name).
 				PodSets([]kueue.PodSet{
 					{
 						Name:  "main",
@@ -105,7 +105,7 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 	ginkgo.Context("When updating a Workload", func() {
 		ginkgo.It("Should allow the change of priority", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).Obj()
+			workload := builder.MakeWorkload(workloadName, ns.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, workload)).Should(gomega.Succeed())
 
 			ginkgo.By("Updating the priority")
@@ -116,7 +116,7 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 
 		ginkgo.It("Should forbid the change of spec.podSet", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).Obj()
+			workload := builder.MakeWorkload(workloadName, ns.Name).Obj()
 			gomega.Expect(k8sClient.Create(ctx, workload)).Should(gomega.Succeed())
 
 			ginkgo.By("Updating podSet")
@@ -128,9 +128,9 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 
 		ginkgo.It("Should forbid the change of spec.queueName of an admitted workload", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).
+			workload := builder.MakeWorkload(workloadName, ns.Name).
 				Queue("queue1").
-				Admit(testing.MakeAdmission("cq").Obj()).
+				Admit(builder.MakeAdmission("cq").Obj()).
 				Obj()
 			gomega.Expect(k8sClient.Create(ctx, workload)).Should(gomega.Succeed())
 
@@ -143,8 +143,8 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 
 		ginkgo.It("Should forbid the change of spec.admission", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).Admit(
-				testing.MakeAdmission("cluster-queue").Obj(),
+			workload := builder.MakeWorkload(workloadName, ns.Name).Admit(
+				builder.MakeAdmission("cluster-queue").Obj(),
 			).Obj()
 			gomega.Expect(k8sClient.Create(ctx, workload)).Should(gomega.Succeed())
 
@@ -157,7 +157,7 @@ var _ = ginkgo.Describe("Workload validating webhook", func() {
 
 		ginkgo.It("Should have priority once priorityClassName is set", func() {
 			ginkgo.By("Creating a new Workload")
-			workload := testing.MakeWorkload(workloadName, ns.Name).PriorityClass("priority").Obj()
+			workload := builder.MakeWorkload(workloadName, ns.Name).PriorityClass("priority").Obj()
 			err := k8sClient.Create(ctx, workload)
 			gomega.Expect(err).Should(gomega.HaveOccurred())
 			gomega.Expect(errors.IsForbidden(err)).Should(gomega.BeTrue(), "error: %v", err)